@@ -0,0 +1,107 @@
+package gormkit
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type SessionInfo struct {
+	PID        int64
+	User       string
+	Database   string
+	ClientAddr string
+	State      string
+	Query      string
+	Duration   time.Duration
+}
+
+const postgresSessionsSQL = `
+SELECT pid,
+       COALESCE(usename, '') AS user_name,
+       COALESCE(datname, '') AS database_name,
+       COALESCE(client_addr::text, '') AS client_addr,
+       COALESCE(state, '') AS state,
+       COALESCE(query, '') AS query,
+       COALESCE(EXTRACT(EPOCH FROM now() - query_start), 0)::float8 AS seconds
+FROM pg_stat_activity
+WHERE pid <> pg_backend_pid() AND backend_type = 'client backend'
+ORDER BY seconds DESC`
+
+const mysqlSessionsSQL = `
+SELECT ID AS pid,
+       COALESCE(USER, '') AS user_name,
+       COALESCE(DB, '') AS database_name,
+       COALESCE(HOST, '') AS client_addr,
+       COALESCE(COMMAND, '') AS state,
+       COALESCE(INFO, '') AS query,
+       TIME AS seconds
+FROM information_schema.PROCESSLIST
+WHERE ID <> CONNECTION_ID()
+ORDER BY TIME DESC`
+
+type sessionRow struct {
+	PID          int64
+	UserName     string
+	DatabaseName string
+	ClientAddr   string
+	State        string
+	Query        string
+	Seconds      float64
+}
+
+// Sessions lists the server's client sessions other than the calling one,
+// longest-running first.
+func (m *Manager) Sessions(ctx context.Context) ([]SessionInfo, error) {
+	var query string
+	switch m.config.Driver {
+	case "postgres":
+		query = postgresSessionsSQL
+	case "mysql":
+		query = mysqlSessionsSQL
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedDriver, m.config.Driver)
+	}
+
+	rows, err := Query[sessionRow](ctx, m.db, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	sessions := make([]SessionInfo, len(rows))
+	for i, r := range rows {
+		sessions[i] = SessionInfo{
+			PID:        r.PID,
+			User:       r.UserName,
+			Database:   r.DatabaseName,
+			ClientAddr: r.ClientAddr,
+			State:      r.State,
+			Query:      r.Query,
+			Duration:   time.Duration(r.Seconds * float64(time.Second)),
+		}
+	}
+	return sessions, nil
+}
+
+// CancelSession cancels the statement currently running in session pid,
+// leaving the session itself connected.
+func (m *Manager) CancelSession(ctx context.Context, pid int64) error {
+	switch m.config.Driver {
+	case "postgres":
+		var cancelled bool
+		if err := m.sqlDB.QueryRowContext(ctx, "SELECT pg_cancel_backend($1)", pid).Scan(&cancelled); err != nil {
+			return fmt.Errorf("failed to cancel session %d: %w", pid, err)
+		}
+		if !cancelled {
+			return fmt.Errorf("session %d not found", pid)
+		}
+		return nil
+	case "mysql":
+		if _, err := m.sqlDB.ExecContext(ctx, fmt.Sprintf("KILL QUERY %d", pid)); err != nil {
+			return fmt.Errorf("failed to cancel session %d: %w", pid, err)
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w: %s", ErrUnsupportedDriver, m.config.Driver)
+	}
+}
@@ -0,0 +1,28 @@
+package gormkit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestSessionsUnsupportedOnSQLite(t *testing.T) {
+	manager, err := gormkit.New(&gormkit.Config{
+		Driver:   "test",
+		LogLevel: "silent",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	ctx := context.Background()
+	if _, err := manager.Sessions(ctx); !errors.Is(err, gormkit.ErrUnsupportedDriver) {
+		t.Errorf("Sessions: expected ErrUnsupportedDriver, got %v", err)
+	}
+	if err := manager.CancelSession(ctx, 1); !errors.Is(err, gormkit.ErrUnsupportedDriver) {
+		t.Errorf("CancelSession: expected ErrUnsupportedDriver, got %v", err)
+	}
+}
@@ -0,0 +1,159 @@
+package gormkit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const maxHealthChecks = 50
+
+// HealthCheck is the outcome of a readiness check.
+type HealthCheck struct {
+	Time  time.Time
+	Error string // "" when ready
+}
+
+// healthHistory keeps the latest readiness outcomes.
+type healthHistory struct {
+	mu     sync.Mutex
+	checks []HealthCheck
+}
+
+func (h *healthHistory) record(err error) {
+	check := HealthCheck{Time: time.Now()}
+	if err != nil {
+		check.Error = err.Error()
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.checks) == maxHealthChecks {
+		h.checks = append(h.checks[:0], h.checks[1:]...)
+	}
+	h.checks = append(h.checks, check)
+}
+
+// HealthHistory returns the outcomes of the latest readiness probes, newest
+// first.
+func (m *Manager) HealthHistory() []HealthCheck {
+	m.health.mu.Lock()
+	defer m.health.mu.Unlock()
+	checks := make([]HealthCheck, len(m.health.checks))
+	for i, check := range m.health.checks {
+		checks[len(checks)-1-i] = check
+	}
+	return checks
+}
+
+// AdminReport is what AdminHandler serves.
+type AdminReport struct {
+	Server        ServerInfo
+	Pool          sql.DBStats
+	Endpoints     []EndpointHealth
+	TopQueries    []QueryStats
+	SlowQueries   []SlowQuery
+	SchemaVersion string
+	Migrations    []SchemaMigration // newest first
+	Health        []HealthCheck
+}
+
+// AdminReport gathers pool stats, the top queries by total time, the slow
+// query log, applied migrations and the readiness history. Query stats need
+// Config.Metrics.
+func (m *Manager) AdminReport(ctx context.Context) (AdminReport, error) {
+	report := AdminReport{
+		Server:        m.ServerInfo(),
+		Pool:          m.Stats(),
+		Endpoints:     m.Endpoints(),
+		TopQueries:    m.TopQueries(20),
+		SlowQueries:   m.SlowQueries(),
+		SchemaVersion: m.SchemaVersion(),
+		Health:        m.HealthHistory(),
+	}
+	db := m.db.WithContext(withoutDeadlineAudit(ctx))
+	if db.Migrator().HasTable(&SchemaMigration{}) {
+		if err := db.Order("id DESC").Limit(50).Find(&report.Migrations).Error; err != nil {
+			return report, err
+		}
+	}
+	return report, nil
+}
+
+// AdminHandler serves a small read-only admin page of the AdminReport, or
+// the report as JSON when requested with ?format=json or an Accept header of
+// application/json. It shows query shapes, not values, but still reveals the
+// schema: mount it behind authentication on an internal port.
+//
+//	mux.Handle("/admin/db", requireAdmin(manager.AdminHandler()))
+func (m *Manager) AdminHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), defaultProbeTimeout)
+		defer cancel()
+		report, err := m.AdminReport(ctx)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if r.URL.Query().Get("format") == "json" || strings.Contains(r.Header.Get("Accept"), "application/json") {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(report)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		adminPage.Execute(w, report)
+	})
+}
+
+var adminPage = template.Must(template.New("admin").Parse(`<!DOCTYPE html>
+<html><head><meta charset="utf-8"><title>gormkit</title>
+<style>body{font-family:sans-serif;margin:2em}table{border-collapse:collapse;margin-bottom:2em}td,th{border:1px solid #ccc;padding:.3em .6em;text-align:left}code{font-size:.9em}</style>
+</head><body>
+<h1>{{.Server.Flavor}} {{.Server.Version}}</h1>
+
+<h2>Pool</h2>
+<table>
+<tr><th>Open</th><th>In use</th><th>Idle</th><th>Max open</th><th>Waits</th><th>Wait time</th></tr>
+<tr><td>{{.Pool.OpenConnections}}</td><td>{{.Pool.InUse}}</td><td>{{.Pool.Idle}}</td><td>{{.Pool.MaxOpenConnections}}</td><td>{{.Pool.WaitCount}}</td><td>{{.Pool.WaitDuration}}</td></tr>
+</table>
+{{with .Endpoints}}
+<h2>Endpoints</h2>
+<table>
+<tr><th>Address</th><th>Ejected</th><th>Error rate</th></tr>
+{{range .}}<tr><td>{{.Address}}</td><td>{{.Ejected}}</td><td>{{printf "%.2f" .ErrorRate}}</td></tr>
+{{end}}</table>
+{{end}}
+<h2>Top queries</h2>
+<table>
+<tr><th>Query</th><th>Count</th><th>Errors</th><th>Total</th><th>Max</th></tr>
+{{range .TopQueries}}<tr><td><code>{{.Fingerprint}}</code></td><td>{{.Count}}</td><td>{{.Errors}}</td><td>{{.Duration}}</td><td>{{.MaxDuration}}</td></tr>
+{{else}}<tr><td colspan="5">None recorded (Config.Metrics)</td></tr>
+{{end}}</table>
+
+<h2>Slow queries</h2>
+<table>
+<tr><th>Time</th><th>Query</th><th>Duration</th><th>Error</th></tr>
+{{range .SlowQueries}}<tr><td>{{.Time.Format "2006-01-02 15:04:05"}}</td><td><code>{{.Fingerprint}}</code></td><td>{{.Duration}}</td><td>{{.Error}}</td></tr>
+{{else}}<tr><td colspan="4">None</td></tr>
+{{end}}</table>
+
+<h2>Migrations</h2>
+{{with .SchemaVersion}}<p>Schema version {{.}}</p>{{end}}
+<table>
+<tr><th>ID</th><th>Applied</th></tr>
+{{range .Migrations}}<tr><td>{{.ID}}</td><td>{{.AppliedAt.Format "2006-01-02 15:04:05"}}</td></tr>
+{{else}}<tr><td colspan="2">None</td></tr>
+{{end}}</table>
+
+<h2>Readiness</h2>
+<table>
+<tr><th>Time</th><th>Status</th></tr>
+{{range .Health}}<tr><td>{{.Time.Format "2006-01-02 15:04:05"}}</td><td>{{if .Error}}{{.Error}}{{else}}ok{{end}}</td></tr>
+{{else}}<tr><td colspan="2">No readiness probes yet</td></tr>
+{{end}}</table>
+</body></html>
+`))
@@ -0,0 +1,69 @@
+package gormkit_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestAdminHandler(t *testing.T) {
+	manager, err := gormkit.New(&gormkit.Config{
+		Driver:        "test",
+		LogLevel:      "silent",
+		Metrics:       true,
+		SlowThreshold: time.Nanosecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	db := manager.DB()
+	db.AutoMigrate(&User{})
+	for _, name := range []string{"a", "b", "c"} {
+		db.Where("name = ?", name).Find(&[]User{})
+	}
+	err = manager.ApplyMigrations(context.Background(), gormkit.Migration{ID: "001_notes", Up: func(s *gormkit.Schema) {
+		s.Exec("CREATE TABLE notes (id INTEGER)")
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	manager.ReadinessProbe(gormkit.ReadinessOptions{}).ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/ready", nil))
+
+	rec := httptest.NewRecorder()
+	manager.AdminHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/admin?format=json", nil))
+	var report gormkit.AdminReport
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, q := range report.TopQueries {
+		if q.Fingerprint == "SELECT * FROM `users` WHERE name = ?" {
+			found = q.Count == 3
+		}
+	}
+	if !found {
+		t.Errorf("Expected the user lookups under one fingerprint, got %+v", report.TopQueries)
+	}
+	if len(report.SlowQueries) == 0 || report.SlowQueries[0].Time.Before(report.SlowQueries[len(report.SlowQueries)-1].Time) {
+		t.Errorf("Expected slow queries newest first, got %+v", report.SlowQueries)
+	}
+	if len(report.Migrations) != 1 || report.Migrations[0].ID != "001_notes" {
+		t.Errorf("Unexpected migrations: %+v", report.Migrations)
+	}
+	if len(report.Health) != 1 || report.Health[0].Error != "" {
+		t.Errorf("Expected one passed readiness check, got %+v", report.Health)
+	}
+
+	rec = httptest.NewRecorder()
+	manager.AdminHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/admin", nil))
+	if body := rec.Body.String(); !strings.Contains(body, "001_notes") || !strings.Contains(body, "<h2>Top queries</h2>") {
+		t.Errorf("Unexpected admin page: %s", body)
+	}
+}
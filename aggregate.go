@@ -0,0 +1,117 @@
+package gormkit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GroupCount counts the T rows of db, which may carry conditions, per value
+// of column. NULL is counted under "".
+//
+//	counts, err := gormkit.GroupCount[Order](ctx, db.Where("created_at > ?", since), "status")
+func GroupCount[T any](ctx context.Context, db *gorm.DB, column string) (map[string]int64, error) {
+	return groupBy[T, int64](ctx, db, column, "COUNT(*)")
+}
+
+// SumBy sums column of the T rows of db per value of groupColumn.
+//
+//	revenue, err := gormkit.SumBy[Order](ctx, db, "total", "currency")
+func SumBy[T any](ctx context.Context, db *gorm.DB, column, groupColumn string) (map[string]float64, error) {
+	return groupBy[T, float64](ctx, db, groupColumn, "COALESCE(SUM(?), 0)", clause.Column{Name: column})
+}
+
+func groupBy[T any, V int64 | float64](ctx context.Context, db *gorm.DB, column, aggregate string, vars ...interface{}) (map[string]V, error) {
+	group := clause.Column{Name: column}
+	rows, err := db.WithContext(ctx).Model(new(T)).
+		Select("?, "+aggregate, append([]interface{}{group}, vars...)...).
+		Group(db.Statement.Quote(group)).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	out := make(map[string]V)
+	for rows.Next() {
+		var key sql.NullString
+		var value V
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, err
+		}
+		out[key.String] += value
+	}
+	return out, rows.Err()
+}
+
+// HistogramBucket counts the rows of one time bucket.
+type HistogramBucket struct {
+	Start time.Time
+	Count int64
+}
+
+// Histogram counts the T rows of db per bucket of timeColumn between from
+// and to, e.g. signups per day. Buckets are aligned to the Unix epoch, so
+// day buckets start at midnight UTC, and empty buckets are included.
+//
+//	perDay, err := gormkit.Histogram[User](ctx, db, "created_at", 24*time.Hour, from, to)
+func Histogram[T any](ctx context.Context, db *gorm.DB, timeColumn string, bucket time.Duration, from, to time.Time) ([]HistogramBucket, error) {
+	seconds := int64(bucket / time.Second)
+	if seconds <= 0 {
+		return nil, fmt.Errorf("histogram bucket must be at least a second, got %v", bucket)
+	}
+	if !to.After(from) {
+		return nil, fmt.Errorf("histogram range must end after it starts, got %v to %v", from, to)
+	}
+	column := clause.Column{Name: timeColumn}
+	var epoch string
+	switch flavorOf(db) {
+	case "postgres":
+		epoch = "FLOOR(EXTRACT(EPOCH FROM ?) / ?)"
+	case "mysql":
+		epoch = "FLOOR(UNIX_TIMESTAMP(?) / ?)"
+	case "sqlite":
+		epoch = "CAST(strftime('%s', ?) AS INTEGER) / ?"
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedDriver, flavorOf(db))
+	}
+
+	rows, err := db.WithContext(ctx).Model(new(T)).
+		Select(epoch+" AS bucket, COUNT(*)", column, seconds).
+		Where(clause.Gte{Column: column, Value: from}).
+		Where(clause.Lt{Column: column, Value: to}).
+		Group("bucket").Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	counts := make(map[int64]int64)
+	for rows.Next() {
+		var b float64
+		var n int64
+		if err := rows.Scan(&b, &n); err != nil {
+			return nil, err
+		}
+		counts[int64(b)] += n
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	first, last := floorDiv(from.Unix(), seconds), floorDiv(to.Add(-time.Nanosecond).Unix(), seconds)
+	buckets := make([]HistogramBucket, 0, last-first+1)
+	for b := first; b <= last; b++ {
+		buckets = append(buckets, HistogramBucket{Start: time.Unix(b*seconds, 0).UTC(), Count: counts[b]})
+	}
+	return buckets, nil
+}
+
+func floorDiv(a, b int64) int64 {
+	q := a / b
+	if a%b != 0 && a < 0 {
+		q--
+	}
+	return q
+}
@@ -0,0 +1,83 @@
+package gormkit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+type sale struct {
+	ID        uint
+	Status    string
+	Region    *string
+	Total     float64
+	CreatedAt time.Time
+}
+
+func TestGroupCountAndSumBy(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &sale{})
+	db := manager.DB()
+	ctx := context.Background()
+	eu := "eu"
+	db.Create(&[]sale{
+		{Status: "paid", Region: &eu, Total: 10},
+		{Status: "paid", Region: &eu, Total: 5.5},
+		{Status: "refunded", Total: 3},
+	})
+
+	counts, err := gormkit.GroupCount[sale](ctx, db, "status")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(counts) != 2 || counts["paid"] != 2 || counts["refunded"] != 1 {
+		t.Errorf("Unexpected counts: %v", counts)
+	}
+
+	sums, err := gormkit.SumBy[sale](ctx, db.Where("status = ?", "paid"), "total", "region")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sums) != 1 || sums["eu"] != 15.5 {
+		t.Errorf("Unexpected sums: %v", sums)
+	}
+
+	byRegion, err := gormkit.GroupCount[sale](ctx, db, "region")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if byRegion["eu"] != 2 || byRegion[""] != 1 {
+		t.Errorf("Expected NULL under \"\", got %v", byRegion)
+	}
+}
+
+func TestHistogram(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &sale{})
+	db := manager.DB()
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	db.Create(&[]sale{
+		{Status: "paid", CreatedAt: day.Add(time.Hour)},
+		{Status: "paid", CreatedAt: day.Add(20 * time.Hour)},
+		{Status: "paid", CreatedAt: day.Add(50 * time.Hour)},
+		{Status: "paid", CreatedAt: day.Add(80 * time.Hour)}, // after the range
+	})
+
+	buckets, err := gormkit.Histogram[sale](context.Background(), db, "created_at", 24*time.Hour, day, day.Add(72*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []int64{2, 0, 1}
+	if len(buckets) != len(want) {
+		t.Fatalf("Expected %d buckets, got %+v", len(want), buckets)
+	}
+	for i, b := range buckets {
+		if !b.Start.Equal(day.Add(time.Duration(i)*24*time.Hour)) || b.Count != want[i] {
+			t.Errorf("Unexpected bucket %d: %+v", i, b)
+		}
+	}
+
+	if _, err := gormkit.Histogram[sale](context.Background(), db, "created_at", time.Hour, day, day.Add(-72*time.Hour)); err == nil {
+		t.Error("Expected a reversed range to be rejected")
+	}
+}
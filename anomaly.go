@@ -0,0 +1,119 @@
+package gormkit
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const tableEstimateTTL = time.Minute
+
+// RowCountLimits flags UPDATE and DELETE statements that touch suspiciously
+// many rows, e.g. a missing WHERE clause.
+type RowCountLimits struct {
+	// MaxRows flags statements affecting more rows than this.
+	MaxRows int64
+	// MaxTableFraction flags statements affecting more than this fraction of
+	// the table's estimated row count (0.5 = half the table). Tables smaller
+	// than MinTableRows, or without an estimate yet, are ignored.
+	MaxTableFraction float64
+	MinTableRows     int64
+
+	// OnAnomaly is called for every flagged statement, after it has run.
+	// Anomalies are logged and counted in metrics either way.
+	OnAnomaly func(ctx context.Context, a RowAnomaly)
+}
+
+// RowAnomaly describes a flagged statement.
+type RowAnomaly struct {
+	Table         string
+	Operation     string // update or delete
+	SQL           string
+	RowsAffected  int64
+	TableEstimate int64 // 0 when unknown
+}
+
+type tableEstimate struct {
+	rows int64
+	at   time.Time
+}
+
+type rowCountGuard struct {
+	manager   *Manager
+	limits    RowCountLimits
+	estimates sync.Map // table -> tableEstimate
+}
+
+func (g *rowCountGuard) check(db *gorm.DB) {
+	if db.Error != nil || db.RowsAffected <= 0 {
+		return
+	}
+	op := operationOf(db.Statement.SQL.String())
+	if op != "update" && op != "delete" {
+		return
+	}
+
+	a := RowAnomaly{
+		Table:        db.Statement.Table,
+		Operation:    op,
+		SQL:          db.Statement.SQL.String(),
+		RowsAffected: db.RowsAffected,
+	}
+	flagged := g.limits.MaxRows > 0 && a.RowsAffected > g.limits.MaxRows
+	if !flagged && g.limits.MaxTableFraction > 0 && a.Table != "" {
+		var known bool
+		a.TableEstimate, known = g.estimate(db, a.Table)
+		// The estimate may already exclude deleted rows, so never let it
+		// drop below what the statement touched.
+		total := max(a.TableEstimate, a.RowsAffected)
+		flagged = known && total >= g.limits.MinTableRows &&
+			float64(a.RowsAffected)/float64(total) > g.limits.MaxTableFraction
+	}
+	if !flagged {
+		return
+	}
+
+	log.Printf("gormkit: %s on %s affected %d rows (table estimate %d): %s",
+		a.Operation, a.Table, a.RowsAffected, a.TableEstimate, a.SQL)
+	g.manager.metrics.inc("gormkit_row_anomalies_total", "UPDATE and DELETE statements over RowCountLimits.",
+		"table", a.Table, "operation", a.Operation)
+	if g.limits.OnAnomaly != nil {
+		g.limits.OnAnomaly(db.Statement.Context, a)
+	}
+}
+
+// estimate returns the approximate row count of table, cached for a minute,
+// and whether it is known: Postgres has no estimate for a table that was
+// never analyzed. It runs on the statement's own connection, so it works
+// inside transactions.
+func (g *rowCountGuard) estimate(db *gorm.DB, table string) (int64, bool) {
+	if e, ok := g.estimates.Load(table); ok && time.Since(e.(tableEstimate).at) < tableEstimateTTL {
+		return e.(tableEstimate).rows, true
+	}
+
+	var query string
+	switch flavorOf(db) {
+	case "postgres":
+		query = "SELECT reltuples::bigint FROM pg_class WHERE oid = to_regclass($1)"
+	case "mysql", "mariadb":
+		query = "SELECT TABLE_ROWS FROM information_schema.TABLES WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ?"
+	case "sqlite":
+		query = "SELECT count(*) FROM " + quoteIdent(table)
+	default:
+		return 0, false
+	}
+
+	var args []interface{}
+	if flavorOf(db) != "sqlite" {
+		args = append(args, table)
+	}
+	var rows int64
+	if err := db.Statement.ConnPool.QueryRowContext(db.Statement.Context, query, args...).Scan(&rows); err != nil || rows < 0 {
+		return 0, false
+	}
+	g.estimates.Store(table, tableEstimate{rows: rows, at: time.Now()})
+	return rows, true
+}
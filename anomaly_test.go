@@ -0,0 +1,52 @@
+package gormkit_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestRowCountLimits(t *testing.T) {
+	var anomalies []gormkit.RowAnomaly
+	manager, err := gormkit.New(&gormkit.Config{
+		Driver:   "test",
+		LogLevel: "silent",
+		Metrics:  true,
+		RowCountLimits: &gormkit.RowCountLimits{
+			MaxRows:          50,
+			MaxTableFraction: 0.5,
+			MinTableRows:     4,
+			OnAnomaly: func(ctx context.Context, a gormkit.RowAnomaly) {
+				anomalies = append(anomalies, a)
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	db := manager.DB()
+	db.AutoMigrate(&User{})
+	for _, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		db.Create(&User{Name: name})
+	}
+
+	db.Model(&User{}).Where("name = ?", "a").Update("name", "z")
+	if len(anomalies) != 0 {
+		t.Fatalf("Expected targeted update to pass, got %+v", anomalies)
+	}
+
+	db.Model(&User{}).Where("1 = 1").Update("name", "x")
+	if len(anomalies) != 1 {
+		t.Fatalf("Expected mass update to be flagged, got %d", len(anomalies))
+	}
+	a := anomalies[0]
+	if a.Table != "users" || a.Operation != "update" || a.RowsAffected != 6 {
+		t.Errorf("Unexpected anomaly: %+v", a)
+	}
+	if got := manager.Metrics().Counters[`gormkit_row_anomalies_total{table="users",operation="update"}`]; got != 1 {
+		t.Errorf("Expected anomaly counter, got %d", got)
+	}
+}
@@ -0,0 +1,52 @@
+package gormkit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// Anonymizer replaces a column value on export, e.g. in ExportSeed.
+// Deterministic anonymizers map equal inputs to equal outputs, keeping
+// joins and uniqueness on the column intact.
+type Anonymizer func(value interface{}) interface{}
+
+// Redact replaces every non-NULL value with replacement.
+func Redact(replacement interface{}) Anonymizer {
+	return func(value interface{}) interface{} {
+		if value == nil {
+			return nil
+		}
+		return replacement
+	}
+}
+
+// HashValue replaces non-NULL values with a salted hash, deterministically.
+// Keep the salt secret, or short values can be recovered by brute force.
+func HashValue(salt string) Anonymizer {
+	return func(value interface{}) interface{} {
+		if value == nil {
+			return nil
+		}
+		return saltedHash(salt, value)
+	}
+}
+
+// AnonymizeEmail replaces non-NULL values with a deterministic address at
+// example.com, so unique constraints on the column still hold.
+func AnonymizeEmail(salt string) Anonymizer {
+	return func(value interface{}) interface{} {
+		if value == nil {
+			return nil
+		}
+		return "user-" + saltedHash(salt, value)[:16] + "@example.com"
+	}
+}
+
+func saltedHash(salt string, value interface{}) string {
+	if b, ok := value.([]byte); ok {
+		value = string(b)
+	}
+	sum := sha256.Sum256([]byte(salt + "\x00" + fmt.Sprint(value)))
+	return hex.EncodeToString(sum[:])
+}
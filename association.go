@@ -0,0 +1,143 @@
+package gormkit
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// SyncAssociation makes the many2many association name of owner hold
+// exactly desired, a slice of the associated model: it diffs the join
+// table rows against desired and, in one transaction, deletes and inserts
+// only the rows that differ, where gorm's Replace rewrites them all.
+// Records of desired not saved yet are created first. The owner's field is
+// set to desired.
+//
+//	err := gormkit.SyncAssociation(ctx, db, &post, "Tags", []Tag{go, sql})
+func SyncAssociation(ctx context.Context, db *gorm.DB, owner interface{}, name string, desired interface{}) error {
+	db = db.WithContext(ctx)
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(owner); err != nil {
+		return err
+	}
+	rel, ok := stmt.Schema.Relationships.Relations[name]
+	if !ok || rel.JoinTable == nil {
+		return fmt.Errorf("%s has no many2many association %s", stmt.Schema.Name, name)
+	}
+	values := reflect.ValueOf(desired)
+	if values.Kind() != reflect.Slice {
+		return fmt.Errorf("desired %s must be a slice, got %T", name, desired)
+	}
+	ownerValue := reflect.Indirect(reflect.ValueOf(owner))
+
+	// The join table's columns referencing the owner, with its key values,
+	// and the ones referencing the associated records.
+	ownerKeys := map[string]interface{}{}
+	var assocRefs []*schema.Reference
+	for _, ref := range rel.References {
+		if ref.OwnPrimaryKey {
+			value, zero := ref.PrimaryKey.ValueOf(ctx, ownerValue)
+			if zero {
+				return fmt.Errorf("%s must be saved before syncing %s", stmt.Schema.Name, name)
+			}
+			ownerKeys[ref.ForeignKey.DBName] = value
+		} else {
+			assocRefs = append(assocRefs, ref)
+		}
+	}
+	assocColumns := make([]string, len(assocRefs))
+	for i, ref := range assocRefs {
+		assocColumns[i] = ref.ForeignKey.DBName
+	}
+	join := rel.JoinTable.Table
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		want := map[string][]interface{}{}
+		for i := 0; i < values.Len(); i++ {
+			record := reflect.Indirect(values.Index(i))
+			if _, zero := assocRefs[0].PrimaryKey.ValueOf(ctx, record); zero {
+				if err := tx.Create(record.Addr().Interface()).Error; err != nil {
+					return err
+				}
+			}
+			key := make([]interface{}, len(assocRefs))
+			for j, ref := range assocRefs {
+				key[j], _ = ref.PrimaryKey.ValueOf(ctx, record)
+			}
+			want[assocKey(key)] = key
+		}
+
+		var rows []map[string]interface{}
+		if err := tx.Table(join).Select(assocColumns).Where(ownerKeys).Find(&rows).Error; err != nil {
+			return err
+		}
+		var removed [][]interface{}
+		for _, row := range rows {
+			key := make([]interface{}, len(assocColumns))
+			for j, column := range assocColumns {
+				key[j] = row[column]
+			}
+			if _, ok := want[assocKey(key)]; ok {
+				delete(want, assocKey(key))
+			} else {
+				removed = append(removed, key)
+			}
+		}
+
+		if err := InChunks(tx, removed, func(chunk [][]interface{}) error {
+			in := clause.IN{Column: clause.Column{Name: assocColumns[0]}}
+			if len(assocColumns) > 1 {
+				columns := make([]clause.Column, len(assocColumns))
+				for i, column := range assocColumns {
+					columns[i] = clause.Column{Name: column}
+				}
+				in.Column = columns
+			}
+			for _, key := range chunk {
+				if len(key) == 1 {
+					in.Values = append(in.Values, key[0])
+				} else {
+					in.Values = append(in.Values, key)
+				}
+			}
+			return tx.Table(join).Where(ownerKeys).Where(in).Delete(map[string]interface{}{}).Error
+		}); err != nil {
+			return err
+		}
+
+		if len(want) > 0 {
+			added := make([]map[string]interface{}, 0, len(want))
+			for _, key := range want {
+				row := make(map[string]interface{}, len(ownerKeys)+len(key))
+				for column, value := range ownerKeys {
+					row[column] = value
+				}
+				for j, column := range assocColumns {
+					row[column] = key[j]
+				}
+				added = append(added, row)
+			}
+			if err := tx.Table(join).CreateInBatches(added, 500).Error; err != nil {
+				return err
+			}
+		}
+
+		return rel.Field.Set(ctx, ownerValue, desired)
+	})
+}
+
+// assocKey identifies a join row by its association columns. Values are
+// normalized with idKey, so the []byte MySQL returns matches the int or
+// string of the record, and joined with NUL so composite keys can't collide.
+func assocKey(key []interface{}) string {
+	parts := make([]string, len(key))
+	for i, value := range key {
+		parts[i] = idKey(value)
+	}
+	return strings.Join(parts, "\x00")
+}
@@ -0,0 +1,78 @@
+package gormkit_test
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+	"gorm.io/gorm"
+)
+
+type tag struct {
+	ID   uint
+	Name string
+}
+
+type post struct {
+	ID    uint
+	Title string
+	Tags  []tag `gorm:"many2many:post_tags"`
+}
+
+func postTags(t *testing.T, db *gorm.DB, p post) string {
+	t.Helper()
+	var tags []tag
+	if err := db.Model(&p).Association("Tags").Find(&tags); err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, tg := range tags {
+		got = append(got, tg.Name)
+	}
+	sort.Strings(got)
+	return fmt.Sprint(got)
+}
+
+func TestSyncAssociation(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &tag{}, &post{})
+	db := manager.DB()
+	ctx := context.Background()
+
+	golang, sql, orm := tag{Name: "go"}, tag{Name: "sql"}, tag{Name: "orm"}
+	db.Create(&[]*tag{&golang, &sql, &orm})
+	p := post{Title: "hello", Tags: []tag{golang, sql}}
+	db.Create(&p)
+
+	// gorm's Replace deletes and reinserts every row; a kept row keeps its
+	// rowid here.
+	rowid := func() (id int64) {
+		db.Raw("SELECT rowid FROM post_tags WHERE tag_id = ?", sql.ID).Scan(&id)
+		return id
+	}
+	kept := rowid()
+
+	if err := gormkit.SyncAssociation(ctx, db, &p, "Tags", []tag{sql, orm, {Name: "new"}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := postTags(t, db, p); got != "[new orm sql]" {
+		t.Errorf("Expected [new orm sql], got %s", got)
+	}
+	if id := rowid(); id != kept {
+		t.Errorf("Expected the kept row to stay in place, rowid %d became %d", kept, id)
+	}
+	if len(p.Tags) != 3 {
+		t.Errorf("Expected the field to be set, got %+v", p.Tags)
+	}
+
+	if err := gormkit.SyncAssociation(ctx, db, &p, "Tags", []tag{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := postTags(t, db, p); got != "[]" {
+		t.Errorf("Expected no tags, got %s", got)
+	}
+	if err := gormkit.SyncAssociation(ctx, db, &p, "Title", []tag{}); err == nil {
+		t.Error("Expected an error for a field that isn't a many2many association")
+	}
+}
@@ -0,0 +1,266 @@
+package gormkit
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// attachmentChunkSize is the size of the rows database-stored attachment
+// content is split into, so neither writes nor reads hold a whole file.
+const attachmentChunkSize = 256 << 10
+
+// Attachment is the metadata of a stored file, kept in gormkit_attachments
+// whichever BlobStore holds its content.
+type Attachment struct {
+	ID          string `gorm:"primaryKey;size:32"`
+	Name        string `gorm:"size:255"`
+	ContentType string `gorm:"size:255"`
+	Size        int64
+	SHA256      string `gorm:"column:sha256;size:64"`
+	CreatedAt   time.Time
+}
+
+func (Attachment) TableName() string {
+	return "gormkit_attachments"
+}
+
+// AttachmentChunk is one piece of an attachment's content stored in the
+// database.
+type AttachmentChunk struct {
+	AttachmentID string `gorm:"primaryKey;size:32"`
+	Seq          int    `gorm:"primaryKey;autoIncrement:false"`
+	Data         []byte
+}
+
+func (AttachmentChunk) TableName() string {
+	return "gormkit_attachment_chunks"
+}
+
+// BlobStore holds attachment content by key. Implementations must stream:
+// Put reads r to the end without buffering it whole where the backend
+// allows, and Get returns content as it is read. gormkits3 and gormkitgcs
+// provide stores for S3 and Google Cloud Storage.
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// Attachments stores files: metadata in gormkit_attachments, content in a
+// BlobStore.
+type Attachments struct {
+	manager *Manager
+	store   BlobStore
+}
+
+// Attachments returns a file store keeping content in store, or in the
+// database (gormkit_attachment_chunks, a bytea/blob column) when store is
+// nil.
+//
+//	files := manager.Attachments(gormkits3.New(client, "uploads"))
+//	att, err := files.Create(ctx, header.Filename, header.Header.Get("Content-Type"), file)
+func (m *Manager) Attachments(store BlobStore) *Attachments {
+	if store == nil {
+		store = &databaseBlobs{manager: m}
+	}
+	return &Attachments{manager: m, store: store}
+}
+
+// Create streams r into the store and records its metadata, with Size and
+// SHA256 computed while copying. Content is written before metadata, so a
+// crash between the two leaves an unreferenced blob but never an
+// attachment without content.
+func (a *Attachments) Create(ctx context.Context, name, contentType string, r io.Reader) (*Attachment, error) {
+	db := a.manager.db.WithContext(ctx)
+	if err := ensureTable(db, &Attachment{}); err != nil {
+		return nil, err
+	}
+	id, err := newAttachmentID()
+	if err != nil {
+		return nil, err
+	}
+	counter := &countingReader{r: r, hash: sha256.New()}
+	if err := a.store.Put(ctx, id, counter); err != nil {
+		a.store.Delete(ctx, id)
+		return nil, fmt.Errorf("failed to store attachment %s: %w", name, err)
+	}
+	att := &Attachment{
+		ID:          id,
+		Name:        name,
+		ContentType: contentType,
+		Size:        counter.n,
+		SHA256:      hex.EncodeToString(counter.hash.Sum(nil)),
+		CreatedAt:   db.NowFunc(),
+	}
+	if err := db.Create(att).Error; err != nil {
+		a.store.Delete(ctx, id)
+		return nil, err
+	}
+	return att, nil
+}
+
+// Get returns the metadata of attachment id, or gorm.ErrRecordNotFound.
+func (a *Attachments) Get(ctx context.Context, id string) (*Attachment, error) {
+	db := a.manager.db.WithContext(ctx)
+	if err := ensureTable(db, &Attachment{}); err != nil {
+		return nil, err
+	}
+	var att Attachment
+	if err := db.Where("id = ?", id).Take(&att).Error; err != nil {
+		return nil, err
+	}
+	return &att, nil
+}
+
+// Open returns the metadata of attachment id and a reader streaming its
+// content, which the caller must close.
+//
+//	body, att, err := files.Open(ctx, id)
+//	defer body.Close()
+//	w.Header().Set("Content-Type", att.ContentType)
+//	io.Copy(w, body)
+func (a *Attachments) Open(ctx context.Context, id string) (io.ReadCloser, *Attachment, error) {
+	att, err := a.Get(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	body, err := a.store.Get(ctx, id)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open attachment %s: %w", id, err)
+	}
+	return body, att, nil
+}
+
+// Delete removes attachment id and its content. Metadata goes first, so a
+// failed content delete leaves an unreferenced blob rather than a dangling
+// attachment.
+func (a *Attachments) Delete(ctx context.Context, id string) error {
+	db := a.manager.db.WithContext(ctx)
+	if err := ensureTable(db, &Attachment{}); err != nil {
+		return err
+	}
+	result := db.Where("id = ?", id).Delete(&Attachment{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	if err := a.store.Delete(ctx, id); err != nil {
+		return fmt.Errorf("failed to delete attachment %s content: %w", id, err)
+	}
+	return nil
+}
+
+func newAttachmentID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// countingReader measures and hashes what is read through it.
+type countingReader struct {
+	r    io.Reader
+	n    int64
+	hash hash.Hash
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	c.hash.Write(p[:n])
+	return n, err
+}
+
+// databaseBlobs is the BlobStore keeping content in the database as
+// attachmentChunkSize rows.
+type databaseBlobs struct {
+	manager *Manager
+}
+
+func (s *databaseBlobs) Put(ctx context.Context, key string, r io.Reader) error {
+	db := s.manager.db.WithContext(ctx)
+	if err := ensureTable(db, &AttachmentChunk{}); err != nil {
+		return err
+	}
+	buf := make([]byte, attachmentChunkSize)
+	for seq := 0; ; seq++ {
+		n, err := io.ReadFull(r, buf)
+		if n > 0 {
+			chunk := &AttachmentChunk{AttachmentID: key, Seq: seq, Data: buf[:n]}
+			if err := db.Create(chunk).Error; err != nil {
+				return err
+			}
+		}
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (s *databaseBlobs) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	db := s.manager.db.WithContext(ctx)
+	if err := ensureTable(db, &AttachmentChunk{}); err != nil {
+		return nil, err
+	}
+	return &chunkReader{db: db, key: key}, nil
+}
+
+func (s *databaseBlobs) Delete(ctx context.Context, key string) error {
+	db := s.manager.db.WithContext(ctx)
+	if err := ensureTable(db, &AttachmentChunk{}); err != nil {
+		return err
+	}
+	return db.Where("attachment_id = ?", key).Delete(&AttachmentChunk{}).Error
+}
+
+// chunkReader loads one chunk at a time as it is read.
+type chunkReader struct {
+	db   *gorm.DB
+	key  string
+	seq  int
+	data []byte
+	done bool
+}
+
+func (c *chunkReader) Read(p []byte) (int, error) {
+	for len(c.data) == 0 {
+		if c.done {
+			return 0, io.EOF
+		}
+		var chunk AttachmentChunk
+		result := c.db.Where("attachment_id = ? AND seq = ?", c.key, c.seq).Limit(1).Find(&chunk)
+		if result.Error != nil {
+			return 0, result.Error
+		}
+		if result.RowsAffected == 0 {
+			c.done = true
+			continue
+		}
+		c.data = chunk.Data
+		c.seq++
+	}
+	n := copy(p, c.data)
+	c.data = c.data[n:]
+	return n, nil
+}
+
+func (c *chunkReader) Close() error {
+	c.done = true
+	c.data = nil
+	return nil
+}
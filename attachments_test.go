@@ -0,0 +1,153 @@
+package gormkit_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+	"gorm.io/gorm"
+)
+
+type memoryBlobs struct {
+	mu    sync.Mutex
+	blobs map[string][]byte
+}
+
+func (s *memoryBlobs) Put(ctx context.Context, key string, r io.Reader) error {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blobs[key] = data
+	return nil
+}
+
+func (s *memoryBlobs) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.blobs[key]
+	if !ok {
+		return nil, errors.New("no such blob")
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+func (s *memoryBlobs) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.blobs, key)
+	return nil
+}
+
+func TestAttachmentsInDatabase(t *testing.T) {
+	manager := gormkit.NewTestManager(t)
+	files := manager.Attachments(nil)
+	ctx := context.Background()
+
+	// Larger than one chunk, so it is split across rows.
+	content := bytes.Repeat([]byte("0123456789abcdef"), 40000)
+	att, err := files.Create(ctx, "report.bin", "application/octet-stream", bytes.NewReader(content))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(content)
+	if att.Size != int64(len(content)) || att.SHA256 != hex.EncodeToString(sum[:]) {
+		t.Errorf("Expected size %d and matching hash, got %+v", len(content), att)
+	}
+
+	var chunks int64
+	manager.DB().Table("gormkit_attachment_chunks").Where("attachment_id = ?", att.ID).Count(&chunks)
+	if chunks != 3 {
+		t.Errorf("Expected 3 chunks, got %d", chunks)
+	}
+
+	body, got, err := files.Open(ctx, att.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(body)
+	body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, content) {
+		t.Errorf("Expected %d bytes back, got %d", len(content), len(data))
+	}
+	if got.Name != "report.bin" || got.ContentType != "application/octet-stream" {
+		t.Errorf("Unexpected metadata %+v", got)
+	}
+
+	if err := files.Delete(ctx, att.ID); err != nil {
+		t.Fatal(err)
+	}
+	manager.DB().Table("gormkit_attachment_chunks").Where("attachment_id = ?", att.ID).Count(&chunks)
+	if chunks != 0 {
+		t.Errorf("Expected chunks to be deleted, got %d", chunks)
+	}
+	if _, _, err := files.Open(ctx, att.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected ErrRecordNotFound, got %v", err)
+	}
+}
+
+func TestAttachmentsEmptyFile(t *testing.T) {
+	manager := gormkit.NewTestManager(t)
+	files := manager.Attachments(nil)
+	ctx := context.Background()
+
+	att, err := files.Create(ctx, "empty.txt", "text/plain", strings.NewReader(""))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _, err := files.Open(ctx, att.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer body.Close()
+	if data, _ := io.ReadAll(body); len(data) != 0 || att.Size != 0 {
+		t.Errorf("Expected an empty attachment, got %d bytes", len(data))
+	}
+}
+
+func TestAttachmentsExternalStore(t *testing.T) {
+	manager := gormkit.NewTestManager(t)
+	store := &memoryBlobs{blobs: map[string][]byte{}}
+	files := manager.Attachments(store)
+	ctx := context.Background()
+
+	att, err := files.Create(ctx, "avatar.png", "image/png", strings.NewReader("png bytes"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(store.blobs[att.ID]) != "png bytes" {
+		t.Errorf("Expected content in the external store, got %q", store.blobs[att.ID])
+	}
+
+	body, _, err := files.Open(ctx, att.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(body)
+	body.Close()
+	if string(data) != "png bytes" {
+		t.Errorf("Expected png bytes, got %q", data)
+	}
+
+	if err := files.Delete(ctx, att.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store.blobs[att.ID]; ok {
+		t.Error("Expected blob to be deleted")
+	}
+	if err := files.Delete(ctx, att.ID); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected ErrRecordNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,202 @@
+package gormkit
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// BatchOptions configures a BatchWriter.
+type BatchOptions[T any] struct {
+	Size          int           // rows per insert, default 500
+	FlushInterval time.Duration // flush partial batches this often, default 1s
+	MaxAttempts   int           // inserts of a batch before its rows are dropped, default 3
+
+	// Stripes splits rows into this many independent batches, each flushed
+	// in its own transaction, so concurrent flushes don't contend for the
+	// same index pages and locks. Default 1.
+	Stripes int
+	// StripeKey picks a row's stripe, e.g. its tenant or primary key, so
+	// rows sharing a key stay ordered in one stripe. Without it rows are
+	// spread round-robin.
+	StripeKey func(row *T) string
+
+	// OnError receives errors of background flushes; they are logged
+	// otherwise.
+	OnError func(err error)
+}
+
+// BatchWriter buffers rows of T and inserts them in batches, for
+// high-volume writes such as events or metrics where one insert per row is
+// too slow. Batches are flushed when full, every FlushInterval, and on
+// Flush, Close and Manager close.
+type BatchWriter[T any] struct {
+	manager *Manager
+	opts    BatchOptions[T]
+	stripes []*batchStripe[T]
+	next    atomic.Uint64
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+type batchStripe[T any] struct {
+	flushMu  sync.Mutex // serializes flushes, keeping the stripe's rows in order
+	attempts int        // failed inserts of the rows at the head, under flushMu
+	mu       sync.Mutex
+	rows     []T
+}
+
+// NewBatchWriter returns a writer inserting T through m.
+//
+//	w := gormkit.NewBatchWriter(manager, gormkit.BatchOptions[Event]{
+//		Stripes:   8,
+//		StripeKey: func(e *Event) string { return e.TenantID },
+//	})
+//	err := w.Write(ctx, event)
+func NewBatchWriter[T any](m *Manager, opts BatchOptions[T]) *BatchWriter[T] {
+	if opts.Size <= 0 {
+		opts.Size = 500
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = time.Second
+	}
+	if opts.Stripes <= 0 {
+		opts.Stripes = 1
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 3
+	}
+	w := &BatchWriter[T]{
+		manager: m,
+		opts:    opts,
+		stripes: make([]*batchStripe[T], opts.Stripes),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	for i := range w.stripes {
+		w.stripes[i] = &batchStripe[T]{}
+	}
+	go w.run()
+	m.onClose(func() { w.Close() })
+	return w
+}
+
+// Write buffers rows. When a stripe fills up, its batch is inserted before
+// Write returns, so callers feel backpressure and see insert errors. Rows of
+// a failed insert stay buffered for the next flush, until MaxAttempts
+// inserts have failed and they are dropped, so a row the database rejects
+// doesn't block the rows behind it.
+func (w *BatchWriter[T]) Write(ctx context.Context, rows ...T) error {
+	full := make(map[*batchStripe[T]]bool)
+	for i := range rows {
+		s := w.stripe(&rows[i])
+		s.mu.Lock()
+		s.rows = append(s.rows, rows[i])
+		if len(s.rows) >= w.opts.Size {
+			full[s] = true
+		}
+		s.mu.Unlock()
+	}
+	for s := range full {
+		if err := w.flushStripe(ctx, s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Flush inserts all buffered rows, one transaction per stripe, with the
+// stripes flushed concurrently.
+func (w *BatchWriter[T]) Flush(ctx context.Context) error {
+	errs := make([]error, len(w.stripes))
+	var wg sync.WaitGroup
+	for i, s := range w.stripes {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs[i] = w.flushStripe(ctx, s)
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close stops the background flushes and flushes the remaining rows.
+func (w *BatchWriter[T]) Close() error {
+	var err error
+	w.closeOnce.Do(func() {
+		close(w.stop)
+		<-w.done
+		err = w.Flush(withoutDeadlineAudit(context.Background()))
+	})
+	return err
+}
+
+func (w *BatchWriter[T]) stripe(row *T) *batchStripe[T] {
+	if len(w.stripes) == 1 {
+		return w.stripes[0]
+	}
+	if w.opts.StripeKey == nil {
+		return w.stripes[w.next.Add(1)%uint64(len(w.stripes))]
+	}
+	h := fnv.New32a()
+	h.Write([]byte(w.opts.StripeKey(row)))
+	return w.stripes[h.Sum32()%uint32(len(w.stripes))]
+}
+
+func (w *BatchWriter[T]) flushStripe(ctx context.Context, s *batchStripe[T]) error {
+	s.flushMu.Lock()
+	defer s.flushMu.Unlock()
+	s.mu.Lock()
+	rows := s.rows
+	s.rows = nil
+	s.mu.Unlock()
+	if len(rows) == 0 {
+		return nil
+	}
+	if err := w.manager.db.WithContext(ctx).CreateInBatches(&rows, w.opts.Size).Error; err != nil {
+		if s.attempts++; s.attempts >= w.opts.MaxAttempts {
+			s.attempts = 0
+			return fmt.Errorf("batch insert failed %d times, dropped %d rows: %w", w.opts.MaxAttempts, len(rows), err)
+		}
+		// Put the rows back ahead of any written since, so the next flush
+		// retries them in order.
+		s.mu.Lock()
+		s.rows = append(rows, s.rows...)
+		s.mu.Unlock()
+		return err
+	}
+	s.attempts = 0
+	return nil
+}
+
+func (w *BatchWriter[T]) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.opts.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			if err := w.Flush(withoutDeadlineAudit(context.Background())); err != nil {
+				if w.opts.OnError != nil {
+					w.opts.OnError(err)
+				} else {
+					log.Printf("gormkit: batch flush failed: %v", err)
+				}
+			}
+		}
+	}
+}
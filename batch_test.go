@@ -0,0 +1,114 @@
+package gormkit_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestBatchWriterStripes(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &User{})
+	w := gormkit.NewBatchWriter(manager, gormkit.BatchOptions[User]{
+		Size:          10,
+		FlushInterval: time.Hour,
+		Stripes:       4,
+		StripeKey:     func(u *User) string { return u.Name[:1] },
+	})
+	ctx := context.Background()
+
+	var users []User
+	for i := 0; i < 25; i++ {
+		users = append(users, User{Name: fmt.Sprintf("%c-%d", 'a'+i%3, i)})
+	}
+	if err := w.Write(ctx, users...); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var count int64
+	manager.DB().Model(&User{}).Count(&count)
+	if count != 25 {
+		t.Errorf("Expected 25 users, got %d", count)
+	}
+
+	// Rows sharing a stripe key keep their order.
+	var names []string
+	manager.DB().Model(&User{}).Where("name LIKE ?", "a-%").Order("id").Pluck("name", &names)
+	for i, name := range names {
+		if want := fmt.Sprintf("a-%d", i*3); name != want {
+			t.Errorf("Expected %s at %d, got %s", want, i, name)
+		}
+	}
+}
+
+func TestBatchWriterInterval(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &User{})
+	gormkit.NewBatchWriter(manager, gormkit.BatchOptions[User]{FlushInterval: 10 * time.Millisecond}).
+		Write(context.Background(), User{Name: "alice"})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		var count int64
+		manager.DB().Model(&User{}).Count(&count)
+		if count == 1 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the interval to flush the row")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestBatchWriterDeadlineAudit(t *testing.T) {
+	manager, err := gormkit.New(&gormkit.Config{Driver: "test", LogLevel: "silent", DeadlineAudit: "error"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	manager.DB().WithContext(ctx).AutoMigrate(&User{})
+
+	w := gormkit.NewBatchWriter(manager, gormkit.BatchOptions[User]{FlushInterval: time.Hour})
+	w.Write(ctx, User{Name: "alice"}, User{Name: "bob"})
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	var count int64
+	manager.DB().WithContext(ctx).Model(&User{}).Count(&count)
+	if count != 2 {
+		t.Errorf("Expected the background flush to write 2 users, got %d", count)
+	}
+}
+
+func TestBatchWriterDropsFailingRows(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &User{})
+	ctx := context.Background()
+	manager.DB().Create(&User{ID: 1, Name: "alice"})
+
+	w := gormkit.NewBatchWriter(manager, gormkit.BatchOptions[User]{FlushInterval: time.Hour, MaxAttempts: 2})
+	w.Write(ctx, User{ID: 1, Name: "duplicate"})
+	if err := w.Flush(ctx); err == nil {
+		t.Fatal("Expected the duplicate to fail")
+	}
+	if err := w.Flush(ctx); err == nil || !strings.Contains(err.Error(), "dropped 1 rows") {
+		t.Fatalf("Expected the duplicate to be dropped, got %v", err)
+	}
+
+	w.Write(ctx, User{Name: "bob"})
+	if err := w.Flush(ctx); err != nil {
+		t.Fatalf("Expected the stripe to flush again, got %v", err)
+	}
+	var count int64
+	manager.DB().Model(&User{}).Count(&count)
+	if count != 2 {
+		t.Errorf("Expected 2 users, got %d", count)
+	}
+}
@@ -0,0 +1,83 @@
+package gormkit
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const budgetStartKey = "gormkit:budget_start"
+
+type queryBudget struct {
+	maxQueries int64
+	maxTime    time.Duration
+
+	queries atomic.Int64
+	elapsed atomic.Int64
+	warned  atomic.Bool
+}
+
+// WithQueryBudget returns a context that allows at most maxQueries statements
+// taking maxTotalTime in total. A zero limit is not enforced. Depending on
+// Config.QueryBudgetMode, exceeding the budget fails the statement with
+// ErrQueryBudgetExceeded or logs a single warning.
+func WithQueryBudget(ctx context.Context, maxQueries int, maxTotalTime time.Duration) context.Context {
+	return context.WithValue(ctx, queryBudgetKey, &queryBudget{
+		maxQueries: int64(maxQueries),
+		maxTime:    maxTotalTime,
+	})
+}
+
+// QueryBudgetUsage reports how many statements ran under ctx's budget and
+// how long they took in total.
+func QueryBudgetUsage(ctx context.Context) (queries int, elapsed time.Duration) {
+	b, ok := ctx.Value(queryBudgetKey).(*queryBudget)
+	if !ok {
+		return 0, 0
+	}
+	return int(b.queries.Load()), time.Duration(b.elapsed.Load())
+}
+
+func (b *queryBudget) exceeded(queries int64) error {
+	if b.maxQueries > 0 && queries > b.maxQueries {
+		return fmt.Errorf("%w: %d queries (max %d)", ErrQueryBudgetExceeded, queries, b.maxQueries)
+	}
+	if elapsed := time.Duration(b.elapsed.Load()); b.maxTime > 0 && elapsed >= b.maxTime {
+		return fmt.Errorf("%w: %v spent (max %v)", ErrQueryBudgetExceeded, elapsed, b.maxTime)
+	}
+	return nil
+}
+
+func (m *Manager) beforeQueryBudget(db *gorm.DB) {
+	b, ok := db.Statement.Context.Value(queryBudgetKey).(*queryBudget)
+	if !ok {
+		return
+	}
+	db.InstanceSet(budgetStartKey, time.Now())
+
+	err := b.exceeded(b.queries.Add(1))
+	if err == nil {
+		return
+	}
+	if m.config.QueryBudgetMode == "warn" {
+		if b.warned.CompareAndSwap(false, true) {
+			log.Printf("gormkit: %v", err)
+		}
+		return
+	}
+	db.AddError(err)
+}
+
+func (m *Manager) afterQueryBudget(db *gorm.DB) {
+	b, ok := db.Statement.Context.Value(queryBudgetKey).(*queryBudget)
+	if !ok {
+		return
+	}
+	if start, ok := db.InstanceGet(budgetStartKey); ok {
+		b.elapsed.Add(int64(time.Since(start.(time.Time))))
+	}
+}
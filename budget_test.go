@@ -0,0 +1,66 @@
+package gormkit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestQueryBudgetExceeded(t *testing.T) {
+	manager, err := gormkit.New(&gormkit.Config{
+		Driver:   "test",
+		LogLevel: "silent",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	manager.DB().AutoMigrate(&User{})
+
+	ctx := gormkit.WithQueryBudget(context.Background(), 2, 0)
+	db := manager.WithContext(ctx)
+
+	for i := 0; i < 2; i++ {
+		var users []User
+		if err := db.Find(&users).Error; err != nil {
+			t.Fatalf("query %d failed: %v", i+1, err)
+		}
+	}
+
+	var users []User
+	err = db.Find(&users).Error
+	if !errors.Is(err, gormkit.ErrQueryBudgetExceeded) {
+		t.Errorf("Expected ErrQueryBudgetExceeded, got %v", err)
+	}
+
+	queries, _ := gormkit.QueryBudgetUsage(ctx)
+	if queries != 3 {
+		t.Errorf("Expected 3 queries recorded, got %d", queries)
+	}
+}
+
+func TestQueryBudgetWarnMode(t *testing.T) {
+	manager, err := gormkit.New(&gormkit.Config{
+		Driver:          "test",
+		LogLevel:        "silent",
+		QueryBudgetMode: "warn",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	manager.DB().AutoMigrate(&User{})
+
+	ctx := gormkit.WithQueryBudget(context.Background(), 1, time.Second)
+	for i := 0; i < 3; i++ {
+		var users []User
+		if err := manager.WithContext(ctx).Find(&users).Error; err != nil {
+			t.Errorf("warn mode should not fail queries: %v", err)
+		}
+	}
+}
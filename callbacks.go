@@ -0,0 +1,86 @@
+package gormkit
+
+import (
+	"errors"
+
+	"gorm.io/gorm"
+)
+
+func (m *Manager) registerCallbacks() error {
+	errs := []error{
+		registerAround(m.db, "gormkit:query_budget", m.beforeQueryBudget, m.afterQueryBudget),
+		m.registerTemporal(),
+		m.registerEvents(),
+		registerAround(m.db, "gormkit:lock_error", nil, afterLockError),
+		registerAround(m.db, "gormkit:check_violation", nil, afterCheckViolation),
+		registerClosureHooks(m.db),
+		m.db.Callback().Create().Before("gorm:create").Register("gormkit:generated_before_create", beforeGenerated),
+		m.db.Callback().Update().Before("gorm:update").Register("gormkit:generated_before_update", beforeGenerated),
+		m.db.Callback().Query().Before("gorm:query").Register("gormkit:as_of_system_time_query", m.beforeAsOfSystemTime),
+		m.db.Callback().Row().Before("gorm:row").Register("gormkit:as_of_system_time_row", m.beforeAsOfSystemTime),
+	}
+	// Quotas go first, so time spent waiting for a token isn't counted
+	// as query time.
+	if len(m.config.Quotas) > 0 {
+		errs = append(errs, m.registerQuotas())
+	}
+	for _, slo := range m.config.SLOs {
+		m.slos = append(m.slos, newSLOTracker(slo))
+	}
+	if m.config.Metrics || len(m.slos) > 0 {
+		m.metrics = newMetricsRegistry()
+		errs = append(errs, registerAround(m.db, "gormkit:metrics", m.beforeMetrics, m.afterMetrics))
+	}
+	if m.config.RowCountLimits != nil {
+		guard := &rowCountGuard{manager: m, limits: *m.config.RowCountLimits}
+		errs = append(errs, registerAround(m.db, "gormkit:row_count", nil, guard.check))
+	}
+	if m.config.SchemaVersions != (SchemaVersionRange{}) {
+		errs = append(errs, registerAround(m.db, "gormkit:schema_version", m.beforeSchemaVersion, nil))
+	}
+	if m.config.PrepareStmt {
+		errs = append(errs, registerAround(m.db, "gormkit:stmt_cache", nil, m.afterStmtCache))
+	}
+	if m.config.DeadlineAudit != "" {
+		audit := &deadlineAudit{mode: m.config.DeadlineAudit}
+		errs = append(errs, registerAround(m.db, "gormkit:deadline_audit", audit.check, nil))
+	}
+	if m.config.FaultInjector != nil {
+		errs = append(errs, registerAround(m.db, "gormkit:fault", m.config.FaultInjector.inject, nil))
+	}
+	if m.config.QueryComments {
+		errs = append(errs, m.registerQueryComments())
+	}
+	if m.config.OnError != nil {
+		errs = append(errs, registerAround(m.db, "gormkit:on_error", m.beforeErrorHook, m.afterErrorHook))
+	}
+	return errors.Join(errs...)
+}
+
+// registerAround installs before and after around the main callback of every
+// gorm processor, so a hook sees each statement exactly once.
+func registerAround(db *gorm.DB, name string, before, after func(*gorm.DB)) error {
+	cb := db.Callback()
+	var errs []error
+	if before != nil {
+		errs = append(errs,
+			cb.Create().Before("gorm:create").Register(name+"_before_create", before),
+			cb.Query().Before("gorm:query").Register(name+"_before_query", before),
+			cb.Update().Before("gorm:update").Register(name+"_before_update", before),
+			cb.Delete().Before("gorm:delete").Register(name+"_before_delete", before),
+			cb.Row().Before("gorm:row").Register(name+"_before_row", before),
+			cb.Raw().Before("gorm:raw").Register(name+"_before_raw", before),
+		)
+	}
+	if after != nil {
+		errs = append(errs,
+			cb.Create().After("gorm:create").Register(name+"_after_create", after),
+			cb.Query().After("gorm:query").Register(name+"_after_query", after),
+			cb.Update().After("gorm:update").Register(name+"_after_update", after),
+			cb.Delete().After("gorm:delete").Register(name+"_after_delete", after),
+			cb.Row().After("gorm:row").Register(name+"_after_row", after),
+			cb.Raw().After("gorm:raw").Register(name+"_after_raw", after),
+		)
+	}
+	return errors.Join(errs...)
+}
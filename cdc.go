@@ -0,0 +1,363 @@
+package gormkit
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// ChangeEvent is a row change read from the database's change stream.
+type ChangeEvent struct {
+	LSN    string
+	XID    string
+	Schema string
+	Table  string
+	Op     string // insert, update or delete
+
+	// Values holds the new row for inserts and updates, and the key (or the
+	// whole row with REPLICA IDENTITY FULL) for deletes. SQL NULL is nil,
+	// everything else is the value's text form.
+	Values map[string]interface{}
+	// Old holds the previous key or row of an update, when the server sends it.
+	Old map[string]interface{}
+}
+
+// CDCOptions configures change data capture.
+type CDCOptions struct {
+	// Slot is the logical replication slot, created on first start with the
+	// built-in test_decoding plugin. It keeps WAL until changes are
+	// delivered, so drop it with DropSlot when CDC is retired.
+	Slot string
+	// Tables limits delivery to these tables ("users" or "public.users").
+	Tables       []string
+	PollInterval time.Duration // default 1s
+	BatchSize    int           // changes read per poll, default 1000
+}
+
+// CDC tails a Postgres logical replication slot and delivers each change to
+// the subscribers of its table. Delivery is at least once: the slot only
+// advances after every subscriber accepted a batch.
+type CDC struct {
+	manager *Manager
+	opts    CDCOptions
+
+	mu   sync.RWMutex
+	subs map[string][]func(context.Context, ChangeEvent) error
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// StartCDC starts tailing changes until Close, or until the Manager is
+// closed. It needs Postgres with wal_level=logical; MySQL binlogs are not
+// supported.
+func (m *Manager) StartCDC(opts CDCOptions) (*CDC, error) {
+	if m.config.Driver != "postgres" {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedDriver, m.config.Driver)
+	}
+	if opts.Slot == "" {
+		return nil, fmt.Errorf("cdc slot is required")
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Second
+	}
+	if opts.BatchSize <= 0 {
+		opts.BatchSize = 1000
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := m.ensureSlot(ctx, opts.Slot); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	c := &CDC{
+		manager: m,
+		opts:    opts,
+		subs:    make(map[string][]func(context.Context, ChangeEvent) error),
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+	m.onClose(func() { c.Close() })
+	go c.run(ctx)
+	return c, nil
+}
+
+func (m *Manager) ensureSlot(ctx context.Context, slot string) error {
+	var exists bool
+	if err := m.sqlDB.QueryRowContext(ctx,
+		"SELECT EXISTS (SELECT 1 FROM pg_replication_slots WHERE slot_name = $1)", slot).Scan(&exists); err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+	if _, err := m.sqlDB.ExecContext(ctx,
+		"SELECT pg_create_logical_replication_slot($1, 'test_decoding')", slot); err != nil {
+		return fmt.Errorf("failed to create replication slot %s: %w", slot, err)
+	}
+	return nil
+}
+
+// Subscribe delivers the changes of table ("" for every table) to fn. An
+// error from fn makes the batch be delivered again on the next poll.
+func (c *CDC) Subscribe(table string, fn func(ctx context.Context, e ChangeEvent) error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.subs[table] = append(c.subs[table], fn)
+}
+
+// SubscribeChanges delivers the changes of T's table decoded into T.
+func SubscribeChanges[T any](c *CDC, fn func(ctx context.Context, op string, value T) error) error {
+	stmt := &gorm.Statement{DB: c.manager.db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return err
+	}
+	c.Subscribe(stmt.Schema.Table, func(ctx context.Context, e ChangeEvent) error {
+		var value T
+		if err := e.Scan(&value); err != nil {
+			return err
+		}
+		return fn(ctx, e.Op, value)
+	})
+	return nil
+}
+
+// Scan decodes Values into dest, a pointer to a model struct, matching
+// columns to fields as gorm does.
+func (e ChangeEvent) Scan(dest interface{}) error {
+	s, err := schema.Parse(dest, &cdcSchemas, schema.NamingStrategy{})
+	if err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(dest).Elem()
+	for column, value := range e.Values {
+		field := s.LookUpField(column)
+		if field == nil || value == nil {
+			continue
+		}
+		if text, ok := value.(string); ok && field.FieldType == reflect.TypeOf(time.Time{}) {
+			if t, err := parsePgTimestamp(text); err == nil {
+				value = t
+			}
+		}
+		if err := field.Set(context.Background(), rv, value); err != nil {
+			return fmt.Errorf("column %s: %w", column, err)
+		}
+	}
+	return nil
+}
+
+var cdcSchemas sync.Map
+
+// parsePgTimestamp parses the text form of timestamp and timestamptz values.
+func parsePgTimestamp(s string) (time.Time, error) {
+	for _, layout := range []string{"2006-01-02 15:04:05.999999999-07:00", "2006-01-02 15:04:05.999999999-07"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Parse("2006-01-02 15:04:05.999999999", s)
+}
+
+// DropSlot stops CDC and drops its replication slot, releasing retained WAL.
+func (c *CDC) DropSlot(ctx context.Context) error {
+	c.Close()
+	_, err := c.manager.sqlDB.ExecContext(ctx, "SELECT pg_drop_replication_slot($1)", c.opts.Slot)
+	return err
+}
+
+// Close stops tailing. Undelivered changes stay in the slot.
+func (c *CDC) Close() error {
+	c.cancel()
+	<-c.done
+	return nil
+}
+
+func (c *CDC) run(ctx context.Context) {
+	defer close(c.done)
+	ticker := time.NewTicker(c.opts.PollInterval)
+	defer ticker.Stop()
+	for {
+		if err := c.poll(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("gormkit: cdc on slot %s: %v", c.opts.Slot, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *CDC) poll(ctx context.Context) error {
+	rows, err := c.manager.sqlDB.QueryContext(ctx,
+		"SELECT lsn::text, xid::text, data FROM pg_logical_slot_peek_changes($1, NULL, $2)",
+		c.opts.Slot, c.opts.BatchSize)
+	if err != nil {
+		return err
+	}
+	var events []ChangeEvent
+	var last string
+	for rows.Next() {
+		var lsn, xid, data string
+		if err := rows.Scan(&lsn, &xid, &data); err != nil {
+			rows.Close()
+			return err
+		}
+		last = lsn
+		if e, ok := parseTestDecoding(data); ok && c.wants(e) {
+			e.LSN, e.XID = lsn, xid
+			events = append(events, e)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if last == "" {
+		return nil
+	}
+
+	for _, e := range events {
+		if err := c.deliver(ctx, e); err != nil {
+			return fmt.Errorf("%s change at %s not delivered: %w", e.Table, e.LSN, err)
+		}
+	}
+	_, err = c.manager.sqlDB.ExecContext(ctx, "SELECT pg_replication_slot_advance($1, $2::pg_lsn)", c.opts.Slot, last)
+	return err
+}
+
+func (c *CDC) wants(e ChangeEvent) bool {
+	if len(c.opts.Tables) == 0 {
+		return true
+	}
+	for _, t := range c.opts.Tables {
+		if t == e.Table || t == e.Schema+"."+e.Table {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *CDC) deliver(ctx context.Context, e ChangeEvent) error {
+	c.mu.RLock()
+	handlers := append(append([]func(context.Context, ChangeEvent) error(nil), c.subs[e.Table]...), c.subs[""]...)
+	c.mu.RUnlock()
+	for _, fn := range handlers {
+		if err := fn(ctx, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseTestDecoding parses a test_decoding line such as
+//
+//	table public.users: UPDATE: old-key: id[integer]:1 new-tuple: id[integer]:2 name[text]:'Ali'
+//
+// BEGIN and COMMIT lines are skipped.
+func parseTestDecoding(line string) (ChangeEvent, bool) {
+	rest, ok := strings.CutPrefix(line, "table ")
+	if !ok {
+		return ChangeEvent{}, false
+	}
+	name, rest, ok := strings.Cut(rest, ": ")
+	if !ok {
+		return ChangeEvent{}, false
+	}
+	op, rest, ok := strings.Cut(rest, ":")
+	if !ok {
+		return ChangeEvent{}, false
+	}
+
+	e := ChangeEvent{Op: strings.ToLower(op), Table: name}
+	if s, t, ok := strings.Cut(name, "."); ok {
+		e.Schema, e.Table = unquoteIdent(s), unquoteIdent(t)
+	}
+
+	rest = strings.TrimSpace(rest)
+	if old, newTuple, ok := strings.Cut(rest, "new-tuple: "); ok && strings.HasPrefix(old, "old-key: ") {
+		e.Old = parseTuple(strings.TrimPrefix(old, "old-key: "))
+		rest = newTuple
+	}
+	if rest != "(no-tuple-data)" {
+		e.Values = parseTuple(rest)
+	}
+	return e, true
+}
+
+// parseTuple parses name[type]:value pairs, where value is null, a bare
+// token, or a single-quoted string with doubled quotes as escapes.
+func parseTuple(s string) map[string]interface{} {
+	values := make(map[string]interface{})
+	for i := 0; i < len(s); {
+		for i < len(s) && s[i] == ' ' {
+			i++
+		}
+		start := i
+		for i < len(s) && s[i] != '[' {
+			i++
+		}
+		column := unquoteIdent(s[start:i])
+
+		// Skip [type], which may itself contain brackets ("integer[]").
+		for depth := 0; i < len(s); i++ {
+			if s[i] == '[' {
+				depth++
+			} else if s[i] == ']' {
+				if depth--; depth == 0 {
+					i++
+					break
+				}
+			}
+		}
+		if i >= len(s) || s[i] != ':' {
+			break
+		}
+		i++
+
+		if i < len(s) && s[i] == '\'' {
+			var b strings.Builder
+			for i++; i < len(s); i++ {
+				if s[i] == '\'' {
+					if i+1 < len(s) && s[i+1] == '\'' {
+						b.WriteByte('\'')
+						i++
+						continue
+					}
+					i++
+					break
+				}
+				b.WriteByte(s[i])
+			}
+			values[column] = b.String()
+			continue
+		}
+
+		start = i
+		for i < len(s) && s[i] != ' ' {
+			i++
+		}
+		if token := s[start:i]; token != "null" {
+			values[column] = token
+		} else {
+			values[column] = nil
+		}
+	}
+	return values
+}
+
+func unquoteIdent(s string) string {
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		return strings.ReplaceAll(s[1:len(s)-1], `""`, `"`)
+	}
+	return s
+}
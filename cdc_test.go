@@ -0,0 +1,50 @@
+package gormkit_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestCDCUnsupportedOnSQLite(t *testing.T) {
+	manager, err := gormkit.New(&gormkit.Config{
+		Driver:   "test",
+		LogLevel: "silent",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	_, err = manager.StartCDC(gormkit.CDCOptions{Slot: "app"})
+	if !errors.Is(err, gormkit.ErrUnsupportedDriver) {
+		t.Errorf("Expected ErrUnsupportedDriver, got %v", err)
+	}
+}
+
+func TestChangeEventScan(t *testing.T) {
+	e := gormkit.ChangeEvent{
+		Table: "users",
+		Op:    "insert",
+		Values: map[string]interface{}{
+			"id":         "7",
+			"name":       "O'Brien",
+			"created_at": "2024-03-01 10:30:00.5+00",
+			"unknown":    "ignored",
+		},
+	}
+
+	var user User
+	if err := e.Scan(&user); err != nil {
+		t.Fatal(err)
+	}
+	if user.ID != 7 || user.Name != "O'Brien" {
+		t.Errorf("Unexpected user: %+v", user)
+	}
+	want := time.Date(2024, 3, 1, 10, 30, 0, 5e8, time.UTC)
+	if !user.CreatedAt.Equal(want) {
+		t.Errorf("Expected created_at %v, got %v", want, user.CreatedAt)
+	}
+}
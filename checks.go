@@ -0,0 +1,131 @@
+package gormkit
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Check is a CHECK constraint declared by a model implementing Checked,
+// typically one spanning several columns. Constraints on a single column
+// can be declared with gorm's check tag instead, e.g.
+// `gorm:"check:price_positive,price > 0"`.
+type Check struct {
+	Name  string
+	Expr  string // e.g. "ends_at > starts_at"
+	Field string // struct field violations are reported on, if any
+}
+
+// Checked is implemented by models declaring CHECK constraints, created by
+// Schema.AddChecks.
+//
+//	func (Booking) Checks() []gormkit.Check {
+//		return []gormkit.Check{{Name: "bookings_ends_after_start", Expr: "ends_at > starts_at", Field: "EndsAt"}}
+//	}
+type Checked interface {
+	Checks() []Check
+}
+
+// CheckViolation is the error of a statement rejected by a CHECK
+// constraint, with the model field the constraint is declared on, so it
+// can be reported as a validation error of that field. It matches
+// ErrCheckViolation with errors.Is.
+type CheckViolation struct {
+	Table      string
+	Constraint string
+	Field      string // empty when the constraint isn't declared on the model
+	Err        error
+}
+
+func (e *CheckViolation) Error() string {
+	if e.Field != "" {
+		return fmt.Sprintf("check constraint %s violated by %s: %v", e.Constraint, e.Field, e.Err)
+	}
+	return fmt.Sprintf("check constraint %s violated: %v", e.Constraint, e.Err)
+}
+
+func (e *CheckViolation) Unwrap() []error {
+	return []error{ErrCheckViolation, e.Err}
+}
+
+// AddChecks adds the CHECK constraints declared on model, by check tags and
+// by Checked, to its table. SQLite can't add constraints to existing
+// tables, so they are skipped there; AutoMigrate creates the tagged ones
+// with the table.
+func (s *Schema) AddChecks(model interface{}) {
+	stmt := &gorm.Statement{DB: s.db}
+	if err := stmt.Parse(model); err != nil {
+		s.skip("skipped checks of %T: %v", model, err)
+		return
+	}
+	for _, chk := range modelChecks(stmt.Schema) {
+		s.AddCheckConstraint(stmt.Schema.Table, chk.Name, chk.Expr)
+	}
+}
+
+// modelChecks returns the checks declared on the model of sch, tagged ones
+// first, each in name order.
+func modelChecks(sch *schema.Schema) []Check {
+	var checks []Check
+	for name, chk := range sch.ParseCheckConstraints() {
+		checks = append(checks, Check{Name: name, Expr: chk.Constraint, Field: chk.Field.Name})
+	}
+	sort.Slice(checks, func(i, j int) bool { return checks[i].Name < checks[j].Name })
+	if checked, ok := reflect.New(sch.ModelType).Interface().(Checked); ok {
+		declared := append([]Check(nil), checked.Checks()...)
+		sort.Slice(declared, func(i, j int) bool { return declared[i].Name < declared[j].Name })
+		checks = append(checks, declared...)
+	}
+	return checks
+}
+
+var (
+	mysqlCheckViolated  = regexp.MustCompile("Check constraint '([^']+)' is violated")
+	sqliteCheckViolated = regexp.MustCompile(`CHECK constraint failed: (\w+)`)
+)
+
+// afterCheckViolation wraps check constraint violations in CheckViolation.
+func afterCheckViolation(db *gorm.DB) {
+	var violation *CheckViolation
+	if db.Error == nil || errors.As(db.Error, &violation) {
+		return
+	}
+	name, ok := checkConstraintOf(db.Error)
+	if !ok {
+		return
+	}
+	violation = &CheckViolation{Table: db.Statement.Table, Constraint: name, Err: db.Error}
+	if db.Statement.Schema != nil {
+		for _, chk := range modelChecks(db.Statement.Schema) {
+			if chk.Name == name {
+				violation.Field = chk.Field
+			}
+		}
+	}
+	db.Error = violation
+}
+
+func checkConstraintOf(err error) (string, bool) {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.ConstraintName, pgErr.Code == "23514" // check_violation
+	}
+	var myErr *mysql.MySQLError
+	if errors.As(err, &myErr) {
+		if match := mysqlCheckViolated.FindStringSubmatch(myErr.Message); myErr.Number == 3819 && match != nil {
+			return match[1], true
+		}
+		return "", false
+	}
+	if match := sqliteCheckViolated.FindStringSubmatch(err.Error()); match != nil {
+		return match[1], true
+	}
+	return "", false
+}
@@ -0,0 +1,65 @@
+package gormkit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+type product struct {
+	ID    uint
+	Price float64 `gorm:"check:products_price_positive,price > 0"`
+}
+
+type booking struct {
+	ID       uint
+	StartsAt time.Time
+	EndsAt   time.Time
+}
+
+func (booking) Checks() []gormkit.Check {
+	return []gormkit.Check{{Name: "bookings_ends_after_start", Expr: "ends_at > starts_at", Field: "EndsAt"}}
+}
+
+func TestCheckViolation(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &product{})
+	db := manager.DB()
+	db.Exec(`CREATE TABLE bookings (id INTEGER PRIMARY KEY, starts_at DATETIME, ends_at DATETIME,
+		CONSTRAINT bookings_ends_after_start CHECK (ends_at > starts_at))`)
+
+	err := db.Create(&product{Price: -1}).Error
+	var violation *gormkit.CheckViolation
+	if !errors.As(err, &violation) || !errors.Is(err, gormkit.ErrCheckViolation) {
+		t.Fatalf("Expected a CheckViolation, got %v", err)
+	}
+	if violation.Constraint != "products_price_positive" || violation.Field != "Price" || violation.Table != "products" {
+		t.Errorf("Unexpected violation %+v", violation)
+	}
+
+	now := time.Now()
+	err = db.Create(&booking{StartsAt: now, EndsAt: now.Add(-time.Hour)}).Error
+	if !errors.As(err, &violation) || violation.Field != "EndsAt" {
+		t.Errorf("Expected a violation on EndsAt, got %v", err)
+	}
+	if err := db.Create(&product{Price: 1}).Error; err != nil {
+		t.Error(err)
+	}
+}
+
+func TestAddChecks(t *testing.T) {
+	manager := gormkit.NewTestManager(t)
+	plan, err := manager.PlanMigrations(context.Background(), gormkit.Migration{ID: "001_checks", Up: func(s *gormkit.Schema) {
+		s.AddChecks(&product{})
+		s.AddChecks(&booking{})
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ops := plan[0].Operations
+	if len(ops) != 2 || ops[0].Note == "" || ops[1].Note == "" {
+		t.Errorf("Expected both checks to be skipped on sqlite with a note, got %+v", ops)
+	}
+}
@@ -0,0 +1,45 @@
+package gormkit_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestTableChecksum(t *testing.T) {
+	manager := gormkit.NewTestManager(t)
+	db := manager.DB()
+	ctx := context.Background()
+	// The copy stores amounts as integers and flags as booleans, as a
+	// migration to another dialect might.
+	db.Exec("CREATE TABLE src (id INTEGER PRIMARY KEY, amount REAL, active INTEGER, note TEXT)")
+	db.Exec("CREATE TABLE dst (id INTEGER PRIMARY KEY, amount INTEGER, active BOOLEAN, note BLOB, extra TEXT)")
+	for i := 1; i <= 7; i++ {
+		db.Exec("INSERT INTO src VALUES (?, ?, ?, ?)", i, i*10, i%2, fmt.Sprint("n", i))
+		db.Exec("INSERT INTO dst VALUES (?, ?, ?, ?, 'x')", i, i*10, i%2 == 1, []byte(fmt.Sprint("n", i)))
+	}
+
+	columns := []string{"id", "amount", "active", "note"}
+	src, err := gormkit.TableChecksum(ctx, db, "src", gormkit.ChecksumOptions{KeyRange: gormkit.KeyRange{ChunkSize: 3}, Columns: columns})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if src.Rows != 7 || len(src.Chunks) != 3 {
+		t.Fatalf("Expected 7 rows in 3 chunks, got %+v", src)
+	}
+	dst, err := gormkit.TableChecksum(ctx, db, "dst", gormkit.ChecksumOptions{Columns: columns, Like: src})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dst.Sum != src.Sum {
+		t.Errorf("Expected equal checksums, got mismatches %+v", src.Mismatches(dst))
+	}
+
+	db.Exec("DELETE FROM dst WHERE id = 2")
+	dst, _ = gormkit.TableChecksum(ctx, db, "dst", gormkit.ChecksumOptions{Columns: columns, Like: src})
+	if got := fmt.Sprintf("%+v", src.Mismatches(dst)); got != "[{From:<nil> To:3 PrimaryRows:3 ReplicaRows:2}]" {
+		t.Errorf("Expected only the first chunk to differ, got %s", got)
+	}
+}
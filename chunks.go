@@ -0,0 +1,69 @@
+package gormkit
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// placeholderReserve leaves room under the placeholder limit for the
+// conditions already on a query, e.g. tenant or soft-delete scopes.
+const placeholderReserve = 100
+
+// maxPlaceholders is the most bind parameters one statement may have:
+// 65535 in the Postgres and MySQL protocols, 32766 in SQLite since 3.32.
+func maxPlaceholders(db *gorm.DB) int {
+	if flavorOf(db) == "sqlite" {
+		return 32766
+	}
+	return 65535
+}
+
+// InChunks calls fn with consecutive chunks of values, each small enough to
+// bind as one IN list on db's dialect, stopping at the first error. Use it
+// for updates and deletes by long lists of keys; FindIn covers reads.
+//
+//	err := gormkit.InChunks(db, ids, func(chunk []uint) error {
+//		return db.Model(&Order{}).Where("id IN ?", chunk).Update("archived", true).Error
+//	})
+func InChunks[V any](db *gorm.DB, values []V, fn func(chunk []V) error) error {
+	size := maxPlaceholders(db) - placeholderReserve
+	for start := 0; start < len(values); start += size {
+		if err := fn(values[start:min(start+size, len(values))]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindIn loads the rows of T whose column is one of values, querying the
+// list in chunks below the dialect's placeholder limit and concatenating
+// the results, ordered within but not across chunks. db may carry other
+// conditions.
+//
+//	orders, err := gormkit.FindIn[Order](ctx, db.Where("status = ?", "open"), "customer_id", customerIDs)
+func FindIn[T any, V any](ctx context.Context, db *gorm.DB, column string, values []V) ([]T, error) {
+	db = db.WithContext(ctx)
+	result := []T{}
+	err := InChunks(db, values, func(chunk []V) error {
+		var rows []T
+		err := db.Session(&gorm.Session{}).
+			Where(clause.IN{Column: clause.Column{Table: clause.CurrentTable, Name: column}, Values: toValues(chunk)}).
+			Find(&rows).Error
+		result = append(result, rows...)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func toValues[V any](values []V) []interface{} {
+	out := make([]interface{}, len(values))
+	for i, v := range values {
+		out[i] = v
+	}
+	return out
+}
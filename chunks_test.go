@@ -0,0 +1,68 @@
+package gormkit_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestInChunks(t *testing.T) {
+	manager := gormkit.NewTestManager(t)
+	ids := make([]int, 70000)
+
+	var sizes []int
+	err := gormkit.InChunks(manager.DB(), ids, func(chunk []int) error {
+		sizes = append(sizes, len(chunk))
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// SQLite binds at most 32766 parameters; some are kept for other
+	// conditions.
+	if fmt.Sprint(sizes) != "[32666 32666 4668]" {
+		t.Errorf("Unexpected chunk sizes %v", sizes)
+	}
+
+	calls := 0
+	boom := errors.New("boom")
+	err = gormkit.InChunks(manager.DB(), ids, func(chunk []int) error {
+		calls++
+		return boom
+	})
+	if !errors.Is(err, boom) || calls != 1 {
+		t.Errorf("Expected to stop at the first error, got %v after %d calls", err, calls)
+	}
+}
+
+func TestFindIn(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &User{})
+	db := manager.DB()
+	users := make([]User, 0, 35000)
+	for i := 1; i <= 35000; i++ {
+		users = append(users, User{Name: fmt.Sprintf("u%d", i%2)})
+	}
+	if err := db.CreateInBatches(users, 1000).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	ids := make([]uint, 0, 35000)
+	for i := uint(1); i <= 35000; i++ {
+		ids = append(ids, i)
+	}
+	found, err := gormkit.FindIn[User](context.Background(), db.Where("name = ?", "u1"), "id", ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 17500 {
+		t.Errorf("Expected 17500 users across chunks, got %d", len(found))
+	}
+
+	none, err := gormkit.FindIn[User](context.Background(), db, "id", []uint{})
+	if err != nil || none == nil || len(none) != 0 {
+		t.Errorf("Expected an empty result without querying, got %v, %v", none, err)
+	}
+}
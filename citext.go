@@ -0,0 +1,63 @@
+package gormkit
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// CIText is a string column compared case-insensitively, so a unique index
+// on it rejects "Ali@example.com" next to "ali@example.com": citext on
+// Postgres (install the citext extension first, e.g. with
+// Schema.CreateExtension), a case-insensitive collation on MySQL and
+// NOCASE on SQLite. The stored value keeps its case.
+//
+//	type Account struct {
+//		ID    uint
+//		Email gormkit.CIText `gorm:"uniqueIndex"`
+//	}
+type CIText string
+
+// GormDBDataType returns the column type for the dialect. On MySQL the
+// column is a VARCHAR of the field's size, 255 by default.
+func (CIText) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	switch flavorOf(db) {
+	case "postgres":
+		return "citext"
+	case "mysql":
+		size := field.Size
+		if size == 0 {
+			size = 255
+		}
+		return fmt.Sprintf("varchar(%d) COLLATE %s", size, ciCollation(serverInfoOf(db)))
+	default:
+		return "text COLLATE NOCASE"
+	}
+}
+
+// ciCollation is MySQL's accent-sensitive, case-insensitive collation, or
+// the general one on servers without it.
+func ciCollation(info ServerInfo) string {
+	if info.Flavor == "mysql" && info.AtLeast(8, 0) {
+		return "utf8mb4_0900_as_ci"
+	}
+	return "utf8mb4_unicode_ci"
+}
+
+// CreateUniqueIndexCI adds a case-insensitive unique index on column of
+// table, for existing text columns that aren't CIText: on lower(column)
+// on Postgres and with NOCASE on SQLite. MySQL compares with the column's
+// collation, case-insensitive by default, so the index is a plain unique
+// one there. The index is named idx_<table>_<column>_ci.
+func (s *Schema) CreateUniqueIndexCI(table, column string) {
+	name := s.quote("idx_" + table + "_" + column + "_ci")
+	switch flavorOf(s.db) {
+	case "postgres":
+		s.Exec(fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s (lower(%s))", name, s.quote(table), s.quote(column)))
+	case "mysql":
+		s.Exec(fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s (%s)", name, s.quote(table), s.quote(column)))
+	default:
+		s.Exec(fmt.Sprintf("CREATE UNIQUE INDEX %s ON %s (%s COLLATE NOCASE)", name, s.quote(table), s.quote(column)))
+	}
+}
@@ -0,0 +1,71 @@
+package gormkit_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+type account struct {
+	ID    uint
+	Email gormkit.CIText `gorm:"uniqueIndex"`
+}
+
+func TestCIText(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &account{})
+	db := manager.DB()
+
+	if err := db.Create(&account{Email: "Ali@example.com"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&account{Email: "ali@EXAMPLE.com"}).Error; err == nil {
+		t.Error("Expected a duplicate differing only in case to be rejected")
+	}
+	var found account
+	if err := db.Where("email = ?", "ALI@example.com").First(&found).Error; err != nil {
+		t.Fatal(err)
+	}
+	if found.Email != "Ali@example.com" {
+		t.Errorf("Expected the stored case to be kept, got %q", found.Email)
+	}
+}
+
+func TestCITextDataType(t *testing.T) {
+	dryRun := func() *gorm.Config {
+		return &gorm.Config{DryRun: true, DisableAutomaticPing: true}
+	}
+	pg, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), dryRun())
+	if err != nil {
+		t.Fatal(err)
+	}
+	my, err := gorm.Open(mysql.New(mysql.Config{DSN: "app@tcp(localhost)/app", SkipInitializeWithVersion: true}), dryRun())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := gormkit.CIText("").GormDBDataType(pg, &schema.Field{}); got != "citext" {
+		t.Errorf("Expected citext on postgres, got %q", got)
+	}
+	if got := gormkit.CIText("").GormDBDataType(my, &schema.Field{Size: 320}); got != "varchar(320) COLLATE utf8mb4_unicode_ci" {
+		t.Errorf("Unexpected mysql type %q", got)
+	}
+}
+
+func TestCreateUniqueIndexCI(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &User{})
+	db := manager.DB()
+	err := manager.ApplyMigrations(context.Background(), gormkit.Migration{ID: "001_users_name_ci", Up: func(s *gormkit.Schema) {
+		s.CreateUniqueIndexCI("users", "name")
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.Create(&User{Name: "Ali"})
+	if err := db.Create(&User{Name: "ALI"}).Error; err == nil {
+		t.Error("Expected the case-insensitive unique index to reject ALI")
+	}
+}
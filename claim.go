@@ -0,0 +1,65 @@
+package gormkit
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Claim takes up to n rows of T matching query and args, oldest primary key
+// first, setting the columns of claim on them (e.g. the worker and a lease
+// expiry) so other workers calling Claim concurrently get other rows, and
+// returns them as updated. It is the building block of a durable work queue
+// on every driver:
+//
+//	jobs, err := gormkit.Claim[Job](ctx, db, 10,
+//		map[string]interface{}{"worker": id, "lease_until": time.Now().Add(time.Minute)},
+//		"status = ? AND (lease_until IS NULL OR lease_until < ?)", "pending", time.Now())
+//
+// With SKIP LOCKED (Postgres, MySQL 8, MariaDB 10.6) the rows are selected,
+// skipping those being claimed by others, and updated in one transaction.
+// SQLite has a single writer and no row locks, and a transaction that reads
+// before writing fails under contention, so the rows are claimed by one
+// UPDATE ... RETURNING there; they are still the oldest, but SQLite returns
+// them in no particular order. Elsewhere the selected rows are locked,
+// waiting for other claims, and returned in primary key order.
+func Claim[T any](ctx context.Context, db *gorm.DB, n int, claim map[string]interface{}, query interface{}, args ...interface{}) ([]T, error) {
+	db = db.WithContext(ctx)
+	var out []T
+	pk, err := primaryKey(db, new(T))
+	if err != nil || n <= 0 {
+		return out, err
+	}
+	column := clause.Column{Name: pk}
+	info := serverInfoOf(db)
+
+	if info.Flavor == "sqlite" && info.Supports(FeatureReturning) {
+		candidates := db.Session(&gorm.Session{NewDB: true}).Model(new(T)).Select(pk).
+			Where(query, args...).Order(clause.OrderByColumn{Column: column}).Limit(n)
+		err := db.Model(&out).Clauses(clause.Returning{}).
+			Where(clause.Expr{SQL: "? IN (?)", Vars: []interface{}{column, candidates}}).Updates(claim).Error
+		return out, err
+	}
+
+	err = db.Transaction(func(tx *gorm.DB) error {
+		lock := LockForUpdate()
+		if info.Supports(FeatureSkipLocked) {
+			lock = LockSkipLocked()
+		}
+		var ids []interface{}
+		if err := tx.Model(new(T)).Scopes(lock).Where(query, args...).
+			Order(clause.OrderByColumn{Column: column}).Limit(n).Pluck(pk, &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		in := clause.IN{Column: column, Values: ids}
+		if err := tx.Model(new(T)).Where(in).Updates(claim).Error; err != nil {
+			return err
+		}
+		return tx.Where(in).Order(clause.OrderByColumn{Column: column}).Find(&out).Error
+	})
+	return out, err
+}
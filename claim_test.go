@@ -0,0 +1,72 @@
+package gormkit_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+type queuedJob struct {
+	ID     uint
+	Status string
+	Worker string
+}
+
+func TestClaim(t *testing.T) {
+	// A database file, so workers use several connections as on an edge
+	// node.
+	manager, err := gormkit.New(&gormkit.Config{Driver: "sqlite", Database: filepath.Join(t.TempDir(), "queue.db"),
+		LogLevel: "silent", AutoMigrate: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+	if err := manager.Migrate(&queuedJob{}); err != nil {
+		t.Fatal(err)
+	}
+	db := manager.DB()
+	jobs := make([]queuedJob, 30)
+	for i := range jobs {
+		jobs[i].Status = "pending"
+	}
+	db.Create(&jobs)
+
+	ctx := context.Background()
+	claimed := make(map[uint]string)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(worker string) {
+			defer wg.Done()
+			for {
+				batch, err := gormkit.Claim[queuedJob](ctx, db, 4,
+					map[string]interface{}{"status": "running", "worker": worker}, "status = ?", "pending")
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if len(batch) == 0 {
+					return
+				}
+				mu.Lock()
+				for _, job := range batch {
+					if prev, ok := claimed[job.ID]; ok || job.Worker != worker {
+						t.Errorf("Job %d claimed by %s and %s", job.ID, prev, job.Worker)
+					}
+					claimed[job.ID] = worker
+				}
+				mu.Unlock()
+			}
+		}(fmt.Sprintf("worker-%d", w))
+	}
+	wg.Wait()
+
+	if len(claimed) != len(jobs) {
+		t.Errorf("Expected %d jobs claimed, got %d", len(jobs), len(claimed))
+	}
+}
@@ -0,0 +1,69 @@
+package gormkit
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+)
+
+// ClockSkew measures how far the database clock is ahead of the local one
+// (negative when behind), taking the local time halfway through the round
+// trip. Writers on skewed hosts order events wrongly when comparing
+// NowFunc timestamps with database-generated ones.
+func (m *Manager) ClockSkew(ctx context.Context) (time.Duration, error) {
+	var query string
+	switch m.config.Driver {
+	case "postgres":
+		query = "SELECT EXTRACT(EPOCH FROM clock_timestamp())::float8"
+	case "mysql":
+		query = "SELECT UNIX_TIMESTAMP(SYSDATE(6))"
+	default:
+		query = "SELECT (julianday('now') - 2440587.5) * 86400.0"
+	}
+
+	start := time.Now()
+	var epoch float64
+	if err := m.sqlDB.QueryRowContext(ctx, query).Scan(&epoch); err != nil {
+		return 0, fmt.Errorf("failed to read database clock: %w", err)
+	}
+	local := start.Add(time.Since(start) / 2)
+	remote := time.Unix(0, int64(epoch*float64(time.Second)))
+	return remote.Sub(local), nil
+}
+
+// checkClockSkew measures the skew for the readiness probe and metrics, and
+// reports it when beyond Config.MaxClockSkew.
+func (m *Manager) checkClockSkew(ctx context.Context) {
+	skew, err := m.ClockSkew(ctx)
+	if err != nil {
+		log.Printf("gormkit: %v", err)
+		return
+	}
+	m.clockSkew.Store(&skew)
+	if skewExceeds(skew, m.config.MaxClockSkew) {
+		log.Printf("gormkit: database clock is %v off the local clock, beyond MaxClockSkew %v", skew, m.config.MaxClockSkew)
+		m.metrics.inc("gormkit_clock_skew_exceeded_total", "Clock checks beyond MaxClockSkew.")
+	}
+}
+
+// watchClockSkew re-measures the skew every ClockSkewInterval until ctx is
+// cancelled.
+func (m *Manager) watchClockSkew(ctx context.Context) {
+	ticker := time.NewTicker(m.config.ClockSkewInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			checkCtx, cancel := context.WithTimeout(ctx, m.config.ConnectTimeout)
+			m.checkClockSkew(checkCtx)
+			cancel()
+		}
+	}
+}
+
+func skewExceeds(skew, max time.Duration) bool {
+	return skew > max || skew < -max
+}
@@ -0,0 +1,70 @@
+package gormkit_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestClockSkew(t *testing.T) {
+	manager, err := gormkit.New(&gormkit.Config{
+		Driver:       "test",
+		LogLevel:     "silent",
+		Metrics:      true,
+		MaxClockSkew: time.Minute,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	// SQLite reads the local clock, so only the measurement error remains.
+	skew, err := manager.ClockSkew(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if skew > time.Second || skew < -time.Second {
+		t.Errorf("Expected no skew against the local clock, got %v", skew)
+	}
+
+	rec := httptest.NewRecorder()
+	manager.ReadinessProbe(gormkit.ReadinessOptions{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected ready, got %d: %s", rec.Code, rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	manager.MetricsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if !strings.Contains(rec.Body.String(), "gormkit_clock_skew_seconds ") {
+		t.Errorf("Expected a clock skew gauge, got:\n%s", rec.Body)
+	}
+}
+
+func TestClockSkewExceeded(t *testing.T) {
+	manager, err := gormkit.New(&gormkit.Config{
+		Driver:   "test",
+		LogLevel: "silent",
+		Metrics:  true,
+		// SQLite's clock has millisecond precision, so it is always off by
+		// more than this.
+		MaxClockSkew: time.Nanosecond,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	rec := httptest.NewRecorder()
+	manager.ReadinessProbe(gormkit.ReadinessOptions{}).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if rec.Code != http.StatusServiceUnavailable || !strings.Contains(rec.Body.String(), "clock skew") {
+		t.Errorf("Expected not ready because of clock skew, got %d: %s", rec.Code, rec.Body)
+	}
+	if got := manager.Metrics().Counters["gormkit_clock_skew_exceeded_total"]; got != 1 {
+		t.Errorf("Expected 1 exceeded check, got %d", got)
+	}
+}
@@ -0,0 +1,226 @@
+package gormkit
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// ConnectionArgs are the Relay connection arguments of a GraphQL list
+// field. Without First or Last, the first 10 items are returned.
+type ConnectionArgs struct {
+	First  *int
+	After  *string
+	Last   *int
+	Before *string
+}
+
+// Edge is a node of a Connection with its cursor.
+type Edge[T any] struct {
+	Cursor string `json:"cursor"`
+	Node   T      `json:"node"`
+}
+
+// PageInfo is the Relay pageInfo of a Connection.
+type PageInfo struct {
+	HasPreviousPage bool    `json:"hasPreviousPage"`
+	HasNextPage     bool    `json:"hasNextPage"`
+	StartCursor     *string `json:"startCursor"`
+	EndCursor       *string `json:"endCursor"`
+}
+
+// Connection is a Relay cursor connection: one page of a keyset-paginated
+// list.
+type Connection[T any] struct {
+	Edges    []Edge[T] `json:"edges"`
+	Nodes    []T       `json:"nodes"`
+	PageInfo PageInfo  `json:"pageInfo"`
+}
+
+// keysetKey is a column a keyset-paginated list is ordered by.
+type keysetKey struct {
+	field *schema.Field
+	desc  bool
+}
+
+// FindConnection loads the page of db described by args as a Relay
+// connection, paginating by keyset: rows are ordered by keys (columns,
+// "-" prefixed for descending, none null) with the primary key appended as
+// a tie-breaker, and cursors hold the key values of their row, so pages
+// stay stable while rows are inserted. db must not be ordered; an index on
+// the keys keeps every page cheap.
+//
+//	conn, err := gormkit.FindConnection[Post](ctx, db.Where("author_id = ?", id), gormkit.ConnectionArgs{
+//		First: args.First, After: args.After, Last: args.Last, Before: args.Before,
+//	}, "-created_at")
+//
+// As the Relay spec allows, HasPreviousPage is only known when paginating
+// backwards, and HasNextPage when paginating forwards; otherwise each
+// reports whether a cursor was given.
+func FindConnection[T any](ctx context.Context, db *gorm.DB, args ConnectionArgs, keys ...string) (*Connection[T], error) {
+	if args.First != nil && args.Last != nil {
+		return nil, fmt.Errorf("first and last cannot be combined")
+	}
+	limit, backward := 10, args.Last != nil
+	if args.First != nil {
+		limit = *args.First
+	} else if args.Last != nil {
+		limit = *args.Last
+	}
+	if limit < 0 {
+		return nil, fmt.Errorf("first and last must not be negative, got %d", limit)
+	}
+
+	db = db.WithContext(ctx)
+	order, err := keysetOrder(db, new(T), keys)
+	if err != nil {
+		return nil, err
+	}
+	tx := db.Session(&gorm.Session{})
+	if args.After != nil {
+		values, err := decodeCursor(*args.After, order)
+		if err != nil {
+			return nil, err
+		}
+		tx = tx.Where(keysetCondition(order, values, true))
+	}
+	if args.Before != nil {
+		values, err := decodeCursor(*args.Before, order)
+		if err != nil {
+			return nil, err
+		}
+		tx = tx.Where(keysetCondition(order, values, false))
+	}
+	for _, k := range order {
+		tx = tx.Order(clause.OrderByColumn{Column: clause.Column{Name: k.field.DBName}, Desc: k.desc != backward})
+	}
+
+	var items []T
+	if err := tx.Limit(limit + 1).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	more := len(items) > limit
+	if more {
+		items = items[:limit]
+	}
+	if backward {
+		for i, j := 0, len(items)-1; i < j; i, j = i+1, j-1 {
+			items[i], items[j] = items[j], items[i]
+		}
+	}
+
+	conn := &Connection[T]{Edges: make([]Edge[T], len(items)), Nodes: items}
+	if conn.Nodes == nil {
+		conn.Nodes = []T{}
+	}
+	for i := range items {
+		cursor, err := encodeCursor(ctx, order, reflect.ValueOf(&items[i]).Elem())
+		if err != nil {
+			return nil, err
+		}
+		conn.Edges[i] = Edge[T]{Cursor: cursor, Node: items[i]}
+	}
+	if len(items) > 0 {
+		conn.PageInfo.StartCursor = &conn.Edges[0].Cursor
+		conn.PageInfo.EndCursor = &conn.Edges[len(items)-1].Cursor
+	}
+	if backward {
+		conn.PageInfo.HasPreviousPage, conn.PageInfo.HasNextPage = more, args.Before != nil
+	} else {
+		conn.PageInfo.HasPreviousPage, conn.PageInfo.HasNextPage = args.After != nil, more
+	}
+	return conn, nil
+}
+
+// keysetOrder resolves keys to fields of model and appends its primary key
+// unless already present.
+func keysetOrder(db *gorm.DB, model interface{}, keys []string) ([]keysetKey, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, err
+	}
+	var order []keysetKey
+	hasPrimary := false
+	for _, key := range keys {
+		name, desc := strings.TrimPrefix(key, "-"), strings.HasPrefix(key, "-")
+		field := stmt.Schema.LookUpField(name)
+		if field == nil || field.DBName == "" {
+			return nil, fmt.Errorf("%s has no column %s", stmt.Schema.Table, name)
+		}
+		hasPrimary = hasPrimary || field == stmt.Schema.PrioritizedPrimaryField
+		order = append(order, keysetKey{field: field, desc: desc})
+	}
+	if !hasPrimary {
+		if stmt.Schema.PrioritizedPrimaryField == nil {
+			return nil, fmt.Errorf("%s needs a single primary key", stmt.Schema.Table)
+		}
+		order = append(order, keysetKey{field: stmt.Schema.PrioritizedPrimaryField})
+	}
+	return order, nil
+}
+
+// keysetCondition matches the rows strictly after (or before) the row with
+// the given key values: (k1 > v1) OR (k1 = v1 AND k2 > v2) OR ..., which
+// unlike a row-value comparison allows mixed directions.
+func keysetCondition(order []keysetKey, values []interface{}, after bool) clause.Expression {
+	ors := make([]clause.Expression, 0, len(order))
+	for i, k := range order {
+		ands := make([]clause.Expression, 0, i+1)
+		for j := 0; j < i; j++ {
+			ands = append(ands, clause.Eq{Column: clause.Column{Name: order[j].field.DBName}, Value: values[j]})
+		}
+		column := clause.Column{Name: k.field.DBName}
+		if k.desc == after {
+			ands = append(ands, clause.Lt{Column: column, Value: values[i]})
+		} else {
+			ands = append(ands, clause.Gt{Column: column, Value: values[i]})
+		}
+		ors = append(ors, clause.And(ands...))
+	}
+	return clause.Or(ors...)
+}
+
+// encodeCursor returns the opaque cursor of row: its key values as
+// base64url JSON.
+func encodeCursor(ctx context.Context, order []keysetKey, row reflect.Value) (string, error) {
+	values := make([]interface{}, len(order))
+	for i, k := range order {
+		values[i], _ = k.field.ValueOf(ctx, row)
+	}
+	data, err := json.Marshal(values)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor returns the key values in cursor, typed like their fields.
+func decodeCursor(cursor string, order []keysetKey) ([]interface{}, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	if len(raw) != len(order) {
+		return nil, fmt.Errorf("%w: expected %d values, got %d", ErrInvalidCursor, len(order), len(raw))
+	}
+	values := make([]interface{}, len(order))
+	for i, k := range order {
+		value := reflect.New(k.field.FieldType)
+		if err := json.Unmarshal(raw[i], value.Interface()); err != nil {
+			return nil, fmt.Errorf("%w: %s: %v", ErrInvalidCursor, k.field.DBName, err)
+		}
+		values[i] = value.Elem().Interface()
+	}
+	return values, nil
+}
@@ -0,0 +1,107 @@
+package gormkit_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func userNames(users []User) []string {
+	return names(users, func(u User) string { return u.Name })
+}
+
+func TestFindConnection(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &User{})
+	db := manager.DB()
+	// Pairs of users share a creation time, so the primary key breaks ties.
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 1; i <= 7; i++ {
+		db.Create(&User{Name: fmt.Sprintf("u%d", i), CreatedAt: base.Add(time.Duration(i/2) * time.Hour)})
+	}
+	ctx := context.Background()
+	two := 2
+
+	// Newest first, ties in id order: u6, u7, u4, u5, u2, u3, u1.
+	var got []string
+	args := gormkit.ConnectionArgs{First: &two}
+	for {
+		conn, err := gormkit.FindConnection[User](ctx, db, args, "-created_at")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, userNames(conn.Nodes)...)
+		if len(conn.Edges) != len(conn.Nodes) || *conn.PageInfo.EndCursor != conn.Edges[len(conn.Edges)-1].Cursor {
+			t.Fatalf("Edges and page info disagree: %+v", conn.PageInfo)
+		}
+		if conn.PageInfo.HasPreviousPage != (args.After != nil) {
+			t.Errorf("Unexpected hasPreviousPage %v", conn.PageInfo.HasPreviousPage)
+		}
+		if !conn.PageInfo.HasNextPage {
+			break
+		}
+		args.After = conn.PageInfo.EndCursor
+	}
+	want := []string{"u6", "u7", "u4", "u5", "u2", "u3", "u1"}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+
+	// Paginating backwards from the end returns the same order.
+	got = nil
+	args = gormkit.ConnectionArgs{Last: &two}
+	for {
+		conn, err := gormkit.FindConnection[User](ctx, db, args, "-created_at")
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(userNames(conn.Nodes), got...)
+		if !conn.PageInfo.HasPreviousPage {
+			break
+		}
+		args.Before = conn.PageInfo.StartCursor
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Expected %v backwards, got %v", want, got)
+	}
+}
+
+func TestFindConnectionArgs(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &User{})
+	db := manager.DB()
+	for i := 1; i <= 12; i++ {
+		db.Create(&User{Name: fmt.Sprintf("u%d", i)})
+	}
+	ctx := context.Background()
+
+	conn, err := gormkit.FindConnection[User](ctx, db.Where("id > ?", 1), gormkit.ConnectionArgs{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conn.Nodes) != 10 || conn.Nodes[0].Name != "u2" || !conn.PageInfo.HasNextPage {
+		t.Errorf("Expected the first 10 users after u1, got %v", userNames(conn.Nodes))
+	}
+
+	zero := 0
+	conn, err = gormkit.FindConnection[User](ctx, db, gormkit.ConnectionArgs{First: &zero})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(conn.Edges) != 0 || conn.PageInfo.StartCursor != nil || !conn.PageInfo.HasNextPage {
+		t.Errorf("Expected an empty page with more to come, got %+v", conn.PageInfo)
+	}
+
+	bad := "not-a-cursor"
+	if _, err := gormkit.FindConnection[User](ctx, db, gormkit.ConnectionArgs{After: &bad}); !errors.Is(err, gormkit.ErrInvalidCursor) {
+		t.Errorf("Expected ErrInvalidCursor, got %v", err)
+	}
+	if _, err := gormkit.FindConnection[User](ctx, db, gormkit.ConnectionArgs{First: &zero, Last: &zero}); err == nil {
+		t.Error("Expected first and last to be rejected together")
+	}
+	if _, err := gormkit.FindConnection[User](ctx, db, gormkit.ConnectionArgs{}, "missing"); err == nil {
+		t.Error("Expected an unknown key to be rejected")
+	}
+}
@@ -0,0 +1,121 @@
+package gormkit
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	gomysql "github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// needsConnector reports whether the pool must be opened by the kit rather
+// than the gorm dialector, to hook into every new connection.
+func (m *Manager) needsConnector() bool {
+	return m.config.CredentialProvider != nil || len(m.config.Hosts) > 0 || m.config.Resolver != nil
+}
+
+// connectorDialector opens the pool through a connector that applies the
+// CredentialProvider's credentials and picks a healthy endpoint from
+// Config.Hosts for every new connection. On Postgres, pooled connections of
+// a superseded user or an ejected endpoint are discarded when next used.
+func (m *Manager) connectorDialector(dsn string) (gorm.Dialector, error) {
+	switch m.config.Driver {
+	case "postgres":
+		cfg, err := pgx.ParseConfig(dsn)
+		if err != nil {
+			return nil, err
+		}
+		if m.config.PoolerCompat {
+			cfg.DefaultQueryExecMode = pgx.QueryExecModeSimpleProtocol
+		}
+		opts := []stdlib.OptionOpenDB{
+			stdlib.OptionBeforeConnect(func(ctx context.Context, cc *pgx.ConnConfig) error {
+				if e := endpointFrom(ctx); e != nil {
+					cc.Host, cc.Port = e.host, uint16(e.port)
+					cc.Fallbacks = nil
+					if cc.TLSConfig != nil {
+						cc.TLSConfig = cc.TLSConfig.Clone()
+						cc.TLSConfig.ServerName = e.host
+					}
+				}
+				if m.config.CredentialProvider == nil {
+					return nil
+				}
+				creds, err := m.credentialsFor(ctx)
+				if err != nil {
+					return err
+				}
+				cc.User, cc.Password = creds.User, creds.Password
+				return nil
+			}),
+			// A new password for the same user (a refreshed token) does not
+			// end existing sessions, but a new user (a rotated Vault lease)
+			// means the old one is about to be revoked.
+			stdlib.OptionResetSession(func(ctx context.Context, conn *pgx.Conn) error {
+				cc := conn.Config()
+				if current := m.creds.Load(); current != nil && cc.User != current.User {
+					return driver.ErrBadConn
+				}
+				if m.endpoints != nil && m.endpoints.ejected(net.JoinHostPort(cc.Host, strconv.Itoa(int(cc.Port)))) {
+					return driver.ErrBadConn
+				}
+				return nil
+			}),
+		}
+		// Match what the gorm dialector does for the TimeZone DSN parameter.
+		if loc, err := time.LoadLocation(m.config.Timezone); err == nil {
+			cfg.RuntimeParams["timezone"] = m.config.Timezone
+			opts = append(opts, stdlib.OptionAfterConnect(func(ctx context.Context, conn *pgx.Conn) error {
+				conn.TypeMap().RegisterType(&pgtype.Type{
+					Name:  "timestamp",
+					OID:   pgtype.TimestampOID,
+					Codec: &pgtype.TimestampCodec{ScanLocation: loc},
+				})
+				return nil
+			}))
+		}
+		connector := m.endpointConnector(stdlib.GetConnector(*cfg, opts...))
+		return postgres.New(postgres.Config{Conn: sql.OpenDB(connector)}), nil
+
+	case "mysql":
+		cfg, err := gomysql.ParseDSN(dsn)
+		if err != nil {
+			return nil, err
+		}
+		if err := cfg.Apply(gomysql.BeforeConnect(func(ctx context.Context, mc *gomysql.Config) error {
+			if e := endpointFrom(ctx); e != nil {
+				mc.Addr = e.addr
+			}
+			if m.config.CredentialProvider == nil {
+				return nil
+			}
+			creds, err := m.credentialsFor(ctx)
+			if err != nil {
+				return err
+			}
+			mc.User, mc.Passwd = creds.User, creds.Password
+			// Token authentication (e.g. Azure AD) sends the token with the
+			// cleartext plugin, which is only acceptable over TLS.
+			mc.AllowCleartextPasswords = mc.TLS != nil || mc.TLSConfig != ""
+			return nil
+		})); err != nil {
+			return nil, err
+		}
+		connector, err := gomysql.NewConnector(cfg)
+		if err != nil {
+			return nil, err
+		}
+		return mysql.New(mysql.Config{Conn: sql.OpenDB(m.endpointConnector(connector))}), nil
+	}
+	return nil, fmt.Errorf("%w: %s with CredentialProvider or Hosts", ErrUnsupportedDriver, m.config.Driver)
+}
@@ -0,0 +1,44 @@
+package gormkit_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestConsistencyCheck(t *testing.T) {
+	primary := gormkit.NewTestManager(t, &User{})
+	replica := gormkit.NewTestManager(t, &User{})
+	ctx := context.Background()
+
+	for i := 1; i <= 10; i++ {
+		user := User{ID: uint(i), Name: fmt.Sprintf("user%d", i)}
+		primary.DB().Create(&user)
+		replica.DB().Create(&user)
+	}
+	check := func(keys gormkit.KeyRange) []gormkit.RangeMismatch {
+		t.Helper()
+		mismatches, err := primary.ConsistencyCheck(ctx, replica, "users", keys)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return mismatches
+	}
+	if got := check(gormkit.KeyRange{ChunkSize: 3}); len(got) != 0 {
+		t.Fatalf("Expected identical tables to match, got %+v", got)
+	}
+
+	replica.DB().Model(&User{ID: 5}).Update("name", "diverged")
+	replica.DB().Create(&User{ID: 11, Name: "extra"})
+	got := check(gormkit.KeyRange{ChunkSize: 3})
+	want := "[{From:3 To:6 PrimaryRows:3 ReplicaRows:3} {From:9 To:<nil> PrimaryRows:1 ReplicaRows:2}]"
+	if fmt.Sprintf("%+v", got) != want {
+		t.Errorf("Expected %s, got %+v", want, got)
+	}
+
+	if got := check(gormkit.KeyRange{From: 6, To: 10, ChunkSize: 3}); len(got) != 0 {
+		t.Errorf("Expected rows outside the range to be ignored, got %+v", got)
+	}
+}
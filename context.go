@@ -0,0 +1,13 @@
+package gormkit
+
+type ctxKey int
+
+const (
+	sessionSettingsKey ctxKey = iota
+	queryBudgetKey
+	fieldsKey
+	deadlineExemptKey
+	endpointKey
+	asOfSystemTimeKey
+	txEventsKey
+)
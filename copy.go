@@ -0,0 +1,233 @@
+package gormkit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CopySpec selects what Copy copies and how.
+type CopySpec struct {
+	// Tables to copy, in order; by default every table of src except the
+	// kit's own, with referenced tables first.
+	Tables []string
+	// Transform rewrites each row of a table before it is written, e.g. to
+	// convert a value the destination dialect stores differently. Returning
+	// a nil row skips it.
+	Transform map[string]func(row map[string]interface{}) (map[string]interface{}, error)
+	// BatchSize is the number of rows read and written together, default
+	// 1000.
+	BatchSize int
+	// SkipExisting leaves out rows conflicting with rows already in dst, so
+	// an interrupted copy can be rerun.
+	SkipExisting bool
+	// Progress is called after each batch with the rows of table copied so
+	// far.
+	Progress func(table string, rows int)
+}
+
+// Copy streams the rows of tables from src to dst, which may be of another
+// dialect, e.g. to move from MySQL to Postgres. Tables must exist in dst,
+// e.g. created by AutoMigrate or ApplyMigrations. Each table is read in
+// batches in primary key order and each batch written in one statement;
+// there is no transaction across batches, so copy from a quiesced source
+// or rerun with SkipExisting. Rows of self-referencing tables must
+// reference rows with lower keys. Values are converted where dialects
+// differ (integers to booleans, bytes to text); Transform handles the rest.
+// On Postgres, key sequences are moved past the copied keys.
+//
+//	err := gormkit.Copy(ctx, mysqlManager, postgresManager, gormkit.CopySpec{
+//		Progress: func(table string, rows int) { log.Printf("%s: %d rows", table, rows) },
+//	})
+func Copy(ctx context.Context, src, dst *Manager, spec CopySpec) error {
+	if spec.BatchSize <= 0 {
+		spec.BatchSize = 1000
+	}
+	srcDB := src.db.WithContext(withoutDeadlineAudit(ctx))
+	dstDB := dst.db.WithContext(withoutDeadlineAudit(ctx))
+
+	tables := spec.Tables
+	if len(tables) == 0 {
+		all, err := srcDB.Migrator().GetTables()
+		if err != nil {
+			return fmt.Errorf("failed to list tables: %w", err)
+		}
+		set := &rowSet{rows: make(map[string][]map[string]interface{})}
+		for _, table := range all {
+			if !strings.HasPrefix(table, "gormkit_") && !strings.HasPrefix(table, "sqlite_") {
+				set.rows[table] = nil
+			}
+		}
+		fks, err := src.ForeignKeys(ctx)
+		if err != nil {
+			return err
+		}
+		tables = set.order(fks)
+	}
+
+	for _, table := range tables {
+		if err := copyTable(srcDB, dstDB, table, spec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyTable(src, dst *gorm.DB, table string, spec CopySpec) error {
+	key, err := primaryKeyOf(src, table)
+	if err != nil {
+		return err
+	}
+	convert, err := columnConverters(dst, table)
+	if err != nil {
+		return err
+	}
+	transform := spec.Transform[table]
+	column := clause.Column{Name: key}
+
+	var last interface{}
+	copied := 0
+	for {
+		q := src.Table(table).Order(clause.OrderByColumn{Column: column}).Limit(spec.BatchSize)
+		if last != nil {
+			q = q.Where(clause.Gt{Column: column, Value: last})
+		}
+		var rows []map[string]interface{}
+		if err := q.Find(&rows).Error; err != nil {
+			return fmt.Errorf("failed to read %s: %w", table, err)
+		}
+		if len(rows) == 0 {
+			break
+		}
+		last = rows[len(rows)-1][key]
+
+		batch := make([]map[string]interface{}, 0, len(rows))
+		for _, row := range rows {
+			if transform != nil {
+				if row, err = transform(row); err != nil {
+					return fmt.Errorf("failed to transform a row of %s: %w", table, err)
+				}
+				if row == nil {
+					continue
+				}
+			}
+			for name, value := range row {
+				if f := convert[name]; f != nil {
+					row[name] = f(value)
+				}
+			}
+			batch = append(batch, row)
+		}
+		if len(batch) > 0 {
+			w := dst.Table(table)
+			if spec.SkipExisting {
+				w = w.Clauses(SQL.SkipConflicts(dst, key))
+			}
+			if err := w.Create(&batch).Error; err != nil {
+				return fmt.Errorf("failed to write %s: %w", table, err)
+			}
+		}
+		copied += len(batch)
+		if spec.Progress != nil {
+			spec.Progress(table, copied)
+		}
+		if len(rows) < spec.BatchSize {
+			break
+		}
+	}
+
+	if copied > 0 && flavorOf(dst) == "postgres" {
+		return resetSequence(dst, table, key)
+	}
+	return nil
+}
+
+// primaryKeyOf returns the single primary key column of table, or id.
+func primaryKeyOf(db *gorm.DB, table string) (string, error) {
+	columns, err := db.Migrator().ColumnTypes(table)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the columns of %s: %w", table, err)
+	}
+	var keys []string
+	hasID := false
+	for _, c := range columns {
+		if pk, ok := c.PrimaryKey(); ok && pk {
+			keys = append(keys, c.Name())
+		}
+		hasID = hasID || c.Name() == "id"
+	}
+	switch {
+	case len(keys) == 1:
+		return keys[0], nil
+	case hasID:
+		return "id", nil
+	}
+	return "", fmt.Errorf("%s has no single-column primary key to copy by (has %v)", table, keys)
+}
+
+// columnConverters returns, per column of table, a conversion of values
+// read from another dialect that its type would reject.
+func columnConverters(db *gorm.DB, table string) (map[string]func(interface{}) interface{}, error) {
+	columns, err := db.Migrator().ColumnTypes(table)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the columns of %s: %w", table, err)
+	}
+	converters := make(map[string]func(interface{}) interface{})
+	for _, c := range columns {
+		typ := strings.ToLower(c.DatabaseTypeName())
+		switch {
+		case typ == "bool" || typ == "boolean":
+			converters[c.Name()] = toBool
+		case !strings.Contains(typ, "bytea") && !strings.Contains(typ, "blob") && !strings.Contains(typ, "binary"):
+			converters[c.Name()] = bytesToString
+		}
+	}
+	return converters, nil
+}
+
+// toBool converts the integers MySQL and SQLite store booleans as.
+func toBool(v interface{}) interface{} {
+	switch v := v.(type) {
+	case int64:
+		return v != 0
+	case int32:
+		return v != 0
+	case int:
+		return v != 0
+	case uint8:
+		return v != 0
+	case uint64:
+		return v != 0
+	}
+	return v
+}
+
+func bytesToString(v interface{}) interface{} {
+	if b, ok := v.([]byte); ok {
+		return string(b)
+	}
+	return v
+}
+
+// resetSequence moves the Postgres sequence of table's column past its
+// largest value, so later inserts don't collide with copied rows. Columns
+// without a sequence, such as uuid or text keys, are left alone.
+func resetSequence(db *gorm.DB, table, column string) error {
+	var sequence sql.NullString
+	if err := db.Raw("SELECT pg_get_serial_sequence(?, ?)", table, column).Row().Scan(&sequence); err != nil {
+		return fmt.Errorf("failed to find the %s sequence of %s: %w", column, table, err)
+	}
+	if !sequence.Valid {
+		return nil
+	}
+	err := db.Exec(fmt.Sprintf("SELECT setval(?::regclass, MAX(%s)) FROM %s",
+		db.Statement.Quote(column), db.Statement.Quote(table)), sequence.String).Error
+	if err != nil {
+		return fmt.Errorf("failed to reset the %s sequence of %s: %w", column, table, err)
+	}
+	return nil
+}
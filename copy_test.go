@@ -0,0 +1,58 @@
+package gormkit_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestCopy(t *testing.T) {
+	src := gormkit.NewTestManager(t, &Customer{}, &Invoice{})
+	ctx := context.Background()
+	customers := []Customer{{Email: "A@corp.com"}, {Email: "B@corp.com"}, {Email: "spam@corp.com"}, {Email: "C@corp.com"}}
+	src.DB().Create(&customers)
+	src.DB().Create(&[]Invoice{{CustomerID: customers[0].ID, Total: 1}, {CustomerID: customers[3].ID, Total: 2}})
+
+	dst := gormkit.NewTestManager(t, &Customer{}, &Invoice{})
+	progress := map[string][]int{}
+	spec := gormkit.CopySpec{
+		BatchSize: 2,
+		Transform: map[string]func(map[string]interface{}) (map[string]interface{}, error){
+			"customers": func(row map[string]interface{}) (map[string]interface{}, error) {
+				if strings.HasPrefix(row["email"].(string), "spam") {
+					return nil, nil
+				}
+				row["email"] = strings.ToLower(row["email"].(string))
+				return row, nil
+			},
+		},
+		Progress: func(table string, rows int) { progress[table] = append(progress[table], rows) },
+	}
+	if err := gormkit.Copy(ctx, src, dst, spec); err != nil {
+		t.Fatal(err)
+	}
+
+	var copied []Customer
+	dst.DB().Order("id").Find(&copied)
+	if len(copied) != 3 || copied[0].Email != "a@corp.com" || copied[2].ID != customers[3].ID {
+		t.Errorf("Unexpected customers: %+v", copied)
+	}
+	var invoices []Invoice
+	dst.DB().Order("id").Find(&invoices)
+	if len(invoices) != 2 || invoices[1].CustomerID != customers[3].ID {
+		t.Errorf("Unexpected invoices: %+v", invoices)
+	}
+	if got := progress["customers"]; len(got) != 2 || got[0] != 2 || got[1] != 3 {
+		t.Errorf("Unexpected customer progress: %v", got)
+	}
+
+	// A rerun fails on the copied rows unless they are skipped.
+	if err := gormkit.Copy(ctx, src, dst, gormkit.CopySpec{Tables: []string{"invoices"}}); err == nil {
+		t.Error("Expected a conflict copying invoices twice")
+	}
+	if err := gormkit.Copy(ctx, src, dst, gormkit.CopySpec{Tables: []string{"invoices"}, SkipExisting: true}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,62 @@
+package gormkit_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestIncrement(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &Article{})
+	db := manager.DB()
+	article := Article{Title: "hello"}
+	db.Create(&article)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := gormkit.Increment(ctx, db, &Article{}, article.ID, "views", 1); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var got Article
+	db.First(&got, article.ID)
+	if got.Views != 20 {
+		t.Errorf("Expected 20 views, got %d", got.Views)
+	}
+
+	if err := gormkit.Increment(ctx, db, &Article{}, article.ID+1, "views", 1); !errors.Is(err, gormkit.ErrNoRowsAffected) {
+		t.Errorf("Expected ErrNoRowsAffected, got %v", err)
+	}
+}
+
+func TestIncrementMany(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &Article{})
+	db := manager.DB()
+	articles := []Article{{Title: "a"}, {Title: "b"}, {Title: "c"}}
+	db.Create(&articles)
+
+	err := gormkit.IncrementMany(context.Background(), db, &Article{}, "views", map[interface{}]int64{
+		articles[0].ID: 1,
+		articles[1].ID: 1,
+		articles[2].ID: 5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var views []int64
+	db.Model(&Article{}).Order("id").Pluck("views", &views)
+	if len(views) != 3 || views[0] != 1 || views[1] != 1 || views[2] != 5 {
+		t.Errorf("Unexpected views: %v", views)
+	}
+}
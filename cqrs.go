@@ -0,0 +1,250 @@
+package gormkit
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// Projection is a read model kept up to date from changes to write models.
+type Projection struct {
+	// Model is the read model, e.g. &OrderSummary{}. It is written through
+	// the write Manager and read through the read one.
+	Model interface{}
+	// Sources names the registered write models feeding it, e.g. "Order".
+	Sources []string
+	// Apply updates the read model from one change of a source, in a
+	// transaction on the write Manager. Lifecycle events arrive after
+	// commit but not necessarily in order (CDC changes do), so write the
+	// read model from the event's value, e.g. with an upsert, rather than
+	// incrementally. Write sources in a transaction with CQRS.Transaction
+	// rather than Write(ctx).Transaction, whose changes would be applied
+	// before it commits, even if it rolls back.
+	Apply func(ctx context.Context, tx *gorm.DB, e Event) error
+}
+
+// CQRS separates commands, written through the primary Manager to write
+// models, from queries, read from projections through a replica or
+// read-only Manager. Projections follow the write models' lifecycle events,
+// or their CDC stream after FollowCDC, and can be rebuilt from the write
+// models at any time.
+type CQRS struct {
+	write, read *Manager
+
+	mu          sync.Mutex
+	models      map[string]reflect.Type // write models by name
+	tables      map[string]string       // write model names by table
+	projections map[string]*projection
+	cdc         *CDC
+}
+
+type projection struct {
+	Projection
+	unsubscribe []func()
+}
+
+// NewCQRS returns a CQRS writing through write and reading through read,
+// or through write as well when read is nil.
+//
+//	app := gormkit.NewCQRS(primary, replica)
+//	app.RegisterWriteModels(&Order{})
+//	app.RegisterProjection("order_totals", gormkit.Projection{
+//		Model:   &OrderTotal{},
+//		Sources: []string{"Order"},
+//		Apply: func(ctx context.Context, tx *gorm.DB, e gormkit.Event) error {
+//			order := e.Value.(*Order)
+//			...
+//		},
+//	})
+func NewCQRS(write, read *Manager) *CQRS {
+	if read == nil {
+		read = write
+	}
+	return &CQRS{
+		write:       write,
+		read:        read,
+		models:      make(map[string]reflect.Type),
+		tables:      make(map[string]string),
+		projections: make(map[string]*projection),
+	}
+}
+
+// Write returns a session on the write Manager, for commands.
+func (c *CQRS) Write(ctx context.Context) *gorm.DB {
+	return c.write.db.WithContext(ctx)
+}
+
+// Transaction runs a command of several writes in a transaction on the
+// write Manager. Projections see its changes once it commits, and none of
+// them if it rolls back.
+func (c *CQRS) Transaction(ctx context.Context, fn func(tx *gorm.DB) error) error {
+	return c.write.Transaction(ctx, fn)
+}
+
+// Read returns a session on the read Manager, for queries of projections.
+// Replicas lag, so a command's effects may not be visible immediately.
+func (c *CQRS) Read(ctx context.Context) *gorm.DB {
+	return c.read.db.WithContext(ctx)
+}
+
+// RegisterWriteModels registers the models commands write, so projections
+// can name them as sources.
+func (c *CQRS) RegisterWriteModels(models ...interface{}) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: c.write.db}
+		if err := stmt.Parse(model); err != nil {
+			return err
+		}
+		c.models[stmt.Schema.Name] = stmt.Schema.ModelType
+		c.tables[stmt.Schema.Table] = stmt.Schema.Name
+	}
+	return nil
+}
+
+// RegisterProjection registers the projection name, creating its table on
+// the write Manager if needed, and starts feeding it changes of its
+// sources. Existing rows of the sources are not applied; call Rebuild for
+// that.
+func (c *CQRS) RegisterProjection(name string, p Projection) error {
+	if p.Model == nil || p.Apply == nil {
+		return fmt.Errorf("projection %s needs a Model and Apply", name)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.projections[name]; ok {
+		return fmt.Errorf("projection %s is already registered", name)
+	}
+	for _, source := range p.Sources {
+		if _, ok := c.models[source]; !ok {
+			return fmt.Errorf("projection %s: %s is not a registered write model", name, source)
+		}
+	}
+	if err := c.write.db.AutoMigrate(p.Model); err != nil {
+		return err
+	}
+
+	proj := &projection{Projection: p}
+	if c.cdc == nil {
+		c.subscribeEvents(proj)
+	}
+	c.projections[name] = proj
+	return nil
+}
+
+// subscribeEvents feeds proj the lifecycle events of its sources.
+func (c *CQRS) subscribeEvents(proj *projection) {
+	for _, source := range proj.Sources {
+		unsubscribe := c.write.events.Subscribe(source+".*", func(ctx context.Context, e Event) error {
+			return c.apply(ctx, proj, e)
+		}, Async)
+		proj.unsubscribe = append(proj.unsubscribe, unsubscribe)
+	}
+}
+
+func (c *CQRS) apply(ctx context.Context, proj *projection, e Event) error {
+	return c.write.Transaction(ctx, func(tx *gorm.DB) error {
+		return proj.Apply(ctx, tx, e)
+	})
+}
+
+// FollowCDC feeds projections from cdc instead of lifecycle events: in
+// commit order, including changes made outside this process, and without
+// losing changes to a crash between commit and delivery.
+func (c *CQRS) FollowCDC(cdc *CDC) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.cdc = cdc
+	for _, proj := range c.projections {
+		for _, unsubscribe := range proj.unsubscribe {
+			unsubscribe()
+		}
+		proj.unsubscribe = nil
+	}
+	for table, name := range c.tables {
+		cdc.Subscribe(table, func(ctx context.Context, change ChangeEvent) error {
+			return c.applyChange(ctx, name, change)
+		})
+	}
+}
+
+// applyChange applies a CDC change of write model name to the projections
+// it feeds, as the equivalent lifecycle event.
+func (c *CQRS) applyChange(ctx context.Context, name string, change ChangeEvent) error {
+	c.mu.Lock()
+	typ := c.models[name]
+	var projs []*projection
+	for _, proj := range c.projections {
+		for _, source := range proj.Sources {
+			if source == name {
+				projs = append(projs, proj)
+			}
+		}
+	}
+	c.mu.Unlock()
+	if len(projs) == 0 {
+		return nil
+	}
+
+	value := reflect.New(typ).Interface()
+	if err := change.Scan(value); err != nil {
+		return err
+	}
+	action := map[string]string{"insert": "created", "update": "updated", "delete": "deleted"}[change.Op]
+	e := Event{Name: name + "." + action, Model: name, Action: action, Value: value}
+	for _, proj := range projs {
+		if err := c.apply(ctx, proj, e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Rebuild empties projection name and applies every row of its sources to
+// it as a created event, in one transaction, e.g. after changing Apply or
+// to repair drift.
+func (c *CQRS) Rebuild(ctx context.Context, name string) error {
+	c.mu.Lock()
+	proj, ok := c.projections[name]
+	types := make(map[string]reflect.Type)
+	if ok {
+		for _, source := range proj.Sources {
+			types[source] = c.models[source]
+		}
+	}
+	c.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("projection %s is not registered", name)
+	}
+
+	return c.write.Transaction(ctx, func(tx *gorm.DB) error {
+		if err := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Unscoped().Delete(proj.Model).Error; err != nil {
+			return err
+		}
+		for _, source := range proj.Sources {
+			rows := reflect.New(reflect.SliceOf(types[source]))
+			err := tx.Session(&gorm.Session{}).FindInBatches(rows.Interface(), 500, func(batch *gorm.DB, _ int) error {
+				for i := 0; i < rows.Elem().Len(); i++ {
+					e := Event{
+						Name:   source + ".created",
+						Model:  source,
+						Action: "created",
+						Value:  rows.Elem().Index(i).Addr().Interface(),
+					}
+					if err := proj.Apply(ctx, tx, e); err != nil {
+						return err
+					}
+				}
+				return nil
+			}).Error
+			if err != nil {
+				return fmt.Errorf("failed to rebuild %s from %s: %w", name, source, err)
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,110 @@
+package gormkit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alinemone/gorm-kit"
+	"gorm.io/gorm"
+)
+
+type customerTotal struct {
+	CustomerID uint `gorm:"primaryKey;autoIncrement:false"`
+	Invoices   int
+	Total      float64
+	DeletedAt  gorm.DeletedAt
+}
+
+// applyCustomerTotal recomputes the customer's row from the invoices, so
+// the order events arrive in doesn't matter.
+func applyCustomerTotal(ctx context.Context, tx *gorm.DB, e gormkit.Event) error {
+	invoice := e.Value.(*Invoice)
+	row := customerTotal{CustomerID: invoice.CustomerID}
+	err := tx.Model(&Invoice{}).Where("customer_id = ?", invoice.CustomerID).
+		Select("COUNT(*), COALESCE(SUM(total), 0)").Row().Scan(&row.Invoices, &row.Total)
+	if err != nil {
+		return err
+	}
+	return tx.Save(&row).Error
+}
+
+func waitForTotal(t *testing.T, db *gorm.DB, customerID uint, want float64) {
+	t.Helper()
+	var row customerTotal
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		if db.Where("customer_id = ?", customerID).Limit(1).Find(&row).Error == nil && row.Total == want {
+			return
+		}
+	}
+	t.Fatalf("Expected total %v for customer %d, got %+v", want, customerID, row)
+}
+
+func TestCQRSProjection(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &Customer{}, &Invoice{})
+	app := gormkit.NewCQRS(manager, nil)
+	ctx := context.Background()
+
+	if err := app.RegisterProjection("customer_totals", gormkit.Projection{
+		Model: &customerTotal{}, Sources: []string{"Invoice"}, Apply: applyCustomerTotal,
+	}); err == nil {
+		t.Fatal("Expected an error for an unregistered source")
+	}
+	if err := app.RegisterWriteModels(&Customer{}, &Invoice{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.RegisterProjection("customer_totals", gormkit.Projection{
+		Model: &customerTotal{}, Sources: []string{"Invoice"}, Apply: applyCustomerTotal,
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	customer := Customer{Email: "a@example.com"}
+	app.Write(ctx).Create(&customer)
+	invoice := Invoice{CustomerID: customer.ID, Total: 50}
+	app.Write(ctx).Create(&invoice)
+	waitForTotal(t, app.Read(ctx), customer.ID, 50)
+
+	app.Write(ctx).Create(&Invoice{CustomerID: customer.ID, Total: 30})
+	waitForTotal(t, app.Read(ctx), customer.ID, 80)
+
+	// A rolled-back command never reaches the projection.
+	app.Transaction(ctx, func(tx *gorm.DB) error {
+		tx.Create(&Invoice{CustomerID: customer.ID, Total: 1000})
+		return errors.New("rollback")
+	})
+	app.Write(ctx).Create(&Invoice{CustomerID: customer.ID, Total: 20})
+	waitForTotal(t, app.Read(ctx), customer.ID, 100)
+
+	// Drift, e.g. from a bulk update that fires no events, is repaired by a
+	// rebuild.
+	app.Write(ctx).Exec("UPDATE invoices SET total = 100")
+	app.Write(ctx).Exec("INSERT INTO customer_totals (customer_id, invoices, total) VALUES (99, 1, 1)")
+	if err := app.Rebuild(ctx, "customer_totals"); err != nil {
+		t.Fatal(err)
+	}
+	var rows []customerTotal
+	app.Read(ctx).Unscoped().Find(&rows)
+	if len(rows) != 1 || rows[0].Invoices != 3 || rows[0].Total != 300 {
+		t.Errorf("Expected one rebuilt row with 3 invoices totalling 300, got %+v", rows)
+	}
+
+	if err := app.Rebuild(ctx, "missing"); err == nil {
+		t.Error("Expected an error for an unknown projection")
+	}
+}
+
+func TestCQRSReadsFromReadManager(t *testing.T) {
+	write := gormkit.NewTestManager(t, &Invoice{})
+	read := gormkit.NewTestManager(t)
+	app := gormkit.NewCQRS(write, read)
+	ctx := context.Background()
+
+	if err := app.Write(ctx).Create(&Invoice{Total: 1}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := app.Read(ctx).Find(&[]Invoice{}).Error; err == nil {
+		t.Error("Expected reads to go to the read manager, which has no invoices table")
+	}
+}
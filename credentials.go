@@ -0,0 +1,53 @@
+package gormkit
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Credentials are a database user and password, valid until ExpiresAt when
+// it is set.
+type Credentials struct {
+	User      string
+	Password  string
+	ExpiresAt time.Time
+}
+
+// CredentialProvider supplies the credentials of new connections, for
+// short-lived secrets such as Vault dynamic users or cloud IAM tokens. It is
+// called for every connection the pool opens, so it should cache.
+type CredentialProvider interface {
+	Credentials(ctx context.Context) (Credentials, error)
+}
+
+// credentialsFor fetches the credentials of a new connection and remembers
+// them as the current ones.
+func (m *Manager) credentialsFor(ctx context.Context) (Credentials, error) {
+	creds, err := m.config.CredentialProvider.Credentials(ctx)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("failed to get credentials: %w", err)
+	}
+	m.creds.Store(&creds)
+	return creds, nil
+}
+
+// Reconnect moves the pool to fresh credentials: it fetches them from the
+// CredentialProvider, closes idle connections and opens a new one. Busy
+// connections finish their work first; on Postgres those of a previous user
+// are replaced when next used, elsewhere ConnMaxLifetime ends them. Call it
+// when the provider's credentials are about to expire.
+func (m *Manager) Reconnect(ctx context.Context) error {
+	if m.config.CredentialProvider != nil {
+		if _, err := m.credentialsFor(ctx); err != nil {
+			return err
+		}
+	}
+
+	m.mu.Lock()
+	m.sqlDB.SetMaxIdleConns(0)
+	m.sqlDB.SetMaxIdleConns(m.config.MaxIdleConns)
+	m.mu.Unlock()
+
+	return m.sqlDB.PingContext(ctx)
+}
@@ -0,0 +1,40 @@
+package gormkit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+type staticCredentials struct{}
+
+func (staticCredentials) Credentials(context.Context) (gormkit.Credentials, error) {
+	return gormkit.Credentials{User: "app", Password: "secret"}, nil
+}
+
+func TestCredentialProviderUnsupportedOnSQLite(t *testing.T) {
+	_, err := gormkit.New(&gormkit.Config{
+		Driver:             "test",
+		LogLevel:           "silent",
+		CredentialProvider: staticCredentials{},
+	})
+	if !errors.Is(err, gormkit.ErrUnsupportedDriver) {
+		t.Errorf("Expected ErrUnsupportedDriver, got %v", err)
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &User{})
+	if err := manager.DB().Create(&User{Name: "Ali"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.Reconnect(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	var count int64
+	if err := manager.DB().Model(&User{}).Count(&count).Error; err != nil || count != 1 {
+		t.Errorf("Expected the pool to keep working, got %d, %v", count, err)
+	}
+}
@@ -0,0 +1,310 @@
+package gormkit
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Trigger is a row-level trigger created by Schema.CreateTrigger.
+type Trigger struct {
+	Name   string
+	Table  string
+	Timing string   // BEFORE or AFTER
+	Events []string // INSERT, UPDATE and/or DELETE
+	// Body holds the statements run for each row, in the dialect's
+	// procedural language, e.g. "NEW.updated_at := now();" on Postgres or
+	// "SET NEW.updated_at = NOW();" on MySQL.
+	Body string
+}
+
+// Function is a stored function created by Schema.CreateFunction.
+type Function struct {
+	Name          string
+	Args          string // e.g. "a integer, b integer"
+	Returns       string
+	Body          string // Postgres: the function body; MySQL: the routine body, e.g. "RETURN a + b"
+	Language      string // Postgres only, default plpgsql
+	Deterministic bool   // IMMUTABLE on Postgres, DETERMINISTIC on MySQL
+}
+
+// Index is an index created by Schema.CreateIndex, for what index tags
+// can't express: partial and expression indexes.
+type Index struct {
+	// Name defaults to idx_<table>_<columns and expressions>.
+	Name    string
+	Table   string
+	Columns []string
+	// Expressions are indexed after Columns, e.g. "lower(email)". MySQL
+	// needs 8.0.13 for them and MariaDB has none, so the index is skipped
+	// there.
+	Expressions []string
+	Unique      bool
+	// Where makes a partial index of the rows matching it, e.g.
+	// "deleted_at IS NULL". MySQL has no partial indexes: a plain index
+	// covers every row instead, and a unique one fails the migration with
+	// ErrUnsupportedDriver, since it would reject rows the predicate
+	// excludes and skipping it would leave uniqueness unenforced.
+	Where string
+	// Concurrently builds the index without blocking writes on Postgres,
+	// as CreateIndexConcurrently does.
+	Concurrently bool
+}
+
+// ForeignKey is a constraint added by Schema.AddForeignKey.
+type ForeignKey struct {
+	Name       string
+	Table      string
+	Columns    []string
+	References string // referenced table
+	RefColumns []string
+	OnDelete   string // e.g. CASCADE, SET NULL
+	OnUpdate   string
+	// Deferrable checks the constraint at commit instead of per
+	// statement, so rows referencing each other can be inserted in any
+	// order within a transaction. Postgres only.
+	Deferrable bool
+	// NotValid skips checking existing rows, which blocks writes on large
+	// tables; check them later with Schema.ValidateConstraint. Postgres
+	// only.
+	NotValid bool
+}
+
+func (s *Schema) quote(name string) string {
+	return s.db.Statement.Quote(name)
+}
+
+func (s *Schema) quoteAll(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = s.quote(name)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// CreateTrigger creates or replaces t. On Postgres the body is wrapped in a
+// trigger function named after the trigger; MySQL and SQLite allow one
+// event per trigger, so a trigger with several events is created once per
+// event, suffixed with the event name.
+func (s *Schema) CreateTrigger(t Trigger) {
+	timing := strings.ToUpper(t.Timing)
+	switch flavorOf(s.db) {
+	case "postgres":
+		fn := s.quote(t.Name + "_fn")
+		s.Exec(fmt.Sprintf(`CREATE OR REPLACE FUNCTION %s() RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+%s
+IF TG_OP = 'DELETE' THEN RETURN OLD; END IF;
+RETURN NEW;
+END
+$$`, fn, t.Body))
+		s.Exec(fmt.Sprintf("DROP TRIGGER IF EXISTS %s ON %s", s.quote(t.Name), s.quote(t.Table)))
+		s.Exec(fmt.Sprintf("CREATE TRIGGER %s %s %s ON %s FOR EACH ROW EXECUTE PROCEDURE %s()",
+			s.quote(t.Name), timing, strings.ToUpper(strings.Join(t.Events, " OR ")), s.quote(t.Table), fn))
+	default:
+		body := strings.TrimSpace(t.Body)
+		if !strings.HasSuffix(body, ";") {
+			body += ";"
+		}
+		for _, event := range t.Events {
+			name := t.Name
+			if len(t.Events) > 1 {
+				name += "_" + strings.ToLower(event)
+			}
+			s.Exec(fmt.Sprintf("DROP TRIGGER IF EXISTS %s", s.quote(name)))
+			s.Exec(fmt.Sprintf("CREATE TRIGGER %s %s %s ON %s FOR EACH ROW BEGIN %s END",
+				s.quote(name), timing, strings.ToUpper(event), s.quote(t.Table), body))
+		}
+	}
+}
+
+// CreateFunction creates or replaces f. SQLite has no stored functions, so
+// it is skipped there.
+func (s *Schema) CreateFunction(f Function) {
+	switch flavorOf(s.db) {
+	case "postgres":
+		language := f.Language
+		if language == "" {
+			language = "plpgsql"
+		}
+		volatility := ""
+		if f.Deterministic {
+			volatility = " IMMUTABLE"
+		}
+		s.Exec(fmt.Sprintf("CREATE OR REPLACE FUNCTION %s(%s) RETURNS %s LANGUAGE %s%s AS $$\n%s\n$$",
+			s.quote(f.Name), f.Args, f.Returns, language, volatility, f.Body))
+	case "mysql":
+		characteristic := "NOT DETERMINISTIC"
+		if f.Deterministic {
+			characteristic = "DETERMINISTIC"
+		}
+		s.Exec(fmt.Sprintf("DROP FUNCTION IF EXISTS %s", s.quote(f.Name)))
+		s.Exec(fmt.Sprintf("CREATE FUNCTION %s(%s) RETURNS %s %s %s",
+			s.quote(f.Name), f.Args, f.Returns, characteristic, f.Body))
+	default:
+		s.skip("skipped function %s: %s has no stored functions", f.Name, flavorOf(s.db))
+	}
+}
+
+// AddCheckConstraint adds a CHECK constraint to table. SQLite can't add
+// constraints to existing tables, so it is skipped there; declare it with a
+// check tag on the model instead.
+func (s *Schema) AddCheckConstraint(table, name, expr string) {
+	if flavorOf(s.db) == "sqlite" {
+		s.skip("skipped check constraint %s: sqlite can't add constraints to existing tables", name)
+		return
+	}
+	s.Exec(fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s CHECK (%s)", s.quote(table), s.quote(name), expr))
+}
+
+// AddForeignKey adds fk. Deferrable constraints are created as immediate
+// ones on MySQL, and SQLite, which can't add constraints to existing
+// tables, skips it.
+func (s *Schema) AddForeignKey(fk ForeignKey) {
+	dialect := flavorOf(s.db)
+	if dialect == "sqlite" {
+		s.skip("skipped foreign key %s: sqlite can't add constraints to existing tables", fk.Name)
+		return
+	}
+	sql := fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)",
+		s.quote(fk.Table), s.quote(fk.Name), s.quoteAll(fk.Columns), s.quote(fk.References), s.quoteAll(fk.RefColumns))
+	if fk.OnDelete != "" {
+		sql += " ON DELETE " + fk.OnDelete
+	}
+	if fk.OnUpdate != "" {
+		sql += " ON UPDATE " + fk.OnUpdate
+	}
+	op := Operation{SQL: sql}
+	if fk.Deferrable {
+		if dialect == "postgres" {
+			op.SQL += " DEFERRABLE INITIALLY DEFERRED"
+		} else {
+			op.Note = fmt.Sprintf("foreign key %s created as not deferrable: %s has no deferred constraints", fk.Name, dialect)
+		}
+	}
+	if fk.NotValid && dialect == "postgres" {
+		op.SQL += " NOT VALID"
+	}
+	s.ops = append(s.ops, op)
+}
+
+// ReplaceForeignKey drops the foreign key fk.Name of fk.Table and adds fk
+// in its place, e.g. to change its ON DELETE action. On MySQL the old key
+// must exist. SQLite, which can't change constraints of existing tables,
+// skips it.
+func (s *Schema) ReplaceForeignKey(fk ForeignKey) {
+	switch flavorOf(s.db) {
+	case "postgres":
+		s.Exec(fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s", s.quote(fk.Table), s.quote(fk.Name)))
+	case "mysql":
+		s.Exec(fmt.Sprintf("ALTER TABLE %s DROP FOREIGN KEY %s", s.quote(fk.Table), s.quote(fk.Name)))
+	default:
+		s.skip("skipped replacing foreign key %s: sqlite can't change constraints of existing tables", fk.Name)
+		return
+	}
+	s.AddForeignKey(fk)
+}
+
+// ValidateConstraint checks the existing rows against a constraint added
+// NOT VALID, without blocking writes. Postgres only; elsewhere constraints
+// are validated when added and it is skipped.
+func (s *Schema) ValidateConstraint(table, name string) {
+	if flavorOf(s.db) != "postgres" {
+		s.skip("skipped validating %s: %s validates constraints when adding them", name, flavorOf(s.db))
+		return
+	}
+	s.Exec(fmt.Sprintf("ALTER TABLE %s VALIDATE CONSTRAINT %s", s.quote(table), s.quote(name)))
+}
+
+// CreateIndexConcurrently builds an index on columns of table without
+// blocking writes: CREATE INDEX CONCURRENTLY on Postgres, run outside the
+// migration's transaction as it requires, and an online (LOCK=NONE) build
+// on MySQL. The index is named idx_<table>_<columns>.
+//
+// A failed concurrent build leaves an invalid index behind, so on Postgres
+// any index of that name is dropped first and a rerun starts over.
+func (s *Schema) CreateIndexConcurrently(table string, columns ...string) {
+	name := "idx_" + strings.Join(append([]string{table}, columns...), "_")
+	switch flavorOf(s.db) {
+	case "postgres":
+		s.ops = append(s.ops,
+			Operation{SQL: fmt.Sprintf("DROP INDEX CONCURRENTLY IF EXISTS %s", s.quote(name)), NoTransaction: true},
+			Operation{SQL: fmt.Sprintf("CREATE INDEX CONCURRENTLY %s ON %s (%s)",
+				s.quote(name), s.quote(table), s.quoteAll(columns)), NoTransaction: true},
+		)
+	case "mysql":
+		s.Exec(fmt.Sprintf("CREATE INDEX %s ON %s (%s) ALGORITHM=INPLACE LOCK=NONE",
+			s.quote(name), s.quote(table), s.quoteAll(columns)))
+	default:
+		s.Exec(fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (%s)", s.quote(name), s.quote(table), s.quoteAll(columns)))
+	}
+}
+
+var indexNameInvalid = regexp.MustCompile(`[^a-z0-9]+`)
+
+// CreateIndex creates idx, e.g. a unique index on emails of rows that
+// aren't soft-deleted, or one on lower(email):
+//
+//	s.CreateIndex(gormkit.Index{Table: "users", Columns: []string{"email"}, Unique: true, Where: "deleted_at IS NULL"})
+//	s.CreateIndex(gormkit.Index{Table: "users", Expressions: []string{"lower(email)"}})
+func (s *Schema) CreateIndex(idx Index) {
+	if idx.Name == "" {
+		parts := append([]string{"idx", idx.Table}, idx.Columns...)
+		for _, expr := range idx.Expressions {
+			parts = append(parts, strings.Trim(indexNameInvalid.ReplaceAllString(strings.ToLower(expr), "_"), "_"))
+		}
+		idx.Name = strings.Join(parts, "_")
+	}
+	dialect := flavorOf(s.db)
+	keys := make([]string, 0, len(idx.Columns)+len(idx.Expressions))
+	for _, column := range idx.Columns {
+		keys = append(keys, s.quote(column))
+	}
+	for _, expr := range idx.Expressions {
+		if dialect == "mysql" {
+			// MySQL takes expressions as functional key parts, in their
+			// own parentheses.
+			expr = "(" + expr + ")"
+		}
+		keys = append(keys, expr)
+	}
+
+	create := "CREATE INDEX "
+	if idx.Unique {
+		create = "CREATE UNIQUE INDEX "
+	}
+	where := ""
+	if idx.Where != "" {
+		where = " WHERE " + idx.Where
+	}
+
+	switch dialect {
+	case "postgres":
+		if idx.Concurrently {
+			s.ops = append(s.ops,
+				Operation{SQL: fmt.Sprintf("DROP INDEX CONCURRENTLY IF EXISTS %s", s.quote(idx.Name)), NoTransaction: true},
+				Operation{SQL: fmt.Sprintf("%sCONCURRENTLY %s ON %s (%s)%s",
+					create, s.quote(idx.Name), s.quote(idx.Table), strings.Join(keys, ", "), where), NoTransaction: true},
+			)
+			return
+		}
+		s.Exec(fmt.Sprintf("%sIF NOT EXISTS %s ON %s (%s)%s", create, s.quote(idx.Name), s.quote(idx.Table), strings.Join(keys, ", "), where))
+	case "mysql":
+		info := serverInfoOf(s.db)
+		if len(idx.Expressions) > 0 && (info.Flavor == "mariadb" || !versionAtLeast(info, ServerInfo{Major: 8, Patch: 13})) {
+			s.skip("skipped index %s: %s %s has no expression indexes", idx.Name, info.Flavor, info.Version)
+			return
+		}
+		if idx.Where != "" && idx.Unique {
+			s.fail(fmt.Errorf("%w: unique index %s: mysql has no partial indexes", ErrUnsupportedDriver, idx.Name))
+			return
+		}
+		op := Operation{SQL: fmt.Sprintf("%s%s ON %s (%s)", create, s.quote(idx.Name), s.quote(idx.Table), strings.Join(keys, ", "))}
+		if idx.Where != "" {
+			op.Note = fmt.Sprintf("index %s covers every row: mysql has no partial indexes", idx.Name)
+		}
+		s.ops = append(s.ops, op)
+	default:
+		s.Exec(fmt.Sprintf("%sIF NOT EXISTS %s ON %s (%s)%s", create, s.quote(idx.Name), s.quote(idx.Table), strings.Join(keys, ", "), where))
+	}
+}
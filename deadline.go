@@ -0,0 +1,46 @@
+package gormkit
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// deadlineAudit flags statements whose context has no deadline, so handlers
+// that forget a timeout are found in development instead of in an incident.
+type deadlineAudit struct {
+	mode   string // "warn" or "error"
+	warned sync.Map
+}
+
+func (a *deadlineAudit) check(db *gorm.DB) {
+	ctx := db.Statement.Context
+	if db.Error != nil || ctx == nil {
+		return
+	}
+	if _, ok := ctx.Deadline(); ok || ctx.Value(deadlineExemptKey) != nil {
+		return
+	}
+
+	caller := fileWithLineNum()
+	if a.mode == "error" {
+		db.AddError(fmt.Errorf("%w at %s", ErrNoDeadline, caller))
+		return
+	}
+	if _, seen := a.warned.LoadOrStore(caller, struct{}{}); !seen {
+		log.Printf("gormkit: query without context deadline at %s", caller)
+	}
+}
+
+// internalDB returns a handle for statements gorm-kit issues on its own
+// behalf (migrations, snapshots, ...), which are exempt from the audit.
+func (m *Manager) internalDB() *gorm.DB {
+	return m.db.WithContext(withoutDeadlineAudit(context.Background()))
+}
+
+func withoutDeadlineAudit(ctx context.Context) context.Context {
+	return context.WithValue(ctx, deadlineExemptKey, true)
+}
@@ -0,0 +1,41 @@
+package gormkit_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestDeadlineAuditError(t *testing.T) {
+	manager, err := gormkit.New(&gormkit.Config{
+		Driver:        "test",
+		LogLevel:      "silent",
+		AutoMigrate:   true,
+		DeadlineAudit: "error",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	if err := manager.Migrate(&User{}); err != nil {
+		t.Fatalf("Expected migrations to be exempt: %v", err)
+	}
+
+	db := manager.DB()
+	var users []User
+	err = db.Find(&users).Error
+	if !errors.Is(err, gormkit.ErrNoDeadline) || !strings.Contains(err.Error(), "deadline_test.go") {
+		t.Errorf("Expected ErrNoDeadline with caller, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := db.WithContext(ctx).Find(&users).Error; err != nil {
+		t.Errorf("Expected query with deadline to pass, got %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package gormkit
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DebugReport is a live view of the Manager's internals, served by
+// DebugHandler.
+type DebugReport struct {
+	Time   time.Time
+	Closed bool
+	Pool   sql.DBStats
+	// Endpoints are the circuit-breaker state of Config.Hosts: ejected
+	// endpoints get no new connections until a probe reaches them again.
+	Endpoints []EndpointHealth
+	StmtCache StmtCacheStats
+	// ActiveQueries are the server's sessions running a statement, longest
+	// first (Postgres and MySQL); ActiveQueriesError tells why they are
+	// missing.
+	ActiveQueries      []SessionInfo
+	ActiveQueriesError string `json:",omitempty"`
+}
+
+// DebugReport gathers pool stats, endpoint ejections, the prepared statement
+// cache hit rate and the statements running on the server.
+func (m *Manager) DebugReport(ctx context.Context) DebugReport {
+	report := DebugReport{
+		Time:      time.Now(),
+		Closed:    m.closed.Load(),
+		Pool:      m.Stats(),
+		Endpoints: m.Endpoints(),
+		StmtCache: m.StmtCacheStats(),
+	}
+	sessions, err := m.Sessions(ctx)
+	if err != nil {
+		report.ActiveQueriesError = err.Error()
+	}
+	for _, s := range sessions {
+		if s.Query != "" && !strings.EqualFold(s.State, "idle") && !strings.EqualFold(s.State, "sleep") {
+			report.ActiveQueries = append(report.ActiveQueries, s)
+		}
+	}
+	return report
+}
+
+// DebugHandler serves the DebugReport as JSON, for quick inspection during
+// incidents, like net/http/pprof. Active queries show SQL text with its
+// values: mount it on an internal port only.
+//
+//	debugMux.Handle("/debug/gormkit", manager.DebugHandler())
+func (m *Manager) DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), defaultProbeTimeout)
+		defer cancel()
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.Encode(m.DebugReport(ctx))
+	})
+}
@@ -0,0 +1,40 @@
+package gormkit_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestDebugHandler(t *testing.T) {
+	manager, err := gormkit.New(&gormkit.Config{Driver: "test", LogLevel: "silent", PrepareStmt: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+	db := manager.DB()
+	db.AutoMigrate(&User{})
+	for i := 0; i < 3; i++ {
+		db.Where("name = ?", "alice").Find(&[]User{})
+	}
+
+	rec := httptest.NewRecorder()
+	manager.DebugHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/debug/gormkit", nil))
+	var report gormkit.DebugReport
+	if err := json.NewDecoder(rec.Body).Decode(&report); err != nil {
+		t.Fatal(err)
+	}
+	if report.Closed || report.Pool.OpenConnections == 0 {
+		t.Errorf("Unexpected pool state: %+v", report)
+	}
+	if !report.StmtCache.Enabled || report.StmtCache.Hits == 0 {
+		t.Errorf("Expected statement cache hits, got %+v", report.StmtCache)
+	}
+	// SQLite has no session list.
+	if !strings.Contains(report.ActiveQueriesError, gormkit.ErrUnsupportedDriver.Error()) {
+		t.Errorf("Expected active queries to be unsupported, got %q", report.ActiveQueriesError)
+	}
+}
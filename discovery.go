@@ -0,0 +1,104 @@
+package gormkit
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Resolver discovers the endpoints ("host:port") of the database, e.g. from
+// DNS SRV records or a service registry.
+type Resolver interface {
+	Resolve(ctx context.Context) ([]string, error)
+}
+
+// ResolverFunc adapts a function to Resolver.
+type ResolverFunc func(ctx context.Context) ([]string, error)
+
+func (f ResolverFunc) Resolve(ctx context.Context) ([]string, error) {
+	return f(ctx)
+}
+
+type srvResolver struct {
+	service, proto, name string
+}
+
+// SRVResolver resolves endpoints from the DNS SRV records of
+// _service._proto.name, ordered by priority, e.g.
+// SRVResolver("postgresql", "tcp", "db.service.consul"). Empty service and
+// proto look up name directly.
+func SRVResolver(service, proto, name string) Resolver {
+	return &srvResolver{service: service, proto: proto, name: name}
+}
+
+func (r *srvResolver) Resolve(ctx context.Context) ([]string, error) {
+	_, records, err := net.DefaultResolver.LookupSRV(ctx, r.service, r.proto, r.name)
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(records, func(i, j int) bool {
+		if records[i].Priority != records[j].Priority {
+			return records[i].Priority < records[j].Priority
+		}
+		return records[i].Weight > records[j].Weight
+	})
+	hosts := make([]string, len(records))
+	for i, rec := range records {
+		hosts[i] = net.JoinHostPort(strings.TrimSuffix(rec.Target, "."), strconv.Itoa(int(rec.Port)))
+	}
+	return hosts, nil
+}
+
+// resolveHosts fills Config.Hosts from Config.Resolver before connecting.
+func (m *Manager) resolveHosts(ctx context.Context) error {
+	if m.config.Resolver == nil {
+		return nil
+	}
+	hosts, err := m.config.Resolver.Resolve(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to resolve endpoints: %w", err)
+	}
+	if len(hosts) == 0 {
+		return fmt.Errorf("failed to resolve endpoints: none found")
+	}
+	m.config.Hosts = hosts
+	return nil
+}
+
+// rediscover re-resolves the endpoints every ResolveInterval until ctx is
+// done. When an endpoint disappears, idle connections are closed so the
+// pool reconnects to the current ones.
+func (m *Manager) rediscover(ctx context.Context) {
+	ticker := time.NewTicker(m.config.ResolveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		hosts, err := m.config.Resolver.Resolve(ctx)
+		if err != nil || len(hosts) == 0 {
+			if ctx.Err() == nil {
+				log.Printf("gormkit: keeping endpoints, resolve failed: %v (%d found)", err, len(hosts))
+			}
+			continue
+		}
+		removed, err := m.endpoints.update(hosts)
+		if err != nil {
+			log.Printf("gormkit: keeping endpoints: %v", err)
+			continue
+		}
+		if removed {
+			m.mu.Lock()
+			m.sqlDB.SetMaxIdleConns(0)
+			m.sqlDB.SetMaxIdleConns(m.config.MaxIdleConns)
+			m.mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package gormkit
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// unsealDSN decrypts Config.SealedDSN and fills the unset connection fields
+// of the config from it, so they reflect the database actually used. It
+// returns "" when no sealed DSN is configured.
+func (m *Manager) unsealDSN(ctx context.Context) (string, error) {
+	if m.config.SealedDSN == "" {
+		return "", nil
+	}
+	if m.config.DecryptDSN == nil {
+		return "", fmt.Errorf("sealed dsn requires DecryptDSN")
+	}
+	sealed, err := base64.StdEncoding.DecodeString(m.config.SealedDSN)
+	if err != nil {
+		return "", fmt.Errorf("invalid sealed dsn: %w", err)
+	}
+	plain, err := m.config.DecryptDSN(ctx, sealed)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt dsn: %w", err)
+	}
+	dsn := string(plain)
+	if err := fillFromDSN(m.config, dsn); err != nil {
+		return "", err
+	}
+	return dsn, nil
+}
+
+// fillFromDSN copies host, port, user and database from a driver DSN into
+// the unset fields of cfg. The password is left in the DSN only.
+func fillFromDSN(cfg *Config, dsn string) error {
+	switch cfg.Driver {
+	case "postgres":
+		pc, err := pgconn.ParseConfig(dsn)
+		if err != nil {
+			return fmt.Errorf("invalid postgres dsn: %w", err)
+		}
+		setDefault(&cfg.Host, pc.Host)
+		setDefault(&cfg.Port, int(pc.Port))
+		setDefault(&cfg.User, pc.User)
+		setDefault(&cfg.Database, pc.Database)
+	case "mysql":
+		mc, err := mysql.ParseDSN(dsn)
+		if err != nil {
+			return fmt.Errorf("invalid mysql dsn: %w", err)
+		}
+		if host, port, err := net.SplitHostPort(mc.Addr); err == nil {
+			setDefault(&cfg.Host, host)
+			if p, err := strconv.Atoi(port); err == nil {
+				setDefault(&cfg.Port, p)
+			}
+		}
+		setDefault(&cfg.User, mc.User)
+		setDefault(&cfg.Database, mc.DBName)
+	case "sqlite", "test":
+		setDefault(&cfg.Database, dsn)
+	}
+	return nil
+}
+
+// withBusyTimeout makes connections to a SQLite database file wait up to
+// 5s for another connection's write to finish instead of failing with
+// "database is locked", as the kit's workers (scheduler, leases, Claim)
+// write concurrently. DSNs setting a busy_timeout, and in-memory databases,
+// are left as is.
+func withBusyTimeout(dsn string) string {
+	if dsn == ":memory:" || strings.Contains(dsn, "mode=memory") || strings.Contains(dsn, "busy_timeout") {
+		return dsn
+	}
+	sep := "?"
+	if strings.Contains(dsn, "?") {
+		sep = "&"
+	}
+	return dsn + sep + "_pragma=busy_timeout(5000)"
+}
+
+// SealDSN encrypts dsn with AES-GCM under key (16, 24 or 32 bytes) for
+// Config.SealedDSN, to be opened with DecryptAESGCM(key).
+func SealDSN(key []byte, dsn string) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(nonce, nonce, []byte(dsn), nil)), nil
+}
+
+// DecryptAESGCM returns a Config.DecryptDSN hook opening DSNs sealed by
+// SealDSN with the same key.
+func DecryptAESGCM(key []byte) func(context.Context, []byte) ([]byte, error) {
+	return func(_ context.Context, sealed []byte) ([]byte, error) {
+		gcm, err := newGCM(key)
+		if err != nil {
+			return nil, err
+		}
+		if len(sealed) < gcm.NonceSize() {
+			return nil, fmt.Errorf("sealed dsn too short")
+		}
+		nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+		return gcm.Open(nil, nonce, ciphertext, nil)
+	}
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
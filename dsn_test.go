@@ -0,0 +1,50 @@
+package gormkit_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestSealedDSN(t *testing.T) {
+	key := bytes.Repeat([]byte("k"), 32)
+	sealed, err := gormkit.SealDSN(key, "file:sealed?mode=memory&cache=shared")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(sealed, "sealed") {
+		t.Fatal("Expected the DSN to be encrypted")
+	}
+
+	cfg := &gormkit.Config{
+		Driver:     "test",
+		LogLevel:   "silent",
+		SealedDSN:  sealed,
+		DecryptDSN: gormkit.DecryptAESGCM(key),
+	}
+	manager, err := gormkit.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+	if cfg.Database != "file:sealed?mode=memory&cache=shared" {
+		t.Errorf("Expected Database from the DSN, got %q", cfg.Database)
+	}
+
+	_, err = gormkit.New(&gormkit.Config{
+		Driver:     "test",
+		LogLevel:   "silent",
+		SealedDSN:  sealed,
+		DecryptDSN: gormkit.DecryptAESGCM(bytes.Repeat([]byte("x"), 32)),
+	})
+	if err == nil || !strings.Contains(err.Error(), "failed to decrypt dsn") {
+		t.Errorf("Expected a decrypt error with the wrong key, got %v", err)
+	}
+
+	_, err = gormkit.New(&gormkit.Config{Driver: "test", LogLevel: "silent", SealedDSN: sealed})
+	if err == nil {
+		t.Error("Expected an error without DecryptDSN")
+	}
+}
@@ -0,0 +1,254 @@
+package gormkit
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"log"
+	"math/rand/v2"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	endpointWindow     = 20  // outcomes kept per endpoint
+	endpointMinSamples = 5   // outcomes needed before ejecting
+	endpointEjectRate  = 0.5 // error rate that ejects an endpoint
+	endpointProbeEvery = 5 * time.Second
+)
+
+// EndpointHealth is the state of one of Config.Hosts.
+type EndpointHealth struct {
+	Address   string
+	Ejected   bool
+	ErrorRate float64 // over the last 20 connection attempts and probes
+	LastError string
+}
+
+type endpoint struct {
+	addr string
+	host string
+	port int
+
+	failures [endpointWindow]bool
+	samples  int
+	next     int
+	ejected  bool
+	lastErr  string
+}
+
+func (e *endpoint) errorRate() float64 {
+	if e.samples == 0 {
+		return 0
+	}
+	failed := 0
+	for i := 0; i < e.samples; i++ {
+		if e.failures[i] {
+			failed++
+		}
+	}
+	return float64(failed) / float64(e.samples)
+}
+
+// endpointSet tracks the health of several endpoints of the same database
+// and spreads new connections over the healthy ones, weighted by their
+// recent success rate.
+type endpointSet struct {
+	defaultPort int
+
+	mu   sync.Mutex
+	list []*endpoint
+}
+
+func newEndpointSet(hosts []string, defaultPort int) (*endpointSet, error) {
+	s := &endpointSet{defaultPort: defaultPort}
+	for _, h := range hosts {
+		host, port := h, defaultPort
+		if hp, p, err := net.SplitHostPort(h); err == nil {
+			n, err := strconv.Atoi(p)
+			if err != nil {
+				return nil, fmt.Errorf("invalid host %q: %w", h, err)
+			}
+			host, port = hp, n
+		}
+		s.list = append(s.list, &endpoint{addr: net.JoinHostPort(host, strconv.Itoa(port)), host: host, port: port})
+	}
+	return s, nil
+}
+
+// pick chooses the endpoint of a new connection. When every endpoint is
+// ejected, all of them are candidates again rather than failing outright.
+func (s *endpointSet) pick() *endpoint {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	candidates := make([]*endpoint, 0, len(s.list))
+	for _, e := range s.list {
+		if !e.ejected {
+			candidates = append(candidates, e)
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = s.list
+	}
+
+	weights := make([]float64, len(candidates))
+	total := 0.0
+	for i, e := range candidates {
+		weights[i] = max(1-e.errorRate(), 0.05)
+		total += weights[i]
+	}
+	r := rand.Float64() * total
+	for i, w := range weights {
+		if r < w {
+			return candidates[i]
+		}
+		r -= w
+	}
+	return candidates[len(candidates)-1]
+}
+
+// record adds the outcome of a connection attempt or probe. Endpoints whose
+// error rate reaches endpointEjectRate are ejected; an ejected endpoint is
+// restored by its next success.
+func (s *endpointSet) record(e *endpoint, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err == nil && e.ejected {
+		e.ejected = false
+		e.samples, e.next = 0, 0
+		log.Printf("gormkit: endpoint %s restored", e.addr)
+	}
+	e.failures[e.next] = err != nil
+	e.next = (e.next + 1) % endpointWindow
+	e.samples = min(e.samples+1, endpointWindow)
+	if err != nil {
+		e.lastErr = err.Error()
+	}
+	if !e.ejected && e.samples >= endpointMinSamples && e.errorRate() >= endpointEjectRate {
+		e.ejected = true
+		log.Printf("gormkit: endpoint %s ejected: %s", e.addr, e.lastErr)
+	}
+}
+
+// ejected reports whether addr is ejected or no longer listed.
+func (s *endpointSet) ejected(addr string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, e := range s.list {
+		if e.addr == addr {
+			return e.ejected
+		}
+	}
+	return true
+}
+
+// update replaces the endpoints with hosts, keeping the health of those
+// still listed, and reports whether any endpoint was removed.
+func (s *endpointSet) update(hosts []string) (bool, error) {
+	next, err := newEndpointSet(hosts, s.defaultPort)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	current := make(map[string]*endpoint, len(s.list))
+	for _, e := range s.list {
+		current[e.addr] = e
+	}
+	for i, e := range next.list {
+		if existing, ok := current[e.addr]; ok {
+			next.list[i] = existing
+			delete(current, e.addr)
+		} else {
+			log.Printf("gormkit: endpoint %s added", e.addr)
+		}
+	}
+	for addr := range current {
+		log.Printf("gormkit: endpoint %s removed", addr)
+	}
+	s.list = next.list
+	return len(current) > 0, nil
+}
+
+func (s *endpointSet) endpoints() []*endpoint {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]*endpoint(nil), s.list...)
+}
+
+func (s *endpointSet) snapshot() []EndpointHealth {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]EndpointHealth, len(s.list))
+	for i, e := range s.list {
+		out[i] = EndpointHealth{Address: e.addr, Ejected: e.ejected, ErrorRate: e.errorRate(), LastError: e.lastErr}
+	}
+	return out
+}
+
+// probe dials every endpoint every endpointProbeEvery until ctx is done, so
+// ejected endpoints are restored and failing ones noticed without traffic.
+func (s *endpointSet) probe(ctx context.Context, timeout time.Duration) {
+	ticker := time.NewTicker(endpointProbeEvery)
+	defer ticker.Stop()
+	dialer := net.Dialer{Timeout: timeout}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for _, e := range s.endpoints() {
+			conn, err := dialer.DialContext(ctx, "tcp", e.addr)
+			if ctx.Err() != nil {
+				return
+			}
+			if err == nil {
+				conn.Close()
+			}
+			s.record(e, err)
+		}
+	}
+}
+
+// Endpoints reports the health of Config.Hosts, or nil without them.
+func (m *Manager) Endpoints() []EndpointHealth {
+	if m.endpoints == nil {
+		return nil
+	}
+	return m.endpoints.snapshot()
+}
+
+// endpointConnector picks an endpoint for every new connection and records
+// whether connecting to it worked. The driver's connect hook reads the
+// choice from the context.
+type endpointConnector struct {
+	driver.Connector
+	set *endpointSet
+}
+
+func (m *Manager) endpointConnector(inner driver.Connector) driver.Connector {
+	if m.endpoints == nil {
+		return inner
+	}
+	return &endpointConnector{Connector: inner, set: m.endpoints}
+}
+
+func (c *endpointConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	e := c.set.pick()
+	conn, err := c.Connector.Connect(context.WithValue(ctx, endpointKey, e))
+	if ctx.Err() == nil {
+		c.set.record(e, err)
+	}
+	return conn, err
+}
+
+func endpointFrom(ctx context.Context) *endpoint {
+	e, _ := ctx.Value(endpointKey).(*endpoint)
+	return e
+}
@@ -0,0 +1,71 @@
+package gormkit_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestHostsUnsupportedOnSQLite(t *testing.T) {
+	_, err := gormkit.New(&gormkit.Config{
+		Driver:   "test",
+		LogLevel: "silent",
+		Hosts:    []string{"a", "b"},
+	})
+	if !errors.Is(err, gormkit.ErrUnsupportedDriver) {
+		t.Errorf("Expected ErrUnsupportedDriver, got %v", err)
+	}
+}
+
+func TestHostsAllUnreachable(t *testing.T) {
+	// Reserve two ports that refuse connections.
+	var hosts []string
+	for i := 0; i < 2; i++ {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		hosts = append(hosts, l.Addr().String())
+		l.Close()
+	}
+
+	_, err := gormkit.New(&gormkit.Config{
+		Driver:         "postgres",
+		LogLevel:       "silent",
+		Hosts:          hosts,
+		User:           "app",
+		Database:       "app",
+		SSLMode:        "disable",
+		RetryAttempts:  1,
+		ConnectTimeout: time.Second,
+	})
+	if err == nil || !strings.Contains(err.Error(), "database=app") {
+		t.Errorf("Expected a connection error for database app, got %v", err)
+	}
+}
+
+func TestResolver(t *testing.T) {
+	resolved := false
+	resolver := gormkit.ResolverFunc(func(ctx context.Context) ([]string, error) {
+		resolved = true
+		return nil, nil
+	})
+	_, err := gormkit.New(&gormkit.Config{Driver: "postgres", LogLevel: "silent", Resolver: resolver})
+	if !resolved || err == nil || !strings.Contains(err.Error(), "none found") {
+		t.Errorf("Expected an empty resolution to fail, got %v", err)
+	}
+
+	_, err = gormkit.New(&gormkit.Config{
+		Driver:   "postgres",
+		LogLevel: "silent",
+		Resolver: gormkit.SRVResolver("postgresql", "tcp", "missing.invalid"),
+	})
+	if err == nil || !strings.Contains(err.Error(), "failed to resolve endpoints") {
+		t.Errorf("Expected an SRV lookup failure, got %v", err)
+	}
+}
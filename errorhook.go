@@ -0,0 +1,49 @@
+package gormkit
+
+import (
+	"context"
+	"errors"
+	"runtime/debug"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const errorStartKey = "gormkit:error_start"
+
+// QueryError describes a failed statement passed to Config.OnError.
+type QueryError struct {
+	Err         error
+	SQL         string // with placeholders, never argument values
+	Fingerprint string // SQL with literals and IN-lists normalized
+	Table       string
+	Duration    time.Duration
+	Stack       []byte
+}
+
+func (m *Manager) beforeErrorHook(db *gorm.DB) {
+	db.InstanceSet(errorStartKey, time.Now())
+}
+
+func (m *Manager) afterErrorHook(db *gorm.DB) {
+	if db.Error == nil || errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		return
+	}
+
+	qe := QueryError{
+		Err:         db.Error,
+		SQL:         db.Statement.SQL.String(),
+		Fingerprint: fingerprint(db.Statement.SQL.String()),
+		Table:       db.Statement.Table,
+		Stack:       debug.Stack(),
+	}
+	if start, ok := db.InstanceGet(errorStartKey); ok {
+		qe.Duration = time.Since(start.(time.Time))
+	}
+
+	ctx := db.Statement.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	m.config.OnError(ctx, qe)
+}
@@ -0,0 +1,48 @@
+package gormkit_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestOnError(t *testing.T) {
+	var reported []gormkit.QueryError
+	manager, err := gormkit.New(&gormkit.Config{
+		Driver:   "test",
+		LogLevel: "silent",
+		OnError: func(ctx context.Context, qe gormkit.QueryError) {
+			reported = append(reported, qe)
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	db := manager.DB()
+	db.AutoMigrate(&User{})
+
+	var user User
+	db.First(&user, 42)
+	if len(reported) != 0 {
+		t.Fatalf("Expected record-not-found to be ignored, got %v", reported[0].Err)
+	}
+
+	db.Exec("SELECT * FROM missing_table WHERE id IN (?)", []int{1, 2, 3})
+	if len(reported) != 1 {
+		t.Fatalf("Expected 1 reported error, got %d", len(reported))
+	}
+	qe := reported[0]
+	if qe.Err == nil || len(qe.Stack) == 0 {
+		t.Errorf("Expected error and stack, got %+v", qe)
+	}
+	if qe.Fingerprint != "SELECT * FROM missing_table WHERE id IN (?)" {
+		t.Errorf("Unexpected fingerprint: %q", qe.Fingerprint)
+	}
+	if strings.Contains(qe.SQL, "1") {
+		t.Errorf("Expected placeholders only, got %q", qe.SQL)
+	}
+}
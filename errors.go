@@ -0,0 +1,23 @@
+package gormkit
+
+import "errors"
+
+var (
+	ErrQueryBudgetExceeded   = errors.New("query budget exceeded")
+	ErrUnsupportedDriver     = errors.New("operation not supported by driver")
+	ErrDestructiveMigration  = errors.New("destructive migrations are not allowed")
+	ErrInjectedFault         = errors.New("injected fault")
+	ErrNoDeadline            = errors.New("query without context deadline")
+	ErrNoRowsAffected        = errors.New("no rows affected")
+	ErrMultipleRowsAffected  = errors.New("multiple rows affected")
+	ErrIdempotencyInProgress = errors.New("idempotent call already in progress")
+	ErrVersionConflict       = errors.New("version conflict")
+	ErrLockNotAvailable      = errors.New("lock not available")
+	ErrInvalidTransition     = errors.New("invalid state transition")
+	ErrInvalidCursor         = errors.New("invalid cursor")
+	ErrSchemaVersion         = errors.New("incompatible schema version")
+	ErrRequirementsNotMet    = errors.New("database requirements not met")
+	ErrInsufficientPrivilege = errors.New("insufficient privilege")
+	ErrCheckViolation        = errors.New("check constraint violated")
+	ErrQuotaExceeded         = errors.New("quota exceeded")
+)
@@ -0,0 +1,237 @@
+package gormkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"reflect"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// Event is published after a model is created, updated or deleted.
+type Event struct {
+	Name   string // "<Model>.<Action>", e.g. "User.created"
+	Model  string
+	Action string      // created, updated or deleted
+	Value  interface{} // pointer to the model value, e.g. *User
+}
+
+// EventHandler handles an event. A synchronous handler that fails also
+// fails the statement, rolling back its transaction.
+type EventHandler func(ctx context.Context, e Event) error
+
+// SubscribeOption configures a subscription.
+type SubscribeOption int
+
+const (
+	// Async delivers the event on its own goroutine once the statement has
+	// committed; inside Manager.Transaction that is when the transaction
+	// commits, and the event is dropped if it rolls back. Transactions begun
+	// on the gorm.DB directly have no commit hook, so their events are
+	// delivered when the statement runs. Errors are logged.
+	Async SubscribeOption = iota + 1
+)
+
+type subscription struct {
+	id      uint64
+	handler EventHandler
+	async   bool
+}
+
+// EventBus delivers lifecycle events from gorm callbacks to subscribers.
+type EventBus struct {
+	mu     sync.RWMutex
+	nextID uint64
+	subs   map[string][]subscription
+	wg     sync.WaitGroup
+}
+
+func newEventBus() *EventBus {
+	return &EventBus{subs: make(map[string][]subscription)}
+}
+
+// txEvents holds the async deliveries of a Manager.Transaction until it
+// commits.
+type txEvents struct {
+	mu      sync.Mutex
+	deliver []func()
+}
+
+func (q *txEvents) add(fn func()) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.deliver = append(q.deliver, fn)
+}
+
+func (q *txEvents) flush() {
+	q.mu.Lock()
+	deliver := q.deliver
+	q.deliver = nil
+	q.mu.Unlock()
+	for _, fn := range deliver {
+		fn()
+	}
+}
+
+// Events returns the Manager's event bus.
+func (m *Manager) Events() *EventBus {
+	return m.events
+}
+
+// Subscribe registers handler for name: "User.created", "User.*" for every
+// action on User, or "*" for everything. It returns a function that removes
+// the subscription.
+func (b *EventBus) Subscribe(name string, handler EventHandler, opts ...SubscribeOption) (unsubscribe func()) {
+	sub := subscription{handler: handler}
+	for _, opt := range opts {
+		if opt == Async {
+			sub.async = true
+		}
+	}
+
+	b.mu.Lock()
+	b.nextID++
+	sub.id = b.nextID
+	b.subs[name] = append(b.subs[name], sub)
+	b.mu.Unlock()
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		subs := b.subs[name]
+		for i, s := range subs {
+			if s.id == sub.id {
+				b.subs[name] = append(subs[:i:i], subs[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// On subscribes a typed handler to action ("created", "updated", "deleted"
+// or "*") on T.
+func On[T any](b *EventBus, action string, fn func(ctx context.Context, value *T) error, opts ...SubscribeOption) (unsubscribe func()) {
+	return b.Subscribe(reflect.TypeFor[T]().Name()+"."+action, func(ctx context.Context, e Event) error {
+		value, ok := e.Value.(*T)
+		if !ok {
+			return nil
+		}
+		return fn(ctx, value)
+	}, opts...)
+}
+
+func (b *EventBus) matching(e Event, async bool) []EventHandler {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	var out []EventHandler
+	for _, name := range []string{e.Name, e.Model + ".*", "*"} {
+		for _, s := range b.subs[name] {
+			if s.async == async {
+				out = append(out, s.handler)
+			}
+		}
+	}
+	return out
+}
+
+func (b *EventBus) hasSubscribers() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, subs := range b.subs {
+		if len(subs) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// wait blocks until async handlers in flight have returned.
+func (b *EventBus) wait() {
+	b.wg.Wait()
+}
+
+func (m *Manager) registerEvents() error {
+	cb := m.db.Callback()
+	const committed = "gorm:commit_or_rollback_transaction"
+	return errors.Join(
+		cb.Create().After("gorm:create").Before(committed).Register("gormkit:events_created", m.publishEvents("created", false)),
+		cb.Update().After("gorm:update").Before(committed).Register("gormkit:events_updated", m.publishEvents("updated", false)),
+		cb.Delete().After("gorm:delete").Before(committed).Register("gormkit:events_deleted", m.publishEvents("deleted", false)),
+		cb.Create().After(committed).Register("gormkit:events_async_created", m.publishEvents("created", true)),
+		cb.Update().After(committed).Register("gormkit:events_async_updated", m.publishEvents("updated", true)),
+		cb.Delete().After(committed).Register("gormkit:events_async_deleted", m.publishEvents("deleted", true)),
+	)
+}
+
+func (m *Manager) publishEvents(action string, async bool) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		if db.Error != nil || db.Statement.Schema == nil || db.RowsAffected == 0 || !m.events.hasSubscribers() {
+			return
+		}
+		ctx := db.Statement.Context
+		var pending *txEvents
+		if _, inTx := db.Statement.ConnPool.(gorm.TxCommitter); inTx && async {
+			pending, _ = ctx.Value(txEventsKey).(*txEvents)
+		}
+		for _, value := range eventValues(db) {
+			e := Event{
+				Name:   db.Statement.Schema.Name + "." + action,
+				Model:  db.Statement.Schema.Name,
+				Action: action,
+				Value:  value,
+			}
+			for _, handler := range m.events.matching(e, async) {
+				if !async {
+					if err := handler(ctx, e); err != nil {
+						db.AddError(fmt.Errorf("%s handler: %w", e.Name, err))
+						return
+					}
+					continue
+				}
+				deliver := func() {
+					m.events.wg.Add(1)
+					go func() {
+						defer m.events.wg.Done()
+						if err := handler(context.WithoutCancel(ctx), e); err != nil {
+							log.Printf("gormkit: %s handler: %v", e.Name, err)
+						}
+					}()
+				}
+				if pending != nil {
+					pending.add(deliver)
+				} else {
+					deliver()
+				}
+			}
+		}
+	}
+}
+
+// eventValues returns a pointer to each model value the statement touched.
+func eventValues(db *gorm.DB) []interface{} {
+	rv := db.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Struct:
+		if rv.CanAddr() {
+			return []interface{}{rv.Addr().Interface()}
+		}
+		p := reflect.New(rv.Type())
+		p.Elem().Set(rv)
+		return []interface{}{p.Interface()}
+	case reflect.Slice, reflect.Array:
+		out := make([]interface{}, 0, rv.Len())
+		for i := 0; i < rv.Len(); i++ {
+			elem := rv.Index(i)
+			if elem.Kind() == reflect.Pointer {
+				out = append(out, elem.Interface())
+			} else if elem.CanAddr() {
+				out = append(out, elem.Addr().Interface())
+			}
+		}
+		return out
+	}
+	return nil
+}
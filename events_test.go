@@ -0,0 +1,127 @@
+package gormkit_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+	"gorm.io/gorm"
+)
+
+func TestEventsSync(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &User{})
+	db := manager.DB()
+	bus := manager.Events()
+
+	var names []string
+	bus.Subscribe("User.*", func(ctx context.Context, e gormkit.Event) error {
+		names = append(names, e.Name)
+		return nil
+	})
+	var created []string
+	gormkit.On[User](bus, "created", func(ctx context.Context, u *User) error {
+		created = append(created, u.Name)
+		return nil
+	})
+
+	user := User{Name: "Ali"}
+	db.Create(&user)
+	db.Create(&[]User{{Name: "Sara"}, {Name: "Reza"}})
+	db.Model(&user).Update("name", "Ali2")
+	db.Delete(&user)
+
+	want := []string{"User.created", "User.created", "User.created", "User.updated", "User.deleted"}
+	if len(names) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, names)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("Expected %v, got %v", want, names)
+			break
+		}
+	}
+	if len(created) != 3 || created[0] != "Ali" || created[2] != "Reza" {
+		t.Errorf("Expected typed payloads, got %v", created)
+	}
+}
+
+func TestEventsSyncErrorRollsBack(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &User{})
+	db := manager.DB()
+
+	unsubscribe := gormkit.On[User](manager.Events(), "created", func(ctx context.Context, u *User) error {
+		return errors.New("rejected")
+	})
+	if err := db.Create(&User{Name: "Nope"}).Error; err == nil {
+		t.Error("Expected handler error to fail the create")
+	}
+	unsubscribe()
+	if err := db.Create(&User{Name: "Yes"}).Error; err != nil {
+		t.Errorf("Expected create to succeed after unsubscribe, got %v", err)
+	}
+
+	var count int64
+	db.Model(&User{}).Count(&count)
+	if count != 1 {
+		t.Errorf("Expected the failed create to be rolled back, got %d users", count)
+	}
+}
+
+func TestEventsAsync(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &User{})
+
+	var mu sync.Mutex
+	var got []string
+	manager.Events().Subscribe("*", func(ctx context.Context, e gormkit.Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, e.Name)
+		return nil
+	}, gormkit.Async)
+
+	manager.DB().Create(&User{Name: "Ali"})
+	manager.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 1 || got[0] != "User.created" {
+		t.Errorf("Expected async delivery before Close returns, got %v", got)
+	}
+}
+
+func TestEventsAsyncAfterCommit(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &User{})
+
+	var mu sync.Mutex
+	var got []string
+	gormkit.On[User](manager.Events(), "created", func(ctx context.Context, u *User) error {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, u.Name)
+		return nil
+	}, gormkit.Async)
+
+	ctx := context.Background()
+	manager.Transaction(ctx, func(tx *gorm.DB) error {
+		tx.Create(&User{Name: "Ali"})
+		mu.Lock()
+		defer mu.Unlock()
+		if len(got) != 0 {
+			t.Errorf("Expected no delivery before commit, got %v", got)
+		}
+		return nil
+	})
+	manager.Transaction(ctx, func(tx *gorm.DB) error {
+		tx.Create(&User{Name: "Sara"})
+		return errors.New("rollback")
+	})
+	manager.Close()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 1 || got[0] != "Ali" {
+		t.Errorf("Expected only the committed create, got %v", got)
+	}
+}
@@ -0,0 +1,225 @@
+package gormkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Named binds @name parameters in Exec, e.g. Named{"id": 5} for "WHERE id = @id".
+type Named map[string]interface{}
+
+type Statement struct {
+	SQL  string
+	Args []interface{}
+}
+
+type ExecOptions struct {
+	// Transactional runs every statement in one transaction and rolls back on
+	// the first error.
+	Transactional bool
+	// StopOnError stops a non-transactional batch at the first failure;
+	// otherwise remaining statements still run.
+	StopOnError bool
+	// Progress is called after each statement.
+	Progress func(ExecProgress)
+}
+
+type ExecProgress struct {
+	Index        int
+	Total        int
+	Statement    Statement
+	RowsAffected int64
+	Duration     time.Duration
+	Err          error
+}
+
+type ExecResult struct {
+	RowsAffected int64
+	Duration     time.Duration
+	Err          error
+}
+
+// Exec runs sqlText, which may hold several ;-separated statements, and
+// returns the total number of affected rows. Parameters are either positional
+// (?) for a single statement or Named (@name) shared by every statement;
+// placeholders are translated to the dialect's style. Multiple statements run
+// in one transaction.
+func (m *Manager) Exec(ctx context.Context, sqlText string, args ...interface{}) (int64, error) {
+	texts := splitStatements(sqlText, m.config.Driver == "mysql")
+	named, positional := splitNamedArgs(args)
+	if len(texts) > 1 && len(positional) > 0 {
+		return 0, errors.New("positional arguments require a single statement; use gormkit.Named")
+	}
+
+	stmts := make([]Statement, len(texts))
+	for i, text := range texts {
+		stmts[i] = Statement{SQL: text, Args: positional}
+		if named != nil {
+			stmts[i].Args = append([]interface{}{Named(named)}, positional...)
+		}
+	}
+
+	results, err := m.ExecBatch(ctx, stmts, ExecOptions{Transactional: len(stmts) > 1, StopOnError: true})
+	var total int64
+	for _, r := range results {
+		total += r.RowsAffected
+	}
+	return total, err
+}
+
+// ExecBatch runs stmts in order, for data-fix scripts and operational tooling.
+// It returns one result per statement that ran; with neither Transactional
+// nor StopOnError set, the returned error joins every failure.
+func (m *Manager) ExecBatch(ctx context.Context, stmts []Statement, opts ExecOptions) ([]ExecResult, error) {
+	results := make([]ExecResult, 0, len(stmts))
+	var errs []error
+
+	run := func(db *gorm.DB) error {
+		for i, stmt := range stmts {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			start := time.Now()
+			result := execStatement(db, stmt)
+			r := ExecResult{RowsAffected: result.RowsAffected, Duration: time.Since(start), Err: result.Error}
+			results = append(results, r)
+
+			if opts.Progress != nil {
+				opts.Progress(ExecProgress{
+					Index:        i,
+					Total:        len(stmts),
+					Statement:    stmt,
+					RowsAffected: r.RowsAffected,
+					Duration:     r.Duration,
+					Err:          r.Err,
+				})
+			}
+
+			if r.Err != nil {
+				err := fmt.Errorf("statement %d: %w", i+1, r.Err)
+				if opts.Transactional || opts.StopOnError {
+					return err
+				}
+				errs = append(errs, err)
+			}
+		}
+		return errors.Join(errs...)
+	}
+
+	if opts.Transactional {
+		return results, m.Transaction(ctx, run)
+	}
+	return results, run(m.WithContext(ctx))
+}
+
+func execStatement(db *gorm.DB, stmt Statement) *gorm.DB {
+	named, vars := splitNamedArgs(stmt.Args)
+	if named != nil && strings.Contains(stmt.SQL, "@") {
+		vars = append([]interface{}{named}, vars...)
+	}
+	return db.Exec(stmt.SQL, vars...)
+}
+
+func splitNamedArgs(args []interface{}) (map[string]interface{}, []interface{}) {
+	var named map[string]interface{}
+	var positional []interface{}
+	for _, arg := range args {
+		if n, ok := arg.(Named); ok {
+			if named == nil {
+				named = map[string]interface{}{}
+			}
+			for k, v := range n {
+				named[k] = v
+			}
+			continue
+		}
+		positional = append(positional, arg)
+	}
+	return named, positional
+}
+
+// splitStatements splits sqlText on semicolons that are not inside quotes,
+// comments or Postgres dollar-quoted bodies. Empty statements are dropped.
+// backslashEscapes enables MySQL-style \' escapes inside string literals.
+func splitStatements(sqlText string, backslashEscapes bool) []string {
+	var stmts []string
+	start := 0
+	for i := 0; i < len(sqlText); i++ {
+		switch c := sqlText[i]; c {
+		case '\'', '"', '`':
+			i = skipQuoted(sqlText, i, c, backslashEscapes)
+		case '-':
+			if strings.HasPrefix(sqlText[i:], "--") {
+				i = skipUntil(sqlText, i, "\n")
+			}
+		case '/':
+			if strings.HasPrefix(sqlText[i:], "/*") {
+				i = skipUntil(sqlText, i+2, "*/")
+			}
+		case '$':
+			if tag := dollarTag(sqlText[i:]); tag != "" {
+				i = skipUntil(sqlText, i+len(tag), tag)
+			}
+		case ';':
+			stmts = appendStatement(stmts, sqlText[start:i])
+			start = i + 1
+		}
+	}
+	return appendStatement(stmts, sqlText[start:])
+}
+
+func appendStatement(stmts []string, stmt string) []string {
+	if stmt = strings.TrimSpace(stmt); stmt != "" {
+		stmts = append(stmts, stmt)
+	}
+	return stmts
+}
+
+// skipQuoted returns the index of the quote closing the one at i; doubled
+// quotes (and backslash escapes, if enabled) are part of the literal.
+func skipQuoted(s string, i int, quote byte, backslashEscapes bool) int {
+	for j := i + 1; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			if backslashEscapes {
+				j++
+			}
+		case quote:
+			if j+1 < len(s) && s[j+1] == quote {
+				j++
+				continue
+			}
+			return j
+		}
+	}
+	return len(s) - 1
+}
+
+// skipUntil returns the index of the last byte of the first end found at or
+// after i, or the end of s.
+func skipUntil(s string, i int, end string) int {
+	if idx := strings.Index(s[i:], end); idx >= 0 {
+		return i + idx + len(end) - 1
+	}
+	return len(s) - 1
+}
+
+// dollarTag returns the $tag$ opening s, or "" when s does not start one.
+func dollarTag(s string) string {
+	for j := 1; j < len(s); j++ {
+		c := s[j]
+		if c == '$' {
+			return s[:j+1]
+		}
+		if !(c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || j > 1 && c >= '0' && c <= '9') {
+			return ""
+		}
+	}
+	return ""
+}
@@ -0,0 +1,114 @@
+package gormkit_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestExecNamedMultiStatement(t *testing.T) {
+	manager, err := gormkit.New(&gormkit.Config{
+		Driver:   "test",
+		LogLevel: "silent",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	manager.DB().AutoMigrate(&User{})
+	ctx := context.Background()
+
+	script := `
+		-- seed; two users
+		INSERT INTO users (name) VALUES (@name);
+		INSERT INTO users (name) VALUES ('semi;colon');
+		/* rename; the first one */
+		UPDATE users SET name = @renamed WHERE name = @name;
+	`
+	affected, err := manager.Exec(ctx, script, gormkit.Named{"name": "Ali", "renamed": "Ali R."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if affected != 3 {
+		t.Errorf("Expected 3 affected rows, got %d", affected)
+	}
+
+	var names []string
+	manager.DB().Model(&User{}).Order("id").Pluck("name", &names)
+	if len(names) != 2 || names[0] != "Ali R." || names[1] != "semi;colon" {
+		t.Errorf("Unexpected names: %v", names)
+	}
+}
+
+func TestExecPositionalRequiresSingleStatement(t *testing.T) {
+	manager, err := gormkit.New(&gormkit.Config{
+		Driver:   "test",
+		LogLevel: "silent",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	manager.DB().AutoMigrate(&User{})
+	ctx := context.Background()
+
+	if _, err := manager.Exec(ctx, "INSERT INTO users (name) VALUES (?)", "One"); err != nil {
+		t.Errorf("Single positional statement failed: %v", err)
+	}
+	if _, err := manager.Exec(ctx, "SELECT 1; SELECT ?", 1); err == nil {
+		t.Error("Expected error for positional args with multiple statements")
+	}
+}
+
+func TestExecBatch(t *testing.T) {
+	manager, err := gormkit.New(&gormkit.Config{
+		Driver:   "test",
+		LogLevel: "silent",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	manager.DB().AutoMigrate(&User{})
+	ctx := context.Background()
+
+	stmts := []gormkit.Statement{
+		{SQL: "INSERT INTO users (name) VALUES (?)", Args: []interface{}{"A"}},
+		{SQL: "INSERT INTO missing_table (name) VALUES ('B')"},
+		{SQL: "INSERT INTO users (name) VALUES (@name)", Args: []interface{}{gormkit.Named{"name": "C"}}},
+	}
+
+	var progress []int
+	results, err := manager.ExecBatch(ctx, stmts, gormkit.ExecOptions{
+		Progress: func(p gormkit.ExecProgress) { progress = append(progress, p.Index) },
+	})
+	if err == nil {
+		t.Error("Expected joined error from failing statement")
+	}
+	if len(results) != 3 || len(progress) != 3 {
+		t.Errorf("Expected all 3 statements to run, got %d results", len(results))
+	}
+
+	var count int64
+	manager.DB().Model(&User{}).Count(&count)
+	if count != 2 {
+		t.Errorf("Expected 2 users after non-transactional batch, got %d", count)
+	}
+
+	results, err = manager.ExecBatch(ctx, stmts, gormkit.ExecOptions{Transactional: true})
+	if err == nil {
+		t.Error("Expected error from transactional batch")
+	}
+	if len(results) != 2 {
+		t.Errorf("Transactional batch should stop after failure, ran %d", len(results))
+	}
+
+	manager.DB().Model(&User{}).Count(&count)
+	if count != 2 {
+		t.Errorf("Transactional batch should roll back, got %d users", count)
+	}
+}
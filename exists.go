@@ -0,0 +1,72 @@
+package gormkit
+
+import (
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ExistsIn keeps the rows with at least one row in sub whose subColumn
+// equals their column, as a correlated EXISTS subquery. Unlike IN, it does
+// not materialize the subquery's keys.
+//
+//	// Customers with an invoice over 100.
+//	db.Scopes(gormkit.ExistsIn(db.Model(&Invoice{}).Where("total > ?", 100), "customer_id", "id")).Find(&customers)
+func ExistsIn(sub *gorm.DB, subColumn, column string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(existsExpr{sub: sub, subColumn: subColumn, column: column})
+	}
+}
+
+// NotExistsIn keeps the rows without any row in sub whose subColumn equals
+// their column: the anti-join that NOT IN gets wrong when the subquery
+// yields a NULL (NOT IN is then never true), without spelling out a LEFT
+// JOIN ... IS NULL.
+//
+//	// Customers who never ordered.
+//	db.Scopes(gormkit.NotExistsIn(db.Model(&Order{}), "customer_id", "id")).Find(&customers)
+func NotExistsIn(sub *gorm.DB, subColumn, column string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where(existsExpr{sub: sub, subColumn: subColumn, column: column, not: true})
+	}
+}
+
+// existsExpr builds the subquery when the outer statement is built, once
+// its table is known.
+type existsExpr struct {
+	sub               *gorm.DB
+	subColumn, column string
+	not               bool
+}
+
+func (e existsExpr) Build(builder clause.Builder) {
+	stmt, ok := builder.(*gorm.Statement)
+	if !ok {
+		return
+	}
+	sub := e.sub.Session(&gorm.Session{})
+	table := sub.Statement.Table
+	if table == "" && sub.Statement.Model != nil {
+		parsed := &gorm.Statement{DB: stmt.DB}
+		if err := parsed.Parse(sub.Statement.Model); err == nil {
+			table = parsed.Schema.Table
+		}
+	}
+	// A subquery on the outer table itself, e.g. employees without reports,
+	// needs an alias to tell the two apart.
+	subTable := table
+	if table == stmt.Table {
+		subTable = table + "_sub"
+		sub = sub.Table(table + " AS " + subTable)
+	}
+	sub = sub.Select("1").Where(clause.Eq{
+		Column: clause.Column{Table: subTable, Name: e.subColumn},
+		Value:  clause.Column{Table: stmt.Table, Name: e.column},
+	})
+
+	if e.not {
+		builder.WriteString("NOT ")
+	}
+	builder.WriteString("EXISTS (")
+	builder.AddVar(builder, sub)
+	builder.WriteString(")")
+}
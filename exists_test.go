@@ -0,0 +1,56 @@
+package gormkit_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestExistsIn(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &Customer{}, &Invoice{})
+	db := manager.DB()
+	db.Create(&[]Customer{{Email: "a@example.com"}, {Email: "b@example.com"}, {Email: "c@example.com"}})
+	db.Create(&[]Invoice{{CustomerID: 1, Total: 50}, {CustomerID: 2, Total: 500}})
+
+	var big []Customer
+	err := db.Scopes(gormkit.ExistsIn(db.Model(&Invoice{}).Where("total > ?", 100), "customer_id", "id")).
+		Order("id").Find(&big).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(names(big, func(c Customer) string { return c.Email })); got != "[b@example.com]" {
+		t.Errorf("Expected the customer with a large invoice, got %s", got)
+	}
+
+	var none []Customer
+	if err := db.Scopes(gormkit.NotExistsIn(db.Model(&Invoice{}), "customer_id", "id")).Find(&none).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(names(none, func(c Customer) string { return c.Email })); got != "[c@example.com]" {
+		t.Errorf("Expected the customer without invoices, got %s", got)
+	}
+}
+
+func TestNotExistsInWithNulls(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &Employee{})
+	db := manager.DB()
+	boss := Employee{Name: "boss"}
+	db.Create(&boss)
+	db.Create(&[]Employee{{Name: "alice", ManagerID: &boss.ID}, {Name: "bob", ManagerID: &boss.ID}})
+
+	// The boss has no manager, so NOT IN sees a NULL and matches nothing.
+	var naive []Employee
+	db.Where("id NOT IN (?)", db.Model(&Employee{}).Select("manager_id")).Find(&naive)
+	if len(naive) != 0 {
+		t.Fatalf("Expected NOT IN to match nothing, got %d rows", len(naive))
+	}
+
+	var leaves []Employee
+	if err := db.Scopes(gormkit.NotExistsIn(db.Model(&Employee{}), "manager_id", "id")).Order("id").Find(&leaves).Error; err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(names(leaves, func(e Employee) string { return e.Name })); got != "[alice bob]" {
+		t.Errorf("Expected employees without reports, got %s", got)
+	}
+}
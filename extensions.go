@@ -0,0 +1,61 @@
+package gormkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+)
+
+// EnsureExtensions installs the given Postgres extensions unless they are
+// installed already, e.g. EnsureExtensions(ctx, "pg_trgm", "uuid-ossp").
+// Installing one takes a superuser, or CREATE on the database for trusted
+// extensions; without that it fails with ErrInsufficientPrivilege naming
+// the extension, so a DBA can install it once. Elsewhere it does nothing.
+func (m *Manager) EnsureExtensions(ctx context.Context, names ...string) error {
+	if flavorOf(m.db) != "postgres" {
+		return nil
+	}
+	return ensureExtensions(m.db.WithContext(withoutDeadlineAudit(ctx)), names)
+}
+
+// CreateExtension installs Postgres extensions as EnsureExtensions does.
+// Other databases have no extensions, so it is skipped there.
+func (s *Schema) CreateExtension(names ...string) {
+	if flavorOf(s.db) != "postgres" {
+		s.skip("skipped extensions %v: %s has no extensions", names, flavorOf(s.db))
+		return
+	}
+	for _, name := range names {
+		s.ops = append(s.ops, Operation{
+			SQL:       "CREATE EXTENSION IF NOT EXISTS " + s.quote(name),
+			extension: name,
+		})
+	}
+}
+
+// ensureExtensions checks pg_extension first, so extensions installed by a
+// DBA don't need the privilege to create them.
+func ensureExtensions(db *gorm.DB, names []string) error {
+	var installed []string
+	if err := db.Raw("SELECT extname FROM pg_extension").Scan(&installed).Error; err != nil {
+		return fmt.Errorf("failed to list extensions: %w", err)
+	}
+	for _, name := range names {
+		if containsFold(installed, name) {
+			continue
+		}
+		err := db.Exec("CREATE EXTENSION IF NOT EXISTS " + db.Statement.Quote(name)).Error
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "42501" { // insufficient_privilege
+			return fmt.Errorf("%w: extension %s is not installed and the database user can't create it; "+
+				"have a superuser run CREATE EXTENSION %q: %w", ErrInsufficientPrivilege, name, name, err)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to create extension %s: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package gormkit_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestExtensionsOutsidePostgres(t *testing.T) {
+	manager := gormkit.NewTestManager(t)
+	ctx := context.Background()
+
+	if err := manager.EnsureExtensions(ctx, "pg_trgm", "uuid-ossp"); err != nil {
+		t.Errorf("Expected EnsureExtensions to do nothing on sqlite, got %v", err)
+	}
+
+	mig := gormkit.Migration{ID: "001_extensions", Up: func(s *gormkit.Schema) {
+		s.CreateExtension("pg_trgm", "uuid-ossp")
+	}}
+	plan, err := manager.PlanMigrations(ctx, mig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ops := plan[0].Operations; len(ops) != 1 || ops[0].SQL != "" || ops[0].Note == "" {
+		t.Errorf("Expected the extensions to be skipped with a note, got %+v", ops)
+	}
+	if err := manager.ApplyMigrations(ctx, mig); err != nil {
+		t.Fatal(err)
+	}
+}
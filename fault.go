@@ -0,0 +1,49 @@
+package gormkit
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math/rand/v2"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// FaultInjector describes faults applied to a random share of statements.
+// Rates are probabilities between 0 and 1 and are rolled independently.
+type FaultInjector struct {
+	LatencyRate float64
+	Latency     time.Duration
+
+	// ErrorRate fails statements with ErrInjectedFault.
+	ErrorRate float64
+
+	// DropRate fails statements as if the connection was lost; the error
+	// wraps both ErrInjectedFault and driver.ErrBadConn.
+	DropRate float64
+
+	// Filter restricts injection to matching statements, e.g. one table.
+	Filter func(db *gorm.DB) bool
+}
+
+func (f *FaultInjector) inject(db *gorm.DB) {
+	if db.Error != nil || (f.Filter != nil && !f.Filter(db)) {
+		return
+	}
+	if f.Latency > 0 && roll(f.LatencyRate) {
+		if err := sleepContext(db.Statement.Context, f.Latency); err != nil {
+			db.AddError(err)
+			return
+		}
+	}
+	switch {
+	case roll(f.DropRate):
+		db.AddError(fmt.Errorf("%w: connection dropped: %w", ErrInjectedFault, driver.ErrBadConn))
+	case roll(f.ErrorRate):
+		db.AddError(ErrInjectedFault)
+	}
+}
+
+func roll(rate float64) bool {
+	return rate > 0 && rand.Float64() < rate
+}
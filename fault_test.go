@@ -0,0 +1,57 @@
+package gormkit_test
+
+import (
+	"database/sql/driver"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alinemone/gorm-kit"
+	"gorm.io/gorm"
+)
+
+func TestFaultInjector(t *testing.T) {
+	injector := &gormkit.FaultInjector{
+		ErrorRate: 1,
+		Filter: func(db *gorm.DB) bool {
+			return db.Statement.Table == "users"
+		},
+	}
+	manager, err := gormkit.New(&gormkit.Config{
+		Driver:        "test",
+		LogLevel:      "silent",
+		FaultInjector: injector,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	db := manager.DB()
+	if err := db.AutoMigrate(&User{}); err != nil {
+		t.Fatalf("Expected migration to bypass the filter: %v", err)
+	}
+
+	var users []User
+	if err := db.Find(&users).Error; !errors.Is(err, gormkit.ErrInjectedFault) {
+		t.Errorf("Expected ErrInjectedFault, got %v", err)
+	}
+
+	injector.ErrorRate = 0
+	injector.DropRate = 1
+	err = db.Find(&users).Error
+	if !errors.Is(err, gormkit.ErrInjectedFault) || !errors.Is(err, driver.ErrBadConn) {
+		t.Errorf("Expected injected bad connection, got %v", err)
+	}
+
+	injector.DropRate = 0
+	injector.LatencyRate = 1
+	injector.Latency = 20 * time.Millisecond
+	start := time.Now()
+	if err := db.Find(&users).Error; err != nil {
+		t.Fatal(err)
+	}
+	if time.Since(start) < 20*time.Millisecond {
+		t.Error("Expected injected latency")
+	}
+}
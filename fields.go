@@ -0,0 +1,110 @@
+package gormkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WithFields returns a context whose queries carry fields (request id, user
+// id, tenant, ...) in their log entries and, with Config.QueryComments, in a
+// trailing SQL comment. Fields merge with those already on ctx.
+func WithFields(ctx context.Context, fields map[string]any) context.Context {
+	prev := Fields(ctx)
+	merged := make(map[string]any, len(prev)+len(fields))
+	for k, v := range prev {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, fieldsKey, merged)
+}
+
+// Fields returns a copy of the fields attached to ctx by WithFields, e.g. to
+// add them to a tracing span.
+func Fields(ctx context.Context) map[string]any {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(fieldsKey).(map[string]any)
+	if fields == nil {
+		return nil
+	}
+	out := make(map[string]any, len(fields))
+	for k, v := range fields {
+		out[k] = v
+	}
+	return out
+}
+
+// fieldsComment renders fields sqlcommenter-style: /*key='value',...*/ with
+// sorted, URL-encoded keys and values, so it can never terminate early.
+func fieldsComment(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	fields, _ := ctx.Value(fieldsKey).(map[string]any)
+	if len(fields) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = fmt.Sprintf("%s='%s'", url.QueryEscape(k), url.QueryEscape(fmt.Sprint(fields[k])))
+	}
+	return "/*" + strings.Join(pairs, ",") + "*/"
+}
+
+func (m *Manager) registerQueryComments() error {
+	cb := m.db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("gormkit:comment_create", commentClause("INSERT")),
+		cb.Query().Before("gorm:query").Register("gormkit:comment_query", commentClause("SELECT")),
+		cb.Update().Before("gorm:update").Register("gormkit:comment_update", commentClause("UPDATE")),
+		cb.Delete().Before("gorm:delete").Register("gormkit:comment_delete", commentClause("DELETE")),
+		cb.Row().Before("gorm:row").Register("gormkit:comment_row", commentClause("SELECT")),
+		cb.Raw().Before("gorm:raw").Register("gormkit:comment_raw", commentClause("")),
+	)
+}
+
+// commentClause places the fields comment right after the statement keyword
+// ("SELECT /*...*/ *"), or in front of raw SQL that is already built.
+func commentClause(name string) func(*gorm.DB) {
+	return func(db *gorm.DB) {
+		comment := fieldsComment(db.Statement.Context)
+		if comment == "" || db.Error != nil {
+			return
+		}
+		if db.Statement.SQL.Len() > 0 || name == "" {
+			sql := db.Statement.SQL.String()
+			db.Statement.SQL.Reset()
+			db.Statement.SQL.WriteString(comment + " " + sql)
+			return
+		}
+
+		c := db.Statement.Clauses[name]
+		c.Name = name
+		if name == "INSERT" {
+			// Dialects such as sqlite build INSERT themselves and only keep
+			// the modifier, so the comment rides along there.
+			insert, _ := c.Expression.(clause.Insert)
+			insert.Modifier = strings.TrimSpace(insert.Modifier + " " + comment)
+			c.Expression = insert
+		} else {
+			c.AfterNameExpression = clause.Expr{SQL: comment}
+		}
+		db.Statement.Clauses[name] = c
+	}
+}
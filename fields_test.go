@@ -0,0 +1,93 @@
+package gormkit_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestWithFields(t *testing.T) {
+	ctx := gormkit.WithFields(context.Background(), map[string]any{"request_id": "r1"})
+	ctx = gormkit.WithFields(ctx, map[string]any{"tenant": "acme"})
+
+	fields := gormkit.Fields(ctx)
+	if fields["request_id"] != "r1" || fields["tenant"] != "acme" {
+		t.Errorf("Expected merged fields, got %v", fields)
+	}
+	if gormkit.Fields(context.Background()) != nil {
+		t.Error("Expected no fields on a bare context")
+	}
+}
+
+func TestWithFieldsLogsAndComments(t *testing.T) {
+	var buf bytes.Buffer
+	manager, err := gormkit.New(&gormkit.Config{
+		Driver:        "test",
+		LogLevel:      "silent",
+		LogFormat:     "json",
+		LogOutput:     &buf,
+		QueryComments: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	db := manager.DB()
+	db.AutoMigrate(&User{})
+	manager.SetLogLevel("info")
+
+	ctx := gormkit.WithFields(context.Background(), map[string]any{"request_id": "r1", "user": "a*/b"})
+	if err := db.WithContext(ctx).Create(&User{Name: "Commented"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	var users []User
+	if err := db.WithContext(ctx).Find(&users).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.WithContext(ctx).Exec("UPDATE users SET name = ?", "x").Error; err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected 3 log lines, got %d:\n%s", len(lines), buf.String())
+	}
+	for _, line := range lines {
+		var entry struct {
+			SQL    string         `json:"sql"`
+			Fields map[string]any `json:"fields"`
+		}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatal(err)
+		}
+		if entry.Fields["request_id"] != "r1" {
+			t.Errorf("Expected fields in log entry, got %s", line)
+		}
+		if !strings.Contains(entry.SQL, "/*request_id='r1',user='a%2A%2Fb'*/") {
+			t.Errorf("Expected query comment, got %s", entry.SQL)
+		}
+	}
+}
+
+func TestQueryCommentsSkippedWithPrepareStmt(t *testing.T) {
+	manager, err := gormkit.New(&gormkit.Config{Driver: "test", LogLevel: "silent", QueryComments: true, PrepareStmt: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+	db := manager.DB()
+	db.AutoMigrate(&User{})
+
+	for _, id := range []string{"r1", "r2", "r3"} {
+		ctx := gormkit.WithFields(context.Background(), map[string]any{"request_id": id})
+		db.WithContext(ctx).Where("name = ?", "alice").Find(&[]User{})
+	}
+	if stats := manager.StmtCacheStats(); stats.Hits < 2 {
+		t.Errorf("Expected requests to share a prepared statement, got %+v", stats)
+	}
+}
@@ -0,0 +1,121 @@
+package gormkit
+
+import (
+	"context"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+const flagsChannel = "gormkit_feature_flags"
+
+// FeatureFlag is a stored flag. Rollout is the percentage of units it is
+// enabled for, 100 meaning everyone.
+type FeatureFlag struct {
+	Name      string `gorm:"primaryKey;size:191"`
+	Enabled   bool
+	Rollout   int
+	UpdatedAt time.Time
+}
+
+func (FeatureFlag) TableName() string {
+	return "gormkit_feature_flags"
+}
+
+// Flags reads feature flags from the gormkit_feature_flags table through a
+// cache refreshed every TTL, or immediately on Postgres when Listen runs.
+type Flags struct {
+	// TTL bounds how stale cached flags may be, default 30s.
+	TTL time.Duration
+
+	manager *Manager
+	mu      sync.Mutex
+	flags   map[string]FeatureFlag
+	loaded  time.Time
+}
+
+// Flags returns the Manager's feature flags.
+func (m *Manager) Flags() *Flags {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.flags == nil {
+		m.flags = &Flags{TTL: 30 * time.Second, manager: m}
+	}
+	return m.flags
+}
+
+// Enabled reports whether flag is on for unit, e.g. a user or tenant id.
+// A partial rollout always gives the same answer for the same unit. Unknown
+// flags are off.
+//
+//	if ok, _ := manager.Flags().Enabled(ctx, "new-checkout", userID); ok {
+func (f *Flags) Enabled(ctx context.Context, flag, unit string) (bool, error) {
+	flags, err := f.load(ctx)
+	if err != nil {
+		return false, err
+	}
+	ff, ok := flags[flag]
+	if !ok || !ff.Enabled || ff.Rollout <= 0 {
+		return false, nil
+	}
+	if ff.Rollout >= 100 {
+		return true, nil
+	}
+	h := fnv.New32a()
+	h.Write([]byte(flag + "/" + unit))
+	return int(h.Sum32()%100) < ff.Rollout, nil
+}
+
+// Set creates or updates a flag. On Postgres other instances running Listen
+// see the change right away; elsewhere, CockroachDB included, within TTL.
+func (f *Flags) Set(ctx context.Context, flag FeatureFlag) error {
+	db := f.manager.db.WithContext(ctx)
+	if err := ensureTable(db, &FeatureFlag{}); err != nil {
+		return err
+	}
+	if err := db.Clauses(clause.OnConflict{UpdateAll: true}).Create(&flag).Error; err != nil {
+		return err
+	}
+	f.invalidate()
+	if f.manager.info.Flavor == "postgres" {
+		return f.manager.Notify(ctx, flagsChannel, flag.Name)
+	}
+	return nil
+}
+
+// Listen invalidates the cache whenever a flag is set by any instance, until
+// ctx is done. It requires Postgres.
+func (f *Flags) Listen(ctx context.Context) error {
+	return f.manager.Listen(ctx, flagsChannel, func(string) { f.invalidate() })
+}
+
+func (f *Flags) invalidate() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.flags = nil
+}
+
+func (f *Flags) load(ctx context.Context) (map[string]FeatureFlag, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.flags != nil && time.Since(f.loaded) < f.TTL {
+		return f.flags, nil
+	}
+
+	db := f.manager.db.WithContext(ctx)
+	if err := ensureTable(db, &FeatureFlag{}); err != nil {
+		return nil, err
+	}
+	var rows []FeatureFlag
+	if err := db.Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	f.flags = make(map[string]FeatureFlag, len(rows))
+	for _, row := range rows {
+		f.flags[row.Name] = row
+	}
+	f.loaded = time.Now()
+	return f.flags, nil
+}
@@ -0,0 +1,79 @@
+package gormkit_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestFlagsRollout(t *testing.T) {
+	manager := gormkit.NewTestManager(t)
+	ctx := context.Background()
+	flags := manager.Flags()
+
+	if ok, err := flags.Enabled(ctx, "new-checkout", "u1"); err != nil || ok {
+		t.Errorf("Expected unknown flag to be off, got %v, %v", ok, err)
+	}
+
+	if err := flags.Set(ctx, gormkit.FeatureFlag{Name: "new-checkout", Enabled: true, Rollout: 30}); err != nil {
+		t.Fatal(err)
+	}
+	on := 0
+	for i := 0; i < 1000; i++ {
+		unit := fmt.Sprintf("u%d", i)
+		ok, err := flags.Enabled(ctx, "new-checkout", unit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if again, _ := flags.Enabled(ctx, "new-checkout", unit); again != ok {
+			t.Fatalf("Expected a stable answer for %s", unit)
+		}
+		if ok {
+			on++
+		}
+	}
+	if on < 250 || on > 350 {
+		t.Errorf("Expected about 30%% of units, got %d/1000", on)
+	}
+
+	if err := flags.Set(ctx, gormkit.FeatureFlag{Name: "new-checkout", Enabled: false, Rollout: 100}); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := flags.Enabled(ctx, "new-checkout", "u1"); ok {
+		t.Error("Expected a disabled flag to be off")
+	}
+}
+
+func TestFlagsCache(t *testing.T) {
+	manager := gormkit.NewTestManager(t)
+	ctx := context.Background()
+	flags := manager.Flags()
+	flags.TTL = 50 * time.Millisecond
+
+	if err := flags.Set(ctx, gormkit.FeatureFlag{Name: "beta", Enabled: true, Rollout: 100}); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := flags.Enabled(ctx, "beta", ""); !ok {
+		t.Fatal("Expected beta to be on")
+	}
+
+	// A change made elsewhere shows up once the cache expires.
+	if err := manager.DB().Model(&gormkit.FeatureFlag{Name: "beta"}).Update("enabled", false).Error; err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := flags.Enabled(ctx, "beta", ""); !ok {
+		t.Error("Expected the cached value before TTL")
+	}
+	time.Sleep(60 * time.Millisecond)
+	if ok, _ := flags.Enabled(ctx, "beta", ""); ok {
+		t.Error("Expected the new value after TTL")
+	}
+
+	if err := flags.Listen(ctx); !errors.Is(err, gormkit.ErrUnsupportedDriver) {
+		t.Errorf("Expected ErrUnsupportedDriver, got %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package gormkit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// AsOfSystemTime returns a context whose queries may read data up to
+// staleness old (a negative duration, e.g. -5*time.Second), in exchange for
+// being served by the nearest replica without contending with writes: on
+// CockroachDB, selects outside transactions get AS OF SYSTEM TIME. A zero
+// staleness uses follower_read_timestamp(), the freshest time any replica
+// can serve. Other databases have no equivalent, and the queries read
+// current data there; on Aurora, read replicas through a Manager connected
+// to the cluster's reader endpoint instead. Raw SQL and locking reads are
+// left alone.
+//
+//	ctx = gormkit.AsOfSystemTime(ctx, -10*time.Second)
+//	db.WithContext(ctx).Model(&Order{}).Group("status").Select("status, count(*)").Scan(&counts)
+func AsOfSystemTime(ctx context.Context, staleness time.Duration) context.Context {
+	return context.WithValue(ctx, asOfSystemTimeKey, staleness)
+}
+
+// beforeAsOfSystemTime adds AS OF SYSTEM TIME after the FROM clause of
+// selects whose context asks for stale reads.
+func (m *Manager) beforeAsOfSystemTime(db *gorm.DB) {
+	if db.Error != nil || db.Statement.Context == nil || !m.info.Supports(FeatureFollowerReads) {
+		return
+	}
+	staleness, ok := db.Statement.Context.Value(asOfSystemTimeKey).(time.Duration)
+	if !ok {
+		return
+	}
+	// CockroachDB fixes a transaction's timestamp at BEGIN, and locking
+	// reads can't be historical.
+	if _, inTx := db.Statement.ConnPool.(gorm.TxCommitter); inTx {
+		return
+	}
+	if _, locking := db.Statement.Clauses["FOR"]; locking {
+		return
+	}
+	at := "follower_read_timestamp()"
+	if staleness != 0 {
+		at = fmt.Sprintf("'%gs'", staleness.Seconds())
+	}
+	c := db.Statement.Clauses["FROM"]
+	c.AfterExpression = clause.Expr{SQL: "AS OF SYSTEM TIME " + at}
+	db.Statement.Clauses["FROM"] = c
+}
@@ -0,0 +1,25 @@
+package gormkit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestAsOfSystemTimeIgnoredWithoutFollowerReads(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &User{})
+	db := manager.DB()
+	db.Create(&User{Name: "alice"})
+
+	ctx := gormkit.AsOfSystemTime(context.Background(), -5*time.Second)
+	var users []User
+	if err := db.WithContext(ctx).Find(&users).Error; err != nil || len(users) != 1 {
+		t.Errorf("Expected a current read, got %v, %v", users, err)
+	}
+	var count int64
+	if err := db.WithContext(ctx).Model(&User{}).Count(&count).Error; err != nil || count != 1 {
+		t.Errorf("Expected a count of 1, got %d, %v", count, err)
+	}
+}
@@ -0,0 +1,166 @@
+package gormkit
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ForeignKeyRef is a single-column foreign key found by introspection.
+type ForeignKeyRef struct {
+	Table     string
+	Column    string
+	RefTable  string
+	RefColumn string
+	Name      string // empty on SQLite, which doesn't report it
+	OnDelete  string // NO ACTION, RESTRICT, CASCADE, SET NULL or SET DEFAULT
+	OnUpdate  string
+}
+
+const postgresForeignKeysSQL = `
+SELECT c.conrelid::regclass::text AS "table", a.attname AS "column",
+       c.confrelid::regclass::text AS ref_table, af.attname AS ref_column, c.conname AS name,
+       CASE c.confdeltype WHEN 'r' THEN 'RESTRICT' WHEN 'c' THEN 'CASCADE' WHEN 'n' THEN 'SET NULL'
+            WHEN 'd' THEN 'SET DEFAULT' ELSE 'NO ACTION' END AS on_delete,
+       CASE c.confupdtype WHEN 'r' THEN 'RESTRICT' WHEN 'c' THEN 'CASCADE' WHEN 'n' THEN 'SET NULL'
+            WHEN 'd' THEN 'SET DEFAULT' ELSE 'NO ACTION' END AS on_update
+FROM pg_constraint c
+JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = c.conkey[1]
+JOIN pg_attribute af ON af.attrelid = c.confrelid AND af.attnum = c.confkey[1]
+WHERE c.contype = 'f' AND array_length(c.conkey, 1) = 1
+  AND c.connamespace = current_schema()::regnamespace
+ORDER BY 1, 2`
+
+const mysqlForeignKeysSQL = `
+SELECT k.TABLE_NAME AS ` + "`table`" + `, k.COLUMN_NAME AS ` + "`column`" + `,
+       k.REFERENCED_TABLE_NAME AS ref_table, k.REFERENCED_COLUMN_NAME AS ref_column,
+       k.CONSTRAINT_NAME AS name, rc.DELETE_RULE AS on_delete, rc.UPDATE_RULE AS on_update
+FROM information_schema.KEY_COLUMN_USAGE k
+JOIN information_schema.REFERENTIAL_CONSTRAINTS rc
+  ON rc.CONSTRAINT_SCHEMA = k.CONSTRAINT_SCHEMA AND rc.CONSTRAINT_NAME = k.CONSTRAINT_NAME
+WHERE k.TABLE_SCHEMA = DATABASE() AND k.REFERENCED_TABLE_NAME IS NOT NULL
+  AND (SELECT COUNT(*) FROM information_schema.KEY_COLUMN_USAGE k2
+       WHERE k2.CONSTRAINT_SCHEMA = k.CONSTRAINT_SCHEMA AND k2.TABLE_NAME = k.TABLE_NAME
+         AND k2.CONSTRAINT_NAME = k.CONSTRAINT_NAME) = 1
+ORDER BY 1, 2`
+
+const sqliteForeignKeysSQL = `
+SELECT m.name AS "table", f."from" AS "column", f."table" AS ref_table,
+       COALESCE(f."to", (SELECT p.name FROM pragma_table_info(f."table") p WHERE p.pk = 1), '') AS ref_column,
+       '' AS name, f.on_delete AS on_delete, f.on_update AS on_update
+FROM sqlite_master m, pragma_foreign_key_list(m.name) f
+WHERE m.type = 'table'
+  AND (SELECT COUNT(*) FROM pragma_foreign_key_list(m.name) f2 WHERE f2.id = f.id) = 1
+ORDER BY 1, 2`
+
+// ForeignKeys lists the single-column foreign keys of the current schema,
+// read from the database catalog. Composite keys are left out.
+func (m *Manager) ForeignKeys(ctx context.Context) ([]ForeignKeyRef, error) {
+	var query string
+	switch m.config.Driver {
+	case "postgres":
+		query = postgresForeignKeysSQL
+	case "mysql":
+		query = mysqlForeignKeysSQL
+	case "sqlite", "test":
+		query = sqliteForeignKeysSQL
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedDriver, m.config.Driver)
+	}
+	refs, err := Query[ForeignKeyRef](withoutDeadlineAudit(ctx), m.db, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query foreign keys: %w", err)
+	}
+	return refs, nil
+}
+
+// ForeignKeyDrift is a foreign key whose referential actions in the
+// database differ from the ones its model declares, or that is missing.
+type ForeignKeyDrift struct {
+	Table        string
+	Column       string
+	RefTable     string
+	WantOnDelete string
+	WantOnUpdate string
+	OnDelete     string // empty when the foreign key is missing
+	OnUpdate     string
+}
+
+// CheckForeignKeys compares the foreign keys of models' relationships with
+// the database. Declare their actions with gorm's constraint tag, e.g.
+// `gorm:"constraint:OnDelete:CASCADE"`; unset ones are NO ACTION, the SQL
+// default, so a key a DBA changed to CASCADE is reported too. Drift is
+// sorted by table and column; fix it with Schema.ReplaceForeignKey.
+func (m *Manager) CheckForeignKeys(ctx context.Context, models ...interface{}) ([]ForeignKeyDrift, error) {
+	refs, err := m.ForeignKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	actual := make(map[[2]string]ForeignKeyRef, len(refs))
+	for _, ref := range refs {
+		actual[[2]string{ref.Table, ref.Column}] = ref
+	}
+
+	var drift []ForeignKeyDrift
+	seen := make(map[[2]string]bool)
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: m.db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, err
+		}
+		for _, rel := range stmt.Schema.Relationships.Relations {
+			c := rel.ParseConstraint()
+			if c == nil || len(c.ForeignKeys) != 1 {
+				continue
+			}
+			key := [2]string{c.Schema.Table, c.ForeignKeys[0].DBName}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			d := ForeignKeyDrift{
+				Table:        key[0],
+				Column:       key[1],
+				RefTable:     c.ReferenceSchema.Table,
+				WantOnDelete: fkAction(c.OnDelete),
+				WantOnUpdate: fkAction(c.OnUpdate),
+			}
+			ref, ok := actual[key]
+			if ok {
+				d.OnDelete, d.OnUpdate = fkAction(ref.OnDelete), fkAction(ref.OnUpdate)
+				if m.sameFKAction(d.OnDelete, d.WantOnDelete) && m.sameFKAction(d.OnUpdate, d.WantOnUpdate) {
+					continue
+				}
+			}
+			drift = append(drift, d)
+		}
+	}
+	sort.Slice(drift, func(i, j int) bool {
+		if drift[i].Table != drift[j].Table {
+			return drift[i].Table < drift[j].Table
+		}
+		return drift[i].Column < drift[j].Column
+	})
+	return drift, nil
+}
+
+func fkAction(action string) string {
+	if action = strings.ToUpper(strings.TrimSpace(action)); action != "" {
+		return action
+	}
+	return "NO ACTION"
+}
+
+// sameFKAction treats NO ACTION and RESTRICT alike on MySQL, where they are
+// the same thing and either is reported for keys declared without actions.
+func (m *Manager) sameFKAction(a, b string) bool {
+	if flavorOf(m.db) == "mysql" {
+		a = strings.Replace(a, "RESTRICT", "NO ACTION", 1)
+		b = strings.Replace(b, "RESTRICT", "NO ACTION", 1)
+	}
+	return a == b
+}
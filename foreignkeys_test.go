@@ -0,0 +1,45 @@
+package gormkit_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+type invoiceNote struct {
+	ID        uint
+	InvoiceID uint
+	Invoice   Invoice `gorm:"constraint:OnDelete:CASCADE"`
+	Text      string
+}
+
+func TestCheckForeignKeys(t *testing.T) {
+	manager := gormkit.NewTestManager(t)
+	db := manager.DB()
+	// A DBA made invoices cascade, which the model doesn't declare.
+	db.Exec("CREATE TABLE customers (id INTEGER PRIMARY KEY, email TEXT)")
+	db.Exec("CREATE TABLE invoices (id INTEGER PRIMARY KEY, customer_id INTEGER REFERENCES customers (id) ON DELETE CASCADE, total REAL)")
+	if err := db.AutoMigrate(&invoiceNote{}); err != nil {
+		t.Fatal(err)
+	}
+
+	drift, err := manager.CheckForeignKeys(context.Background(), &Invoice{}, &invoiceNote{}, &Employee{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []gormkit.ForeignKeyDrift{
+		{Table: "employees", Column: "manager_id", RefTable: "employees",
+			WantOnDelete: "NO ACTION", WantOnUpdate: "NO ACTION"},
+		{Table: "invoices", Column: "customer_id", RefTable: "customers",
+			WantOnDelete: "NO ACTION", WantOnUpdate: "NO ACTION", OnDelete: "CASCADE", OnUpdate: "NO ACTION"},
+	}
+	if len(drift) != len(want) {
+		t.Fatalf("Expected %d drifted keys, got %+v", len(want), drift)
+	}
+	for i := range want {
+		if drift[i] != want[i] {
+			t.Errorf("Expected %+v, got %+v", want[i], drift[i])
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package gormkit
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// StateMachine declares the states of a model's status column and the
+// transitions allowed between them.
+type StateMachine struct {
+	Column      string              // status column or field name, default "status"
+	Transitions map[string][]string // allowed target states by current state
+	History     bool                // record transitions in gormkit_state_transitions
+}
+
+// Stateful is implemented by models with a state machine.
+//
+//	func (Order) StateMachine() gormkit.StateMachine {
+//		return gormkit.StateMachine{Transitions: map[string][]string{
+//			"pending": {"paid", "cancelled"},
+//			"paid":    {"shipped", "refunded"},
+//		}}
+//	}
+type Stateful interface {
+	StateMachine() StateMachine
+}
+
+// StateTransition records one transition of a model with History enabled.
+type StateTransition struct {
+	ID        uint64 `gorm:"primaryKey"`
+	Table     string `gorm:"column:table_name;size:191;index:idx_gormkit_state_transitions_row"`
+	RowID     string `gorm:"size:191;index:idx_gormkit_state_transitions_row"`
+	From      string `gorm:"column:from_state;size:64"`
+	To        string `gorm:"column:to_state;size:64"`
+	CreatedAt time.Time
+}
+
+func (StateTransition) TableName() string {
+	return "gormkit_state_transitions"
+}
+
+// Transition moves model, a pointer to a saved Stateful row, to state to.
+// The UPDATE only matches the row while it is still in model's current
+// state, so a concurrent transition can't be overwritten; when the
+// transition isn't allowed or the row has changed, it fails with
+// ErrInvalidTransition. On success the model's status field is set to to.
+//
+//	err := gormkit.Transition(ctx, db, &order, "paid")
+func Transition(ctx context.Context, db *gorm.DB, model Stateful, to string) error {
+	sm := model.StateMachine()
+	if sm.Column == "" {
+		sm.Column = "status"
+	}
+	db = db.WithContext(ctx)
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return err
+	}
+	field := stmt.Schema.LookUpField(sm.Column)
+	if field == nil {
+		return fmt.Errorf("%s has no column %s", stmt.Schema.Table, sm.Column)
+	}
+	pk := stmt.Schema.PrioritizedPrimaryField
+	if pk == nil {
+		return fmt.Errorf("%s needs a single primary key", stmt.Schema.Table)
+	}
+	rv := reflect.Indirect(reflect.ValueOf(model))
+	id, zero := pk.ValueOf(ctx, rv)
+	if zero {
+		return fmt.Errorf("%s has no primary key value", stmt.Schema.Table)
+	}
+	value, _ := field.ValueOf(ctx, rv)
+	from := fmt.Sprint(value)
+	if !slices.Contains(sm.Transitions[from], to) {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, from, to)
+	}
+
+	if sm.History {
+		if err := ensureTable(db, &StateTransition{}); err != nil {
+			return err
+		}
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// Update through a fresh value: gorm writes assignments back to the
+		// model even when no row matched.
+		res := tx.Model(reflect.New(rv.Type()).Interface()).
+			Where(clause.Eq{Column: clause.Column{Name: pk.DBName}, Value: id}).
+			Where(clause.Eq{Column: clause.Column{Name: field.DBName}, Value: value}).
+			Update(field.DBName, to)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return fmt.Errorf("%w: %s to %s, row no longer %s", ErrInvalidTransition, from, to, from)
+		}
+		if !sm.History {
+			return nil
+		}
+		return tx.Create(&StateTransition{
+			Table: stmt.Schema.Table,
+			RowID: fmt.Sprint(id),
+			From:  from,
+			To:    to,
+		}).Error
+	})
+	if err != nil {
+		return err
+	}
+	return field.Set(ctx, rv, to)
+}
+
+// Transitions returns the recorded transitions of model, oldest first.
+func Transitions(ctx context.Context, db *gorm.DB, model Stateful) ([]StateTransition, error) {
+	db = db.WithContext(ctx)
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, err
+	}
+	if err := ensureTable(db, &StateTransition{}); err != nil {
+		return nil, err
+	}
+	pk := stmt.Schema.PrioritizedPrimaryField
+	if pk == nil {
+		return nil, fmt.Errorf("%s needs a single primary key", stmt.Schema.Table)
+	}
+	id, _ := pk.ValueOf(ctx, reflect.Indirect(reflect.ValueOf(model)))
+	var transitions []StateTransition
+	err := db.Where("table_name = ? AND row_id = ?", stmt.Schema.Table, fmt.Sprint(id)).
+		Order("id").Find(&transitions).Error
+	return transitions, err
+}
@@ -0,0 +1,89 @@
+package gormkit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+type shipment struct {
+	ID     uint
+	Status string
+}
+
+func (shipment) StateMachine() gormkit.StateMachine {
+	return gormkit.StateMachine{
+		Transitions: map[string][]string{
+			"pending": {"paid", "cancelled"},
+			"paid":    {"shipped"},
+		},
+		History: true,
+	}
+}
+
+func TestTransition(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &shipment{})
+	db := manager.DB()
+	ctx := context.Background()
+	o := shipment{Status: "pending"}
+	db.Create(&o)
+
+	if err := gormkit.Transition(ctx, db, &o, "shipped"); !errors.Is(err, gormkit.ErrInvalidTransition) {
+		t.Errorf("Expected ErrInvalidTransition, got %v", err)
+	}
+	if err := gormkit.Transition(ctx, db, &o, "paid"); err != nil {
+		t.Fatal(err)
+	}
+	if o.Status != "paid" {
+		t.Errorf("Expected paid, got %s", o.Status)
+	}
+
+	// A stale copy can't overwrite the transition.
+	stale := shipment{ID: o.ID, Status: "pending"}
+	if err := gormkit.Transition(ctx, db, &stale, "cancelled"); !errors.Is(err, gormkit.ErrInvalidTransition) {
+		t.Errorf("Expected ErrInvalidTransition, got %v", err)
+	}
+	if stale.Status != "pending" {
+		t.Errorf("Expected the stale copy to stay pending, got %s", stale.Status)
+	}
+	var saved shipment
+	db.First(&saved, o.ID)
+	if saved.Status != "paid" {
+		t.Errorf("Expected paid, got %s", saved.Status)
+	}
+
+	history, err := gormkit.Transitions(ctx, db, &o)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != 1 || history[0].From != "pending" || history[0].To != "paid" {
+		t.Errorf("Unexpected history: %+v", history)
+	}
+}
+
+type ticket struct {
+	ID    uint
+	Phase string `gorm:"column:current_phase"`
+}
+
+func (ticket) StateMachine() gormkit.StateMachine {
+	return gormkit.StateMachine{Column: "Phase", Transitions: map[string][]string{"open": {"closed"}}}
+}
+
+func TestTransitionByFieldName(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &ticket{})
+	db := manager.DB()
+	tk := ticket{Phase: "open"}
+	db.Create(&tk)
+
+	if err := gormkit.Transition(context.Background(), db, &tk, "closed"); err != nil {
+		t.Fatal(err)
+	}
+	var saved ticket
+	db.First(&saved, tk.ID)
+	if saved.Phase != "closed" {
+		t.Errorf("Expected closed, got %s", saved.Phase)
+	}
+}
@@ -0,0 +1,147 @@
+package gormkit
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Generated is a column computed by the database from other columns of the
+// row, declared with its expression in the generated tag and, for one
+// stored on disk rather than computed on read, the stored tag. AutoMigrate
+// creates it as a generated column, and inserts and updates never write it.
+// Postgres only has stored generated columns before version 18.
+//
+//	type LineItem struct {
+//		ID       uint
+//		Price    float64
+//		Quantity int
+//		Total    gormkit.Generated[float64] `gorm:"generated:price * quantity;stored"`
+//	}
+type Generated[T any] struct {
+	V T
+}
+
+// GormDataType maps T to gorm's generic data type.
+func (Generated[T]) GormDataType() string {
+	t := reflect.TypeFor[T]()
+	switch {
+	case t == reflect.TypeFor[time.Time]():
+		return string(schema.Time)
+	case t == reflect.TypeFor[[]byte]():
+		return string(schema.Bytes)
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return string(schema.Bool)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return string(schema.Int)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return string(schema.Uint)
+	case reflect.Float32, reflect.Float64:
+		return string(schema.Float)
+	}
+	return string(schema.String)
+}
+
+// GormDBDataType returns the column definition: T's column type, or the
+// type tag, followed by the generation clause.
+func (Generated[T]) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	return generatedColumnType(db, db.Dialector.DataTypeOf(field), field.TagSettings["GENERATED"], field.TagSettings["STORED"] != "")
+}
+
+func (g *Generated[T]) Scan(src interface{}) error {
+	var v sql.Null[T]
+	if err := v.Scan(src); err != nil {
+		return err
+	}
+	g.V = v.V
+	return nil
+}
+
+func (g Generated[T]) Value() (driver.Value, error) {
+	return driver.DefaultParameterConverter.ConvertValue(g.V)
+}
+
+func (g Generated[T]) MarshalJSON() ([]byte, error) {
+	return json.Marshal(g.V)
+}
+
+func (g *Generated[T]) UnmarshalJSON(data []byte) error {
+	return json.Unmarshal(data, &g.V)
+}
+
+// GeneratedColumn is a column added by Schema.AddGeneratedColumn.
+type GeneratedColumn struct {
+	Table string
+	Name  string
+	Type  string // e.g. "numeric"
+	Expr  string // e.g. "price * quantity"
+	// Stored computes the value on write and keeps it on disk, so it can
+	// be indexed everywhere; otherwise it is computed on read.
+	Stored bool
+}
+
+// AddGeneratedColumn adds c to an existing table. SQLite can't add stored
+// generated columns to existing tables, so it adds a virtual one there.
+func (s *Schema) AddGeneratedColumn(c GeneratedColumn) {
+	op := Operation{}
+	if c.Stored && flavorOf(s.db) == "sqlite" {
+		c.Stored = false
+		op.Note = fmt.Sprintf("generated column %s added as virtual: sqlite can't add stored ones to existing tables", c.Name)
+	}
+	op.SQL = fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", s.quote(c.Table), s.quote(c.Name),
+		generatedColumnType(s.db, c.Type, c.Expr, c.Stored))
+	s.ops = append(s.ops, op)
+}
+
+// generatedColumnType renders a generated column of typ. Postgres before
+// 18 and CockroachDB before 21.1 only have stored generated columns.
+func generatedColumnType(db *gorm.DB, typ, expr string, stored bool) string {
+	if expr == "" {
+		return typ
+	}
+	switch info := serverInfoOf(db); info.Flavor {
+	case "postgres":
+		stored = stored || !info.AtLeast(18, 0)
+	case "cockroachdb":
+		stored = stored || !info.AtLeast(21, 1)
+	}
+	kind := "VIRTUAL"
+	if stored {
+		kind = "STORED"
+	}
+	return fmt.Sprintf("%s GENERATED ALWAYS AS (%s) %s", typ, expr, kind)
+}
+
+// generatedColumns caches the generated columns of each schema.
+var generatedColumns sync.Map // *schema.Schema -> []string
+
+// beforeGenerated omits generated columns from inserts and updates, which
+// the database would reject.
+func beforeGenerated(db *gorm.DB) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return
+	}
+	s := db.Statement.Schema
+	columns, ok := generatedColumns.Load(s)
+	if !ok {
+		var names []string
+		for _, field := range s.Fields {
+			if _, ok := field.TagSettings["GENERATED"]; ok && field.DBName != "" {
+				names = append(names, field.DBName)
+			}
+		}
+		columns, _ = generatedColumns.LoadOrStore(s, names)
+	}
+	if names := columns.([]string); len(names) > 0 {
+		db.Statement.Omits = appendCopy(db.Statement.Omits, names...)
+	}
+}
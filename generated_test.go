@@ -0,0 +1,74 @@
+package gormkit_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+type lineItem struct {
+	ID       uint
+	Price    float64
+	Quantity int
+	Total    gormkit.Generated[float64] `gorm:"generated:price * quantity;stored"`
+	Label    gormkit.Generated[string]  `gorm:"generated:'x' || quantity"`
+}
+
+func TestGeneratedColumns(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &lineItem{})
+	db := manager.DB()
+
+	item := lineItem{Price: 2.5, Quantity: 4, Total: gormkit.Generated[float64]{V: 99}}
+	if err := db.Create(&item).Error; err != nil {
+		t.Fatalf("Expected the generated columns to be left out of the insert, got %v", err)
+	}
+	if err := db.Model(&item).Updates(lineItem{Quantity: 2, Total: gormkit.Generated[float64]{V: 99}}).Error; err != nil {
+		t.Fatalf("Expected the generated columns to be left out of the update, got %v", err)
+	}
+	if err := db.Save(&item).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if err := db.AutoMigrate(&lineItem{}); err != nil {
+		t.Errorf("Expected migrating again to keep the generated columns, got %v", err)
+	}
+
+	var got lineItem
+	db.First(&got, item.ID)
+	if got.Total.V != 5 || got.Label.V != "x2" {
+		t.Errorf("Expected total 5 and label x2, got %+v", got)
+	}
+	if data, _ := json.Marshal(got.Total); string(data) != "5" {
+		t.Errorf("Expected the value to marshal bare, got %s", data)
+	}
+}
+
+func TestAddGeneratedColumn(t *testing.T) {
+	manager := gormkit.NewTestManager(t)
+	db := manager.DB()
+	ctx := context.Background()
+
+	migration := gormkit.Migration{ID: "001_orders_total", Up: func(s *gormkit.Schema) {
+		s.Exec("CREATE TABLE orders (id INTEGER, price REAL, quantity INTEGER)")
+		s.AddGeneratedColumn(gormkit.GeneratedColumn{Table: "orders", Name: "total", Type: "real", Expr: "price * quantity", Stored: true})
+	}}
+	plan, err := manager.PlanMigrations(ctx, migration)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ops := plan[0].Operations
+	if len(ops) != 2 || ops[1].Note == "" || ops[1].SQL != "ALTER TABLE `orders` ADD COLUMN `total` real GENERATED ALWAYS AS (price * quantity) VIRTUAL" {
+		t.Fatalf("Unexpected operations: %+v", ops)
+	}
+	if err := manager.ApplyMigrations(ctx, migration); err != nil {
+		t.Fatal(err)
+	}
+	db.Exec("INSERT INTO orders (id, price, quantity) VALUES (1, 3, 3)")
+	var total float64
+	db.Raw("SELECT total FROM orders").Scan(&total)
+	if total != 9 {
+		t.Errorf("Expected total 9, got %v", total)
+	}
+}
@@ -0,0 +1,73 @@
+package gormkit
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// sqlRecorder is a logger that collects statement SQL with placeholders.
+type sqlRecorder struct {
+	mu   sync.Mutex
+	sqls []string
+}
+
+func (r *sqlRecorder) LogMode(logger.LogLevel) logger.Interface      { return r }
+func (r *sqlRecorder) Info(context.Context, string, ...interface{})  {}
+func (r *sqlRecorder) Warn(context.Context, string, ...interface{})  {}
+func (r *sqlRecorder) Error(context.Context, string, ...interface{}) {}
+
+func (r *sqlRecorder) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	sql, _ := fc()
+	r.mu.Lock()
+	r.sqls = append(r.sqls, sql)
+	r.mu.Unlock()
+}
+
+func (r *sqlRecorder) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
+
+// RecordSQL runs fn against a dry-run session of db and returns the SQL it
+// generated, one statement per entry, with placeholders instead of values.
+// Nothing is executed.
+func RecordSQL(db *gorm.DB, fn func(tx *gorm.DB)) []string {
+	rec := &sqlRecorder{}
+	fn(db.Session(&gorm.Session{DryRun: true, Logger: rec}))
+	return rec.sqls
+}
+
+// AssertGoldenSQL compares the SQL recorded from fn with testdata/<name>.sql.
+// Run the tests with GORMKIT_UPDATE_GOLDEN=1 to write the golden files.
+func AssertGoldenSQL(t testing.TB, db *gorm.DB, name string, fn func(tx *gorm.DB)) {
+	t.Helper()
+
+	got := strings.Join(RecordSQL(db, fn), ";\n") + ";\n"
+	path := filepath.Join("testdata", name+".sql")
+
+	if os.Getenv("GORMKIT_UPDATE_GOLDEN") != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("gormkit: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("gormkit: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("gormkit: %v (run with GORMKIT_UPDATE_GOLDEN=1 to create it)", err)
+		return
+	}
+	if got != string(want) {
+		t.Errorf("gormkit: SQL does not match %s\n--- want\n%s--- got\n%s", path, want, got)
+	}
+}
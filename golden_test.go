@@ -0,0 +1,41 @@
+package gormkit_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+	"gorm.io/gorm"
+)
+
+func TestRecordSQL(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &User{})
+
+	sqls := gormkit.RecordSQL(manager.DB(), func(tx *gorm.DB) {
+		tx.Create(&User{Name: "Dry"})
+		var users []User
+		tx.Where("name = ?", "secret").Find(&users)
+	})
+	if len(sqls) != 2 {
+		t.Fatalf("Expected 2 statements, got %v", sqls)
+	}
+	if strings.Contains(sqls[1], "secret") || !strings.Contains(sqls[1], "name = ?") {
+		t.Errorf("Expected placeholders, got %s", sqls[1])
+	}
+
+	var count int64
+	manager.DB().Model(&User{}).Count(&count)
+	if count != 0 {
+		t.Error("Expected dry run not to execute statements")
+	}
+}
+
+func TestAssertGoldenSQL(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &User{})
+
+	gormkit.AssertGoldenSQL(t, manager.DB(), "users_by_name", func(tx *gorm.DB) {
+		var users []User
+		tx.Where("name = ?", "Ali").Order("id").Limit(10).Find(&users)
+		tx.Model(&User{}).Where("id = ?", 1).Update("name", "Reza")
+	})
+}
@@ -4,18 +4,22 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"io"
 	"log"
 	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	sqlite "github.com/glebarez/sqlite"
 	"gorm.io/driver/mysql"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
-	"gorm.io/gorm/logger"
 )
 
 type Config struct {
+	Profile  string // "dev", "test" or "prod"; fills unset fields with curated defaults
 	Driver   string
 	Host     string
 	Port     int
@@ -25,33 +29,191 @@ type Config struct {
 	SSLMode  string
 	Timezone string // e.g., "UTC", "Asia/Tehran", "America/New_York"
 
+	// Hosts lists several endpoints ("host" or "host:port") of the same
+	// database, e.g. the nodes of a multi-AZ cluster, instead of Host. New
+	// connections go to healthy ones; failing endpoints are ejected until
+	// they recover (Postgres and MySQL).
+	Hosts []string
+
+	// Resolver discovers Hosts at connect time and again every
+	// ResolveInterval (default 30s), e.g. SRVResolver for DNS SRV records.
+	Resolver        Resolver
+	ResolveInterval time.Duration
+
+	// SealedDSN is an encrypted, base64-encoded driver DSN used instead of
+	// the connection fields above, so config files don't hold plaintext
+	// credentials. DecryptDSN opens it at connect time, e.g. with KMS, age
+	// or DecryptAESGCM.
+	SealedDSN  string
+	DecryptDSN func(ctx context.Context, sealed []byte) ([]byte, error)
+
+	// CredentialProvider supplies the user and password of every new
+	// connection instead of User and Password (Postgres and MySQL), e.g. the
+	// gormkitvault provider.
+	CredentialProvider CredentialProvider
+
 	MaxOpenConns    int
 	MaxIdleConns    int
 	ConnMaxLifetime time.Duration
 	ConnMaxIdleTime time.Duration
 
 	LogLevel       string
+	LogFormat      string    // "text" (default) or "json"
+	LogOutput      io.Writer // defaults to os.Stdout
+	LogSampleRate  int       // log 1 in N of the same query at info level
+	SlowThreshold  time.Duration
 	AutoMigrate    bool
 	RetryAttempts  int
 	ConnectTimeout time.Duration
+
+	AllowDestructiveMigrations bool
+	// DisallowDestructiveMigrations refuses DropTables even where
+	// AllowDestructiveMigrations is set, e.g. by the dev or test profile.
+	DisallowDestructiveMigrations bool
+
+	// OnlineSchemaChange runs the AlterTable steps of versioned migrations
+	// through gh-ost or pt-online-schema-change on MySQL, so large tables
+	// aren't locked for the duration of the change.
+	OnlineSchemaChange *OnlineSchemaChange
+
+	// OnError is called for every failed statement except record-not-found,
+	// e.g. to report it to Sentry (see the gormkitsentry package).
+	OnError func(context.Context, QueryError)
+
+	// QueryComments appends WithFields values to each statement as an
+	// sqlcommenter-style comment, visible in pg_stat_activity and slow logs.
+	// It is ignored with PrepareStmt: per-request comments would make every
+	// statement miss the cache.
+	QueryComments bool
+
+	// FaultInjector makes a share of statements slow or fail, for testing
+	// retry and circuit-breaker behavior. Never set it in production.
+	FaultInjector *FaultInjector
+
+	// TraceIDFunc extracts a trace id from the query context for JSON logs.
+	TraceIDFunc func(context.Context) string
+
+	QueryBudgetMode string // "error" (default) or "warn"
+	DeadlineAudit   string // "warn" or "error" on queries without a context deadline
+
+	// RowCountLimits flags UPDATE and DELETE statements affecting more rows
+	// than expected.
+	RowCountLimits *RowCountLimits
+
+	// Metrics collects per-table, per-operation query metrics, served by
+	// Manager.Metrics and Manager.MetricsHandler.
+	Metrics bool
+
+	// Quotas limit the statements per second of tables or tenants,
+	// delaying or rejecting those over the limit.
+	Quotas []Quota
+
+	// SLOs are latency and error objectives whose rolling compliance is
+	// computed from the metrics (which they enable), see Manager.SLOStatus.
+	SLOs []SLO
+
+	// MaxClockSkew, when set, compares the database clock with the local one
+	// at connect and every ClockSkewInterval (default 1m). Skew beyond it is
+	// logged, counted, and fails the ReadinessProbe.
+	MaxClockSkew      time.Duration
+	ClockSkewInterval time.Duration
+
+	// Requirements are checked at connect, failing it with every unmet
+	// one listed, e.g. a missing extension or an unexpected collation.
+	Requirements *Requirements
+
+	// SchemaVersions is the range of schema versions this build works with,
+	// checked at connect so an instance rolled out against an incompatible
+	// schema fails to start, rather than erroring mid-request. With
+	// SchemaVersionMode "degrade" it starts anyway, logging the mismatch;
+	// see Manager.SupportsModel.
+	SchemaVersions    SchemaVersionRange
+	SchemaVersionMode string // "fail" (default) or "degrade"
+
+	// PoolerCompat makes the Manager safe behind a transaction-mode pooler
+	// (PgBouncer, RDS Proxy): no prepared statements, the simple query
+	// protocol, idle connections kept (they are cheap), and LISTEN refused.
+	PoolerCompat bool
+
+	// WarmupConnections opens this many pooled connections at startup, and
+	// WarmupQueries run on them (preparing them when PrepareStmt is set), so
+	// the first requests after a deploy don't pay for connection setup.
+	WarmupConnections int
+	WarmupQueries     []string
+
+	PrepareStmt        bool
+	PrepareStmtMaxSize int           // LRU capacity of the statement cache, unlimited if 0
+	PrepareStmtTTL     time.Duration // evict statements unused for this long, never if 0
+
+	// PlanInvalidationChannel is a Postgres NOTIFY channel used to tell other
+	// instances to invalidate cached plans after this one migrates.
+	PlanInvalidationChannel string
+	OnPlansInvalidated      func()
 }
 
 type Manager struct {
-	db     *gorm.DB
-	sqlDB  *sql.DB
-	config *Config
+	db            *gorm.DB
+	sqlDB         *sql.DB
+	config        *Config
+	info          ServerInfo
+	logger        *kitLogger
+	metrics       *metricsRegistry
+	events        *EventBus
+	stmts         stmtCache
+	scheduler     *Scheduler
+	flags         *Flags
+	settings      *Settings
+	closers       []func()
+	creds         atomic.Pointer[Credentials]
+	endpoints     *endpointSet
+	clockSkew     atomic.Pointer[time.Duration]
+	schemaVersion atomic.Pointer[string]
+	health        healthHistory
+	slos          []*sloTracker
+	closed        atomic.Bool
+	mu            sync.Mutex
 }
 
 func New(cfg *Config) (*Manager, error) {
+	return newManager(context.Background(), cfg)
+}
+
+// newManager is New with connecting, retries included, cut short when ctx
+// is done.
+func newManager(ctx context.Context, cfg *Config) (*Manager, error) {
 	if cfg == nil {
 		return nil, fmt.Errorf("config is required")
 	}
 
+	if err := applyProfile(cfg); err != nil {
+		return nil, err
+	}
+	if err := validateSLOs(cfg.SLOs); err != nil {
+		return nil, err
+	}
+	if err := validateQuotas(cfg.Quotas); err != nil {
+		return nil, err
+	}
+	applyDefaults(cfg)
+
+	m := &Manager{config: cfg, events: newEventBus()}
+
+	if err := m.connect(ctx); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+func applyDefaults(cfg *Config) {
 	if cfg.MaxOpenConns == 0 {
 		cfg.MaxOpenConns = 25
 	}
 	if cfg.MaxIdleConns == 0 {
 		cfg.MaxIdleConns = 5
+		if cfg.PoolerCompat {
+			cfg.MaxIdleConns = cfg.MaxOpenConns
+		}
 	}
 	if cfg.ConnMaxLifetime == 0 {
 		cfg.ConnMaxLifetime = 5 * time.Minute
@@ -68,60 +230,119 @@ func New(cfg *Config) (*Manager, error) {
 	if cfg.Timezone == "" {
 		cfg.Timezone = "Asia/Tehran"
 	}
+	if cfg.ResolveInterval == 0 {
+		cfg.ResolveInterval = 30 * time.Second
+	}
+	if cfg.ClockSkewInterval == 0 {
+		cfg.ClockSkewInterval = time.Minute
+	}
+	if cfg.SlowThreshold == 0 {
+		cfg.SlowThreshold = 200 * time.Millisecond
+	}
+}
 
-	m := &Manager{config: cfg}
+func (m *Manager) connect(ctx context.Context) (err error) {
+	var dialector gorm.Dialector
 
-	if err := m.connect(); err != nil {
-		return nil, err
+	setupCtx, cancelSetup := context.WithTimeout(ctx, m.config.ConnectTimeout)
+	defer cancelSetup()
+	dsn, err := m.unsealDSN(setupCtx)
+	if err != nil {
+		return err
 	}
 
-	return m, nil
-}
+	// NowFunc, the session time zone and the zone the driver reads
+	// timestamps in all follow Timezone, so a value reads back as written.
+	loc, err := time.LoadLocation(m.config.Timezone)
+	if err != nil {
+		return fmt.Errorf("invalid timezone %s: %w", m.config.Timezone, err)
+	}
+	if tz := dsnTimezone(m.config.Driver, dsn); dsn != "" && tz != "" && tz != m.config.Timezone {
+		log.Printf("gormkit: dsn time zone %s differs from Timezone %s; timestamps will read back shifted", tz, m.config.Timezone)
+	}
 
-func (m *Manager) connect() error {
-	var dialector gorm.Dialector
+	if err := m.resolveHosts(setupCtx); err != nil {
+		return err
+	}
+	if len(m.config.Hosts) > 0 {
+		port := m.config.Port
+		setDefault(&port, map[string]int{"postgres": 5432, "mysql": 3306}[m.config.Driver])
+		if m.endpoints, err = newEndpointSet(m.config.Hosts, port); err != nil {
+			return err
+		}
+		first := m.endpoints.list[0]
+		setDefault(&m.config.Host, first.host)
+		setDefault(&m.config.Port, first.port)
+	}
 
 	switch m.config.Driver {
 	case "postgres":
-		dsn := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s TimeZone=%s",
-			m.config.Host, m.config.Port, m.config.User, m.config.Password,
-			m.config.Database, m.config.SSLMode, m.config.Timezone)
-		dialector = postgres.Open(dsn)
+		if dsn == "" {
+			dsn = fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s TimeZone=%s",
+				pgValue(m.config.Host), m.config.Port, pgValue(m.config.User), pgValue(m.config.Password),
+				pgValue(m.config.Database), pgValue(m.config.SSLMode), m.config.Timezone)
+		}
+		dialector = postgres.New(postgres.Config{DSN: dsn, PreferSimpleProtocol: m.config.PoolerCompat})
+		if m.needsConnector() {
+			if dialector, err = m.connectorDialector(dsn); err != nil {
+				return err
+			}
+		}
 
 	case "mysql":
-		dsn := fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=%s",
-			m.config.User, m.config.Password, m.config.Host, m.config.Port, m.config.Database,
-			url.QueryEscape(m.config.Timezone))
+		if dsn == "" {
+			dsn = fmt.Sprintf("%s:%s@tcp(%s:%d)/%s?charset=utf8mb4&parseTime=True&loc=%s&time_zone=%s",
+				m.config.User, m.config.Password, m.config.Host, m.config.Port, m.config.Database,
+				url.QueryEscape(m.config.Timezone), url.QueryEscape("'"+mysqlTimeZone(loc)+"'"))
+			switch m.config.SSLMode {
+			case "require":
+				dsn += "&tls=skip-verify"
+			case "verify-ca", "verify-full":
+				dsn += "&tls=true"
+			}
+		}
 		dialector = mysql.Open(dsn)
+		if m.needsConnector() {
+			if dialector, err = m.connectorDialector(dsn); err != nil {
+				return err
+			}
+		}
 
 	case "sqlite", "test":
 		if m.config.Database == "" {
 			m.config.Database = ":memory:"
 		}
-		dialector = sqlite.Open(m.config.Database)
+		if dsn == "" {
+			dsn = m.config.Database
+		}
+		dialector = sqlite.Open(withBusyTimeout(dsn))
+		if m.needsConnector() {
+			return fmt.Errorf("%w: %s with CredentialProvider or Hosts", ErrUnsupportedDriver, m.config.Driver)
+		}
 
 	default:
 		return fmt.Errorf("unsupported driver: %s", m.config.Driver)
 	}
 
-	logLevel := logger.Info
-	if m.config.LogLevel == "silent" {
-		logLevel = logger.Silent
-	} else if m.config.LogLevel == "error" {
-		logLevel = logger.Error
-	}
+	m.logger = newKitLogger(m.config)
 
-	// Load timezone location
-	loc, err := time.LoadLocation(m.config.Timezone)
-	if err != nil {
-		return fmt.Errorf("invalid timezone %s: %w", m.config.Timezone, err)
+	if m.config.PoolerCompat && m.config.PrepareStmt {
+		log.Printf("gormkit: PrepareStmt is ignored with PoolerCompat")
+		m.config.PrepareStmt = false
+	}
+	if m.config.PrepareStmt && m.config.QueryComments {
+		log.Printf("gormkit: QueryComments is ignored with PrepareStmt")
+		m.config.QueryComments = false
 	}
 
 	gormConfig := &gorm.Config{
-		Logger: logger.Default.LogMode(logLevel),
+		Logger: m.logger,
 		NowFunc: func() time.Time {
 			return time.Now().In(loc)
 		},
+		PrepareStmt:        m.config.PrepareStmt,
+		PrepareStmtMaxSize: m.config.PrepareStmtMaxSize,
+		PrepareStmtTTL:     m.config.PrepareStmtTTL,
 	}
 
 	for i := 0; i < m.config.RetryAttempts; i++ {
@@ -129,14 +350,28 @@ func (m *Manager) connect() error {
 		if err == nil {
 			break
 		}
-		if i < m.config.RetryAttempts-1 {
-			time.Sleep(100 * time.Millisecond)
+		if i < m.config.RetryAttempts-1 && sleepContext(ctx, 100*time.Millisecond) != nil {
+			break
 		}
 	}
 
 	if err != nil {
 		return fmt.Errorf("failed to connect: %w", err)
 	}
+	// A Manager that fails to come up is never returned, so nothing else
+	// would close its pool.
+	defer func() {
+		if err == nil {
+			return
+		}
+		if sqlDB, dbErr := m.db.DB(); dbErr == nil {
+			sqlDB.Close()
+		}
+	}()
+
+	if err := m.db.Use(&plugin{manager: m}); err != nil {
+		return fmt.Errorf("failed to register callbacks: %w", err)
+	}
 
 	m.sqlDB, err = m.db.DB()
 	if err != nil {
@@ -148,17 +383,62 @@ func (m *Manager) connect() error {
 	m.sqlDB.SetConnMaxLifetime(m.config.ConnMaxLifetime)
 	m.sqlDB.SetConnMaxIdleTime(m.config.ConnMaxIdleTime)
 
-	ctx, cancel := context.WithTimeout(context.Background(), m.config.ConnectTimeout)
+	ctx, cancel := context.WithTimeout(ctx, m.config.ConnectTimeout)
 	defer cancel()
 
 	if err := m.sqlDB.PingContext(ctx); err != nil {
 		return fmt.Errorf("ping failed: %w", err)
 	}
 
+	if m.info, err = m.detectServerInfo(ctx); err != nil {
+		log.Printf("gormkit: %v", err)
+	}
+
+	if m.config.Requirements != nil {
+		if err := m.CheckRequirements(ctx, *m.config.Requirements); err != nil {
+			return err
+		}
+	}
+
+	if m.config.SchemaVersions != (SchemaVersionRange{}) {
+		if err := m.checkSchemaVersion(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err := m.warmup(ctx); err != nil {
+		return err
+	}
+
+	if m.config.MaxClockSkew > 0 {
+		m.checkClockSkew(ctx)
+		skewCtx, stopSkew := context.WithCancel(context.Background())
+		go m.watchClockSkew(skewCtx)
+		m.onClose(stopSkew)
+	}
+
+	if m.endpoints != nil {
+		probeCtx, stopProbe := context.WithCancel(context.Background())
+		go m.endpoints.probe(probeCtx, m.config.ConnectTimeout)
+		if m.config.Resolver != nil {
+			go m.rediscover(probeCtx)
+		}
+		m.onClose(stopProbe)
+	}
+
 	log.Printf("Connected to %s database: %s", m.config.Driver, m.config.Database)
 	return nil
 }
 
+// pgValue quotes a key=value DSN value when needed; an empty value would
+// otherwise swallow the next keyword.
+func pgValue(v string) string {
+	if v != "" && !strings.ContainsAny(v, ` '\`) {
+		return v
+	}
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(v) + "'"
+}
+
 func (m *Manager) DB() *gorm.DB {
 	return m.db
 }
@@ -171,11 +451,31 @@ func (m *Manager) Migrate(models ...interface{}) error {
 	if !m.config.AutoMigrate {
 		return nil
 	}
-	return m.db.AutoMigrate(models...)
+	if err := m.internalDB().AutoMigrate(models...); err != nil {
+		return err
+	}
+	if err := m.migrateHistory(models...); err != nil {
+		return err
+	}
+	m.afterMigrate(withoutDeadlineAudit(context.Background()))
+	return nil
 }
 
+// Transaction runs fn in a transaction with the context's session
+// settings. Async event handlers of its statements run once it commits.
 func (m *Manager) Transaction(ctx context.Context, fn func(*gorm.DB) error) error {
-	return m.db.WithContext(ctx).Transaction(fn)
+	pending := &txEvents{}
+	ctx = context.WithValue(ctx, txEventsKey, pending)
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := m.applySessionSettings(ctx, tx); err != nil {
+			return err
+		}
+		return fn(tx)
+	})
+	if err == nil {
+		pending.flush()
+	}
+	return err
 }
 
 func (m *Manager) Ping(ctx context.Context) error {
@@ -187,12 +487,30 @@ func (m *Manager) Stats() sql.DBStats {
 }
 
 func (m *Manager) Close() error {
+	m.closed.Store(true)
+	m.mu.Lock()
+	closers := m.closers
+	m.closers = nil
+	m.mu.Unlock()
+	for _, fn := range closers {
+		fn()
+	}
+	if m.events != nil {
+		m.events.wait()
+	}
 	if m.sqlDB != nil {
 		return m.sqlDB.Close()
 	}
 	return nil
 }
 
+// onClose registers fn to stop a background subsystem when the Manager closes.
+func (m *Manager) onClose(fn func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closers = append(m.closers, fn)
+}
+
 func Paginate(page, perPage int) func(db *gorm.DB) *gorm.DB {
 	return func(db *gorm.DB) *gorm.DB {
 		if page < 1 {
@@ -1,8 +1,12 @@
 package gormkit_test
 
 import (
+	"bytes"
 	"context"
 	"fmt"
+	"log"
+	"os"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -235,6 +239,31 @@ func TestTimezoneInvalid(t *testing.T) {
 	t.Logf("Got expected error: %v", err)
 }
 
+func TestTimezoneDSNMismatch(t *testing.T) {
+	var buf bytes.Buffer
+	log.SetOutput(&buf)
+	defer log.SetOutput(os.Stderr)
+
+	key := bytes.Repeat([]byte("k"), 32)
+	sealed, err := gormkit.SealDSN(key, "app:secret@tcp(127.0.0.1:1)/app?parseTime=true&loc=UTC")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The connection fails; the warning is logged before connecting.
+	gormkit.New(&gormkit.Config{
+		Driver:         "mysql",
+		LogLevel:       "silent",
+		Timezone:       "Asia/Tokyo",
+		SealedDSN:      sealed,
+		DecryptDSN:     gormkit.DecryptAESGCM(key),
+		RetryAttempts:  1,
+		ConnectTimeout: time.Second,
+	})
+	if !strings.Contains(buf.String(), "dsn time zone UTC differs from Timezone Asia/Tokyo") {
+		t.Errorf("Expected a time zone mismatch warning, got %q", buf.String())
+	}
+}
+
 func TestTimezoneComparison(t *testing.T) {
 	// Create two managers with different timezones
 	managerUTC, err := gormkit.New(&gormkit.Config{
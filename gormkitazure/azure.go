@@ -0,0 +1,60 @@
+// Package gormkitazure authenticates to Azure Database for PostgreSQL and
+// MySQL with Microsoft Entra ID (Azure AD) tokens, e.g. from a managed or
+// workload identity, so no database password is stored.
+//
+//	cred, err := azidentity.NewDefaultAzureCredential(nil)
+//	manager, err := gormkit.New(&gormkit.Config{
+//		Driver:             "postgres",
+//		Host:               "app.postgres.database.azure.com",
+//		SSLMode:            "require",
+//		CredentialProvider: gormkitazure.New(cred, "app-identity"),
+//	})
+package gormkitazure
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/alinemone/gorm-kit"
+)
+
+// Scope is the token scope of Azure Database for PostgreSQL and MySQL.
+const Scope = "https://ossrdbms-aad.database.windows.net/.default"
+
+// refreshBefore is how long before expiry a token is replaced.
+const refreshBefore = 5 * time.Minute
+
+// Provider is a gormkit.CredentialProvider using Entra ID access tokens as
+// passwords. Tokens only authenticate new connections; established ones
+// stay open after the token expires.
+type Provider struct {
+	cred azcore.TokenCredential
+	user string
+
+	mu    sync.Mutex
+	token azcore.AccessToken
+}
+
+// New returns a Provider logging in as user, the database role mapped to the
+// identity behind cred (for a managed identity, usually its name).
+func New(cred azcore.TokenCredential, user string) *Provider {
+	return &Provider{cred: cred, user: user}
+}
+
+// Credentials returns user with a current access token.
+func (p *Provider) Credentials(ctx context.Context) (gormkit.Credentials, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if time.Until(p.token.ExpiresOn) < refreshBefore {
+		token, err := p.cred.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{Scope}})
+		if err != nil {
+			return gormkit.Credentials{}, fmt.Errorf("failed to get entra id token: %w", err)
+		}
+		p.token = token
+	}
+	return gormkit.Credentials{User: p.user, Password: p.token.Token, ExpiresAt: p.token.ExpiresOn}, nil
+}
@@ -0,0 +1,63 @@
+package gormkitazure_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/alinemone/gorm-kit/gormkitazure"
+)
+
+type fakeCredential struct {
+	calls   int
+	expires time.Duration
+	err     error
+}
+
+func (c *fakeCredential) GetToken(ctx context.Context, opts policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	if c.err != nil {
+		return azcore.AccessToken{}, c.err
+	}
+	if len(opts.Scopes) != 1 || opts.Scopes[0] != gormkitazure.Scope {
+		return azcore.AccessToken{}, errors.New("unexpected scope")
+	}
+	c.calls++
+	return azcore.AccessToken{Token: "token", ExpiresOn: time.Now().Add(c.expires)}, nil
+}
+
+func TestProviderCachesTokens(t *testing.T) {
+	cred := &fakeCredential{expires: time.Hour}
+	provider := gormkitazure.New(cred, "app-identity")
+
+	for i := 0; i < 3; i++ {
+		creds, err := provider.Credentials(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if creds.User != "app-identity" || creds.Password != "token" {
+			t.Errorf("Unexpected credentials: %+v", creds)
+		}
+	}
+	if cred.calls != 1 {
+		t.Errorf("Expected 1 token request, got %d", cred.calls)
+	}
+}
+
+func TestProviderRefreshesExpiringTokens(t *testing.T) {
+	cred := &fakeCredential{expires: time.Minute}
+	provider := gormkitazure.New(cred, "app-identity")
+
+	provider.Credentials(context.Background())
+	provider.Credentials(context.Background())
+	if cred.calls != 2 {
+		t.Errorf("Expected a token close to expiry to be refreshed, got %d requests", cred.calls)
+	}
+
+	cred.err = errors.New("no identity")
+	if _, err := provider.Credentials(context.Background()); err == nil {
+		t.Error("Expected the credential error")
+	}
+}
@@ -0,0 +1,242 @@
+// Package gormkitbench runs read/write workload mixes against a Manager to
+// size pool settings before launch.
+package gormkitbench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alinemone/gorm-kit"
+	"gorm.io/gorm"
+)
+
+// Workload is one kind of operation in the mix. Weight sets how often it is
+// picked relative to the other workloads.
+type Workload struct {
+	Name   string
+	Weight int
+	Run    func(ctx context.Context, db *gorm.DB) error
+}
+
+// Options configures a run.
+type Options struct {
+	Workloads   []Workload
+	Concurrency int           // default 8
+	Duration    time.Duration // default 10s
+	Operations  int           // stop after this many operations, if set
+}
+
+// Latency holds latency percentiles.
+type Latency struct {
+	P50, P90, P99, Max time.Duration
+}
+
+// WorkloadResult reports one workload.
+type WorkloadResult struct {
+	Operations int
+	Errors     int
+	Latency    Latency
+}
+
+// PoolResult reports connection pool pressure during the run.
+type PoolResult struct {
+	MaxOpen      int
+	PeakInUse    int
+	WaitCount    int64
+	WaitDuration time.Duration
+}
+
+// Result is the outcome of Run.
+type Result struct {
+	Duration   time.Duration
+	Operations int
+	Errors     int
+	Throughput float64 // operations per second
+	Latency    Latency
+	Workloads  map[string]WorkloadResult
+	Pool       PoolResult
+}
+
+type sample struct {
+	workload int
+	latency  time.Duration
+	err      bool
+}
+
+// Run executes the workload mix with Concurrency workers until Duration
+// elapses, Operations have run, or ctx is done.
+func Run(ctx context.Context, m *gormkit.Manager, opts Options) (*Result, error) {
+	if len(opts.Workloads) == 0 {
+		return nil, errors.New("at least one workload is required")
+	}
+	totalWeight := 0
+	for _, w := range opts.Workloads {
+		if w.Run == nil {
+			return nil, fmt.Errorf("workload %q has no Run function", w.Name)
+		}
+		totalWeight += max(w.Weight, 0)
+	}
+	if totalWeight == 0 {
+		return nil, errors.New("workload weights must not all be zero")
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 8
+	}
+	if opts.Duration <= 0 {
+		opts.Duration = 10 * time.Second
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, opts.Duration)
+	defer cancel()
+
+	before := m.Stats()
+	pool := PoolResult{MaxOpen: before.MaxOpenConnections}
+	// The sampler hands its peak back once stopped, so it never shares pool.
+	done := make(chan struct{})
+	peak := make(chan int, 1)
+	go func() {
+		ticker := time.NewTicker(50 * time.Millisecond)
+		defer ticker.Stop()
+		inUse := 0
+		for {
+			select {
+			case <-done:
+				peak <- inUse
+				return
+			case <-ticker.C:
+				inUse = max(inUse, m.Stats().InUse)
+			}
+		}
+	}()
+
+	var (
+		mu       sync.Mutex
+		samples  []sample
+		started  int64
+		budgetMu sync.Mutex
+		wg       sync.WaitGroup
+	)
+	next := func() bool {
+		if opts.Operations <= 0 {
+			return true
+		}
+		budgetMu.Lock()
+		defer budgetMu.Unlock()
+		if started >= int64(opts.Operations) {
+			return false
+		}
+		started++
+		return true
+	}
+
+	start := time.Now()
+	for i := 0; i < opts.Concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var local []sample
+			for ctx.Err() == nil && next() {
+				idx := pick(opts.Workloads, totalWeight)
+				opStart := time.Now()
+				err := opts.Workloads[idx].Run(ctx, m.WithContext(ctx))
+				if err != nil && ctx.Err() != nil {
+					break // cut off by the deadline, not a real failure
+				}
+				local = append(local, sample{workload: idx, latency: time.Since(opStart), err: err != nil})
+			}
+			mu.Lock()
+			samples = append(samples, local...)
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+	close(done)
+
+	after := m.Stats()
+	pool.PeakInUse = max(<-peak, after.InUse)
+	pool.WaitCount = after.WaitCount - before.WaitCount
+	pool.WaitDuration = after.WaitDuration - before.WaitDuration
+
+	return summarize(opts.Workloads, samples, elapsed, pool), nil
+}
+
+func pick(workloads []Workload, totalWeight int) int {
+	n := rand.IntN(totalWeight)
+	for i, w := range workloads {
+		if n < max(w.Weight, 0) {
+			return i
+		}
+		n -= max(w.Weight, 0)
+	}
+	return len(workloads) - 1
+}
+
+func summarize(workloads []Workload, samples []sample, elapsed time.Duration, pool PoolResult) *Result {
+	r := &Result{
+		Duration:   elapsed,
+		Operations: len(samples),
+		Workloads:  make(map[string]WorkloadResult, len(workloads)),
+		Pool:       pool,
+	}
+	if elapsed > 0 {
+		r.Throughput = float64(len(samples)) / elapsed.Seconds()
+	}
+
+	all := make([]time.Duration, 0, len(samples))
+	per := make([][]time.Duration, len(workloads))
+	perErrors := make([]int, len(workloads))
+	for _, s := range samples {
+		all = append(all, s.latency)
+		per[s.workload] = append(per[s.workload], s.latency)
+		if s.err {
+			r.Errors++
+			perErrors[s.workload]++
+		}
+	}
+	r.Latency = percentiles(all)
+	for i, w := range workloads {
+		r.Workloads[w.Name] = WorkloadResult{
+			Operations: len(per[i]),
+			Errors:     perErrors[i],
+			Latency:    percentiles(per[i]),
+		}
+	}
+	return r
+}
+
+func percentiles(latencies []time.Duration) Latency {
+	if len(latencies) == 0 {
+		return Latency{}
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	at := func(p float64) time.Duration {
+		return latencies[int(p*float64(len(latencies)-1))]
+	}
+	return Latency{P50: at(0.50), P90: at(0.90), P99: at(0.99), Max: latencies[len(latencies)-1]}
+}
+
+// String formats the result as a short report.
+func (r *Result) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d ops in %v (%.1f ops/s), %d errors\n", r.Operations, r.Duration.Round(time.Millisecond), r.Throughput, r.Errors)
+	fmt.Fprintf(&b, "latency p50=%v p90=%v p99=%v max=%v\n", r.Latency.P50, r.Latency.P90, r.Latency.P99, r.Latency.Max)
+
+	names := make([]string, 0, len(r.Workloads))
+	for name := range r.Workloads {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		w := r.Workloads[name]
+		fmt.Fprintf(&b, "  %s: %d ops, %d errors, p50=%v p99=%v\n", name, w.Operations, w.Errors, w.Latency.P50, w.Latency.P99)
+	}
+	fmt.Fprintf(&b, "pool: peak %d/%d in use, %d waits (%v)", r.Pool.PeakInUse, r.Pool.MaxOpen, r.Pool.WaitCount, r.Pool.WaitDuration)
+	return b.String()
+}
@@ -0,0 +1,68 @@
+package gormkitbench_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alinemone/gorm-kit"
+	"github.com/alinemone/gorm-kit/gormkitbench"
+	"gorm.io/gorm"
+)
+
+type Item struct {
+	ID   uint `gorm:"primarykey"`
+	Name string
+}
+
+func TestRun(t *testing.T) {
+	manager, err := gormkit.New(&gormkit.Config{Driver: "test", LogLevel: "silent", MaxOpenConns: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+	manager.DB().AutoMigrate(&Item{})
+
+	result, err := gormkitbench.Run(context.Background(), manager, gormkitbench.Options{
+		Concurrency: 4,
+		Duration:    5 * time.Second,
+		Operations:  200,
+		Workloads: []gormkitbench.Workload{
+			{Name: "read", Weight: 3, Run: func(ctx context.Context, db *gorm.DB) error {
+				var items []Item
+				return db.Limit(10).Find(&items).Error
+			}},
+			{Name: "write", Weight: 1, Run: func(ctx context.Context, db *gorm.DB) error {
+				return db.Create(&Item{Name: "bench"}).Error
+			}},
+			{Name: "fail", Weight: 1, Run: func(ctx context.Context, db *gorm.DB) error {
+				return errors.New("boom")
+			}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Operations != 200 {
+		t.Errorf("Expected 200 operations, got %d", result.Operations)
+	}
+	if result.Errors != result.Workloads["fail"].Operations {
+		t.Errorf("Expected only failing workload to error, got %d errors", result.Errors)
+	}
+	if result.Workloads["read"].Operations <= result.Workloads["write"].Operations {
+		t.Errorf("Expected reads to outnumber writes: %+v", result.Workloads)
+	}
+	if result.Latency.Max == 0 || result.Pool.MaxOpen != 1 {
+		t.Errorf("Unexpected result: %+v", result)
+	}
+	if !strings.Contains(result.String(), "ops/s") {
+		t.Errorf("Unexpected report: %s", result)
+	}
+
+	if _, err := gormkitbench.Run(context.Background(), manager, gormkitbench.Options{}); err == nil {
+		t.Error("Expected error without workloads")
+	}
+}
@@ -0,0 +1,81 @@
+package gormkitfactory_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+	"github.com/alinemone/gorm-kit/gormkitfactory"
+)
+
+type Company struct {
+	ID   uint `gorm:"primarykey"`
+	Name string
+}
+
+type Order struct {
+	ID     uint `gorm:"primarykey"`
+	UserID uint
+	Total  int64
+}
+
+type User struct {
+	ID        uint `gorm:"primarykey"`
+	Name      string
+	Nickname  *string
+	CompanyID uint
+	Company   Company
+	Orders    []Order
+}
+
+func TestFactoryBuild(t *testing.T) {
+	users := gormkitfactory.New[User]().
+		With("Name", func(seq int) string { return fmt.Sprintf("user-%d", seq) }).
+		With("Nickname", "nick")
+
+	built := users.Build(2)
+	if built[0].Name != "user-1" || built[1].Name != "user-2" {
+		t.Errorf("Expected sequenced names, got %q and %q", built[0].Name, built[1].Name)
+	}
+	if built[0].Nickname == nil || *built[0].Nickname != "nick" {
+		t.Error("Expected pointer field to be set")
+	}
+	if built[0].Nickname == built[1].Nickname {
+		t.Error("Expected each value to get its own pointer")
+	}
+}
+
+func TestFactoryCreate(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &Company{}, &User{}, &Order{})
+	db := manager.DB()
+
+	orders := gormkitfactory.New[Order]().With("Total", 100)
+	users := gormkitfactory.New[User]().
+		With("Name", func() string { return "buyer" }).
+		With("Company", gormkitfactory.New[Company]().With("Name", "Acme")).
+		WithMany("Orders", orders, 3)
+
+	created, err := users.Create(db, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created[0].ID == 0 || created[0].Company.ID == 0 {
+		t.Errorf("Expected IDs to be filled in, got %+v", created[0])
+	}
+
+	var companies, orderCount int64
+	db.Model(&Company{}).Count(&companies)
+	db.Model(&Order{}).Where("total = ?", 100).Count(&orderCount)
+	if companies != 2 || orderCount != 6 {
+		t.Errorf("Expected 2 companies and 6 orders, got %d and %d", companies, orderCount)
+	}
+}
+
+func TestFactoryInvalidField(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic for unknown field")
+		}
+	}()
+	gormkitfactory.New[User]().With("Missing", 1)
+}
@@ -0,0 +1,84 @@
+package gormkitfactory
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"strings"
+	"time"
+)
+
+var (
+	firstNames = []string{
+		"Ali", "Sara", "Reza", "Maryam", "John", "Emma", "Liam", "Olivia", "Noah", "Ava",
+		"Hassan", "Zahra", "Lucas", "Mia", "Omar", "Leila", "Ethan", "Sofia", "Arash", "Nina",
+	}
+	lastNames = []string{
+		"Ahmadi", "Karimi", "Smith", "Johnson", "Brown", "Garcia", "Miller", "Davis", "Rahimi", "Moradi",
+		"Wilson", "Taylor", "Anderson", "Thomas", "Hosseini", "Martin", "Clark", "Lewis", "Jafari", "Walker",
+	}
+	words = []string{
+		"alpha", "bright", "cedar", "delta", "ember", "frost", "granite", "harbor", "iris", "juniper",
+		"kite", "lumen", "meadow", "nova", "orbit", "pine", "quartz", "river", "summit", "timber",
+	}
+)
+
+// FirstName returns a generator of first names.
+func FirstName() func(*rand.Rand) string {
+	return func(r *rand.Rand) string { return pick(r, firstNames) }
+}
+
+// LastName returns a generator of last names.
+func LastName() func(*rand.Rand) string {
+	return func(r *rand.Rand) string { return pick(r, lastNames) }
+}
+
+// Name returns a generator of full names.
+func Name() func(*rand.Rand) string {
+	return func(r *rand.Rand) string { return pick(r, firstNames) + " " + pick(r, lastNames) }
+}
+
+// Email returns a generator of addresses at example.com. The sequence number
+// keeps them unique within a factory.
+func Email() func(int, *rand.Rand) string {
+	return func(seq int, r *rand.Rand) string {
+		return fmt.Sprintf("%s.%s%d@example.com",
+			strings.ToLower(pick(r, firstNames)), strings.ToLower(pick(r, lastNames)), seq)
+	}
+}
+
+// Words returns a generator of n space-separated words.
+func Words(n int) func(*rand.Rand) string {
+	return func(r *rand.Rand) string {
+		out := make([]string, n)
+		for i := range out {
+			out[i] = pick(r, words)
+		}
+		return strings.Join(out, " ")
+	}
+}
+
+// IntBetween returns a generator of integers in [lo, hi].
+func IntBetween(lo, hi int) func(*rand.Rand) int {
+	return func(r *rand.Rand) int { return lo + r.IntN(hi-lo+1) }
+}
+
+// TimeBetween returns a generator of times in [from, to), truncated to the
+// microsecond so they survive a database round trip unchanged.
+func TimeBetween(from, to time.Time) func(*rand.Rand) time.Time {
+	span := to.Sub(from)
+	return func(r *rand.Rand) time.Time {
+		if span <= 0 {
+			return from
+		}
+		return from.Add(time.Duration(r.Int64N(int64(span)))).Truncate(time.Microsecond)
+	}
+}
+
+// OneOf returns a generator picking one of values.
+func OneOf[V any](values ...V) func(*rand.Rand) V {
+	return func(r *rand.Rand) V { return pick(r, values) }
+}
+
+func pick[V any](r *rand.Rand, values []V) V {
+	return values[r.IntN(len(values))]
+}
@@ -0,0 +1,64 @@
+package gormkitfactory_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alinemone/gorm-kit/gormkitfactory"
+)
+
+type Customer struct {
+	Name     string
+	Email    string
+	Age      int
+	Tier     string
+	JoinedAt time.Time
+}
+
+func customers() *gormkitfactory.Factory[Customer] {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	return gormkitfactory.New[Customer]().
+		With("Name", gormkitfactory.Name()).
+		With("Email", gormkitfactory.Email()).
+		With("Age", gormkitfactory.IntBetween(18, 65)).
+		With("Tier", gormkitfactory.OneOf("free", "pro")).
+		With("JoinedAt", gormkitfactory.TimeBetween(from, from.AddDate(1, 0, 0)))
+}
+
+func TestSeedIsReproducible(t *testing.T) {
+	a := customers().Seed(42).Build(20)
+	b := customers().Seed(42).Build(20)
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("Expected identical data for the same seed, got %+v and %+v", a[i], b[i])
+		}
+	}
+
+	c := customers().Seed(7).Build(20)
+	same := true
+	for i := range a {
+		same = same && a[i] == c[i]
+	}
+	if same {
+		t.Error("Expected different data for a different seed")
+	}
+}
+
+func TestFakers(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for _, c := range customers().Seed(1).Build(50) {
+		if c.Age < 18 || c.Age > 65 {
+			t.Errorf("Age out of range: %d", c.Age)
+		}
+		if c.Tier != "free" && c.Tier != "pro" {
+			t.Errorf("Unexpected tier: %s", c.Tier)
+		}
+		if c.JoinedAt.Before(from) || !c.JoinedAt.Before(from.AddDate(1, 0, 0)) {
+			t.Errorf("JoinedAt out of range: %v", c.JoinedAt)
+		}
+		if !strings.HasSuffix(c.Email, "@example.com") || !strings.Contains(c.Name, " ") {
+			t.Errorf("Unexpected name or email: %q %q", c.Name, c.Email)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+// Package gormkitgcs stores gormkit attachment content in a Google Cloud
+// Storage bucket.
+//
+//	client, err := storage.NewClient(ctx)
+//	files := manager.Attachments(gormkitgcs.New(client.Bucket("uploads")))
+package gormkitgcs
+
+import (
+	"context"
+	"errors"
+	"io"
+	"path"
+
+	"cloud.google.com/go/storage"
+)
+
+// Store is a gormkit.BlobStore keeping content as objects in one bucket.
+type Store struct {
+	bucket *storage.BucketHandle
+	prefix string
+}
+
+// New returns a Store writing to bucket.
+func New(bucket *storage.BucketHandle) *Store {
+	return &Store{bucket: bucket}
+}
+
+// WithPrefix returns a copy of s storing objects under prefix, e.g.
+// "attachments/", to share a bucket with other data.
+func (s *Store) WithPrefix(prefix string) *Store {
+	c := *s
+	c.prefix = prefix
+	return &c
+}
+
+func (s *Store) object(key string) *storage.ObjectHandle {
+	return s.bucket.Object(path.Join(s.prefix, key))
+}
+
+// Put uploads r with a resumable upload, holding at most one chunk
+// (storage.Writer's ChunkSize, 16 MiB by default) in memory.
+func (s *Store) Put(ctx context.Context, key string, r io.Reader) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	w := s.object(key).NewWriter(ctx)
+	if _, err := io.Copy(w, r); err != nil {
+		// Cancelling before Close abandons the upload.
+		cancel()
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
+
+// Get streams the object stored under key.
+func (s *Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	return s.object(key).NewReader(ctx)
+}
+
+// Delete removes the object stored under key; missing objects are not an
+// error.
+func (s *Store) Delete(ctx context.Context, key string) error {
+	err := s.object(key).Delete(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,132 @@
+package gormkitgcs_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"cloud.google.com/go/storage"
+	"github.com/alinemone/gorm-kit"
+	"github.com/alinemone/gorm-kit/gormkitgcs"
+	"google.golang.org/api/option"
+)
+
+// fakeGCS serves the JSON API calls the store makes: multipart uploads,
+// media downloads and deletes.
+type fakeGCS struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func (f *fakeGCS) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/upload/storage/v1/b/"):
+		bucket := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/upload/storage/v1/b/"), "/o")
+		_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		parts := multipart.NewReader(r.Body, params["boundary"])
+		meta, err := parts.NextPart()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		metadata, _ := io.ReadAll(meta)
+		name := between(string(metadata), `"name":"`, `"`)
+		media, err := parts.NextPart()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		data, _ := io.ReadAll(media)
+		f.objects[bucket+"/"+name] = data
+		w.Header().Set("Content-Type", "application/json")
+		io.WriteString(w, `{"bucket":"`+bucket+`","name":"`+name+`"}`)
+
+	case strings.HasPrefix(r.URL.Path, "/storage/v1/b/"):
+		rest := strings.TrimPrefix(r.URL.Path, "/storage/v1/b/")
+		bucket, name, _ := strings.Cut(rest, "/o/")
+		data, ok := f.objects[bucket+"/"+name]
+		if !ok {
+			http.Error(w, `{"error":{"code":404,"message":"Not Found"}}`, http.StatusNotFound)
+			return
+		}
+		if r.Method == http.MethodDelete {
+			delete(f.objects, bucket+"/"+name)
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.Write(data)
+
+	default:
+		http.Error(w, "unexpected request "+r.Method+" "+r.URL.Path, http.StatusBadRequest)
+	}
+}
+
+func between(s, start, end string) string {
+	_, after, _ := strings.Cut(s, start)
+	value, _, _ := strings.Cut(after, end)
+	return value
+}
+
+func TestStoreWithAttachments(t *testing.T) {
+	fake := &fakeGCS{objects: map[string][]byte{}}
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	ctx := context.Background()
+	client, err := storage.NewClient(ctx,
+		option.WithEndpoint(server.URL+"/storage/v1/"),
+		option.WithoutAuthentication(),
+		storage.WithJSONReads(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	manager := gormkit.NewTestManager(t)
+	files := manager.Attachments(gormkitgcs.New(client.Bucket("uploads")).WithPrefix("attachments"))
+
+	att, err := files.Create(ctx, "notes.txt", "text/plain", bytes.NewBufferString("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(fake.objects["uploads/attachments/"+att.ID]) != "hello" {
+		t.Errorf("Expected object under the prefix, got %v", fake.objects)
+	}
+
+	body, _, err := files.Open(ctx, att.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(body)
+	body.Close()
+	if string(data) != "hello" {
+		t.Errorf("Expected hello, got %q", data)
+	}
+
+	if err := files.Delete(ctx, att.ID); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.objects) != 0 {
+		t.Errorf("Expected object to be deleted, got %v", fake.objects)
+	}
+
+	store := gormkitgcs.New(client.Bucket("uploads"))
+	if err := store.Delete(ctx, "missing"); err != nil {
+		t.Errorf("Expected deleting a missing object to succeed, got %v", err)
+	}
+}
@@ -0,0 +1,150 @@
+// Package gormkits3 stores gormkit attachment content in an Amazon S3 (or
+// S3-compatible) bucket.
+//
+//	cfg, err := config.LoadDefaultConfig(ctx)
+//	files := manager.Attachments(gormkits3.New(s3.NewFromConfig(cfg), "uploads"))
+package gormkits3
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// PartSize is the size of the parts larger uploads are split into; content
+// that fits in one part is uploaded with a single PutObject.
+const PartSize = 8 << 20
+
+// Client is the subset of *s3.Client the store uses.
+type Client interface {
+	PutObject(context.Context, *s3.PutObjectInput, ...func(*s3.Options)) (*s3.PutObjectOutput, error)
+	GetObject(context.Context, *s3.GetObjectInput, ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+	DeleteObject(context.Context, *s3.DeleteObjectInput, ...func(*s3.Options)) (*s3.DeleteObjectOutput, error)
+	CreateMultipartUpload(context.Context, *s3.CreateMultipartUploadInput, ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error)
+	UploadPart(context.Context, *s3.UploadPartInput, ...func(*s3.Options)) (*s3.UploadPartOutput, error)
+	CompleteMultipartUpload(context.Context, *s3.CompleteMultipartUploadInput, ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error)
+	AbortMultipartUpload(context.Context, *s3.AbortMultipartUploadInput, ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error)
+}
+
+// Store is a gormkit.BlobStore keeping content as objects in one bucket.
+type Store struct {
+	client Client
+	bucket string
+	prefix string
+}
+
+// New returns a Store writing to bucket through client.
+func New(client Client, bucket string) *Store {
+	return &Store{client: client, bucket: bucket}
+}
+
+// WithPrefix returns a copy of s storing objects under prefix, e.g.
+// "attachments/", to share a bucket with other data.
+func (s *Store) WithPrefix(prefix string) *Store {
+	c := *s
+	c.prefix = prefix
+	return &c
+}
+
+func (s *Store) key(key string) *string {
+	return aws.String(path.Join(s.prefix, key))
+}
+
+// Put uploads r, holding at most one PartSize part in memory.
+func (s *Store) Put(ctx context.Context, key string, r io.Reader) error {
+	buf := make([]byte, PartSize)
+	n, err := io.ReadFull(r, buf)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return err
+	}
+	if n < PartSize {
+		_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+			Bucket:        aws.String(s.bucket),
+			Key:           s.key(key),
+			Body:          bytes.NewReader(buf[:n]),
+			ContentLength: aws.Int64(int64(n)),
+		})
+		return err
+	}
+
+	upload, err := s.client.CreateMultipartUpload(ctx, &s3.CreateMultipartUploadInput{
+		Bucket: aws.String(s.bucket),
+		Key:    s.key(key),
+	})
+	if err != nil {
+		return err
+	}
+	parts, err := s.uploadParts(ctx, key, upload.UploadId, r, buf, n)
+	if err == nil {
+		_, err = s.client.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+			Bucket:          aws.String(s.bucket),
+			Key:             s.key(key),
+			UploadId:        upload.UploadId,
+			MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+		})
+	}
+	if err != nil {
+		// Incomplete uploads are billed until aborted.
+		s.client.AbortMultipartUpload(context.WithoutCancel(ctx), &s3.AbortMultipartUploadInput{
+			Bucket:   aws.String(s.bucket),
+			Key:      s.key(key),
+			UploadId: upload.UploadId,
+		})
+		return err
+	}
+	return nil
+}
+
+// uploadParts uploads the n bytes already in buf and then the rest of r.
+func (s *Store) uploadParts(ctx context.Context, key string, uploadID *string, r io.Reader, buf []byte, n int) ([]types.CompletedPart, error) {
+	var parts []types.CompletedPart
+	for number := int32(1); n > 0; number++ {
+		out, err := s.client.UploadPart(ctx, &s3.UploadPartInput{
+			Bucket:        aws.String(s.bucket),
+			Key:           s.key(key),
+			UploadId:      uploadID,
+			PartNumber:    aws.Int32(number),
+			Body:          bytes.NewReader(buf[:n]),
+			ContentLength: aws.Int64(int64(n)),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to upload part %d: %w", number, err)
+		}
+		parts = append(parts, types.CompletedPart{ETag: out.ETag, PartNumber: aws.Int32(number)})
+
+		n, err = io.ReadFull(r, buf)
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, err
+		}
+	}
+	return parts, nil
+}
+
+// Get streams the object stored under key.
+func (s *Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    s.key(key),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.Body, nil
+}
+
+// Delete removes the object stored under key; missing objects are not an
+// error.
+func (s *Store) Delete(ctx context.Context, key string) error {
+	_, err := s.client.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    s.key(key),
+	})
+	return err
+}
@@ -0,0 +1,144 @@
+package gormkits3_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+	"github.com/alinemone/gorm-kit/gormkits3"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+type fakeS3 struct {
+	objects map[string][]byte
+	uploads map[string][][]byte
+	aborted int
+	failAt  int32
+}
+
+func newFakeS3() *fakeS3 {
+	return &fakeS3{objects: map[string][]byte{}, uploads: map[string][][]byte{}}
+}
+
+func (f *fakeS3) PutObject(ctx context.Context, in *s3.PutObjectInput, _ ...func(*s3.Options)) (*s3.PutObjectOutput, error) {
+	data, err := io.ReadAll(in.Body)
+	if err != nil {
+		return nil, err
+	}
+	f.objects[*in.Bucket+"/"+*in.Key] = data
+	return &s3.PutObjectOutput{}, nil
+}
+
+func (f *fakeS3) GetObject(ctx context.Context, in *s3.GetObjectInput, _ ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	data, ok := f.objects[*in.Bucket+"/"+*in.Key]
+	if !ok {
+		return nil, errors.New("NoSuchKey")
+	}
+	return &s3.GetObjectOutput{Body: io.NopCloser(bytes.NewReader(data))}, nil
+}
+
+func (f *fakeS3) DeleteObject(ctx context.Context, in *s3.DeleteObjectInput, _ ...func(*s3.Options)) (*s3.DeleteObjectOutput, error) {
+	delete(f.objects, *in.Bucket+"/"+*in.Key)
+	return &s3.DeleteObjectOutput{}, nil
+}
+
+func (f *fakeS3) CreateMultipartUpload(ctx context.Context, in *s3.CreateMultipartUploadInput, _ ...func(*s3.Options)) (*s3.CreateMultipartUploadOutput, error) {
+	id := fmt.Sprintf("upload-%d", len(f.uploads))
+	f.uploads[id] = nil
+	return &s3.CreateMultipartUploadOutput{UploadId: aws.String(id)}, nil
+}
+
+func (f *fakeS3) UploadPart(ctx context.Context, in *s3.UploadPartInput, _ ...func(*s3.Options)) (*s3.UploadPartOutput, error) {
+	if *in.PartNumber == f.failAt {
+		return nil, errors.New("connection reset")
+	}
+	data, err := io.ReadAll(in.Body)
+	if err != nil {
+		return nil, err
+	}
+	f.uploads[*in.UploadId] = append(f.uploads[*in.UploadId], data)
+	return &s3.UploadPartOutput{ETag: aws.String(fmt.Sprintf("etag-%d", *in.PartNumber))}, nil
+}
+
+func (f *fakeS3) CompleteMultipartUpload(ctx context.Context, in *s3.CompleteMultipartUploadInput, _ ...func(*s3.Options)) (*s3.CompleteMultipartUploadOutput, error) {
+	parts := f.uploads[*in.UploadId]
+	if len(parts) != len(in.MultipartUpload.Parts) {
+		return nil, errors.New("InvalidPart")
+	}
+	f.objects[*in.Bucket+"/"+*in.Key] = bytes.Join(parts, nil)
+	delete(f.uploads, *in.UploadId)
+	return &s3.CompleteMultipartUploadOutput{}, nil
+}
+
+func (f *fakeS3) AbortMultipartUpload(ctx context.Context, in *s3.AbortMultipartUploadInput, _ ...func(*s3.Options)) (*s3.AbortMultipartUploadOutput, error) {
+	f.aborted++
+	delete(f.uploads, *in.UploadId)
+	return &s3.AbortMultipartUploadOutput{}, nil
+}
+
+func TestStoreWithAttachments(t *testing.T) {
+	manager := gormkit.NewTestManager(t)
+	client := newFakeS3()
+	files := manager.Attachments(gormkits3.New(client, "uploads").WithPrefix("attachments"))
+	ctx := context.Background()
+
+	att, err := files.Create(ctx, "notes.txt", "text/plain", bytes.NewBufferString("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(client.objects["uploads/attachments/"+att.ID]) != "hello" {
+		t.Errorf("Expected object under the prefix, got %v", client.objects)
+	}
+
+	body, _, err := files.Open(ctx, att.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(body)
+	body.Close()
+	if string(data) != "hello" {
+		t.Errorf("Expected hello, got %q", data)
+	}
+
+	if err := files.Delete(ctx, att.ID); err != nil {
+		t.Fatal(err)
+	}
+	if len(client.objects) != 0 {
+		t.Errorf("Expected object to be deleted, got %v", client.objects)
+	}
+}
+
+func TestStoreMultipartUpload(t *testing.T) {
+	client := newFakeS3()
+	store := gormkits3.New(client, "uploads")
+	content := bytes.Repeat([]byte("x"), 2*gormkits3.PartSize+10)
+
+	if err := store.Put(context.Background(), "big", bytes.NewReader(content)); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(client.objects["uploads/big"], content) {
+		t.Errorf("Expected %d bytes, got %d", len(content), len(client.objects["uploads/big"]))
+	}
+}
+
+func TestStoreAbortsFailedUpload(t *testing.T) {
+	client := newFakeS3()
+	client.failAt = 2
+	store := gormkits3.New(client, "uploads")
+	content := bytes.Repeat([]byte("x"), 2*gormkits3.PartSize)
+
+	if err := store.Put(context.Background(), "big", bytes.NewReader(content)); err == nil {
+		t.Fatal("Expected upload to fail")
+	}
+	if client.aborted != 1 || len(client.uploads) != 0 {
+		t.Errorf("Expected the upload to be aborted, got %d aborts", client.aborted)
+	}
+	if _, ok := client.objects["uploads/big"]; ok {
+		t.Error("Expected no object after a failed upload")
+	}
+}
@@ -0,0 +1,31 @@
+// Package gormkitsentry reports gorm-kit query errors to Sentry.
+package gormkitsentry
+
+import (
+	"context"
+
+	"github.com/alinemone/gorm-kit"
+	"github.com/getsentry/sentry-go"
+)
+
+// OnError returns a Config.OnError hook that captures query errors on the
+// hub carried by the query context, falling back to sentry.CurrentHub().
+// Events are grouped by SQL fingerprint, so each failing query shape shows up
+// as one Sentry issue.
+func OnError() func(context.Context, gormkit.QueryError) {
+	return func(ctx context.Context, qe gormkit.QueryError) {
+		hub := sentry.GetHubFromContext(ctx)
+		if hub == nil {
+			hub = sentry.CurrentHub()
+		}
+		hub.WithScope(func(scope *sentry.Scope) {
+			scope.SetTag("db.table", qe.Table)
+			scope.SetFingerprint([]string{"{{ default }}", qe.Fingerprint})
+			scope.SetContext("query", sentry.Context{
+				"sql":         qe.SQL,
+				"duration_ms": qe.Duration.Milliseconds(),
+			})
+			hub.CaptureException(qe.Err)
+		})
+	}
+}
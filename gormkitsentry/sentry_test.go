@@ -0,0 +1,50 @@
+package gormkitsentry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alinemone/gorm-kit"
+	"github.com/alinemone/gorm-kit/gormkitsentry"
+	"github.com/getsentry/sentry-go"
+)
+
+type recordingTransport struct {
+	events []*sentry.Event
+}
+
+func (t *recordingTransport) Configure(sentry.ClientOptions)        {}
+func (t *recordingTransport) SendEvent(event *sentry.Event)         { t.events = append(t.events, event) }
+func (t *recordingTransport) Flush(time.Duration) bool              { return true }
+func (t *recordingTransport) FlushWithContext(context.Context) bool { return true }
+func (t *recordingTransport) Close()                                {}
+
+func TestOnError(t *testing.T) {
+	transport := &recordingTransport{}
+	client, err := sentry.NewClient(sentry.ClientOptions{Transport: transport})
+	if err != nil {
+		t.Fatal(err)
+	}
+	hub := sentry.NewHub(client, sentry.NewScope())
+	ctx := sentry.SetHubOnContext(context.Background(), hub)
+
+	gormkitsentry.OnError()(ctx, gormkit.QueryError{
+		Err:         errors.New("boom"),
+		SQL:         "SELECT * FROM users WHERE id = ?",
+		Fingerprint: "SELECT * FROM users WHERE id = ?",
+		Table:       "users",
+	})
+
+	if len(transport.events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(transport.events))
+	}
+	event := transport.events[0]
+	if event.Tags["db.table"] != "users" {
+		t.Errorf("Expected db.table tag, got %v", event.Tags)
+	}
+	if len(event.Fingerprint) != 2 || event.Fingerprint[1] != "SELECT * FROM users WHERE id = ?" {
+		t.Errorf("Unexpected fingerprint: %v", event.Fingerprint)
+	}
+}
@@ -0,0 +1,346 @@
+// Package gormkitsession stores net/http sessions server-side in the
+// database, in the gormkit_sessions table.
+//
+//	store, err := gormkitsession.New(manager, gormkitsession.Options{TTL: 24 * time.Hour})
+//	store.ScheduleCleanup(manager.Scheduler(), "@hourly")
+//	http.ListenAndServe(":8080", store.Middleware(mux))
+//
+//	func handler(w http.ResponseWriter, r *http.Request) {
+//		sess := gormkitsession.FromContext(r.Context())
+//		sess.Set("user_id", 42)
+//	}
+package gormkitsession
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/alinemone/gorm-kit"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrNotFound is returned by Store.Get for unknown or expired sessions.
+var ErrNotFound = errors.New("session not found")
+
+// Record is a stored session. Only a hash of the session id is kept, so the
+// table cannot be used to hijack sessions.
+type Record struct {
+	IDHash    string `gorm:"primaryKey;size:64"`
+	Data      string
+	ExpiresAt time.Time `gorm:"index"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (Record) TableName() string {
+	return "gormkit_sessions"
+}
+
+// Options configures a Store.
+type Options struct {
+	TTL        time.Duration // idle lifetime, default 24h
+	CookieName string        // default "session"
+	CookiePath string        // default "/"
+	Domain     string
+	Insecure   bool // omit the Secure attribute, for plain-HTTP development
+	SameSite   http.SameSite
+}
+
+// Store creates, loads and expires sessions.
+type Store struct {
+	db   *gorm.DB
+	opts Options
+}
+
+// New returns a Store on the Manager's database, creating its table.
+func New(m *gormkit.Manager, opts Options) (*Store, error) {
+	if opts.TTL <= 0 {
+		opts.TTL = 24 * time.Hour
+	}
+	if opts.CookieName == "" {
+		opts.CookieName = "session"
+	}
+	if opts.CookiePath == "" {
+		opts.CookiePath = "/"
+	}
+	if opts.SameSite == 0 {
+		opts.SameSite = http.SameSiteLaxMode
+	}
+	if err := m.DB().AutoMigrate(&Record{}); err != nil {
+		return nil, err
+	}
+	return &Store{db: m.DB(), opts: opts}, nil
+}
+
+// Session is the data of one session. It is safe for concurrent use.
+type Session struct {
+	mu        sync.Mutex
+	id        string
+	values    map[string]json.RawMessage
+	expiresAt time.Time
+	isNew     bool
+	dirty     bool
+	destroyed bool
+	renewedID string // previous id, deleted on save after Renew
+}
+
+// ID returns the session id sent to the client.
+func (s *Session) ID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.id
+}
+
+// Get decodes the value stored under key into dest and reports whether it
+// was set.
+func (s *Session) Get(key string, dest interface{}) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	raw, ok := s.values[key]
+	return ok && json.Unmarshal(raw, dest) == nil
+}
+
+// Set stores value, which must be JSON-encodable, under key.
+func (s *Session) Set(key string, value interface{}) error {
+	raw, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.values[key] = raw
+	s.dirty = true
+	return nil
+}
+
+// Delete removes key from the session.
+func (s *Session) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.values, key)
+	s.dirty = true
+}
+
+// Renew gives the session a new id, keeping its values. Call it after login
+// to prevent session fixation.
+func (s *Session) Renew() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.isNew && s.renewedID == "" {
+		s.renewedID = s.id
+	}
+	s.id = newID()
+	s.isNew = true
+	s.dirty = true
+}
+
+// Destroy ends the session; the Middleware deletes it and clears the cookie.
+func (s *Session) Destroy() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.destroyed = true
+}
+
+// Create returns a new, not yet saved session.
+func (st *Store) Create() *Session {
+	return &Session{id: newID(), values: make(map[string]json.RawMessage), isNew: true}
+}
+
+// Get loads the session with the given id, or returns ErrNotFound.
+func (st *Store) Get(ctx context.Context, id string) (*Session, error) {
+	var rec Record
+	db := st.db.WithContext(ctx)
+	err := db.Where("id_hash = ? AND expires_at > ?", hashID(id), db.NowFunc()).Take(&rec).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	sess := &Session{id: id, expiresAt: rec.ExpiresAt}
+	if err := json.Unmarshal([]byte(rec.Data), &sess.values); err != nil {
+		return nil, err
+	}
+	if sess.values == nil {
+		sess.values = make(map[string]json.RawMessage)
+	}
+	return sess, nil
+}
+
+// Save stores the session and extends its expiry by TTL.
+func (st *Store) Save(ctx context.Context, sess *Session) error {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+
+	data, err := json.Marshal(sess.values)
+	if err != nil {
+		return err
+	}
+	db := st.db.WithContext(ctx)
+	expires := db.NowFunc().Add(st.opts.TTL)
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if sess.renewedID != "" {
+			if err := tx.Delete(&Record{IDHash: hashID(sess.renewedID)}).Error; err != nil {
+				return err
+			}
+		}
+		// An upsert rather than Save, which would overwrite created_at.
+		return tx.Clauses(clause.OnConflict{UpdateAll: true}).
+			Create(&Record{IDHash: hashID(sess.id), Data: string(data), ExpiresAt: expires}).Error
+	})
+	if err != nil {
+		return err
+	}
+	sess.expiresAt = expires
+	sess.isNew, sess.dirty, sess.renewedID = false, false, ""
+	return nil
+}
+
+// Touch extends the expiry of the session with the given id by TTL.
+func (st *Store) Touch(ctx context.Context, id string) error {
+	db := st.db.WithContext(ctx)
+	return db.Model(&Record{}).Where("id_hash = ?", hashID(id)).
+		Update("expires_at", db.NowFunc().Add(st.opts.TTL)).Error
+}
+
+// Expire deletes the session with the given id.
+func (st *Store) Expire(ctx context.Context, id string) error {
+	return st.db.WithContext(ctx).Delete(&Record{IDHash: hashID(id)}).Error
+}
+
+// Cleanup deletes expired sessions and returns how many were removed.
+func (st *Store) Cleanup(ctx context.Context) (int64, error) {
+	db := st.db.WithContext(ctx)
+	result := db.Where("expires_at <= ?", db.NowFunc()).Delete(&Record{})
+	return result.RowsAffected, result.Error
+}
+
+// ScheduleCleanup registers Cleanup as a "gormkit-session-cleanup" job on
+// sched, e.g. with spec "@hourly".
+func (st *Store) ScheduleCleanup(sched *gormkit.Scheduler, spec string) error {
+	return sched.Register("gormkit-session-cleanup", spec, func(ctx context.Context) error {
+		_, err := st.Cleanup(ctx)
+		return err
+	})
+}
+
+type contextKey struct{}
+
+// FromContext returns the session attached by Middleware, or nil.
+func FromContext(ctx context.Context) *Session {
+	sess, _ := ctx.Value(contextKey{}).(*Session)
+	return sess
+}
+
+// Middleware attaches the request's session, or a new empty one, to the
+// request context. Changed sessions are saved and their cookie set before
+// the response headers are written; sessions past half their TTL are
+// touched so active users stay signed in.
+func (st *Store) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		var sess *Session
+		if cookie, err := r.Cookie(st.opts.CookieName); err == nil {
+			if sess, err = st.Get(ctx, cookie.Value); err != nil && !errors.Is(err, ErrNotFound) {
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+		}
+		if sess == nil {
+			sess = st.Create()
+		} else if time.Until(sess.expiresAt) < st.opts.TTL/2 {
+			sess.dirty = true
+		}
+
+		sw := &sessionWriter{ResponseWriter: w, store: st, sess: sess, ctx: ctx}
+		next.ServeHTTP(sw, r.WithContext(context.WithValue(ctx, contextKey{}, sess)))
+		sw.commit()
+	})
+}
+
+type sessionWriter struct {
+	http.ResponseWriter
+	store     *Store
+	sess      *Session
+	ctx       context.Context
+	committed bool
+}
+
+func (w *sessionWriter) WriteHeader(code int) {
+	w.commit()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *sessionWriter) Write(b []byte) (int, error) {
+	w.commit()
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *sessionWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// commit saves or deletes the session and sets its cookie, once, before the
+// headers go out.
+func (w *sessionWriter) commit() {
+	if w.committed {
+		return
+	}
+	w.committed = true
+
+	w.sess.mu.Lock()
+	id, isNew, dirty, destroyed, renewed := w.sess.id, w.sess.isNew, w.sess.dirty, w.sess.destroyed, w.sess.renewedID
+	w.sess.mu.Unlock()
+
+	opts := w.store.opts
+	cookie := &http.Cookie{
+		Name:     opts.CookieName,
+		Path:     opts.CookiePath,
+		Domain:   opts.Domain,
+		Secure:   !opts.Insecure,
+		HttpOnly: true,
+		SameSite: opts.SameSite,
+	}
+
+	switch {
+	case destroyed:
+		for _, old := range []string{id, renewed} {
+			if old != "" {
+				w.store.Expire(w.ctx, old)
+			}
+		}
+		if !isNew || renewed != "" {
+			cookie.MaxAge = -1
+			http.SetCookie(w.ResponseWriter, cookie)
+		}
+	case dirty:
+		if err := w.store.Save(w.ctx, w.sess); err != nil {
+			log.Printf("gormkitsession: failed to save session: %v", err)
+			return
+		}
+		cookie.Value = id
+		cookie.MaxAge = int(opts.TTL / time.Second)
+		http.SetCookie(w.ResponseWriter, cookie)
+	}
+}
+
+func newID() string {
+	b := make([]byte, 32)
+	rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func hashID(id string) string {
+	sum := sha256.Sum256([]byte(id))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,142 @@
+package gormkitsession_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alinemone/gorm-kit"
+	"github.com/alinemone/gorm-kit/gormkitsession"
+)
+
+func newStore(t *testing.T, ttl time.Duration) *gormkitsession.Store {
+	manager := gormkit.NewTestManager(t)
+	store, err := gormkitsession.New(manager, gormkitsession.Options{TTL: ttl})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestMiddleware(t *testing.T) {
+	store := newStore(t, time.Hour)
+	handler := store.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sess := gormkitsession.FromContext(r.Context())
+		switch r.URL.Path {
+		case "/login":
+			sess.Renew()
+			sess.Set("user_id", 42)
+		case "/logout":
+			sess.Destroy()
+		}
+		var userID int
+		if sess.Get("user_id", &userID) {
+			w.Write([]byte("user 42"))
+		}
+	}))
+
+	serve := func(path string, cookies ...*http.Cookie) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for _, c := range cookies {
+			req.AddCookie(c)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := serve("/"); len(rec.Result().Cookies()) != 0 {
+		t.Error("Expected no cookie for an unchanged session")
+	}
+
+	login := serve("/login")
+	cookies := login.Result().Cookies()
+	if len(cookies) != 1 || !cookies[0].HttpOnly || !cookies[0].Secure {
+		t.Fatalf("Expected a secure session cookie, got %+v", cookies)
+	}
+	session := cookies[0]
+
+	if body := serve("/", session).Body.String(); body != "user 42" {
+		t.Errorf("Expected the session to be loaded, got %q", body)
+	}
+
+	logout := serve("/logout", session)
+	if c := logout.Result().Cookies(); len(c) != 1 || c[0].MaxAge >= 0 {
+		t.Errorf("Expected the cookie to be cleared, got %+v", c)
+	}
+	if body := serve("/", session).Body.String(); body != "" {
+		t.Errorf("Expected the session to be gone, got %q", body)
+	}
+}
+
+func TestStoreRenewAndCleanup(t *testing.T) {
+	store := newStore(t, time.Hour)
+	ctx := context.Background()
+
+	sess := store.Create()
+	sess.Set("cart", []string{"book"})
+	if err := store.Save(ctx, sess); err != nil {
+		t.Fatal(err)
+	}
+	old := sess.ID()
+	sess.Renew()
+	if err := store.Save(ctx, sess); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Get(ctx, old); !errors.Is(err, gormkitsession.ErrNotFound) {
+		t.Errorf("Expected the old id to be invalid, got %v", err)
+	}
+	loaded, err := store.Get(ctx, sess.ID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cart []string
+	if !loaded.Get("cart", &cart) || strings.Join(cart, ",") != "book" {
+		t.Errorf("Expected the cart to survive renewal, got %v", cart)
+	}
+
+	expired := newStore(t, time.Millisecond)
+	if err := expired.Save(ctx, expired.Create()); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if n, err := expired.Cleanup(ctx); err != nil || n != 1 {
+		t.Errorf("Expected 1 expired session removed, got %d, %v", n, err)
+	}
+}
+
+func TestStoreKeepsCreatedAt(t *testing.T) {
+	manager := gormkit.NewTestManager(t)
+	store, err := gormkitsession.New(manager, gormkitsession.Options{TTL: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	sess := store.Create()
+	if err := store.Save(ctx, sess); err != nil {
+		t.Fatal(err)
+	}
+	var created gormkitsession.Record
+	if err := manager.DB().Take(&created).Error; err != nil {
+		t.Fatal(err)
+	}
+	sess.Set("user", 42)
+	if err := store.Save(ctx, sess); err != nil {
+		t.Fatal(err)
+	}
+	var saved gormkitsession.Record
+	if err := manager.DB().Take(&saved).Error; err != nil {
+		t.Fatal(err)
+	}
+	if created.CreatedAt.IsZero() || !saved.CreatedAt.Equal(created.CreatedAt) {
+		t.Errorf("Expected created_at to stay %v, got %v", created.CreatedAt, saved.CreatedAt)
+	}
+	if !strings.Contains(saved.Data, "42") {
+		t.Errorf("Expected the data to be updated, got %q", saved.Data)
+	}
+}
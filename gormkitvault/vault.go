@@ -0,0 +1,201 @@
+// Package gormkitvault gets database credentials from HashiCorp Vault's
+// database secrets engine, renewing their lease and rotating to new ones
+// before the lease reaches its max TTL.
+//
+//	creds := gormkitvault.New(gormkitvault.Options{Role: "app"})
+//	manager, err := gormkit.New(&gormkit.Config{Driver: "postgres", Host: "db", CredentialProvider: creds})
+//	go creds.Run(ctx, manager)
+package gormkitvault
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+// Options configures a Provider.
+type Options struct {
+	Address    string // default $VAULT_ADDR
+	Token      string // default $VAULT_TOKEN
+	Mount      string // secrets engine mount, default "database"
+	Role       string
+	HTTPClient *http.Client
+}
+
+// Provider is a gormkit.CredentialProvider backed by a Vault database role.
+type Provider struct {
+	opts Options
+
+	mu        sync.Mutex
+	creds     gormkit.Credentials
+	leaseID   string
+	renewable bool
+}
+
+// New returns a Provider for opts.Role. Credentials are fetched on first use.
+func New(opts Options) *Provider {
+	if opts.Address == "" {
+		opts.Address = os.Getenv("VAULT_ADDR")
+	}
+	if opts.Token == "" {
+		opts.Token = os.Getenv("VAULT_TOKEN")
+	}
+	if opts.Mount == "" {
+		opts.Mount = "database"
+	}
+	if opts.HTTPClient == nil {
+		opts.HTTPClient = http.DefaultClient
+	}
+	opts.Address = strings.TrimRight(opts.Address, "/")
+	return &Provider{opts: opts}
+}
+
+// Credentials returns the current credentials, fetching new ones when there
+// are none or they have expired.
+func (p *Provider) Credentials(ctx context.Context) (gormkit.Credentials, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.creds.User != "" && time.Now().Before(p.creds.ExpiresAt) {
+		return p.creds, nil
+	}
+	if err := p.fetch(ctx); err != nil {
+		return gormkit.Credentials{}, err
+	}
+	return p.creds, nil
+}
+
+// Between attempts Run waits at least minWait, doubling it after each
+// failure up to maxBackoff, so an expired lease or an unreachable Vault
+// doesn't turn into a busy loop.
+const (
+	minWait    = time.Second
+	maxBackoff = time.Minute
+)
+
+// Run keeps the credentials of m valid until ctx is done: it renews the
+// lease when two thirds of it have passed, and when Vault no longer extends
+// it (max TTL) it fetches new credentials and calls m.Reconnect.
+func (p *Provider) Run(ctx context.Context, m *gormkit.Manager) error {
+	if _, err := p.Credentials(ctx); err != nil {
+		return err
+	}
+	backoff := minWait
+	for {
+		p.mu.Lock()
+		wait := max(time.Until(p.creds.ExpiresAt)*2/3, backoff)
+		p.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+		}
+
+		renewed, err := p.renew(ctx)
+		if err != nil {
+			log.Printf("gormkitvault: lease renewal failed: %v", err)
+		}
+		if renewed {
+			backoff = minWait
+			continue
+		}
+		if err := p.rotate(ctx); err != nil {
+			log.Printf("gormkitvault: failed to rotate credentials: %v", err)
+			backoff = min(backoff*2, maxBackoff)
+			continue
+		}
+		if err := m.Reconnect(ctx); err != nil {
+			log.Printf("gormkitvault: reconnect failed: %v", err)
+			backoff = min(backoff*2, maxBackoff)
+			continue
+		}
+		backoff = minWait
+	}
+}
+
+func (p *Provider) rotate(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.fetch(ctx)
+}
+
+type secret struct {
+	LeaseID       string `json:"lease_id"`
+	LeaseDuration int    `json:"lease_duration"`
+	Renewable     bool   `json:"renewable"`
+	Data          struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	} `json:"data"`
+}
+
+// fetch reads new credentials; p.mu must be held.
+func (p *Provider) fetch(ctx context.Context) error {
+	var s secret
+	if err := p.call(ctx, http.MethodGet, "/v1/"+p.opts.Mount+"/creds/"+p.opts.Role, nil, &s); err != nil {
+		return err
+	}
+	p.creds = gormkit.Credentials{
+		User:      s.Data.Username,
+		Password:  s.Data.Password,
+		ExpiresAt: time.Now().Add(time.Duration(s.LeaseDuration) * time.Second),
+	}
+	p.leaseID, p.renewable = s.LeaseID, s.Renewable
+	return nil
+}
+
+// renew extends the lease and reports whether it now lasts at least as long
+// as before, i.e. whether max TTL is still ahead.
+func (p *Provider) renew(ctx context.Context) (bool, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.renewable {
+		return false, nil
+	}
+	previous := p.creds.ExpiresAt.Sub(time.Now())
+
+	var s secret
+	body := map[string]interface{}{"lease_id": p.leaseID}
+	if err := p.call(ctx, http.MethodPut, "/v1/sys/leases/renew", body, &s); err != nil {
+		return false, err
+	}
+	lease := time.Duration(s.LeaseDuration) * time.Second
+	p.creds.ExpiresAt = time.Now().Add(lease)
+	return lease > previous, nil
+}
+
+func (p *Provider) call(ctx context.Context, method, path string, body, out interface{}) error {
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			return err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, p.opts.Address+path, &payload)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", p.opts.Token)
+	resp, err := p.opts.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Errors []string `json:"errors"`
+		}
+		json.NewDecoder(resp.Body).Decode(&e)
+		return fmt.Errorf("vault %s %s: %s: %s", method, path, resp.Status, strings.Join(e.Errors, "; "))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,90 @@
+package gormkitvault_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/alinemone/gorm-kit"
+	"github.com/alinemone/gorm-kit/gormkitvault"
+)
+
+type fakeVault struct {
+	mu      sync.Mutex
+	issued  int
+	renewed int
+}
+
+func (v *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if r.Header.Get("X-Vault-Token") != "root" {
+		w.WriteHeader(http.StatusForbidden)
+		json.NewEncoder(w).Encode(map[string]interface{}{"errors": []string{"permission denied"}})
+		return
+	}
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/v1/database/creds/app":
+		v.issued++
+		fmt.Fprintf(w, `{"lease_id":"database/creds/app/%d","lease_duration":1,"renewable":true,
+			"data":{"username":"v-app-%d","password":"secret"}}`, v.issued, v.issued)
+	case r.Method == http.MethodPut && r.URL.Path == "/v1/sys/leases/renew":
+		// The lease has reached its max TTL and cannot be extended.
+		v.renewed++
+		fmt.Fprint(w, `{"lease_id":"x","lease_duration":0,"renewable":true}`)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestProviderRotatesAtMaxTTL(t *testing.T) {
+	vault := &fakeVault{}
+	server := httptest.NewServer(vault)
+	defer server.Close()
+
+	provider := gormkitvault.New(gormkitvault.Options{Address: server.URL, Token: "root", Role: "app"})
+	ctx := context.Background()
+
+	creds, err := provider.Credentials(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds.User != "v-app-1" || creds.Password != "secret" {
+		t.Errorf("Unexpected credentials: %+v", creds)
+	}
+	if again, _ := provider.Credentials(ctx); again.User != "v-app-1" {
+		t.Errorf("Expected cached credentials, got %+v", again)
+	}
+
+	manager := gormkit.NewTestManager(t)
+	runCtx, cancel := context.WithTimeout(ctx, 1500*time.Millisecond)
+	defer cancel()
+	provider.Run(runCtx, manager)
+
+	creds, _ = provider.Credentials(ctx)
+	if creds.User != "v-app-2" {
+		t.Errorf("Expected rotated credentials, got %+v", creds)
+	}
+	vault.mu.Lock()
+	defer vault.mu.Unlock()
+	if vault.renewed != 1 {
+		t.Errorf("Expected 1 renewal attempt, got %d", vault.renewed)
+	}
+}
+
+func TestProviderError(t *testing.T) {
+	server := httptest.NewServer(&fakeVault{})
+	defer server.Close()
+
+	provider := gormkitvault.New(gormkitvault.Options{Address: server.URL, Token: "wrong", Role: "app"})
+	_, err := provider.Credentials(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("Expected permission denied, got %v", err)
+	}
+}
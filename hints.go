@@ -0,0 +1,121 @@
+package gormkit
+
+import (
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IndexHint pins the index a query reads its table with, for critical
+// queries whose plan the optimizer gets wrong: USE INDEX on MySQL, INDEXED
+// BY on SQLite, and an IndexScan hint on Postgres, which needs the
+// pg_hint_plan extension and is an ignored comment without it. Several
+// IndexHints on MySQL and Postgres allow any of the indexes. On MySQL and
+// SQLite the hint follows the FROM clause, so don't combine it with joins.
+//
+//	db.Scopes(gormkit.IndexHint("idx_users_email")).Where("email = ?", email).First(&user)
+func IndexHint(index string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Clauses(queryHint{index: index})
+	}
+}
+
+// PlanHint adds optimizer hints to a query: pg_hint_plan hints on Postgres
+// (e.g. "HashJoin(orders users)", "Leading(orders users)"), optimizer hints
+// on MySQL (e.g. "JOIN_ORDER(orders, users)", "MAX_EXECUTION_TIME(1000)").
+// SQLite has none, so it is a no-op there.
+//
+//	db.Scopes(gormkit.PlanHint("Leading(o u)", "HashJoin(o u)")).Table("orders o").Joins("JOIN users u ON u.id = o.user_id").Find(&rows)
+func PlanHint(hints ...string) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Clauses(queryHint{plan: hints})
+	}
+}
+
+// queryHint adds its hint to the statement's SELECT or FROM clause,
+// merging with hints already there.
+type queryHint struct {
+	index string
+	plan  []string
+}
+
+// Build is never called: Clauses applies statement modifiers instead.
+func (queryHint) Build(clause.Builder) {}
+
+func (h queryHint) ModifyStatement(stmt *gorm.Statement) {
+	flavor := flavorOf(stmt.DB)
+	if h.index != "" && flavor != "postgres" {
+		c := stmt.Clauses["FROM"]
+		from, _ := c.AfterExpression.(fromHint)
+		c.AfterExpression = fromHint{indexes: appendCopy(from.indexes, h.index)}
+		stmt.Clauses["FROM"] = c
+		return
+	}
+	if flavor == "sqlite" {
+		return
+	}
+
+	// Hints are read from one comment: at the head of the statement for
+	// pg_hint_plan, right after SELECT for MySQL.
+	c := stmt.Clauses["SELECT"]
+	expr := &c.AfterNameExpression
+	if flavor == "postgres" {
+		expr = &c.BeforeExpression
+	}
+	comment, _ := (*expr).(hintComment)
+	if h.index != "" {
+		comment.indexes = appendCopy(comment.indexes, h.index)
+	}
+	comment.plan = appendCopy(comment.plan, h.plan...)
+	*expr = comment
+	stmt.Clauses["SELECT"] = c
+}
+
+// fromHint is MySQL's USE INDEX or SQLite's INDEXED BY.
+type fromHint struct {
+	indexes []string
+}
+
+func (h fromHint) Build(builder clause.Builder) {
+	stmt, ok := builder.(*gorm.Statement)
+	if !ok || len(h.indexes) == 0 {
+		return
+	}
+	if flavorOf(stmt.DB) == "sqlite" {
+		// SQLite takes a single index; the last hint wins.
+		builder.WriteString("INDEXED BY ")
+		builder.WriteQuoted(h.indexes[len(h.indexes)-1])
+		return
+	}
+	builder.WriteString("USE INDEX (")
+	for i, index := range h.indexes {
+		if i > 0 {
+			builder.WriteByte(',')
+		}
+		builder.WriteQuoted(index)
+	}
+	builder.WriteByte(')')
+}
+
+// hintComment is a /*+ ... */ optimizer hint comment. Index hints become
+// pg_hint_plan IndexScan hints on the statement's table, known only once
+// the statement is built.
+type hintComment struct {
+	plan    []string
+	indexes []string
+}
+
+func (h hintComment) Build(builder clause.Builder) {
+	hints := append([]string(nil), h.plan...)
+	if stmt, ok := builder.(*gorm.Statement); ok && len(h.indexes) > 0 {
+		hints = append(hints, "IndexScan("+stmt.Table+" "+strings.Join(h.indexes, " ")+")")
+	}
+	builder.WriteString("/*+ " + strings.Join(hints, " ") + " */")
+}
+
+// appendCopy appends to a copy of s, since clauses are shared between
+// statements cloned from the same chain.
+func appendCopy(s []string, values ...string) []string {
+	return append(append([]string(nil), s...), values...)
+}
@@ -0,0 +1,74 @@
+package gormkit_test
+
+import (
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestQueryHints(t *testing.T) {
+	dryRun := func() *gorm.Config {
+		return &gorm.Config{DryRun: true, DisableAutomaticPing: true, SkipDefaultTransaction: true}
+	}
+	pg, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), dryRun())
+	if err != nil {
+		t.Fatal(err)
+	}
+	my, err := gorm.Open(mysql.New(mysql.Config{DSN: "app@tcp(localhost)/app", SkipInitializeWithVersion: true}), dryRun())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		db     *gorm.DB
+		scopes []func(*gorm.DB) *gorm.DB
+		want   string
+	}{
+		{pg, []func(*gorm.DB) *gorm.DB{gormkit.IndexHint("idx_users_name")},
+			`/*+ IndexScan(users idx_users_name) */ SELECT * FROM "users" WHERE name = 'alice'`},
+		{pg, []func(*gorm.DB) *gorm.DB{gormkit.PlanHint("SeqScan(users)"), gormkit.IndexHint("a"), gormkit.IndexHint("b")},
+			`/*+ SeqScan(users) IndexScan(users a b) */ SELECT * FROM "users" WHERE name = 'alice'`},
+		{my, []func(*gorm.DB) *gorm.DB{gormkit.IndexHint("a"), gormkit.IndexHint("b")},
+			"SELECT * FROM `users` USE INDEX (`a`,`b`) WHERE name = 'alice'"},
+		{my, []func(*gorm.DB) *gorm.DB{gormkit.PlanHint("MAX_EXECUTION_TIME(1000)")},
+			"SELECT /*+ MAX_EXECUTION_TIME(1000) */ * FROM `users` WHERE name = 'alice'"},
+	}
+	for _, tt := range tests {
+		sql := tt.db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+			return tx.Scopes(tt.scopes...).Where("name = ?", "alice").Find(&[]User{})
+		})
+		if sql != tt.want {
+			t.Errorf("Expected %q, got %q", tt.want, sql)
+		}
+	}
+
+	plain := pg.ToSQL(func(tx *gorm.DB) *gorm.DB { return tx.Find(&[]User{}) })
+	if plain != `SELECT * FROM "users"` {
+		t.Errorf("Expected hints not to leak into other queries, got %q", plain)
+	}
+}
+
+func TestIndexHintOnSQLite(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &User{})
+	db := manager.DB()
+	db.Exec("CREATE INDEX idx_users_name ON users (name)")
+	db.Create(&User{Name: "alice"})
+
+	var users []User
+	if err := db.Scopes(gormkit.IndexHint("idx_users_name")).Where("name = ?", "alice").Find(&users).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 1 {
+		t.Errorf("Expected 1 user, got %d", len(users))
+	}
+	// INDEXED BY fails rather than falling back when the index is missing.
+	if err := db.Scopes(gormkit.IndexHint("idx_missing")).Find(&users).Error; err == nil {
+		t.Error("Expected an error for a missing index")
+	}
+	if err := db.Scopes(gormkit.PlanHint("anything")).Find(&users).Error; err != nil {
+		t.Errorf("Expected PlanHint to be ignored on SQLite, got %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package gormkit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// IdempotencyKey is a key recorded by Idempotent, with the stored result once
+// its call succeeded.
+type IdempotencyKey struct {
+	Key       string `gorm:"primaryKey;size:191"`
+	Done      bool
+	Result    string    // JSON of the result
+	ExpiresAt time.Time `gorm:"index"`
+	CreatedAt time.Time
+}
+
+func (IdempotencyKey) TableName() string {
+	return "gormkit_idempotency_keys"
+}
+
+// Idempotent runs fn at most once per key within ttl and returns its stored
+// result to later calls with the same key:
+//
+//	receipt, err := gormkit.Idempotent(ctx, db, "charge:"+req.IdempotencyKey, 24*time.Hour,
+//		func(ctx context.Context) (Receipt, error) { return charge(ctx, req) })
+//
+// A call made while another with the same key is still running fails with
+// ErrIdempotencyInProgress. Errors are not stored: when fn fails the key is
+// released so the call can be retried. If the process dies while fn runs,
+// the key stays in progress until ttl passes.
+func Idempotent[T any](ctx context.Context, db *gorm.DB, key string, ttl time.Duration, fn func(ctx context.Context) (T, error)) (T, error) {
+	var zero T
+	db = db.WithContext(ctx)
+	if err := ensureTable(db, &IdempotencyKey{}); err != nil {
+		return zero, err
+	}
+
+	// "key" is reserved in MySQL, so conditions are built as quoted clauses.
+	byKey := clause.Eq{Column: clause.Column{Name: "key"}, Value: key}
+	now := db.NowFunc()
+	expired := clause.Lte{Column: clause.Column{Name: "expires_at"}, Value: now}
+	if err := db.Where(byKey).Where(expired).Delete(&IdempotencyKey{}).Error; err != nil {
+		return zero, err
+	}
+
+	claim := db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&IdempotencyKey{Key: key, ExpiresAt: now.Add(ttl)})
+	if claim.Error != nil {
+		return zero, claim.Error
+	}
+	if claim.RowsAffected == 0 {
+		var existing IdempotencyKey
+		if err := db.Where(byKey).First(&existing).Error; err != nil {
+			return zero, err
+		}
+		if !existing.Done {
+			return zero, fmt.Errorf("%w: %s", ErrIdempotencyInProgress, key)
+		}
+		var out T
+		if err := json.Unmarshal([]byte(existing.Result), &out); err != nil {
+			return zero, fmt.Errorf("invalid stored result for %s: %w", key, err)
+		}
+		return out, nil
+	}
+
+	out, err := fn(ctx)
+	if err != nil {
+		if release := db.Delete(&IdempotencyKey{Key: key}).Error; release != nil {
+			return zero, fmt.Errorf("%w (and releasing key %s: %v)", err, key, release)
+		}
+		return zero, err
+	}
+	result, err := json.Marshal(out)
+	if err != nil {
+		return zero, err
+	}
+	if err := db.Model(&IdempotencyKey{Key: key}).
+		Updates(map[string]interface{}{"done": true, "result": string(result)}).Error; err != nil {
+		return zero, fmt.Errorf("failed to store result for %s: %w", key, err)
+	}
+	return out, nil
+}
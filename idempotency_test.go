@@ -0,0 +1,91 @@
+package gormkit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+type receipt struct {
+	ID     string
+	Amount int
+}
+
+func TestIdempotentReturnsStoredResult(t *testing.T) {
+	manager := gormkit.NewTestManager(t)
+	ctx := context.Background()
+
+	calls := 0
+	charge := func(ctx context.Context) (receipt, error) {
+		calls++
+		return receipt{ID: "r1", Amount: 100}, nil
+	}
+	for i := 0; i < 3; i++ {
+		got, err := gormkit.Idempotent(ctx, manager.DB(), "charge:1", time.Hour, charge)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != (receipt{ID: "r1", Amount: 100}) {
+			t.Errorf("Unexpected result: %+v", got)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 call, got %d", calls)
+	}
+}
+
+func TestIdempotentReleasesKeyOnError(t *testing.T) {
+	manager := gormkit.NewTestManager(t)
+	ctx := context.Background()
+
+	declined := errors.New("declined")
+	_, err := gormkit.Idempotent(ctx, manager.DB(), "charge:2", time.Hour, func(ctx context.Context) (int, error) {
+		return 0, declined
+	})
+	if !errors.Is(err, declined) {
+		t.Fatalf("Expected declined, got %v", err)
+	}
+
+	got, err := gormkit.Idempotent(ctx, manager.DB(), "charge:2", time.Hour, func(ctx context.Context) (int, error) {
+		return 42, nil
+	})
+	if err != nil || got != 42 {
+		t.Errorf("Expected retry to run, got %d, %v", got, err)
+	}
+}
+
+func TestIdempotentInProgress(t *testing.T) {
+	manager := gormkit.NewTestManager(t)
+	ctx := context.Background()
+
+	_, err := gormkit.Idempotent(ctx, manager.DB(), "charge:3", time.Hour, func(ctx context.Context) (int, error) {
+		return gormkit.Idempotent(ctx, manager.DB(), "charge:3", time.Hour, func(ctx context.Context) (int, error) {
+			t.Error("Nested call with the same key must not run")
+			return 0, nil
+		})
+	})
+	if !errors.Is(err, gormkit.ErrIdempotencyInProgress) {
+		t.Errorf("Expected ErrIdempotencyInProgress, got %v", err)
+	}
+}
+
+func TestIdempotentExpiredKeyRunsAgain(t *testing.T) {
+	manager := gormkit.NewTestManager(t)
+	ctx := context.Background()
+
+	calls := 0
+	fn := func(ctx context.Context) (int, error) {
+		calls++
+		return calls, nil
+	}
+	if _, err := gormkit.Idempotent(ctx, manager.DB(), "charge:4", -time.Second, fn); err != nil {
+		t.Fatal(err)
+	}
+	got, err := gormkit.Idempotent(ctx, manager.DB(), "charge:4", time.Hour, fn)
+	if err != nil || got != 2 {
+		t.Errorf("Expected expired key to run again, got %d, %v", got, err)
+	}
+}
@@ -0,0 +1,71 @@
+package gormkit
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// InboxMessage records a message processed by an Inbox consumer.
+type InboxMessage struct {
+	Consumer    string    `gorm:"primaryKey;size:100"`
+	MessageID   string    `gorm:"primaryKey;size:191"`
+	ProcessedAt time.Time `gorm:"index"`
+}
+
+func (InboxMessage) TableName() string {
+	return "gormkit_inbox"
+}
+
+// Inbox deduplicates messages from an at-least-once broker (Kafka, NATS)
+// for one consumer, recording processed message ids in gormkit_inbox.
+type Inbox struct {
+	manager  *Manager
+	consumer string
+}
+
+// Inbox returns the inbox of consumer; each consumer of the same messages
+// needs its own name.
+func (m *Manager) Inbox(consumer string) *Inbox {
+	return &Inbox{manager: m, consumer: consumer}
+}
+
+// Process runs fn in a transaction that also records messageID, so the
+// message's effects and its processed mark commit together: a message is
+// processed exactly once even if delivered again, or concurrently to another
+// instance. Already processed messages return nil without calling fn, so the
+// caller can acknowledge them.
+//
+//	err := inbox.Process(ctx, msg.ID, func(tx *gorm.DB) error {
+//		return tx.Create(&Payment{OrderID: msg.OrderID}).Error
+//	})
+func (in *Inbox) Process(ctx context.Context, messageID string, fn func(tx *gorm.DB) error) error {
+	if err := ensureTable(in.manager.db.WithContext(ctx), &InboxMessage{}); err != nil {
+		return err
+	}
+	return in.manager.Transaction(ctx, func(tx *gorm.DB) error {
+		record := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&InboxMessage{
+			Consumer:    in.consumer,
+			MessageID:   messageID,
+			ProcessedAt: tx.NowFunc(),
+		})
+		if record.Error != nil || record.RowsAffected == 0 {
+			return record.Error
+		}
+		return fn(tx)
+	})
+}
+
+// Purge forgets messages processed before olderThan ago and returns how many
+// were removed. Keep them at least as long as the broker may redeliver.
+func (in *Inbox) Purge(ctx context.Context, olderThan time.Duration) (int64, error) {
+	db := in.manager.db.WithContext(ctx)
+	if err := ensureTable(db, &InboxMessage{}); err != nil {
+		return 0, err
+	}
+	result := db.Where("consumer = ? AND processed_at < ?", in.consumer, db.NowFunc().Add(-olderThan)).
+		Delete(&InboxMessage{})
+	return result.RowsAffected, result.Error
+}
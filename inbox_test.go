@@ -0,0 +1,79 @@
+package gormkit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+	"gorm.io/gorm"
+)
+
+func TestInboxProcessOnce(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &User{})
+	ctx := context.Background()
+	inbox := manager.Inbox("signup-consumer")
+
+	calls := 0
+	handle := func(tx *gorm.DB) error {
+		calls++
+		return tx.Create(&User{Name: "Ali"}).Error
+	}
+	for i := 0; i < 3; i++ {
+		if err := inbox.Process(ctx, "msg-1", handle); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 call, got %d", calls)
+	}
+
+	// Another consumer processes the same message independently.
+	if err := manager.Inbox("audit-consumer").Process(ctx, "msg-1", func(tx *gorm.DB) error {
+		calls++
+		return nil
+	}); err != nil || calls != 2 {
+		t.Errorf("Expected the other consumer to run, got %d calls, %v", calls, err)
+	}
+
+	var count int64
+	manager.DB().Model(&User{}).Count(&count)
+	if count != 1 {
+		t.Errorf("Expected 1 user, got %d", count)
+	}
+
+	if n, err := inbox.Purge(ctx, -1); err != nil || n != 1 {
+		t.Errorf("Expected 1 message purged, got %d, %v", n, err)
+	}
+}
+
+func TestInboxFailureIsRetried(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &User{})
+	ctx := context.Background()
+	inbox := manager.Inbox("signup-consumer")
+
+	failed := errors.New("downstream unavailable")
+	err := inbox.Process(ctx, "msg-2", func(tx *gorm.DB) error {
+		if err := tx.Create(&User{Name: "Ali"}).Error; err != nil {
+			return err
+		}
+		return failed
+	})
+	if !errors.Is(err, failed) {
+		t.Fatalf("Expected the handler error, got %v", err)
+	}
+
+	calls := 0
+	if err := inbox.Process(ctx, "msg-2", func(tx *gorm.DB) error {
+		calls++
+		return tx.Create(&User{Name: "Ali"}).Error
+	}); err != nil || calls != 1 {
+		t.Fatalf("Expected the redelivery to run, got %d calls, %v", calls, err)
+	}
+
+	var count int64
+	manager.DB().Model(&User{}).Count(&count)
+	if count != 1 {
+		t.Errorf("Expected the failed attempt to roll back, got %d users", count)
+	}
+}
@@ -0,0 +1,72 @@
+package gormkit
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Key identifies a row of a model with a composite primary key, by field or
+// column name: Key{"TenantID": 1, "ID": 42}.
+type Key map[string]interface{}
+
+// FindByID loads the T with the given primary key: a single value, one value
+// per primary key column in declaration order, or a Key. It returns
+// gorm.ErrRecordNotFound when there is no such row.
+func FindByID[T any](ctx context.Context, db *gorm.DB, id ...interface{}) (T, error) {
+	var out T
+	cond, err := keyCondition[T](db, id)
+	if err != nil {
+		return out, err
+	}
+	err = db.WithContext(ctx).Where(cond).Take(&out).Error
+	return out, err
+}
+
+// DeleteByID deletes the T with the given primary key, accepted as for
+// FindByID, or fails with ErrNoRowsAffected.
+func DeleteByID[T any](ctx context.Context, db *gorm.DB, id ...interface{}) error {
+	cond, err := keyCondition[T](db, id)
+	if err != nil {
+		return err
+	}
+	return DeleteOne[T](ctx, db, cond)
+}
+
+func keyCondition[T any](db *gorm.DB, id []interface{}) (clause.Expression, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return nil, err
+	}
+	primary := stmt.Schema.PrimaryFields
+	if len(primary) == 0 {
+		return nil, fmt.Errorf("%s has no primary key", stmt.Schema.Name)
+	}
+
+	eq := make([]clause.Expression, 0, len(primary))
+	if len(id) == 1 {
+		if key, ok := id[0].(Key); ok {
+			for name, value := range key {
+				field := stmt.Schema.LookUpField(name)
+				if field == nil || !field.PrimaryKey {
+					return nil, fmt.Errorf("%s is not a primary key of %s", name, stmt.Schema.Name)
+				}
+				eq = append(eq, clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: value})
+			}
+			if len(key) != len(primary) {
+				return nil, fmt.Errorf("%s has %d primary key columns, got %d", stmt.Schema.Name, len(primary), len(key))
+			}
+			return clause.And(eq...), nil
+		}
+	}
+
+	if len(id) != len(primary) {
+		return nil, fmt.Errorf("%s has %d primary key columns, got %d", stmt.Schema.Name, len(primary), len(id))
+	}
+	for i, field := range primary {
+		eq = append(eq, clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: field.DBName}, Value: id[i]})
+	}
+	return clause.And(eq...), nil
+}
@@ -0,0 +1,70 @@
+package gormkit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+	"gorm.io/gorm"
+)
+
+type Membership struct {
+	TenantID uint `gorm:"primaryKey"`
+	UserID   uint `gorm:"primaryKey"`
+	Role     string
+}
+
+func TestFindByID(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &User{}, &Membership{})
+	db := manager.DB()
+	ctx := context.Background()
+
+	user := User{Name: "Ali"}
+	db.Create(&user)
+	db.Create(&[]Membership{{TenantID: 1, UserID: 7, Role: "admin"}, {TenantID: 2, UserID: 7, Role: "viewer"}})
+
+	found, err := gormkit.FindByID[User](ctx, db, user.ID)
+	if err != nil || found.Name != "Ali" {
+		t.Errorf("Expected Ali, got %+v (%v)", found, err)
+	}
+
+	m, err := gormkit.FindByID[Membership](ctx, db, 2, 7)
+	if err != nil || m.Role != "viewer" {
+		t.Errorf("Expected viewer membership, got %+v (%v)", m, err)
+	}
+	m, err = gormkit.FindByID[Membership](ctx, db, gormkit.Key{"TenantID": 1, "user_id": 7})
+	if err != nil || m.Role != "admin" {
+		t.Errorf("Expected admin membership, got %+v (%v)", m, err)
+	}
+
+	if _, err := gormkit.FindByID[Membership](ctx, db, 1); err == nil {
+		t.Error("Expected error for incomplete composite key")
+	}
+	if _, err := gormkit.FindByID[Membership](ctx, db, gormkit.Key{"Role": "admin", "UserID": 7}); err == nil {
+		t.Error("Expected error for non-key field")
+	}
+	if _, err := gormkit.FindByID[Membership](ctx, db, 3, 7); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected ErrRecordNotFound, got %v", err)
+	}
+}
+
+func TestDeleteByID(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &Membership{})
+	db := manager.DB()
+	ctx := context.Background()
+	db.Create(&[]Membership{{TenantID: 1, UserID: 7}, {TenantID: 2, UserID: 7}})
+
+	if err := gormkit.DeleteByID[Membership](ctx, db, 1, 7); err != nil {
+		t.Fatal(err)
+	}
+	if err := gormkit.DeleteByID[Membership](ctx, db, 1, 7); !errors.Is(err, gormkit.ErrNoRowsAffected) {
+		t.Errorf("Expected ErrNoRowsAffected, got %v", err)
+	}
+
+	var count int64
+	db.Model(&Membership{}).Count(&count)
+	if count != 1 {
+		t.Errorf("Expected 1 membership left, got %d", count)
+	}
+}
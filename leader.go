@@ -0,0 +1,180 @@
+package gormkit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Lease is a named lease held by one instance until it expires, kept by
+// LeaderElection.
+type Lease struct {
+	Name      string `gorm:"primaryKey;size:191"`
+	Holder    string `gorm:"size:191"`
+	ExpiresAt time.Time
+}
+
+func (Lease) TableName() string {
+	return "gormkit_leases"
+}
+
+// LeaderElection elects one instance among those running it with the same
+// name, using a lease row renewed by heartbeats. Set the fields before Run.
+type LeaderElection struct {
+	// Identity names this instance in the lease, default hostname-pid-random.
+	Identity string
+	// TTL is how long a lease outlives its last heartbeat, default 15s.
+	// Heartbeats are sent every TTL/3, so Run rejects a TTL too short to
+	// divide.
+	TTL time.Duration
+
+	manager *Manager
+	name    string
+	leader  atomic.Bool
+}
+
+// LeaderElection returns an election for name.
+func (m *Manager) LeaderElection(name string) *LeaderElection {
+	host, _ := os.Hostname()
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return &LeaderElection{
+		Identity: fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix)),
+		TTL:      15 * time.Second,
+		manager:  m,
+		name:     name,
+	}
+}
+
+// IsLeader reports whether this instance currently holds the lease.
+func (e *LeaderElection) IsLeader() bool {
+	return e.leader.Load()
+}
+
+// Leader returns the identity of the current lease holder, or "" when the
+// lease is free or expired.
+func (e *LeaderElection) Leader(ctx context.Context) (string, error) {
+	db := e.manager.db.WithContext(ctx)
+	if err := ensureTable(db, &Lease{}); err != nil {
+		return "", err
+	}
+	var lease Lease
+	err := db.Where("name = ? AND expires_at > ?", e.name, db.NowFunc()).Limit(1).Find(&lease).Error
+	return lease.Holder, err
+}
+
+// Run campaigns for leadership until ctx is done. Each time this instance is
+// elected, onElected runs with a context that is canceled when leadership is
+// lost; Run waits for it to return before campaigning again. When onElected
+// returns on its own, the lease is released for another instance to take.
+func (e *LeaderElection) Run(ctx context.Context, onElected func(ctx context.Context)) error {
+	// Campaigning is gorm-kit's own bookkeeping, bounded per statement below.
+	interval := e.TTL / 3
+	if interval <= 0 {
+		return fmt.Errorf("leader election %s: TTL %v is too short", e.name, e.TTL)
+	}
+	db := e.manager.db.WithContext(withoutDeadlineAudit(ctx))
+	if err := ensureTable(db, &Lease{}); err != nil {
+		return err
+	}
+
+	for attempt := 0; ; {
+		acquired, err := e.acquire(db)
+		if err != nil && ctx.Err() == nil {
+			log.Printf("gormkit: leader election %s: %v", e.name, err)
+		}
+		if acquired {
+			attempt = 0
+			e.lead(ctx, db, interval, onElected)
+		} else if err != nil {
+			attempt++
+		}
+		wait := interval
+		if err != nil {
+			wait = backoff(attempt)
+		}
+		if err := sleepContext(ctx, wait); err != nil {
+			return err
+		}
+	}
+}
+
+func (e *LeaderElection) lead(ctx context.Context, db *gorm.DB, interval time.Duration, onElected func(ctx context.Context)) {
+	e.leader.Store(true)
+	defer e.leader.Store(false)
+	log.Printf("gormkit: %s elected leader of %s", e.Identity, e.name)
+
+	leaderCtx, cancel := context.WithCancel(ctx)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer cancel()
+		onElected(leaderCtx)
+	}()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	renewed := time.Now()
+	for leaderCtx.Err() == nil {
+		select {
+		case <-leaderCtx.Done():
+		case <-ticker.C:
+			held, err := e.acquire(db)
+			if err == nil && held {
+				renewed = time.Now()
+				continue
+			}
+			// A failed heartbeat is retried while the lease is still ours.
+			if err != nil && time.Since(renewed) < e.TTL-interval {
+				log.Printf("gormkit: leader election %s: heartbeat failed: %v", e.name, err)
+				continue
+			}
+			log.Printf("gormkit: %s lost leadership of %s", e.Identity, e.name)
+			cancel()
+		}
+	}
+	wg.Wait()
+
+	// Let another instance take over without waiting for the lease to
+	// expire. The release outlives ctx, but not by more than a heartbeat.
+	releaseCtx, cancelRelease := context.WithTimeout(context.WithoutCancel(ctx), interval)
+	defer cancelRelease()
+	release := db.WithContext(releaseCtx)
+	if err := release.Where("name = ? AND holder = ?", e.name, e.Identity).Delete(&Lease{}).Error; err != nil {
+		log.Printf("gormkit: leader election %s: release failed: %v", e.name, err)
+	}
+}
+
+// acquire takes the lease if it is free or expired, or renews it if this
+// instance holds it, giving up after a heartbeat interval.
+func (e *LeaderElection) acquire(db *gorm.DB) (bool, error) {
+	ctx, cancel := context.WithTimeout(db.Statement.Context, e.TTL/3)
+	defer cancel()
+	db = db.WithContext(ctx)
+	now := db.NowFunc()
+	expires := now.Add(e.TTL)
+
+	insert := db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&Lease{Name: e.name, Holder: e.Identity, ExpiresAt: expires})
+	if insert.Error != nil {
+		return false, insert.Error
+	}
+	if insert.RowsAffected == 1 {
+		return true, nil
+	}
+
+	update := db.Model(&Lease{}).
+		Where("name = ? AND (holder = ? OR expires_at <= ?)", e.name, e.Identity, now).
+		Updates(map[string]interface{}{"holder": e.Identity, "expires_at": expires})
+	return update.RowsAffected == 1, update.Error
+}
@@ -0,0 +1,99 @@
+package gormkit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestLeaderElectionFailover(t *testing.T) {
+	manager := gormkit.NewTestManager(t)
+
+	elected := make(chan string, 2)
+	start := func(identity string) (*gormkit.LeaderElection, context.CancelFunc, chan error) {
+		e := manager.LeaderElection("retention")
+		e.Identity = identity
+		e.TTL = 300 * time.Millisecond
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() {
+			done <- e.Run(ctx, func(ctx context.Context) {
+				elected <- identity
+				<-ctx.Done()
+			})
+		}()
+		return e, cancel, done
+	}
+
+	a, cancelA, doneA := start("a")
+	first := <-elected
+	if first != "a" || !a.IsLeader() {
+		t.Fatalf("Expected a to lead, got %s", first)
+	}
+	b, cancelB, doneB := start("b")
+	defer func() { cancelB(); <-doneB }()
+
+	time.Sleep(400 * time.Millisecond)
+	if b.IsLeader() {
+		t.Fatal("Expected only one leader")
+	}
+	if leader, err := b.Leader(context.Background()); err != nil || leader != "a" {
+		t.Errorf("Expected leader a, got %q, %v", leader, err)
+	}
+
+	cancelA()
+	<-doneA
+	if a.IsLeader() {
+		t.Error("Expected a to step down")
+	}
+	select {
+	case next := <-elected:
+		if next != "b" {
+			t.Errorf("Expected b to take over, got %s", next)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("b was not elected after a stopped")
+	}
+}
+
+func TestLeaderElectionDeadlineAudit(t *testing.T) {
+	manager, err := gormkit.New(&gormkit.Config{Driver: "test", LogLevel: "silent", DeadlineAudit: "error"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	e := manager.LeaderElection("retention")
+	ctx, cancel := context.WithCancel(context.Background())
+	elected := make(chan struct{})
+	done := make(chan error, 1)
+	go func() {
+		done <- e.Run(ctx, func(ctx context.Context) {
+			close(elected)
+			<-ctx.Done()
+		})
+	}()
+	select {
+	case <-elected:
+	case err := <-done:
+		t.Fatalf("Expected to be elected, got %v", err)
+	}
+	cancel()
+	<-done
+
+	leaderCtx, cancelLeader := context.WithTimeout(context.Background(), time.Second)
+	defer cancelLeader()
+	if leader, err := e.Leader(leaderCtx); err != nil || leader != "" {
+		t.Errorf("Expected the lease to be released, got %q, %v", leader, err)
+	}
+}
+
+func TestLeaderElectionRejectsShortTTL(t *testing.T) {
+	e := gormkit.NewTestManager(t).LeaderElection("retention")
+	e.TTL = 0
+	if err := e.Run(context.Background(), func(context.Context) {}); err == nil {
+		t.Error("Expected a zero TTL to be rejected")
+	}
+}
@@ -0,0 +1,114 @@
+package gormkit
+
+import (
+	"regexp"
+	"strings"
+)
+
+// LintWarning flags a migration operation that locks or rewrites a table
+// in a way that risks downtime.
+type LintWarning struct {
+	Operation  int // index in PlannedMigration.Operations
+	Rule       string
+	Message    string
+	Suggestion string // the safe multi-step equivalent
+}
+
+var (
+	lintAddColumn    = regexp.MustCompile(`(?is)\bADD\s+(?:COLUMN\s+)?(.*)`)
+	lintNotNull      = regexp.MustCompile(`(?i)\bNOT\s+NULL\b`)
+	lintDefault      = regexp.MustCompile(`(?i)\bDEFAULT\b`)
+	lintTypeChange   = regexp.MustCompile(`(?i)\bALTER\s+(?:COLUMN\s+)?\S+\s+(?:SET\s+DATA\s+)?TYPE\b|\bMODIFY\s+(?:COLUMN\s+)?\S+|\bCHANGE\s+(?:COLUMN\s+)?\S+\s+\S+`)
+	lintSetNotNull   = regexp.MustCompile(`(?i)\bALTER\s+(?:COLUMN\s+)?\S+\s+SET\s+NOT\s+NULL\b`)
+	lintConstraint   = regexp.MustCompile(`(?i)\bADD\s+CONSTRAINT\s+\S+\s+(FOREIGN\s+KEY|CHECK)\b`)
+	lintNotValid     = regexp.MustCompile(`(?i)\bNOT\s+VALID\b`)
+	lintCreateIndex  = regexp.MustCompile(`(?i)^\s*CREATE\s+(?:UNIQUE\s+)?INDEX\b`)
+	lintConcurrently = regexp.MustCompile(`(?i)\bCONCURRENTLY\b`)
+	lintAlterTable   = regexp.MustCompile(`(?i)^\s*ALTER\s+TABLE\b`)
+)
+
+// LintMigration flags the operations of mig that lock or rewrite tables on
+// the server described by info.
+func LintMigration(info ServerInfo, mig PlannedMigration) []LintWarning {
+	var warnings []LintWarning
+	warn := func(i int, rule, message, suggestion string) {
+		warnings = append(warnings, LintWarning{Operation: i, Rule: rule, Message: message, Suggestion: suggestion})
+	}
+	postgres := info.Flavor == "postgres" || info.Flavor == "cockroachdb"
+
+	for i, op := range mig.Operations {
+		sql := op.SQL
+		// Online schema change tools copy the table instead of locking it.
+		if sql == "" || op.alter != "" {
+			continue
+		}
+
+		if postgres && lintCreateIndex.MatchString(sql) && !lintConcurrently.MatchString(sql) {
+			warn(i, "blocking-index",
+				"CREATE INDEX blocks writes to the table while the index builds",
+				"use Schema.CreateIndexConcurrently")
+		}
+		if !lintAlterTable.MatchString(sql) {
+			continue
+		}
+
+		for _, clause := range splitAlterClauses(sql) {
+			if m := lintAddColumn.FindStringSubmatch(clause); m != nil && !lintConstraint.MatchString(clause) {
+				def := m[1]
+				switch {
+				case lintNotNull.MatchString(def) && !lintDefault.MatchString(def):
+					warn(i, "not-null-without-default",
+						"adding a NOT NULL column without a default fails on a non-empty table",
+						"add the column as nullable, backfill it in batches, then set NOT NULL")
+				case postgres && lintDefault.MatchString(def) && !info.AtLeast(11, 0):
+					warn(i, "default-rewrites-table",
+						"before Postgres 11, adding a column with a default rewrites the whole table",
+						"add the column without a default, set the default, then backfill in batches")
+				}
+			}
+			if lintTypeChange.MatchString(clause) {
+				warn(i, "column-type-change",
+					"changing a column's type rewrites the table under an exclusive lock",
+					"add a column of the new type, backfill it in batches, switch reads and writes to it, then drop the old one")
+			}
+			if postgres && lintSetNotNull.MatchString(clause) {
+				warn(i, "set-not-null",
+					"SET NOT NULL scans the whole table under an exclusive lock",
+					"add CHECK (column IS NOT NULL) NOT VALID, validate it in a later migration, then SET NOT NULL (Postgres 12+ skips the scan)")
+			}
+			if m := lintConstraint.FindStringSubmatch(clause); postgres && m != nil && !lintNotValid.MatchString(clause) {
+				warn(i, "validating-constraint",
+					"adding a "+strings.ToUpper(strings.Join(strings.Fields(m[1]), " "))+
+						" constraint validates every row while blocking writes",
+					"add it NOT VALID, then VALIDATE CONSTRAINT in a later migration (Schema.ValidateConstraint)")
+			}
+		}
+	}
+	return warnings
+}
+
+// splitAlterClauses splits the comma-separated clauses of an ALTER TABLE
+// statement, ignoring commas inside parentheses and quotes.
+func splitAlterClauses(sql string) []string {
+	var clauses []string
+	depth, start := 0, 0
+	var quote rune
+	for i, r := range sql {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == ',' && depth == 0:
+			clauses = append(clauses, sql[start:i])
+			start = i + 1
+		}
+	}
+	return append(clauses, sql[start:])
+}
@@ -0,0 +1,56 @@
+package gormkit_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestLintMigration(t *testing.T) {
+	tests := []struct {
+		info  gormkit.ServerInfo
+		sql   string
+		rules []string
+	}{
+		{gormkit.ServerInfo{Flavor: "postgres", Major: 16}, `ALTER TABLE "orders" ADD COLUMN "note" text NOT NULL`, []string{"not-null-without-default"}},
+		{gormkit.ServerInfo{Flavor: "postgres", Major: 10}, `ALTER TABLE "orders" ADD COLUMN "note" text NOT NULL DEFAULT ''`, []string{"default-rewrites-table"}},
+		{gormkit.ServerInfo{Flavor: "postgres", Major: 16}, `ALTER TABLE "orders" ADD COLUMN "note" text NOT NULL DEFAULT ''`, nil},
+		{gormkit.ServerInfo{Flavor: "postgres", Major: 16}, `ALTER TABLE "orders" ALTER COLUMN "total" TYPE numeric(12,2), ALTER COLUMN "note" SET NOT NULL`, []string{"column-type-change", "set-not-null"}},
+		{gormkit.ServerInfo{Flavor: "mysql", Major: 8}, "ALTER TABLE `orders` MODIFY COLUMN `total` DECIMAL(12,2)", []string{"column-type-change"}},
+		{gormkit.ServerInfo{Flavor: "postgres", Major: 16}, `ALTER TABLE "orders" ADD CONSTRAINT "fk" FOREIGN KEY ("customer_id") REFERENCES "customers" ("id")`, []string{"validating-constraint"}},
+		{gormkit.ServerInfo{Flavor: "postgres", Major: 16}, `ALTER TABLE "orders" ADD CONSTRAINT "fk" FOREIGN KEY ("customer_id") REFERENCES "customers" ("id") NOT VALID`, nil},
+		{gormkit.ServerInfo{Flavor: "postgres", Major: 16}, `CREATE INDEX "idx" ON "orders" ("customer_id")`, []string{"blocking-index"}},
+		{gormkit.ServerInfo{Flavor: "postgres", Major: 16}, `CREATE INDEX CONCURRENTLY "idx" ON "orders" ("customer_id")`, nil},
+		{gormkit.ServerInfo{Flavor: "cockroachdb", Major: 23, Minor: 1}, `ALTER TABLE "orders" ALTER COLUMN "note" SET NOT NULL`, []string{"set-not-null"}},
+	}
+	for _, tt := range tests {
+		warnings := gormkit.LintMigration(tt.info, gormkit.PlannedMigration{
+			Operations: []gormkit.Operation{{SQL: tt.sql}},
+		})
+		var rules []string
+		for _, w := range warnings {
+			rules = append(rules, w.Rule)
+			if w.Suggestion == "" {
+				t.Errorf("Expected a suggestion for %s", w.Rule)
+			}
+		}
+		if !reflect.DeepEqual(rules, tt.rules) {
+			t.Errorf("%s: expected %v, got %v", tt.sql, tt.rules, rules)
+		}
+	}
+}
+
+func TestPlanMigrationsWarnings(t *testing.T) {
+	manager := gormkit.NewTestManager(t)
+	plan, err := manager.PlanMigrations(context.Background(), gormkit.Migration{ID: "001", Up: func(s *gormkit.Schema) {
+		s.AlterTable("users", "ADD COLUMN email TEXT NOT NULL")
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan[0].Warnings) != 1 || plan[0].Warnings[0].Rule != "not-null-without-default" {
+		t.Errorf("Unexpected warnings: %+v", plan[0].Warnings)
+	}
+}
@@ -0,0 +1,86 @@
+package gormkit
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type LockWait struct {
+	WaitingPID    int64
+	WaitingQuery  string
+	WaitingFor    time.Duration
+	BlockingPID   int64
+	BlockingQuery string
+	LockType      string
+	Relation      string
+}
+
+const postgresLockWaitsSQL = `
+SELECT w.pid AS waiting_pid,
+       COALESCE(w.query, '') AS waiting_query,
+       COALESCE(EXTRACT(EPOCH FROM now() - w.query_start), 0)::float8 AS wait_seconds,
+       b.pid AS blocking_pid,
+       COALESCE(b.query, '') AS blocking_query,
+       COALESCE(l.locktype, '') AS lock_type,
+       COALESCE(l.relation::regclass::text, '') AS relation
+FROM pg_stat_activity w
+CROSS JOIN LATERAL unnest(pg_blocking_pids(w.pid)) AS bp(pid)
+JOIN pg_stat_activity b ON b.pid = bp.pid
+LEFT JOIN pg_locks l ON l.pid = w.pid AND NOT l.granted
+ORDER BY wait_seconds DESC`
+
+const mysqlLockWaitsSQL = `
+SELECT waiting_pid,
+       COALESCE(waiting_query, '') AS waiting_query,
+       wait_age_secs AS wait_seconds,
+       blocking_pid,
+       COALESCE(blocking_query, '') AS blocking_query,
+       COALESCE(locked_type, '') AS lock_type,
+       COALESCE(locked_table, '') AS relation
+FROM sys.innodb_lock_waits
+ORDER BY wait_age_secs DESC`
+
+type lockWaitRow struct {
+	WaitingPID    int64
+	WaitingQuery  string
+	WaitSeconds   float64
+	BlockingPID   int64
+	BlockingQuery string
+	LockType      string
+	Relation      string
+}
+
+// Locks lists sessions currently waiting on a lock together with the
+// sessions blocking them, from pg_locks on Postgres and sys.innodb_lock_waits
+// on MySQL.
+func (m *Manager) Locks(ctx context.Context) ([]LockWait, error) {
+	var query string
+	switch m.config.Driver {
+	case "postgres":
+		query = postgresLockWaitsSQL
+	case "mysql":
+		query = mysqlLockWaitsSQL
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedDriver, m.config.Driver)
+	}
+
+	rows, err := Query[lockWaitRow](ctx, m.db, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query lock waits: %w", err)
+	}
+
+	waits := make([]LockWait, len(rows))
+	for i, r := range rows {
+		waits[i] = LockWait{
+			WaitingPID:    r.WaitingPID,
+			WaitingQuery:  r.WaitingQuery,
+			WaitingFor:    time.Duration(r.WaitSeconds * float64(time.Second)),
+			BlockingPID:   r.BlockingPID,
+			BlockingQuery: r.BlockingQuery,
+			LockType:      r.LockType,
+			Relation:      r.Relation,
+		}
+	}
+	return waits, nil
+}
@@ -0,0 +1,68 @@
+package gormkit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestLocksUnsupportedOnSQLite(t *testing.T) {
+	manager, err := gormkit.New(&gormkit.Config{
+		Driver:   "test",
+		LogLevel: "silent",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	_, err = manager.Locks(context.Background())
+	if !errors.Is(err, gormkit.ErrUnsupportedDriver) {
+		t.Errorf("Expected ErrUnsupportedDriver, got %v", err)
+	}
+}
+
+func TestLockScopes(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		scope func(*gorm.DB) *gorm.DB
+		want  string
+	}{
+		{gormkit.LockForUpdate(), `SELECT * FROM "users" FOR UPDATE`},
+		{gormkit.LockSkipLocked(), `SELECT * FROM "users" FOR UPDATE SKIP LOCKED`},
+		{gormkit.LockNoWait(), `SELECT * FROM "users" FOR UPDATE NOWAIT`},
+	}
+	for _, tt := range tests {
+		sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+			return tx.Scopes(tt.scope).Find(&[]User{})
+		})
+		if sql != tt.want {
+			t.Errorf("Expected %q, got %q", tt.want, sql)
+		}
+	}
+}
+
+func TestLockScopesOnSQLite(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &User{})
+	manager.DB().Create(&User{Name: "alice"})
+
+	var users []User
+	if err := manager.DB().Scopes(gormkit.LockSkipLocked()).Find(&users).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 1 {
+		t.Errorf("Expected 1 user, got %d", len(users))
+	}
+}
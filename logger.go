@@ -0,0 +1,191 @@
+package gormkit
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var kitSourceDir string
+
+func init() {
+	_, file, _, _ := runtime.Caller(0)
+	kitSourceDir = filepath.ToSlash(filepath.Dir(file)) + "/"
+}
+
+// kitLogger is the gorm logger installed by the Manager. Its level and slow
+// query threshold can be swapped at runtime without racing queries in flight.
+type kitLogger struct {
+	format  string
+	out     io.Writer
+	outMu   sync.Mutex
+	traceID func(context.Context) string
+	sampler logSampler
+	current atomic.Pointer[loggerState]
+}
+
+type loggerState struct {
+	logger.Interface
+	level         logger.LogLevel
+	slowThreshold time.Duration
+}
+
+func newKitLogger(cfg *Config) *kitLogger {
+	l := &kitLogger{format: cfg.LogFormat, out: cfg.LogOutput, traceID: cfg.TraceIDFunc}
+	if l.out == nil {
+		l.out = os.Stdout
+	}
+	l.set(parseLogLevel(cfg.LogLevel), cfg.SlowThreshold)
+	l.sampler.setRate(cfg.LogSampleRate)
+	return l
+}
+
+func (l *kitLogger) set(level logger.LogLevel, slowThreshold time.Duration) {
+	var next logger.Interface
+	if l.format == "json" {
+		next = &jsonLogger{
+			out:           l.out,
+			mu:            &l.outMu,
+			level:         level,
+			slowThreshold: slowThreshold,
+			traceID:       l.traceID,
+		}
+	} else {
+		next = logger.New(callerWriter{log.New(l.out, "\r\n", log.LstdFlags)}, logger.Config{
+			SlowThreshold: slowThreshold,
+			LogLevel:      level,
+			Colorful:      l.out == os.Stdout,
+		})
+	}
+	l.current.Store(&loggerState{Interface: next, level: level, slowThreshold: slowThreshold})
+}
+
+func (l *kitLogger) get() logger.Interface {
+	return l.current.Load().Interface
+}
+
+// LogMode returns a detached logger at level, as used by db.Debug().
+func (l *kitLogger) LogMode(level logger.LogLevel) logger.Interface {
+	return l.get().LogMode(level)
+}
+
+func (l *kitLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	l.get().Info(ctx, msg, data...)
+}
+
+func (l *kitLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	l.get().Warn(ctx, msg, data...)
+}
+
+func (l *kitLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	l.get().Error(ctx, msg, data...)
+}
+
+func (l *kitLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	state := l.current.Load()
+	if state.level >= logger.Info && err == nil && l.sampler.enabled() &&
+		(state.slowThreshold == 0 || time.Since(begin) <= state.slowThreshold) {
+		sql, rows := fc()
+		if !l.sampler.keep(sql) {
+			return
+		}
+		fc = func() (string, int64) { return sql, rows }
+	}
+	if l.format != "json" {
+		if comment := fieldsComment(ctx); comment != "" {
+			inner := fc
+			fc = func() (string, int64) {
+				sql, rows := inner()
+				if !strings.Contains(sql, comment) {
+					sql += " " + comment
+				}
+				return sql, rows
+			}
+		}
+	}
+	state.Trace(ctx, begin, fc, err)
+}
+
+func (l *kitLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	if f, ok := l.get().(gorm.ParamsFilter); ok {
+		return f.ParamsFilter(ctx, sql, params...)
+	}
+	return sql, params
+}
+
+// callerWriter fixes the caller reported by gorm's text logger, which only
+// skips gorm's own frames and would otherwise point at kitLogger.
+type callerWriter struct {
+	logger.Writer
+}
+
+func (w callerWriter) Printf(format string, args ...interface{}) {
+	if len(args) > 0 {
+		if file, ok := args[0].(string); ok && isKitFrame(file) {
+			args[0] = fileWithLineNum()
+		}
+	}
+	w.Writer.Printf(format, args...)
+}
+
+// fileWithLineNum returns the first caller outside gorm and gorm-kit.
+func fileWithLineNum() string {
+	pcs := [16]uintptr{}
+	n := runtime.Callers(3, pcs[:])
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if !strings.Contains(frame.File, "/gorm.io/") && !isKitFrame(frame.File) {
+			return frame.File + ":" + strconv.Itoa(frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
+
+func isKitFrame(file string) bool {
+	return strings.HasPrefix(file, kitSourceDir) && !strings.HasSuffix(file, "_test.go")
+}
+
+// SetLogLevel switches SQL logging ("silent", "error", "warn" or "info") for
+// every query issued from now on, e.g. from a SIGHUP handler or an admin
+// endpoint during an incident.
+func (m *Manager) SetLogLevel(level string) error {
+	switch level {
+	case "silent", "error", "warn", "info":
+	default:
+		return fmt.Errorf("invalid log level: %s", level)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.logger.set(parseLogLevel(level), m.config.SlowThreshold)
+	m.config.LogLevel = level
+	return nil
+}
+
+func parseLogLevel(level string) logger.LogLevel {
+	switch level {
+	case "silent":
+		return logger.Silent
+	case "error":
+		return logger.Error
+	case "warn":
+		return logger.Warn
+	default:
+		return logger.Info
+	}
+}
@@ -0,0 +1,85 @@
+package gormkit_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestSetLogLevel(t *testing.T) {
+	manager, err := gormkit.New(&gormkit.Config{
+		Driver:   "test",
+		LogLevel: "silent",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	db := manager.DB()
+	db.AutoMigrate(&User{})
+
+	if err := manager.SetLogLevel("error"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&User{Name: "Logged"}).Error; err != nil {
+		t.Errorf("Create after SetLogLevel failed: %v", err)
+	}
+
+	if err := manager.SetLogLevel("verbose"); err == nil {
+		t.Error("Expected error for invalid level")
+	}
+}
+
+type traceKey struct{}
+
+func TestJSONLogFormat(t *testing.T) {
+	var buf bytes.Buffer
+	manager, err := gormkit.New(&gormkit.Config{
+		Driver:    "test",
+		LogLevel:  "silent",
+		LogFormat: "json",
+		LogOutput: &buf,
+		TraceIDFunc: func(ctx context.Context) string {
+			id, _ := ctx.Value(traceKey{}).(string)
+			return id
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	db := manager.DB()
+	db.AutoMigrate(&User{})
+	if err := manager.SetLogLevel("info"); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.WithValue(context.Background(), traceKey{}, "trace-1")
+	var users []User
+	db.WithContext(ctx).Where("name = ?", "secret-name").Find(&users)
+
+	line := strings.TrimSpace(buf.String())
+	if strings.Contains(line, "secret-name") {
+		t.Errorf("Expected arguments to be redacted, got %s", line)
+	}
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(line), &entry); err != nil {
+		t.Fatalf("Expected one JSON object, got %q: %v", line, err)
+	}
+	if entry["level"] != "info" || entry["trace_id"] != "trace-1" {
+		t.Errorf("Unexpected entry: %v", entry)
+	}
+	if sql, _ := entry["sql"].(string); !strings.Contains(sql, "name = ?") {
+		t.Errorf("Expected placeholder SQL, got %v", entry["sql"])
+	}
+	if caller, _ := entry["caller"].(string); !strings.Contains(caller, "logger_test.go") {
+		t.Errorf("Expected caller in logger_test.go, got %v", entry["caller"])
+	}
+}
@@ -0,0 +1,111 @@
+package gormkit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// jsonLogger writes one JSON object per line. SQL is logged with its
+// placeholders; argument values are never written.
+type jsonLogger struct {
+	out           io.Writer
+	mu            *sync.Mutex
+	level         logger.LogLevel
+	slowThreshold time.Duration
+	traceID       func(context.Context) string
+}
+
+type logEntry struct {
+	Time       string         `json:"time"`
+	Level      string         `json:"level"`
+	Message    string         `json:"msg,omitempty"`
+	SQL        string         `json:"sql,omitempty"`
+	Rows       *int64         `json:"rows,omitempty"`
+	DurationMs float64        `json:"duration_ms,omitempty"`
+	Slow       bool           `json:"slow,omitempty"`
+	Error      string         `json:"error,omitempty"`
+	Caller     string         `json:"caller,omitempty"`
+	TraceID    string         `json:"trace_id,omitempty"`
+	Fields     map[string]any `json:"fields,omitempty"`
+}
+
+func (l *jsonLogger) LogMode(level logger.LogLevel) logger.Interface {
+	next := *l
+	next.level = level
+	return &next
+}
+
+func (l *jsonLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Info {
+		l.write(ctx, logEntry{Level: "info", Message: fmt.Sprintf(msg, data...)})
+	}
+}
+
+func (l *jsonLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Warn {
+		l.write(ctx, logEntry{Level: "warn", Message: fmt.Sprintf(msg, data...)})
+	}
+}
+
+func (l *jsonLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	if l.level >= logger.Error {
+		l.write(ctx, logEntry{Level: "error", Message: fmt.Sprintf(msg, data...)})
+	}
+}
+
+func (l *jsonLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= logger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	entry := logEntry{DurationMs: float64(elapsed.Nanoseconds()) / 1e6}
+	switch {
+	case err != nil && l.level >= logger.Error && !errors.Is(err, gorm.ErrRecordNotFound):
+		entry.Level = "error"
+		entry.Error = err.Error()
+	case l.slowThreshold != 0 && elapsed > l.slowThreshold && l.level >= logger.Warn:
+		entry.Level = "warn"
+		entry.Slow = true
+	case l.level >= logger.Info:
+		entry.Level = "info"
+	default:
+		return
+	}
+
+	sql, rows := fc()
+	entry.SQL = sql
+	if rows >= 0 {
+		entry.Rows = &rows
+	}
+	l.write(ctx, entry)
+}
+
+func (l *jsonLogger) ParamsFilter(ctx context.Context, sql string, params ...interface{}) (string, []interface{}) {
+	return sql, nil
+}
+
+func (l *jsonLogger) write(ctx context.Context, entry logEntry) {
+	entry.Time = time.Now().Format(time.RFC3339Nano)
+	entry.Caller = fileWithLineNum()
+	if l.traceID != nil && ctx != nil {
+		entry.TraceID = l.traceID(ctx)
+	}
+	entry.Fields = Fields(ctx)
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.out.Write(append(data, '\n'))
+}
@@ -0,0 +1,52 @@
+package gormkit
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+var (
+	sqlComment     = regexp.MustCompile(`/\*.*?\*/`)
+	stringLiteral  = regexp.MustCompile(`'(?:[^']|'')*'`)
+	numberLiteral  = regexp.MustCompile(`\b\d+(?:\.\d+)?\b`)
+	placeholder    = regexp.MustCompile(`\$\d+|@\w+`)
+	placeholderSet = regexp.MustCompile(`\?(?:\s*,\s*\?)+`)
+)
+
+// logSampler lets through 1 in rate occurrences of each query fingerprint.
+type logSampler struct {
+	rate   atomic.Int64
+	counts sync.Map // fingerprint -> *atomic.Uint64
+}
+
+func (s *logSampler) setRate(rate int) {
+	s.rate.Store(int64(rate))
+}
+
+func (s *logSampler) enabled() bool {
+	return s.rate.Load() > 1
+}
+
+func (s *logSampler) keep(sql string) bool {
+	rate := uint64(s.rate.Load())
+	if rate <= 1 {
+		return true
+	}
+	v, _ := s.counts.LoadOrStore(fingerprint(sql), new(atomic.Uint64))
+	return (v.(*atomic.Uint64).Add(1)-1)%rate == 0
+}
+
+// fingerprint reduces sql to its shape: comments are dropped, literals and
+// placeholders become ?, IN-lists collapse to a single ? and whitespace is
+// normalized, so queries that differ only in their arguments share a
+// fingerprint.
+func fingerprint(sql string) string {
+	sql = sqlComment.ReplaceAllString(sql, "")
+	sql = placeholder.ReplaceAllString(sql, "?")
+	sql = stringLiteral.ReplaceAllString(sql, "?")
+	sql = numberLiteral.ReplaceAllString(sql, "?")
+	sql = placeholderSet.ReplaceAllString(sql, "?")
+	return strings.Join(strings.Fields(sql), " ")
+}
@@ -0,0 +1,44 @@
+package gormkit_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestLogSampleRate(t *testing.T) {
+	var buf bytes.Buffer
+	manager, err := gormkit.New(&gormkit.Config{
+		Driver:        "test",
+		LogLevel:      "silent",
+		LogOutput:     &buf,
+		LogSampleRate: 3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	db := manager.DB()
+	db.AutoMigrate(&User{})
+	manager.SetLogLevel("info")
+
+	for i := 0; i < 6; i++ {
+		var users []User
+		db.Where("name = ?", fmt.Sprint("user-", i)).Find(&users)
+	}
+	if n := strings.Count(buf.String(), "SELECT * FROM `users`"); n != 2 {
+		t.Errorf("Expected 2 sampled queries, got %d:\n%s", n, buf.String())
+	}
+
+	buf.Reset()
+	for i := 0; i < 2; i++ {
+		db.Exec("SELECT * FROM missing_table")
+	}
+	if n := strings.Count(buf.String(), "SELECT * FROM missing_table"); n != 2 {
+		t.Errorf("Expected every failing query to be logged, got %d", n)
+	}
+}
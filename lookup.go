@@ -0,0 +1,58 @@
+package gormkit
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+)
+
+// FindByIDs loads the rows of T with the given primary keys, in the order
+// of ids (each once), and returns the ids that matched no row. Long lists
+// are queried in chunks, as with FindIn.
+//
+//	users, missing, err := gormkit.FindByIDs[User](ctx, db, ids)
+func FindByIDs[T any, K comparable](ctx context.Context, db *gorm.DB, ids []K) ([]T, []K, error) {
+	db = db.WithContext(ctx)
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(new(T)); err != nil {
+		return nil, nil, err
+	}
+	field := stmt.Schema.PrioritizedPrimaryField
+	if field == nil {
+		return nil, nil, fmt.Errorf("%s needs a single primary key", stmt.Schema.Table)
+	}
+
+	var unique []K
+	seen := make(map[K]bool, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	rows, err := FindIn[T](ctx, db, field.DBName, unique)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Rows are matched by the string form of their key, so ids of another
+	// integer type than the field still match.
+	found := make(map[string]T, len(rows))
+	for _, row := range rows {
+		id, _ := field.ValueOf(ctx, reflect.ValueOf(&row).Elem())
+		found[idKey(id)] = row
+	}
+
+	result := make([]T, 0, len(found))
+	var missing []K
+	for _, id := range unique {
+		if row, ok := found[idKey(id)]; ok {
+			result = append(result, row)
+		} else {
+			missing = append(missing, id)
+		}
+	}
+	return result, missing, nil
+}
@@ -0,0 +1,56 @@
+package gormkit_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestFindByIDs(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &User{})
+	db := manager.DB()
+	for i := 1; i <= 5; i++ {
+		db.Create(&User{Name: fmt.Sprintf("u%d", i)})
+	}
+
+	users, missing, err := gormkit.FindByIDs[User](context.Background(), db, []int{4, 9, 2, 4, 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(names(users, func(u User) string { return u.Name })); got != "[u4 u2 u1]" {
+		t.Errorf("Expected users in input order, got %s", got)
+	}
+	if fmt.Sprint(missing) != "[9]" {
+		t.Errorf("Expected 9 to be missing, got %v", missing)
+	}
+}
+
+func TestFindByIDsChunks(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &User{})
+	db := manager.DB()
+	users := make([]User, 0, 40000)
+	for i := 1; i <= 40000; i++ {
+		users = append(users, User{Name: fmt.Sprintf("u%d", i)})
+	}
+	if err := db.CreateInBatches(users, 1000).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	// More ids than SQLite allows placeholders in one statement.
+	ids := make([]uint, 0, 40010)
+	for i := uint(40010); i >= 1; i-- {
+		ids = append(ids, i)
+	}
+	found, missing, err := gormkit.FindByIDs[User](context.Background(), db, ids)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 40000 || found[0].ID != 40000 || found[39999].ID != 1 {
+		t.Errorf("Expected all 40000 users in descending order, got %d", len(found))
+	}
+	if len(missing) != 10 || missing[0] != 40010 {
+		t.Errorf("Expected 10 missing ids, got %v", missing)
+	}
+}
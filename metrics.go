@@ -0,0 +1,364 @@
+package gormkit
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const metricsStartKey = "gormkit:metrics_start"
+
+// durationBuckets are the upper bounds, in seconds, of the query duration
+// histogram.
+var durationBuckets = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// OperationStats aggregates the statements of one operation on one table.
+type OperationStats struct {
+	Table     string // "" for raw SQL
+	Operation string // select, insert, update, delete or other
+	Count     int64
+	Errors    int64
+	Duration  time.Duration
+	Buckets   []int64 // statements per durationBuckets bound, not cumulative
+}
+
+// MetricsSnapshot is a point-in-time copy of the collected metrics.
+type MetricsSnapshot struct {
+	Operations []OperationStats
+	Counters   map[string]int64 // keyed by name{label="value",...}
+}
+
+// QueryStats aggregates the statements sharing a fingerprint: the SQL with
+// literals and IN-lists normalized.
+type QueryStats struct {
+	Fingerprint string
+	Count       int64
+	Errors      int64
+	Duration    time.Duration
+	MaxDuration time.Duration
+}
+
+// SlowQuery is a statement that took at least Config.SlowThreshold.
+type SlowQuery struct {
+	Time        time.Time
+	Fingerprint string
+	Table       string
+	Duration    time.Duration
+	Error       string
+}
+
+const (
+	maxFingerprints = 1000 // statements of further shapes aren't tracked
+	maxSlowQueries  = 100
+)
+
+type opKey struct {
+	table, operation string
+}
+
+type metricsRegistry struct {
+	mu         sync.Mutex
+	operations map[opKey]*OperationStats
+	queries    map[string]*QueryStats
+	slow       []SlowQuery // ring of the latest maxSlowQueries
+	slowNext   int
+	counters   map[string]int64
+	help       map[string]string
+}
+
+func newMetricsRegistry() *metricsRegistry {
+	return &metricsRegistry{
+		operations: make(map[opKey]*OperationStats),
+		queries:    make(map[string]*QueryStats),
+		counters:   make(map[string]int64),
+		help:       make(map[string]string),
+	}
+}
+
+// inc adds one to the counter name with labels given as key, value pairs.
+// It is a no-op when metrics are disabled.
+func (r *metricsRegistry) inc(name, help string, labels ...string) {
+	if r == nil {
+		return
+	}
+	var b strings.Builder
+	b.WriteString(name)
+	if len(labels) > 0 {
+		b.WriteByte('{')
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			fmt.Fprintf(&b, "%s=%q", labels[i], labels[i+1])
+		}
+		b.WriteByte('}')
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.counters[b.String()]++
+	r.help[name] = help
+}
+
+func (r *metricsRegistry) observe(table, operation string, d time.Duration, failed bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := opKey{table, operation}
+	s, ok := r.operations[key]
+	if !ok {
+		s = &OperationStats{Table: table, Operation: operation, Buckets: make([]int64, len(durationBuckets))}
+		r.operations[key] = s
+	}
+	s.Count++
+	s.Duration += d
+	if failed {
+		s.Errors++
+	}
+	for i, bound := range durationBuckets {
+		if d.Seconds() <= bound {
+			s.Buckets[i]++
+			break
+		}
+	}
+}
+
+// observeQuery adds a statement to the stats of its fingerprint and, when
+// slow, to the slow query log.
+func (r *metricsRegistry) observeQuery(fp, table string, d time.Duration, err error, slow bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	q, ok := r.queries[fp]
+	if !ok && len(r.queries) < maxFingerprints {
+		q = &QueryStats{Fingerprint: fp}
+		r.queries[fp] = q
+	}
+	if q != nil {
+		q.Count++
+		q.Duration += d
+		q.MaxDuration = max(q.MaxDuration, d)
+		if err != nil {
+			q.Errors++
+		}
+	}
+	if slow {
+		entry := SlowQuery{Time: time.Now(), Fingerprint: fp, Table: table, Duration: d}
+		if err != nil {
+			entry.Error = err.Error()
+		}
+		if len(r.slow) < maxSlowQueries {
+			r.slow = append(r.slow, entry)
+		} else {
+			r.slow[r.slowNext] = entry
+		}
+		r.slowNext = (r.slowNext + 1) % maxSlowQueries
+	}
+}
+
+func (m *Manager) beforeMetrics(db *gorm.DB) {
+	db.InstanceSet(metricsStartKey, time.Now())
+}
+
+func (m *Manager) afterMetrics(db *gorm.DB) {
+	start, ok := db.InstanceGet(metricsStartKey)
+	// A statement held back by a quota never reached the database.
+	if !ok || errors.Is(db.Error, ErrQuotaExceeded) {
+		return
+	}
+	d := time.Since(start.(time.Time))
+	var err error
+	if db.Error != nil && !errors.Is(db.Error, gorm.ErrRecordNotFound) {
+		err = db.Error
+	}
+	sql := db.Statement.SQL.String()
+	operation := operationOf(sql)
+	m.metrics.observe(db.Statement.Table, operation, d, err != nil)
+	if len(m.slos) > 0 {
+		now := time.Now()
+		for _, slo := range m.slos {
+			if slo.matches(db.Statement.Table, operation) {
+				slo.observe(now, d, err != nil)
+			}
+		}
+	}
+	if sql != "" {
+		m.metrics.observeQuery(fingerprint(sql), db.Statement.Table, d, err, d >= m.config.SlowThreshold)
+	}
+}
+
+// operationOf classifies a statement by its leading keyword, skipping
+// comments such as those added by QueryComments.
+func operationOf(sql string) string {
+	sql = strings.TrimSpace(sqlComment.ReplaceAllString(sql, ""))
+	keyword, _, _ := strings.Cut(sql, " ")
+	switch op := strings.ToLower(keyword); op {
+	case "select", "insert", "update", "delete":
+		return op
+	}
+	return "other"
+}
+
+// Metrics returns the query metrics collected since start, labeled by table
+// and operation. It is empty unless Config.Metrics is set.
+func (m *Manager) Metrics() MetricsSnapshot {
+	snap := MetricsSnapshot{Counters: make(map[string]int64)}
+	if m.metrics == nil {
+		return snap
+	}
+	m.metrics.mu.Lock()
+	defer m.metrics.mu.Unlock()
+	for _, s := range m.metrics.operations {
+		c := *s
+		c.Buckets = append([]int64(nil), s.Buckets...)
+		snap.Operations = append(snap.Operations, c)
+	}
+	sort.Slice(snap.Operations, func(i, j int) bool {
+		a, b := snap.Operations[i], snap.Operations[j]
+		if a.Table != b.Table {
+			return a.Table < b.Table
+		}
+		return a.Operation < b.Operation
+	})
+	for k, v := range m.metrics.counters {
+		snap.Counters[k] = v
+	}
+	return snap
+}
+
+// TopQueries returns the n statement fingerprints with the most total time
+// since start, or all of them when n is 0. It is empty unless
+// Config.Metrics is set.
+func (m *Manager) TopQueries(n int) []QueryStats {
+	if m.metrics == nil {
+		return nil
+	}
+	m.metrics.mu.Lock()
+	queries := make([]QueryStats, 0, len(m.metrics.queries))
+	for _, q := range m.metrics.queries {
+		queries = append(queries, *q)
+	}
+	m.metrics.mu.Unlock()
+	sort.Slice(queries, func(i, j int) bool {
+		if queries[i].Duration != queries[j].Duration {
+			return queries[i].Duration > queries[j].Duration
+		}
+		return queries[i].Fingerprint < queries[j].Fingerprint
+	})
+	if n > 0 && len(queries) > n {
+		queries = queries[:n]
+	}
+	return queries
+}
+
+// SlowQueries returns the latest statements that took at least
+// Config.SlowThreshold, newest first. It is empty unless Config.Metrics is
+// set.
+func (m *Manager) SlowQueries() []SlowQuery {
+	if m.metrics == nil {
+		return nil
+	}
+	m.metrics.mu.Lock()
+	defer m.metrics.mu.Unlock()
+	slow := make([]SlowQuery, 0, len(m.metrics.slow))
+	for i := 1; i <= len(m.metrics.slow); i++ {
+		slow = append(slow, m.metrics.slow[(m.metrics.slowNext-i+len(m.metrics.slow))%len(m.metrics.slow)])
+	}
+	return slow
+}
+
+func (r *metricsRegistry) counterSnapshot() (counters map[string]int64, help map[string]string) {
+	counters, help = make(map[string]int64), make(map[string]string)
+	if r == nil {
+		return counters, help
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for k, v := range r.counters {
+		counters[k] = v
+	}
+	for k, v := range r.help {
+		help[k] = v
+	}
+	return counters, help
+}
+
+// MetricsHandler serves the metrics and pool stats in the Prometheus text
+// format.
+func (m *Manager) MetricsHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		snap := m.Metrics()
+		var b strings.Builder
+
+		b.WriteString("# HELP gormkit_queries_total Statements executed.\n# TYPE gormkit_queries_total counter\n")
+		for _, s := range snap.Operations {
+			fmt.Fprintf(&b, "gormkit_queries_total{%s} %d\n", opLabels(s), s.Count)
+		}
+		b.WriteString("# HELP gormkit_query_errors_total Statements that failed.\n# TYPE gormkit_query_errors_total counter\n")
+		for _, s := range snap.Operations {
+			fmt.Fprintf(&b, "gormkit_query_errors_total{%s} %d\n", opLabels(s), s.Errors)
+		}
+		b.WriteString("# HELP gormkit_query_duration_seconds Statement latency.\n# TYPE gormkit_query_duration_seconds histogram\n")
+		for _, s := range snap.Operations {
+			var cumulative int64
+			for i, bound := range durationBuckets {
+				cumulative += s.Buckets[i]
+				fmt.Fprintf(&b, "gormkit_query_duration_seconds_bucket{%s,le=\"%g\"} %d\n", opLabels(s), bound, cumulative)
+			}
+			fmt.Fprintf(&b, "gormkit_query_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", opLabels(s), s.Count)
+			fmt.Fprintf(&b, "gormkit_query_duration_seconds_sum{%s} %g\n", opLabels(s), s.Duration.Seconds())
+			fmt.Fprintf(&b, "gormkit_query_duration_seconds_count{%s} %d\n", opLabels(s), s.Count)
+		}
+
+		counters, help := m.metrics.counterSnapshot()
+		names := make([]string, 0, len(help))
+		for name := range help {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		keys := make([]string, 0, len(counters))
+		for k := range counters {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, name := range names {
+			fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s counter\n", name, help[name], name)
+			for _, k := range keys {
+				if k == name || strings.HasPrefix(k, name+"{") {
+					fmt.Fprintf(&b, "%s %d\n", k, counters[k])
+				}
+			}
+		}
+
+		stats := m.Stats()
+		fmt.Fprintf(&b, "# HELP gormkit_pool_open_connections Open connections.\n# TYPE gormkit_pool_open_connections gauge\ngormkit_pool_open_connections %d\n", stats.OpenConnections)
+		fmt.Fprintf(&b, "# HELP gormkit_pool_in_use_connections Connections in use.\n# TYPE gormkit_pool_in_use_connections gauge\ngormkit_pool_in_use_connections %d\n", stats.InUse)
+		fmt.Fprintf(&b, "# HELP gormkit_pool_wait_total Waits for a connection.\n# TYPE gormkit_pool_wait_total counter\ngormkit_pool_wait_total %d\n", stats.WaitCount)
+
+		if slos := m.SLOStatus(); len(slos) > 0 {
+			b.WriteString("# HELP gormkit_slo_compliance Share of good statements over the SLO window.\n# TYPE gormkit_slo_compliance gauge\n")
+			for _, slo := range slos {
+				fmt.Fprintf(&b, "gormkit_slo_compliance{slo=%q} %g\n", slo.Name, slo.Compliance)
+			}
+			b.WriteString("# HELP gormkit_slo_burn_rate Error budget burn rate over the SLO window.\n# TYPE gormkit_slo_burn_rate gauge\n")
+			for _, slo := range slos {
+				fmt.Fprintf(&b, "gormkit_slo_burn_rate{slo=%q} %g\n", slo.Name, slo.BurnRate)
+			}
+		}
+
+		if skew := m.clockSkew.Load(); skew != nil {
+			fmt.Fprintf(&b, "# HELP gormkit_clock_skew_seconds Database clock minus local clock.\n# TYPE gormkit_clock_skew_seconds gauge\ngormkit_clock_skew_seconds %g\n", skew.Seconds())
+		}
+
+		w.Write([]byte(b.String()))
+	})
+}
+
+func opLabels(s OperationStats) string {
+	return fmt.Sprintf("table=%q,operation=%q", s.Table, s.Operation)
+}
@@ -0,0 +1,55 @@
+package gormkit_test
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestMetricsByTableAndOperation(t *testing.T) {
+	manager, err := gormkit.New(&gormkit.Config{
+		Driver:   "test",
+		LogLevel: "silent",
+		Metrics:  true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	db := manager.DB()
+	db.AutoMigrate(&User{})
+	db.Create(&User{Name: "A"})
+	db.Create(&User{Name: "B"})
+	var users []User
+	db.Find(&users)
+	db.Model(&User{}).Where("name = ?", "A").Update("name", "C")
+	db.Exec("DELETE FROM missing_table")
+
+	stats := map[string]gormkit.OperationStats{}
+	for _, s := range manager.Metrics().Operations {
+		stats[s.Table+"/"+s.Operation] = s
+	}
+	if stats["users/insert"].Count != 2 || stats["users/select"].Count != 1 || stats["users/update"].Count != 1 {
+		t.Errorf("Unexpected metrics: %+v", stats)
+	}
+	if s := stats["/delete"]; s.Count != 1 || s.Errors != 1 {
+		t.Errorf("Expected failed raw delete, got %+v", s)
+	}
+
+	rec := httptest.NewRecorder()
+	manager.MetricsHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+	for _, want := range []string{
+		`gormkit_queries_total{table="users",operation="insert"} 2`,
+		`gormkit_query_duration_seconds_count{table="users",operation="select"} 1`,
+		`gormkit_pool_open_connections`,
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("Expected %q in:\n%s", want, body)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package gormkit
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Migration is a versioned schema change for what AutoMigrate can't
+// express. It is applied once and recorded in gormkit_schema_migrations.
+//
+//	gormkit.Migration{
+//		ID: "20240501_orders_total_check",
+//		Up: func(s *gormkit.Schema) {
+//			s.AddCheckConstraint("orders", "orders_total_positive", "total >= 0")
+//		},
+//	}
+type Migration struct {
+	ID string // applied in ID order, e.g. a timestamp prefix
+	Up func(s *Schema)
+}
+
+// SchemaMigration records an applied Migration.
+type SchemaMigration struct {
+	ID        string `gorm:"primaryKey;size:191"`
+	AppliedAt time.Time
+}
+
+func (SchemaMigration) TableName() string {
+	return "gormkit_schema_migrations"
+}
+
+// Operation is one statement of a Migration.
+type Operation struct {
+	SQL string
+	// Note explains how the operation was degraded for a dialect that
+	// can't fully express it; SQL is empty when it is skipped entirely.
+	Note string
+	// NoTransaction runs the operation outside the migration's
+	// transaction, as CREATE INDEX CONCURRENTLY requires.
+	NoTransaction bool
+
+	// table and alter are set for ALTER TABLE run by an online schema
+	// change tool.
+	table, alter string
+	// extension is set for CREATE EXTENSION, skipped when already
+	// installed.
+	extension string
+}
+
+// PlannedMigration is a pending Migration and the operations it would run.
+type PlannedMigration struct {
+	ID         string
+	Operations []Operation
+	Warnings   []LintWarning // operations that lock or rewrite tables
+}
+
+// Schema collects the operations of a Migration, rendered for the
+// Manager's dialect.
+type Schema struct {
+	db     *gorm.DB
+	online *OnlineSchemaChange
+	ops    []Operation
+	err    error // first operation that can't be rendered at all
+}
+
+// Exec adds a raw SQL statement.
+func (s *Schema) Exec(sql string) {
+	s.ops = append(s.ops, Operation{SQL: sql})
+}
+
+// ExecOutside adds a raw SQL statement run outside the migration's
+// transaction, for statements that refuse to run in one such as VACUUM or,
+// before Postgres 12, ALTER TYPE ... ADD VALUE.
+func (s *Schema) ExecOutside(sql string) {
+	s.ops = append(s.ops, Operation{SQL: sql, NoTransaction: true})
+}
+
+func (s *Schema) skip(format string, args ...interface{}) {
+	s.ops = append(s.ops, Operation{Note: fmt.Sprintf(format, args...)})
+}
+
+// fail rejects the migration, for operations whose intent would be lost by
+// skipping them.
+func (s *Schema) fail(err error) {
+	if s.err == nil {
+		s.err = err
+	}
+}
+
+// PlanMigrations returns the migrations not applied yet, in the order
+// ApplyMigrations would run them, without changing the database. Each
+// carries warnings for operations known to lock or rewrite tables, with
+// their safe multi-step equivalents; check them in CI to catch migrations
+// that would cause downtime.
+func (m *Manager) PlanMigrations(ctx context.Context, migrations ...Migration) ([]PlannedMigration, error) {
+	db := m.db.WithContext(withoutDeadlineAudit(ctx))
+	if err := ensureTable(db, &SchemaMigration{}); err != nil {
+		return nil, err
+	}
+	var applied []string
+	if err := db.Model(&SchemaMigration{}).Pluck("id", &applied).Error; err != nil {
+		return nil, err
+	}
+	done := make(map[string]bool, len(applied))
+	for _, id := range applied {
+		done[id] = true
+	}
+
+	sorted := append([]Migration(nil), migrations...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+	var plan []PlannedMigration
+	for i, mig := range sorted {
+		if i > 0 && mig.ID == sorted[i-1].ID {
+			return nil, fmt.Errorf("duplicate migration %s", mig.ID)
+		}
+		if done[mig.ID] {
+			continue
+		}
+		s := &Schema{db: db, online: m.config.OnlineSchemaChange}
+		mig.Up(s)
+		if s.err != nil {
+			return nil, fmt.Errorf("migration %s: %w", mig.ID, s.err)
+		}
+		planned := PlannedMigration{ID: mig.ID, Operations: s.ops}
+		planned.Warnings = LintMigration(m.info, planned)
+		plan = append(plan, planned)
+	}
+	return plan, nil
+}
+
+// ApplyMigrations runs the pending migrations in ID order, each in its own
+// transaction together with its record, so a failed migration leaves no
+// trace and is retried on the next run. MySQL can't roll back DDL: keep
+// MySQL migrations to one schema change each.
+func (m *Manager) ApplyMigrations(ctx context.Context, migrations ...Migration) error {
+	plan, err := m.PlanMigrations(ctx, migrations...)
+	if err != nil || len(plan) == 0 {
+		return err
+	}
+	db := m.db.WithContext(withoutDeadlineAudit(ctx))
+	for _, mig := range plan {
+		for _, w := range mig.Warnings {
+			log.Printf("gormkit: migration %s: %s (%s): %s", mig.ID, w.Message, w.Rule, w.Suggestion)
+		}
+		if err := m.applyMigration(db, mig); err != nil {
+			return fmt.Errorf("migration %s failed: %w", mig.ID, err)
+		}
+	}
+	m.afterMigrate(withoutDeadlineAudit(ctx))
+	if m.config.SchemaVersions != (SchemaVersionRange{}) {
+		if err := m.CheckSchemaVersion(withoutDeadlineAudit(ctx)); err != nil {
+			log.Printf("gormkit: %v", err)
+		}
+	}
+	return nil
+}
+
+func (m *Manager) applyMigration(db *gorm.DB, mig PlannedMigration) error {
+	record := func(tx *gorm.DB) error {
+		return tx.Create(&SchemaMigration{ID: mig.ID, AppliedAt: tx.NowFunc()}).Error
+	}
+
+	// Operations that can't run in a transaction split the migration: the
+	// operations between them run in transactions of their own, and the
+	// migration is recorded with the last one. A failure leaves the earlier
+	// steps applied, so write such migrations to be rerunnable.
+	ops := mig.Operations
+	for i := 0; i < len(ops); i++ {
+		if !ops[i].NoTransaction {
+			continue
+		}
+		if i > 0 {
+			if err := db.Transaction(func(tx *gorm.DB) error {
+				return m.runOperations(tx, mig.ID, ops[:i])
+			}); err != nil {
+				return err
+			}
+		}
+		if err := m.runOperations(db, mig.ID, ops[i:i+1]); err != nil {
+			return err
+		}
+		ops, i = ops[i+1:], -1
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		// MySQL commits DDL implicitly, so record the migration only once
+		// it succeeded. Elsewhere record it first: a concurrent runner
+		// blocks on the key and then fails instead of applying the
+		// migration twice.
+		if flavorOf(tx) == "mysql" || len(ops) < len(mig.Operations) {
+			if err := m.runOperations(tx, mig.ID, ops); err != nil {
+				return err
+			}
+			return record(tx)
+		}
+		if err := record(tx); err != nil {
+			return err
+		}
+		return m.runOperations(tx, mig.ID, ops)
+	})
+}
+
+func (m *Manager) runOperations(db *gorm.DB, id string, ops []Operation) error {
+	for _, op := range ops {
+		if op.Note != "" {
+			log.Printf("gormkit: migration %s: %s", id, op.Note)
+		}
+		if op.SQL == "" {
+			continue
+		}
+		if op.extension != "" {
+			if err := ensureExtensions(db, []string{op.extension}); err != nil {
+				return err
+			}
+			continue
+		}
+		if op.alter != "" {
+			if err := m.runOnlineSchemaChange(db.Statement.Context, op.table, op.alter); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := db.Exec(op.SQL).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
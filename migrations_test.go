@@ -0,0 +1,193 @@
+package gormkit_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestApplyMigrations(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &User{})
+	db := manager.DB()
+	ctx := context.Background()
+
+	migrations := []gormkit.Migration{
+		{ID: "002_name_trigger", Up: func(s *gormkit.Schema) {
+			s.CreateTrigger(gormkit.Trigger{
+				Name:   "users_audit",
+				Table:  "users",
+				Timing: "AFTER",
+				Events: []string{"INSERT", "DELETE"},
+				Body:   "INSERT INTO user_audits (name) VALUES ('changed')",
+			})
+			s.CreateTrigger(gormkit.Trigger{
+				Name:   "users_upper",
+				Table:  "users",
+				Timing: "AFTER",
+				Events: []string{"INSERT"},
+				Body:   "UPDATE users SET name = upper(name) WHERE id = NEW.id",
+			})
+			s.AddCheckConstraint("users", "users_name_present", "name <> ''")
+		}},
+		{ID: "001_audits", Up: func(s *gormkit.Schema) {
+			s.Exec("CREATE TABLE user_audits (name TEXT)")
+		}},
+	}
+
+	plan, err := manager.PlanMigrations(ctx, migrations...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(plan) != 2 || plan[0].ID != "001_audits" || len(plan[1].Operations) != 7 {
+		t.Fatalf("Unexpected plan: %+v", plan)
+	}
+	if check := plan[1].Operations[6]; check.SQL != "" || check.Note == "" {
+		t.Errorf("Expected the check constraint to be skipped on sqlite, got %+v", check)
+	}
+
+	if err := manager.ApplyMigrations(ctx, migrations...); err != nil {
+		t.Fatal(err)
+	}
+	db.Create(&User{Name: "alice"})
+	var user User
+	db.First(&user)
+	if user.Name != "ALICE" {
+		t.Errorf("Expected the trigger to run, got %q", user.Name)
+	}
+	var audits int64
+	db.Table("user_audits").Count(&audits)
+	if audits != 1 {
+		t.Errorf("Expected 1 audit row, got %d", audits)
+	}
+
+	plan, err = manager.PlanMigrations(ctx, migrations...)
+	if err != nil || len(plan) != 0 {
+		t.Errorf("Expected nothing pending, got %+v, %v", plan, err)
+	}
+}
+
+func TestApplyMigrationsRollsBack(t *testing.T) {
+	manager := gormkit.NewTestManager(t)
+	ctx := context.Background()
+	broken := gormkit.Migration{ID: "001_broken", Up: func(s *gormkit.Schema) {
+		s.Exec("CREATE TABLE widgets (id INTEGER)")
+		s.Exec("INSERT INTO missing VALUES (1)")
+	}}
+
+	if err := manager.ApplyMigrations(ctx, broken); err == nil {
+		t.Fatal("Expected the migration to fail")
+	}
+	if manager.DB().Migrator().HasTable("widgets") {
+		t.Error("Expected the failed migration to be rolled back")
+	}
+	plan, _ := manager.PlanMigrations(ctx, broken)
+	if len(plan) != 1 {
+		t.Errorf("Expected the migration to stay pending, got %+v", plan)
+	}
+}
+
+func TestApplyMigrationsOutsideTransaction(t *testing.T) {
+	manager := gormkit.NewTestManager(t)
+	db := manager.DB()
+	ctx := context.Background()
+
+	failing := true
+	migration := gormkit.Migration{ID: "001_index", Up: func(s *gormkit.Schema) {
+		s.Exec("CREATE TABLE IF NOT EXISTS widgets (id INTEGER, name TEXT)")
+		s.CreateIndexConcurrently("widgets", "name")
+		s.ExecOutside("VACUUM")
+		if failing {
+			s.Exec("INSERT INTO missing VALUES (1)")
+		}
+	}}
+
+	if err := manager.ApplyMigrations(ctx, migration); err == nil {
+		t.Fatal("Expected the migration to fail")
+	}
+	// Steps before the failed one stay applied, the migration pending.
+	if !db.Migrator().HasIndex("widgets", "idx_widgets_name") {
+		t.Error("Expected the index to be created")
+	}
+	if plan, _ := manager.PlanMigrations(ctx, migration); len(plan) != 1 {
+		t.Errorf("Expected the migration to stay pending, got %+v", plan)
+	}
+
+	failing = false
+	if err := manager.ApplyMigrations(ctx, migration); err != nil {
+		t.Fatal(err)
+	}
+	if plan, _ := manager.PlanMigrations(ctx, migration); len(plan) != 0 {
+		t.Errorf("Expected the migration to be recorded, got %+v", plan)
+	}
+}
+
+func TestAlterTableWithoutOnlineTool(t *testing.T) {
+	// The online schema change tool is MySQL only; elsewhere AlterTable is a
+	// plain ALTER TABLE in the migration's transaction.
+	manager, err := gormkit.New(&gormkit.Config{
+		Driver:             "test",
+		LogLevel:           "silent",
+		OnlineSchemaChange: &gormkit.OnlineSchemaChange{Tool: "gh-ost", Path: "/nonexistent/gh-ost"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+	ctx := context.Background()
+	manager.DB().Exec("CREATE TABLE notes (id INTEGER)")
+
+	migration := gormkit.Migration{ID: "001_notes_body", Up: func(s *gormkit.Schema) {
+		s.AlterTable("notes", "ADD COLUMN body TEXT")
+	}}
+	plan, err := manager.PlanMigrations(ctx, migration)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if op := plan[0].Operations[0]; op.SQL != "ALTER TABLE `notes` ADD COLUMN body TEXT" || op.NoTransaction {
+		t.Errorf("Unexpected operation: %+v", op)
+	}
+	if err := manager.ApplyMigrations(ctx, migration); err != nil {
+		t.Fatal(err)
+	}
+	if !manager.DB().Migrator().HasColumn("notes", "body") {
+		t.Error("Expected the column to be added")
+	}
+}
+
+func TestCreateIndex(t *testing.T) {
+	manager := gormkit.NewTestManager(t)
+	db := manager.DB()
+	ctx := context.Background()
+
+	migration := gormkit.Migration{ID: "001_subscribers", Up: func(s *gormkit.Schema) {
+		s.Exec("CREATE TABLE subscribers (id INTEGER, email TEXT, deleted_at DATETIME)")
+		s.CreateIndex(gormkit.Index{Table: "subscribers", Columns: []string{"email"}, Unique: true, Where: "deleted_at IS NULL"})
+		s.CreateIndex(gormkit.Index{Name: "idx_subscribers_email_ci", Table: "subscribers", Expressions: []string{"lower(email)"}})
+	}}
+	plan, err := manager.PlanMigrations(ctx, migration)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "CREATE UNIQUE INDEX IF NOT EXISTS `idx_subscribers_email` ON `subscribers` (`email`) WHERE deleted_at IS NULL"
+	if got := plan[0].Operations[1].SQL; got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+	if err := manager.ApplyMigrations(ctx, migration); err != nil {
+		t.Fatal(err)
+	}
+
+	db.Exec("INSERT INTO subscribers (id, email, deleted_at) VALUES (1, 'a@example.com', CURRENT_TIMESTAMP)")
+	if err := db.Exec("INSERT INTO subscribers (id, email) VALUES (2, 'a@example.com')").Error; err != nil {
+		t.Errorf("Expected soft-deleted rows to be outside the unique index, got %v", err)
+	}
+	if err := db.Exec("INSERT INTO subscribers (id, email) VALUES (3, 'a@example.com')").Error; err == nil {
+		t.Error("Expected a second live row to violate the unique index")
+	}
+	var detail string
+	db.Raw("EXPLAIN QUERY PLAN SELECT id FROM subscribers WHERE lower(email) = 'a@example.com'").Row().Scan(new(int), new(int), new(int), &detail)
+	if !strings.Contains(detail, "idx_subscribers_email_ci") {
+		t.Errorf("Expected the expression index to be used, got %q", detail)
+	}
+}
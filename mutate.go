@@ -0,0 +1,60 @@
+package gormkit
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// UpdateOne applies updates (a struct or map, as for gorm's Updates) to the
+// single T row matching query and args. It fails with ErrNoRowsAffected when
+// no row matched, and with ErrMultipleRowsAffected when more than one did, in
+// which case the update is rolled back.
+func UpdateOne[T any](ctx context.Context, db *gorm.DB, updates interface{}, query interface{}, args ...interface{}) error {
+	return affectOne(ctx, db, func(tx *gorm.DB) *gorm.DB {
+		return tx.Model(new(T)).Where(query, args...).Updates(updates)
+	}, func(tx *gorm.DB) (int64, error) {
+		var n int64
+		err := tx.Model(new(T)).Scopes(LockForUpdate()).Where(query, args...).Count(&n).Error
+		return n, err
+	})
+}
+
+// DeleteOne deletes the single T row matching query and args, with the same
+// guarantees as UpdateOne.
+func DeleteOne[T any](ctx context.Context, db *gorm.DB, query interface{}, args ...interface{}) error {
+	return affectOne(ctx, db, func(tx *gorm.DB) *gorm.DB {
+		return tx.Where(query, args...).Delete(new(T))
+	}, nil)
+}
+
+// affectOne runs fn in a transaction and checks that it affected one row.
+// MySQL reports the rows an update changed rather than matched, so there
+// count (if given) locks and counts the rows fn will match beforehand, and
+// the check is made on that instead.
+func affectOne(ctx context.Context, db *gorm.DB, fn func(tx *gorm.DB) *gorm.DB, count func(tx *gorm.DB) (int64, error)) error {
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		matched := int64(-1)
+		if count != nil && flavorOf(tx) == "mysql" {
+			var err error
+			if matched, err = count(tx); err != nil {
+				return err
+			}
+		}
+		res := fn(tx)
+		if res.Error != nil {
+			return res.Error
+		}
+		if matched < 0 {
+			matched = res.RowsAffected
+		}
+		switch {
+		case matched == 0:
+			return ErrNoRowsAffected
+		case matched > 1:
+			return fmt.Errorf("%w: %d rows", ErrMultipleRowsAffected, matched)
+		}
+		return nil
+	})
+}
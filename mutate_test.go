@@ -0,0 +1,55 @@
+package gormkit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestUpdateOne(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &User{})
+	db := manager.DB()
+	ctx := context.Background()
+	db.Create(&[]User{{Name: "Ali"}, {Name: "Twin"}, {Name: "Twin"}})
+
+	if err := gormkit.UpdateOne[User](ctx, db, map[string]interface{}{"name": "Reza"}, "name = ?", "Ali"); err != nil {
+		t.Errorf("Expected single update to succeed, got %v", err)
+	}
+	if err := gormkit.UpdateOne[User](ctx, db, map[string]interface{}{"name": "X"}, "name = ?", "Nobody"); !errors.Is(err, gormkit.ErrNoRowsAffected) {
+		t.Errorf("Expected ErrNoRowsAffected, got %v", err)
+	}
+	if err := gormkit.UpdateOne[User](ctx, db, map[string]interface{}{"name": "X"}, "name = ?", "Twin"); !errors.Is(err, gormkit.ErrMultipleRowsAffected) {
+		t.Errorf("Expected ErrMultipleRowsAffected, got %v", err)
+	}
+
+	var count int64
+	db.Model(&User{}).Where("name = ?", "Twin").Count(&count)
+	if count != 2 {
+		t.Errorf("Expected multi-row update to be rolled back, got %d twins", count)
+	}
+}
+
+func TestDeleteOne(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &User{})
+	db := manager.DB()
+	ctx := context.Background()
+	db.Create(&[]User{{Name: "Ali"}, {Name: "Twin"}, {Name: "Twin"}})
+
+	if err := gormkit.DeleteOne[User](ctx, db, "name = ?", "Ali"); err != nil {
+		t.Errorf("Expected single delete to succeed, got %v", err)
+	}
+	if err := gormkit.DeleteOne[User](ctx, db, "name = ?", "Ali"); !errors.Is(err, gormkit.ErrNoRowsAffected) {
+		t.Errorf("Expected ErrNoRowsAffected, got %v", err)
+	}
+	if err := gormkit.DeleteOne[User](ctx, db, "name = ?", "Twin"); !errors.Is(err, gormkit.ErrMultipleRowsAffected) {
+		t.Errorf("Expected ErrMultipleRowsAffected, got %v", err)
+	}
+
+	var count int64
+	db.Model(&User{}).Count(&count)
+	if count != 2 {
+		t.Errorf("Expected multi-row delete to be rolled back, got %d users", count)
+	}
+}
@@ -0,0 +1,73 @@
+package gormkit
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/stdlib"
+)
+
+// Notify publishes payload on a Postgres NOTIFY channel.
+func (m *Manager) Notify(ctx context.Context, channel, payload string) error {
+	if m.config.Driver != "postgres" {
+		return fmt.Errorf("%w: %s", ErrUnsupportedDriver, m.config.Driver)
+	}
+	_, err := m.sqlDB.ExecContext(ctx, "SELECT pg_notify($1, $2)", channel, payload)
+	return err
+}
+
+// Listen calls fn for every notification on a Postgres channel until ctx is
+// done. It holds one pooled connection for its lifetime and reconnects with
+// backoff when that connection is lost. LISTEN needs a session of its own,
+// so it is refused with Config.PoolerCompat.
+func (m *Manager) Listen(ctx context.Context, channel string, fn func(payload string)) error {
+	if m.config.Driver != "postgres" {
+		return fmt.Errorf("%w: %s", ErrUnsupportedDriver, m.config.Driver)
+	}
+	if m.config.PoolerCompat {
+		return fmt.Errorf("%w: LISTEN through a transaction pooler", ErrUnsupportedDriver)
+	}
+
+	for attempt := 0; ; attempt++ {
+		err := m.listenOnce(ctx, channel, fn)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		log.Printf("gormkit: listen on %s interrupted: %v", channel, err)
+		if err := sleepContext(ctx, backoff(attempt)); err != nil {
+			return err
+		}
+	}
+}
+
+func (m *Manager) listenOnce(ctx context.Context, channel string, fn func(payload string)) error {
+	conn, err := m.sqlDB.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	err = conn.Raw(func(driverConn interface{}) error {
+		sc, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("unexpected driver connection %T", driverConn)
+		}
+		pc := sc.Conn()
+		if _, err := pc.Exec(ctx, "LISTEN "+pgx.Identifier{channel}.Sanitize()); err != nil {
+			return err
+		}
+		for {
+			n, err := pc.WaitForNotification(ctx)
+			if err != nil {
+				// The session is still subscribed; make the pool discard it.
+				return errors.Join(err, driver.ErrBadConn)
+			}
+			fn(n.Payload)
+		}
+	})
+	return err
+}
@@ -0,0 +1,116 @@
+package gormkit
+
+import (
+	"context"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Page is one page of a list, with the total needed to link to the others.
+type Page[T any] struct {
+	Items   []T   `json:"items"`
+	Page    int   `json:"page"`
+	PerPage int   `json:"per_page"`
+	Total   int64 `json:"total"`
+}
+
+// PageLinks are the URLs of a page and its neighbours, for HATEOAS-style
+// response bodies. Prev and Next are empty on the first and last page.
+type PageLinks struct {
+	Self  string `json:"self"`
+	First string `json:"first"`
+	Prev  string `json:"prev,omitempty"`
+	Next  string `json:"next,omitempty"`
+	Last  string `json:"last"`
+}
+
+// FindPage loads page (from 1) of perPage rows of db, with the same
+// defaults as Paginate, and counts all of its rows.
+//
+//	page, err := gormkit.FindPage[User](ctx, db.Where("active"), 2, 20)
+//	w.Header().Set("Link", page.LinkHeader(baseURL))
+func FindPage[T any](ctx context.Context, db *gorm.DB, page, perPage int) (*Page[T], error) {
+	if page < 1 {
+		page = 1
+	}
+	if perPage < 1 {
+		perPage = 10
+	}
+	db = db.WithContext(ctx)
+	result := &Page[T]{Items: []T{}, Page: page, PerPage: perPage}
+	if err := db.Session(&gorm.Session{}).Model(new(T)).Count(&result.Total).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Session(&gorm.Session{}).Scopes(Paginate(page, perPage)).Find(&result.Items).Error; err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// TotalPages is the number of pages, at least 1 so an empty list still has
+// a first page to link to.
+func (p *Page[T]) TotalPages() int {
+	pages := int((p.Total + int64(p.PerPage) - 1) / int64(p.PerPage))
+	if pages < 1 {
+		return 1
+	}
+	return pages
+}
+
+// HasPrev reports whether a page precedes p.
+func (p *Page[T]) HasPrev() bool {
+	return p.Page > 1
+}
+
+// HasNext reports whether a page follows p.
+func (p *Page[T]) HasNext() bool {
+	return p.Page < p.TotalPages()
+}
+
+// Links returns the URLs of p and its neighbours: base with its page and
+// per_page query parameters replaced and the others kept, e.g. filters.
+// Pass an absolute URL for absolute links.
+func (p *Page[T]) Links(base *url.URL) PageLinks {
+	links := PageLinks{
+		Self:  p.pageURL(base, p.Page),
+		First: p.pageURL(base, 1),
+		Last:  p.pageURL(base, p.TotalPages()),
+	}
+	if p.HasPrev() {
+		links.Prev = p.pageURL(base, min(p.Page-1, p.TotalPages()))
+	}
+	if p.HasNext() {
+		links.Next = p.pageURL(base, p.Page+1)
+	}
+	return links
+}
+
+// LinkHeader renders the Links of p as an RFC 8288 (formerly RFC 5988)
+// Link header value with first, prev, next and last relations.
+func (p *Page[T]) LinkHeader(base *url.URL) string {
+	links := p.Links(base)
+	var parts []string
+	for _, l := range []struct{ rel, url string }{
+		{"first", links.First},
+		{"prev", links.Prev},
+		{"next", links.Next},
+		{"last", links.Last},
+	} {
+		if l.url != "" {
+			parts = append(parts, "<"+l.url+`>; rel="`+l.rel+`"`)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (p *Page[T]) pageURL(base *url.URL, page int) string {
+	u := *base
+	query := u.Query()
+	query.Set("page", strconv.Itoa(page))
+	query.Set("per_page", strconv.Itoa(p.PerPage))
+	u.RawQuery = query.Encode()
+	return u.String()
+}
@@ -0,0 +1,76 @@
+package gormkit_test
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestFindPage(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &User{})
+	db := manager.DB()
+	for i := 1; i <= 25; i++ {
+		db.Create(&User{Name: fmt.Sprintf("user-%02d", i)})
+	}
+
+	page, err := gormkit.FindPage[User](context.Background(), db.Order("id"), 2, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 25 || len(page.Items) != 10 || page.Items[0].Name != "user-11" {
+		t.Errorf("Expected users 11-20 of 25, got %d items of %d starting at %+v", len(page.Items), page.Total, page.Items[0])
+	}
+	if page.TotalPages() != 3 || !page.HasPrev() || !page.HasNext() {
+		t.Errorf("Expected a middle page of 3, got %d pages", page.TotalPages())
+	}
+
+	base, _ := url.Parse("https://api.example.com/users?active=true&page=9")
+	links := page.Links(base)
+	want := gormkit.PageLinks{
+		Self:  "https://api.example.com/users?active=true&page=2&per_page=10",
+		First: "https://api.example.com/users?active=true&page=1&per_page=10",
+		Prev:  "https://api.example.com/users?active=true&page=1&per_page=10",
+		Next:  "https://api.example.com/users?active=true&page=3&per_page=10",
+		Last:  "https://api.example.com/users?active=true&page=3&per_page=10",
+	}
+	if links != want {
+		t.Errorf("Expected %+v, got %+v", want, links)
+	}
+
+	header := page.LinkHeader(base)
+	wantHeader := `<https://api.example.com/users?active=true&page=1&per_page=10>; rel="first", ` +
+		`<https://api.example.com/users?active=true&page=1&per_page=10>; rel="prev", ` +
+		`<https://api.example.com/users?active=true&page=3&per_page=10>; rel="next", ` +
+		`<https://api.example.com/users?active=true&page=3&per_page=10>; rel="last"`
+	if header != wantHeader {
+		t.Errorf("Expected %s, got %s", wantHeader, header)
+	}
+}
+
+func TestFindPageEdges(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &User{})
+	base, _ := url.Parse("/users")
+
+	empty, err := gormkit.FindPage[User](context.Background(), manager.DB(), 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if empty.Page != 1 || empty.PerPage != 10 || empty.Items == nil || empty.HasNext() || empty.HasPrev() {
+		t.Errorf("Expected an empty first page, got %+v", empty)
+	}
+	if header := empty.LinkHeader(base); header != `</users?page=1&per_page=10>; rel="first", </users?page=1&per_page=10>; rel="last"` {
+		t.Errorf("Unexpected header %s", header)
+	}
+
+	manager.DB().Create(&User{Name: "alice"})
+	beyond, err := gormkit.FindPage[User](context.Background(), manager.DB(), 5, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if links := beyond.Links(base); links.Prev != "/users?page=1&per_page=10" || links.Next != "" {
+		t.Errorf("Expected prev to point at the last page, got %+v", links)
+	}
+}
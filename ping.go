@@ -0,0 +1,74 @@
+package gormkit
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type PingResult struct {
+	Latency         time.Duration
+	Attempts        int
+	ServerVersion   string
+	Replica         bool
+	OpenConnections int
+	InUse           int
+	Idle            int
+}
+
+// PingDetailed pings the database, retrying up to Config.RetryAttempts times,
+// and reports latency, server version, pool usage and whether the server is
+// a read-only replica.
+func (m *Manager) PingDetailed(ctx context.Context) (PingResult, error) {
+	var result PingResult
+	var err error
+	for attempt := 0; attempt < m.config.RetryAttempts; attempt++ {
+		result.Attempts = attempt + 1
+		start := time.Now()
+		if err = m.Ping(ctx); err == nil {
+			result.Latency = time.Since(start)
+			break
+		}
+		if attempt < m.config.RetryAttempts-1 {
+			if sleepErr := sleepContext(ctx, backoff(attempt)); sleepErr != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return result, fmt.Errorf("ping failed after %d attempts: %w", result.Attempts, err)
+	}
+
+	if result.ServerVersion, err = m.serverVersion(ctx); err != nil {
+		return result, err
+	}
+	if result.Replica, err = m.isReplica(ctx); err != nil {
+		return result, err
+	}
+
+	stats := m.Stats()
+	result.OpenConnections = stats.OpenConnections
+	result.InUse = stats.InUse
+	result.Idle = stats.Idle
+	return result, nil
+}
+
+func (m *Manager) serverVersion(ctx context.Context) (string, error) {
+	var query string
+	switch m.config.Driver {
+	case "postgres":
+		// CockroachDB reports a Postgres server_version; its own version
+		// is only in version().
+		query = "SELECT CASE WHEN version() LIKE 'CockroachDB%' THEN version() ELSE current_setting('server_version') END"
+	case "mysql":
+		query = "SELECT VERSION()"
+	default:
+		query = "SELECT sqlite_version()"
+	}
+
+	var version string
+	if err := m.sqlDB.QueryRowContext(ctx, query).Scan(&version); err != nil {
+		return "", fmt.Errorf("failed to query server version: %w", err)
+	}
+	return version, nil
+}
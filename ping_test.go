@@ -0,0 +1,55 @@
+package gormkit_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestPingDetailed(t *testing.T) {
+	manager, err := gormkit.New(&gormkit.Config{
+		Driver:   "test",
+		LogLevel: "silent",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	result, err := manager.PingDetailed(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.ServerVersion == "" {
+		t.Error("Expected server version")
+	}
+	if result.Replica {
+		t.Error("SQLite should never report a replica")
+	}
+	if result.Attempts != 1 {
+		t.Errorf("Expected 1 attempt, got %d", result.Attempts)
+	}
+	t.Logf("Ping: latency=%v version=%s open=%d", result.Latency, result.ServerVersion, result.OpenConnections)
+}
+
+func TestPingDetailedClosed(t *testing.T) {
+	manager, err := gormkit.New(&gormkit.Config{
+		Driver:        "test",
+		LogLevel:      "silent",
+		RetryAttempts: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	manager.Close()
+
+	result, err := manager.PingDetailed(context.Background())
+	if err == nil {
+		t.Fatal("Expected ping error on closed manager")
+	}
+	if result.Attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", result.Attempts)
+	}
+}
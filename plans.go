@@ -0,0 +1,54 @@
+package gormkit
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+const planInvalidationPayload = "invalidate"
+
+// InvalidatePlans clears gorm-kit's prepared statement cache and, on
+// Postgres, closes the idle connections, taking their server-side plans and
+// pgx statement caches with them. This avoids most "cached plan must not
+// change result type" errors after migrations. Connections in use at the
+// time keep their plans until ConnMaxLifetime or ConnMaxIdleTime retires
+// them; running DISCARD PLANS on them isn't possible from here, and
+// DEALLOCATE would break pgx's statement cache.
+func (m *Manager) InvalidatePlans() {
+	m.ClearStmtCache()
+
+	// MySQL and SQLite re-prepare statements on schema changes by
+	// themselves.
+	if m.config.Driver == "postgres" {
+		m.mu.Lock()
+		m.sqlDB.SetMaxIdleConns(0)
+		m.sqlDB.SetMaxIdleConns(m.config.MaxIdleConns)
+		m.mu.Unlock()
+	}
+
+	if m.config.OnPlansInvalidated != nil {
+		m.config.OnPlansInvalidated()
+	}
+}
+
+// ListenPlanInvalidation invalidates plans whenever another instance applies
+// migrations, until ctx is done. It requires Config.PlanInvalidationChannel.
+func (m *Manager) ListenPlanInvalidation(ctx context.Context) error {
+	if m.config.PlanInvalidationChannel == "" {
+		return fmt.Errorf("plan invalidation requires Config.PlanInvalidationChannel")
+	}
+	return m.Listen(ctx, m.config.PlanInvalidationChannel, func(string) {
+		m.InvalidatePlans()
+	})
+}
+
+func (m *Manager) afterMigrate(ctx context.Context) {
+	m.InvalidatePlans()
+
+	if m.config.PlanInvalidationChannel != "" {
+		if err := m.Notify(ctx, m.config.PlanInvalidationChannel, planInvalidationPayload); err != nil {
+			log.Printf("gormkit: failed to notify plan invalidation: %v", err)
+		}
+	}
+}
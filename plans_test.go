@@ -0,0 +1,64 @@
+package gormkit_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestMigrateInvalidatesPlans(t *testing.T) {
+	invalidated := 0
+	manager, err := gormkit.New(&gormkit.Config{
+		Driver:             "test",
+		LogLevel:           "silent",
+		AutoMigrate:        true,
+		PrepareStmt:        true,
+		OnPlansInvalidated: func() { invalidated++ },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	if err := manager.Migrate(&User{}); err != nil {
+		t.Fatal(err)
+	}
+	var users []User
+	manager.DB().Find(&users)
+
+	if err := manager.Migrate(&User{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if invalidated != 2 {
+		t.Errorf("Expected 2 invalidations, got %d", invalidated)
+	}
+	if stats := manager.StmtCacheStats(); stats.Size != 0 {
+		t.Errorf("Expected empty statement cache after migrate, got %d", stats.Size)
+	}
+	if err := manager.DB().Find(&users).Error; err != nil {
+		t.Errorf("Query after invalidation failed: %v", err)
+	}
+}
+
+func TestListenUnsupportedOnSQLite(t *testing.T) {
+	manager, err := gormkit.New(&gormkit.Config{
+		Driver:   "test",
+		LogLevel: "silent",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	err = manager.Listen(context.Background(), "events", func(string) {})
+	if !errors.Is(err, gormkit.ErrUnsupportedDriver) {
+		t.Errorf("Expected ErrUnsupportedDriver, got %v", err)
+	}
+	if err := manager.ListenPlanInvalidation(context.Background()); err == nil || !strings.Contains(err.Error(), "PlanInvalidationChannel") {
+		t.Errorf("Expected an error for the missing channel, got %v", err)
+	}
+}
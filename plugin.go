@@ -0,0 +1,26 @@
+package gormkit
+
+import "gorm.io/gorm"
+
+const pluginName = "gormkit"
+
+// plugin ties a *gorm.DB (and every session derived from it) back to its
+// Manager, so helpers that only receive a *gorm.DB can reach kit state.
+type plugin struct {
+	manager *Manager
+}
+
+func (p *plugin) Name() string {
+	return pluginName
+}
+
+func (p *plugin) Initialize(db *gorm.DB) error {
+	return p.manager.registerCallbacks()
+}
+
+func managerOf(db *gorm.DB) *Manager {
+	if p, ok := db.Config.Plugins[pluginName].(*plugin); ok {
+		return p.manager
+	}
+	return nil
+}
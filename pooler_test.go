@@ -0,0 +1,31 @@
+package gormkit_test
+
+import (
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestPoolerCompat(t *testing.T) {
+	manager, err := gormkit.New(&gormkit.Config{
+		Driver:       "test",
+		LogLevel:     "silent",
+		PoolerCompat: true,
+		PrepareStmt:  true,
+		MaxOpenConns: 8,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	if manager.StmtCacheStats().Enabled {
+		t.Error("Expected prepared statements to be disabled")
+	}
+	if err := manager.DB().Exec("SELECT 1").Error; err != nil {
+		t.Fatal(err)
+	}
+	if stats := manager.Stats(); stats.Idle != 1 {
+		t.Errorf("Expected the connection to be kept idle, got %+v", stats)
+	}
+}
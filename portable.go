@@ -0,0 +1,153 @@
+package gormkit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// PortableSQL builds SQL fragments that render in the dialect of the
+// statement they end up in, so raw SQL written against the kit runs on
+// every supported driver. Use it through SQL.
+type PortableSQL struct{}
+
+// SQL is the entry point to the portable fragments. They are expressions,
+// usable as conditions, clause values and vars of Raw and Exec:
+//
+//	db.Where(gormkit.SQL.ILike("email", "%@corp.com")).Find(&users)
+//	db.Exec("UPDATE jobs SET locked_at = ? WHERE id = ?", gormkit.SQL.Now(), id)
+//	db.Raw("DELETE FROM jobs WHERE done ?", gormkit.SQL.Returning("id")).Scan(&ids)
+var SQL PortableSQL
+
+// portableExpr renders per the dialect of the statement it is built into,
+// which is postgres on CockroachDB and mysql on MariaDB.
+type portableExpr func(stmt *gorm.Statement, flavor string)
+
+func (e portableExpr) Build(builder clause.Builder) {
+	if stmt, ok := builder.(*gorm.Statement); ok {
+		e(stmt, flavorOf(stmt.DB))
+	}
+}
+
+// ILike matches column against pattern ignoring case: ILIKE on Postgres,
+// LIKE on lowered operands elsewhere.
+func (PortableSQL) ILike(column string, pattern interface{}) clause.Expression {
+	return portableExpr(func(stmt *gorm.Statement, flavor string) {
+		if flavor == "postgres" {
+			stmt.WriteQuoted(clause.Column{Name: column})
+			stmt.WriteString(" ILIKE ")
+			stmt.AddVar(stmt, pattern)
+			return
+		}
+		stmt.WriteString("LOWER(")
+		stmt.WriteQuoted(clause.Column{Name: column})
+		stmt.WriteString(") LIKE LOWER(")
+		stmt.AddVar(stmt, pattern)
+		stmt.WriteString(")")
+	})
+}
+
+// Now is the database's current timestamp, with microseconds on MySQL.
+func (PortableSQL) Now() clause.Expression {
+	return portableExpr(func(stmt *gorm.Statement, flavor string) {
+		switch flavor {
+		case "mysql":
+			stmt.WriteString("CURRENT_TIMESTAMP(6)")
+		default:
+			stmt.WriteString("CURRENT_TIMESTAMP")
+		}
+	})
+}
+
+// RandomFunc is a call of the database's random function, e.g. to order
+// by.
+func (PortableSQL) RandomFunc() clause.Expression {
+	return portableExpr(func(stmt *gorm.Statement, flavor string) {
+		switch flavor {
+		case "mysql":
+			stmt.WriteString("RAND()")
+		default:
+			stmt.WriteString("RANDOM()")
+		}
+	})
+}
+
+// Returning is a RETURNING clause of columns, all of them when none are
+// given. Where RETURNING isn't supported (MySQL, MariaDB before 10.5 and
+// SQLite before 3.35) it fails the statement with ErrUnsupportedDriver;
+// MariaDB doesn't support it on UPDATE either, which isn't checked.
+func (PortableSQL) Returning(columns ...string) clause.Expression {
+	return portableExpr(func(stmt *gorm.Statement, _ string) {
+		if info := serverInfoOf(stmt.DB); !info.Supports(FeatureReturning) {
+			stmt.AddError(fmt.Errorf("%w: RETURNING on %s", ErrUnsupportedDriver, info.Flavor))
+			return
+		}
+		stmt.WriteString("RETURNING ")
+		if len(columns) == 0 {
+			stmt.WriteByte('*')
+			return
+		}
+		for i, column := range columns {
+			if i > 0 {
+				stmt.WriteString(", ")
+			}
+			stmt.WriteQuoted(clause.Column{Name: column})
+		}
+	})
+}
+
+// SkipConflicts is an ON CONFLICT clause leaving out rows that conflict with
+// existing ones on key, for inserts into db. gorm renders DO NOTHING on MySQL
+// only for models, so there it updates key to itself instead, which also
+// works for Table inserts of maps.
+//
+//	db.Table("events").Clauses(gormkit.SQL.SkipConflicts(db, "id")).Create(&rows)
+func (PortableSQL) SkipConflicts(db *gorm.DB, key string) clause.OnConflict {
+	if flavorOf(db) == "mysql" {
+		column := clause.Column{Name: key}
+		return clause.OnConflict{DoUpdates: clause.Set{{Column: column, Value: column}}}
+	}
+	return clause.OnConflict{DoNothing: true}
+}
+
+// Placeholder returns the n-th (from 1) bind parameter in db's native
+// style: $n on Postgres, ? elsewhere. gorm rewrites ? itself; this is for
+// SQL run on the underlying *sql.DB.
+func (PortableSQL) Placeholder(db *gorm.DB, n int) string {
+	if flavorOf(db) == "postgres" {
+		return "$" + strconv.Itoa(n)
+	}
+	return "?"
+}
+
+// Rebind rewrites the ? bind parameters of query to db's native style,
+// leaving quoted strings and identifiers alone.
+//
+//	rows, err := sqlDB.QueryContext(ctx, gormkit.SQL.Rebind(db, "SELECT id FROM users WHERE name = ?"), name)
+func (p PortableSQL) Rebind(db *gorm.DB, query string) string {
+	if flavorOf(db) != "postgres" {
+		return query
+	}
+	var b strings.Builder
+	var quote rune
+	n := 0
+	for _, r := range query {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '?':
+			n++
+			b.WriteString(p.Placeholder(db, n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
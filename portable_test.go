@@ -0,0 +1,93 @@
+package gormkit_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func TestPortableSQL(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &User{})
+	db := manager.DB()
+	db.Create(&[]User{{Name: "Alice"}, {Name: "bob"}})
+
+	var names []string
+	db.Model(&User{}).Where(gormkit.SQL.ILike("name", "ALI%")).Pluck("name", &names)
+	if len(names) != 1 || names[0] != "Alice" {
+		t.Errorf("Expected Alice, got %v", names)
+	}
+	var ids []uint
+	err := db.Raw("UPDATE users SET name = ? WHERE name = ? ?", "Bob", "bob", gormkit.SQL.Returning("id")).Scan(&ids).Error
+	if err != nil || len(ids) != 1 {
+		t.Errorf("Expected one id returned, got %v, %v", ids, err)
+	}
+	var now string
+	if err := db.Raw("SELECT ?", gormkit.SQL.Now()).Scan(&now).Error; err != nil || now == "" {
+		t.Errorf("Expected the current time, got %q, %v", now, err)
+	}
+}
+
+func TestPortableSQLDialects(t *testing.T) {
+	dryRun := func() *gorm.Config {
+		return &gorm.Config{DryRun: true, DisableAutomaticPing: true}
+	}
+	pg, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), dryRun())
+	if err != nil {
+		t.Fatal(err)
+	}
+	my, err := gorm.Open(mysql.New(mysql.Config{DSN: "app@tcp(localhost)/app", SkipInitializeWithVersion: true}), dryRun())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		db   *gorm.DB
+		want string
+	}{
+		{pg, `SELECT * FROM "users" WHERE "name" ILIKE 'a%' AND created_at < CURRENT_TIMESTAMP ORDER BY RANDOM()`},
+		{my, "SELECT * FROM `users` WHERE LOWER(`name`) LIKE LOWER('a%') AND created_at < CURRENT_TIMESTAMP(6) ORDER BY RAND()"},
+	}
+	for _, tt := range tests {
+		sql := tt.db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+			return tx.Where(gormkit.SQL.ILike("name", "a%")).Where("created_at < ?", gormkit.SQL.Now()).
+				Clauses(clause.OrderBy{Expression: gormkit.SQL.RandomFunc()}).Find(&[]User{})
+		})
+		if sql != tt.want {
+			t.Errorf("Expected %q, got %q", tt.want, sql)
+		}
+	}
+
+	if sql := pg.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Exec("DELETE FROM jobs ?", gormkit.SQL.Returning("id", "name"))
+	}); sql != `DELETE FROM jobs RETURNING "id", "name"` {
+		t.Errorf("Unexpected returning: %q", sql)
+	}
+	if err := my.Exec("DELETE FROM jobs ?", gormkit.SQL.Returning()).Error; !errors.Is(err, gormkit.ErrUnsupportedDriver) {
+		t.Errorf("Expected ErrUnsupportedDriver on mysql, got %v", err)
+	}
+
+	rows := []map[string]interface{}{{"id": 1, "name": "a"}}
+	if sql := my.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Table("users").Clauses(gormkit.SQL.SkipConflicts(tx, "id")).Create(&rows)
+	}); sql != "INSERT INTO `users` (`id`,`name`) VALUES (1,'a') ON DUPLICATE KEY UPDATE `id`=`id`" {
+		t.Errorf("Unexpected mysql skip: %q", sql)
+	}
+	if sql := pg.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Table("users").Clauses(gormkit.SQL.SkipConflicts(tx, "id")).Create(&rows)
+	}); sql != `INSERT INTO "users" ("id","name") VALUES (1,'a') ON CONFLICT DO NOTHING` {
+		t.Errorf("Unexpected postgres skip: %q", sql)
+	}
+
+	query := `SELECT '?' FROM "a?" WHERE x = ? AND y = ?`
+	if got := gormkit.SQL.Rebind(pg, query); got != `SELECT '?' FROM "a?" WHERE x = $1 AND y = $2` {
+		t.Errorf("Unexpected rebind: %q", got)
+	}
+	if got := gormkit.SQL.Rebind(my, query); got != query {
+		t.Errorf("Expected mysql query unchanged, got %q", got)
+	}
+}
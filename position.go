@@ -0,0 +1,200 @@
+package gormkit
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Ordering configures a model whose rows form user-sortable lists.
+type Ordering struct {
+	Column string   // integer position column, default "position"
+	Scope  []string // columns splitting rows into separate lists, e.g. "board_id"
+	Gap    int64    // spacing of positions after renormalizing, default 1024
+}
+
+// Ordered is implemented by sortable models that need more than the
+// Ordering defaults.
+//
+//	func (Card) Ordering() gormkit.Ordering {
+//		return gormkit.Ordering{Scope: []string{"column_id"}}
+//	}
+type Ordered interface {
+	Ordering() Ordering
+}
+
+// MoveBefore moves item, a saved row, right before target in their list.
+//
+// Positions are spaced by Ordering.Gap, so a move usually updates only item,
+// taking the midpoint between its new neighbours; when they are adjacent the
+// whole list is renumbered. The list is locked for the move, so concurrent
+// moves don't interleave.
+func MoveBefore[T any](ctx context.Context, db *gorm.DB, item, target *T) error {
+	return move(ctx, db, item, func(ids []interface{}) (int, error) {
+		return indexOf(ctx, db, ids, target)
+	})
+}
+
+// MoveAfter moves item right after target in their list.
+func MoveAfter[T any](ctx context.Context, db *gorm.DB, item, target *T) error {
+	return move(ctx, db, item, func(ids []interface{}) (int, error) {
+		i, err := indexOf(ctx, db, ids, target)
+		return i + 1, err
+	})
+}
+
+// MoveToIndex moves item to the zero-based index of its list, or to its
+// end when index is past it; moving a new row there gives it a position.
+func MoveToIndex[T any](ctx context.Context, db *gorm.DB, item *T, index int) error {
+	return move(ctx, db, item, func(ids []interface{}) (int, error) {
+		return min(max(index, 0), len(ids)), nil
+	})
+}
+
+type orderingSchema struct {
+	ordering Ordering
+	table    string
+	pk       *schema.Field
+	position *schema.Field
+	scope    []*schema.Field
+}
+
+func parseOrdering(db *gorm.DB, model interface{}) (*orderingSchema, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, err
+	}
+	ord := &orderingSchema{table: stmt.Schema.Table, pk: stmt.Schema.PrioritizedPrimaryField}
+	if o, ok := model.(Ordered); ok {
+		ord.ordering = o.Ordering()
+	}
+	if ord.ordering.Column == "" {
+		ord.ordering.Column = "position"
+	}
+	if ord.ordering.Gap <= 0 {
+		ord.ordering.Gap = 1024
+	}
+	if ord.pk == nil {
+		return nil, fmt.Errorf("%s needs a single primary key", ord.table)
+	}
+	for _, name := range append([]string{ord.ordering.Column}, ord.ordering.Scope...) {
+		field := stmt.Schema.LookUpField(name)
+		if field == nil {
+			return nil, fmt.Errorf("%s has no column %s", ord.table, name)
+		}
+		if ord.position == nil {
+			ord.position = field
+		} else {
+			ord.scope = append(ord.scope, field)
+		}
+	}
+	return ord, nil
+}
+
+// move places item at the index returned by at, given the ids of the other
+// rows of its list in order.
+func move[T any](ctx context.Context, db *gorm.DB, item *T, at func(ids []interface{}) (int, error)) error {
+	db = db.WithContext(ctx)
+	ord, err := parseOrdering(db, item)
+	if err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(item).Elem()
+	id, zero := ord.pk.ValueOf(ctx, rv)
+	if zero {
+		return fmt.Errorf("%s has no primary key value", ord.table)
+	}
+
+	var position int64
+	err = db.Transaction(func(tx *gorm.DB) error {
+		list := tx.Model(new(T)).Scopes(LockForUpdate())
+		for _, field := range ord.scope {
+			value, _ := field.ValueOf(ctx, rv)
+			list = list.Where(clause.Eq{Column: clause.Column{Name: field.DBName}, Value: value})
+		}
+		rows, err := list.Select(fmt.Sprintf("%s, %s",
+			tx.Statement.Quote(ord.pk.DBName), tx.Statement.Quote(ord.position.DBName))).
+			Order(clause.OrderBy{Columns: []clause.OrderByColumn{
+				{Column: clause.Column{Name: ord.position.DBName}},
+				{Column: clause.Column{Name: ord.pk.DBName}},
+			}}).Rows()
+		if err != nil {
+			return err
+		}
+		var ids []interface{}
+		var positions []int64
+		for rows.Next() {
+			var rowID interface{}
+			var p int64
+			if err := rows.Scan(&rowID, &p); err != nil {
+				rows.Close()
+				return err
+			}
+			if idKey(rowID) != idKey(id) {
+				ids = append(ids, rowID)
+				positions = append(positions, p)
+			}
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+		i, err := at(ids)
+		if err != nil {
+			return err
+		}
+
+		prev, next := int64(0), ord.ordering.Gap
+		if i > 0 {
+			prev = positions[i-1]
+			next = prev + 2*ord.ordering.Gap
+		}
+		if i < len(positions) {
+			next = positions[i]
+		}
+		if next-prev >= 2 {
+			position = prev + (next-prev)/2
+			return setPosition[T](tx, ord, id, position)
+		}
+
+		// No room between the neighbours: renumber the whole list.
+		ids = append(ids[:i], append([]interface{}{id}, ids[i:]...)...)
+		for j, rowID := range ids {
+			p := int64(j+1) * ord.ordering.Gap
+			if idKey(rowID) == idKey(id) {
+				position = p
+			}
+			if err := setPosition[T](tx, ord, rowID, p); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return ord.position.Set(ctx, rv, position)
+}
+
+func setPosition[T any](tx *gorm.DB, ord *orderingSchema, id interface{}, position int64) error {
+	return tx.Model(new(T)).Where(clause.Eq{Column: clause.Column{Name: ord.pk.DBName}, Value: id}).
+		UpdateColumn(ord.position.DBName, position).Error
+}
+
+func indexOf[T any](ctx context.Context, db *gorm.DB, ids []interface{}, target *T) (int, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(target); err != nil {
+		return 0, err
+	}
+	id, _ := stmt.Schema.PrioritizedPrimaryField.ValueOf(ctx, reflect.ValueOf(target).Elem())
+	for i, rowID := range ids {
+		if idKey(rowID) == idKey(id) {
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("%v is not in the same list", id)
+}
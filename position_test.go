@@ -0,0 +1,72 @@
+package gormkit_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+type Card struct {
+	ID       uint
+	BoardID  uint
+	Position int64
+	Name     string
+}
+
+func (Card) Ordering() gormkit.Ordering {
+	return gormkit.Ordering{Scope: []string{"board_id"}, Gap: 4}
+}
+
+func boardOrder(t *testing.T, manager *gormkit.Manager, board uint) []string {
+	t.Helper()
+	var names []string
+	manager.DB().Model(&Card{}).Where("board_id = ?", board).Order("position, id").Pluck("name", &names)
+	return names
+}
+
+func TestMoveCards(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &Card{})
+	db := manager.DB()
+	ctx := context.Background()
+
+	cards := []Card{{BoardID: 1, Name: "a"}, {BoardID: 1, Name: "b"}, {BoardID: 1, Name: "c"}, {BoardID: 2, Name: "other"}}
+	db.Create(&cards)
+	for i := range cards[:3] {
+		if err := gormkit.MoveToIndex(ctx, db, &cards[i], i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := boardOrder(t, manager, 1); len(got) != 3 || got[0] != "a" || got[1] != "b" || got[2] != "c" {
+		t.Fatalf("Unexpected order: %v", got)
+	}
+
+	if err := gormkit.MoveBefore(ctx, db, &cards[2], &cards[0]); err != nil {
+		t.Fatal(err)
+	}
+	if err := gormkit.MoveAfter(ctx, db, &cards[0], &cards[1]); err != nil {
+		t.Fatal(err)
+	}
+	if got := boardOrder(t, manager, 1); len(got) != 3 || got[0] != "c" || got[1] != "b" || got[2] != "a" {
+		t.Errorf("Unexpected order: %v", got)
+	}
+
+	// Repeated moves into the same slot exhaust the gap and renumber the list.
+	for i := 0; i < 5; i++ {
+		if err := gormkit.MoveToIndex(ctx, db, &cards[i%3], 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var saved Card
+	db.First(&saved, cards[1].ID)
+	if saved.Position != cards[1].Position {
+		t.Errorf("Expected position %d, got %d", cards[1].Position, saved.Position)
+	}
+	if got := boardOrder(t, manager, 1); len(got) != 3 || got[0] != "a" || got[1] != "b" || got[2] != "c" {
+		t.Errorf("Unexpected order after renumbering: %v", got)
+	}
+
+	if err := gormkit.MoveBefore(ctx, db, &cards[0], &cards[3]); err == nil {
+		t.Error("Expected an error moving next to a card of another board")
+	}
+}
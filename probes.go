@@ -0,0 +1,123 @@
+package gormkit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const defaultProbeTimeout = 2 * time.Second
+
+var errManagerClosed = errors.New("manager is closed")
+
+type ReadinessOptions struct {
+	// MaxPoolUtilization is the fraction of MaxOpenConns in use at which the
+	// service stops accepting traffic. Defaults to 1 (pool saturated).
+	MaxPoolUtilization float64
+	// RequirePrimary reports not ready while connected to a read-only replica.
+	RequirePrimary bool
+	Timeout        time.Duration
+}
+
+// StartupProbe succeeds once the database is reachable and the tables for
+// models have been migrated.
+func (m *Manager) StartupProbe(models ...interface{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), defaultProbeTimeout)
+		defer cancel()
+		writeProbe(w, m.checkReady(ctx, models...))
+	})
+}
+
+// ReadinessProbe fails while the database is unreachable, the pool is
+// saturated, the clock skew exceeds Config.MaxClockSkew, or (with
+// RequirePrimary) only a replica is available. Outcomes are kept for
+// HealthHistory.
+func (m *Manager) ReadinessProbe(opts ReadinessOptions) http.Handler {
+	if opts.MaxPoolUtilization <= 0 {
+		opts.MaxPoolUtilization = 1
+	}
+	if opts.Timeout == 0 {
+		opts.Timeout = defaultProbeTimeout
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), opts.Timeout)
+		defer cancel()
+		err := m.checkReadiness(ctx, opts)
+		m.health.record(err)
+		writeProbe(w, err)
+	})
+}
+
+// LivenessProbe only fails on states a restart would fix; a temporarily
+// unreachable database is a readiness concern, not a liveness one.
+func (m *Manager) LivenessProbe() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		if m.closed.Load() {
+			err = errManagerClosed
+		}
+		writeProbe(w, err)
+	})
+}
+
+func (m *Manager) checkReadiness(ctx context.Context, opts ReadinessOptions) error {
+	if m.closed.Load() {
+		return errManagerClosed
+	}
+	if err := m.Ping(ctx); err != nil {
+		return err
+	}
+
+	stats := m.Stats()
+	if stats.MaxOpenConnections > 0 &&
+		float64(stats.InUse) >= float64(stats.MaxOpenConnections)*opts.MaxPoolUtilization {
+		return fmt.Errorf("connection pool saturated: %d/%d in use", stats.InUse, stats.MaxOpenConnections)
+	}
+
+	if skew := m.clockSkew.Load(); skew != nil && m.config.MaxClockSkew > 0 && skewExceeds(*skew, m.config.MaxClockSkew) {
+		return fmt.Errorf("database clock skew %v exceeds %v", *skew, m.config.MaxClockSkew)
+	}
+
+	if opts.RequirePrimary {
+		replica, err := m.isReplica(ctx)
+		if err != nil {
+			return err
+		}
+		if replica {
+			return errors.New("connected to a read-only replica")
+		}
+	}
+	return nil
+}
+
+func (m *Manager) isReplica(ctx context.Context) (bool, error) {
+	var query string
+	switch m.config.Driver {
+	case "postgres":
+		query = "SELECT pg_is_in_recovery()"
+	case "mysql":
+		query = "SELECT @@global.read_only = 1"
+	default:
+		return false, nil
+	}
+
+	var replica bool
+	if err := m.sqlDB.QueryRowContext(ctx, query).Scan(&replica); err != nil {
+		return false, fmt.Errorf("failed to detect replica: %w", err)
+	}
+	return replica, nil
+}
+
+func writeProbe(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	if err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(map[string]string{"status": "unavailable", "error": err.Error()})
+		return
+	}
+	json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+}
@@ -0,0 +1,50 @@
+package gormkit_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestProbes(t *testing.T) {
+	manager, err := gormkit.New(&gormkit.Config{
+		Driver:   "test",
+		LogLevel: "silent",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	probe := func(h http.Handler) int {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		return rec.Code
+	}
+
+	if code := probe(manager.StartupProbe(&User{})); code != http.StatusServiceUnavailable {
+		t.Errorf("Startup before migration: expected 503, got %d", code)
+	}
+
+	manager.DB().AutoMigrate(&User{})
+
+	if code := probe(manager.StartupProbe(&User{})); code != http.StatusOK {
+		t.Errorf("Startup after migration: expected 200, got %d", code)
+	}
+	if code := probe(manager.ReadinessProbe(gormkit.ReadinessOptions{RequirePrimary: true})); code != http.StatusOK {
+		t.Errorf("Readiness: expected 200, got %d", code)
+	}
+	if code := probe(manager.LivenessProbe()); code != http.StatusOK {
+		t.Errorf("Liveness: expected 200, got %d", code)
+	}
+
+	manager.Close()
+
+	if code := probe(manager.LivenessProbe()); code != http.StatusServiceUnavailable {
+		t.Errorf("Liveness after close: expected 503, got %d", code)
+	}
+	if code := probe(manager.ReadinessProbe(gormkit.ReadinessOptions{})); code != http.StatusServiceUnavailable {
+		t.Errorf("Readiness after close: expected 503, got %d", code)
+	}
+}
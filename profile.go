@@ -0,0 +1,50 @@
+package gormkit
+
+import "fmt"
+
+// applyProfile fills the fields left unset in cfg with the curated defaults
+// of cfg.Profile, never overriding a value the caller set. A false bool
+// can't be told from an unset one, so DisallowDestructiveMigrations opts the
+// dev and test profiles back into the destructive migration guard:
+//
+//	dev:  verbose logging, deadline audit warnings, destructive migrations allowed
+//	test: in-memory sqlite, silent logging, destructive migrations allowed
+//	prod: silent logging, a small pool; destructive migrations stay refused
+//	      unless AllowDestructiveMigrations is set
+func applyProfile(cfg *Config) error {
+	switch cfg.Profile {
+	case "":
+	case "dev":
+		setDefault(&cfg.LogLevel, "info")
+		setDefault(&cfg.DeadlineAudit, "warn")
+		setDefault(&cfg.AllowDestructiveMigrations, true)
+	case "test":
+		setDefault(&cfg.Driver, "test")
+		setDefault(&cfg.LogLevel, "silent")
+		setDefault(&cfg.AllowDestructiveMigrations, true)
+	case "prod":
+		setDefault(&cfg.LogLevel, "silent")
+		setDefault(&cfg.MaxOpenConns, 10)
+		setDefault(&cfg.MaxIdleConns, 2)
+	default:
+		return fmt.Errorf("unknown profile: %s", cfg.Profile)
+	}
+	return nil
+}
+
+func setDefault[T comparable](field *T, value T) {
+	var zero T
+	if *field == zero {
+		*field = value
+	}
+}
+
+// DropTables drops the tables of models. It is refused with
+// ErrDestructiveMigration unless Config.AllowDestructiveMigrations is set
+// and DisallowDestructiveMigrations isn't.
+func (m *Manager) DropTables(models ...interface{}) error {
+	if !m.config.AllowDestructiveMigrations || m.config.DisallowDestructiveMigrations {
+		return ErrDestructiveMigration
+	}
+	return m.internalDB().Migrator().DropTable(models...)
+}
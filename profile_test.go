@@ -0,0 +1,80 @@
+package gormkit_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestProfileTest(t *testing.T) {
+	cfg := &gormkit.Config{Profile: "test"}
+	manager, err := gormkit.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	if cfg.Driver != "test" || cfg.LogLevel != "silent" {
+		t.Errorf("Unexpected test profile defaults: driver=%s log=%s", cfg.Driver, cfg.LogLevel)
+	}
+
+	manager.DB().AutoMigrate(&User{})
+	if err := manager.DropTables(&User{}); err != nil {
+		t.Errorf("Test profile should allow DropTables: %v", err)
+	}
+}
+
+func TestProfileProd(t *testing.T) {
+	cfg := &gormkit.Config{Profile: "prod", Driver: "test", MaxIdleConns: 4}
+	manager, err := gormkit.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	if cfg.MaxOpenConns != 10 || cfg.MaxIdleConns != 4 || cfg.LogLevel != "silent" {
+		t.Errorf("Unexpected prod profile defaults: %+v", cfg)
+	}
+
+	manager.DB().AutoMigrate(&User{})
+	if err := manager.DropTables(&User{}); !errors.Is(err, gormkit.ErrDestructiveMigration) {
+		t.Errorf("Expected ErrDestructiveMigration, got %v", err)
+	}
+}
+
+func TestProfileKeepsExplicitValues(t *testing.T) {
+	cfg := &gormkit.Config{Profile: "prod", Driver: "test", LogLevel: "warn", AllowDestructiveMigrations: true}
+	manager, err := gormkit.New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	if cfg.LogLevel != "warn" {
+		t.Errorf("Expected LogLevel to stay warn, got %s", cfg.LogLevel)
+	}
+	manager.DB().AutoMigrate(&User{})
+	if err := manager.DropTables(&User{}); err != nil {
+		t.Errorf("Expected explicit AllowDestructiveMigrations to survive the prod profile: %v", err)
+	}
+}
+
+func TestProfileDisallowDestructiveMigrations(t *testing.T) {
+	manager, err := gormkit.New(&gormkit.Config{Profile: "test", DisallowDestructiveMigrations: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	manager.DB().AutoMigrate(&User{})
+	if err := manager.DropTables(&User{}); !errors.Is(err, gormkit.ErrDestructiveMigration) {
+		t.Errorf("Expected ErrDestructiveMigration under the test profile, got %v", err)
+	}
+}
+
+func TestProfileUnknown(t *testing.T) {
+	if _, err := gormkit.New(&gormkit.Config{Profile: "staging", Driver: "test"}); err == nil {
+		t.Error("Expected error for unknown profile")
+	}
+}
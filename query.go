@@ -0,0 +1,136 @@
+package gormkit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// QueryOption tightens how Query and QueryOne map result columns. Options are
+// passed among the query arguments and are not bound as parameters.
+type QueryOption int
+
+const (
+	// StrictColumns fails when a result column has no matching field in T.
+	StrictColumns QueryOption = iota + 1
+	// RequireAllFields fails when a column of T is missing from the result.
+	RequireAllFields
+)
+
+// Query runs a raw SQL query and scans every row into a T, which may be a
+// struct or a single-column primitive.
+func Query[T any](ctx context.Context, db *gorm.DB, sqlText string, args ...interface{}) ([]T, error) {
+	var out []T
+	err := queryRows[T](ctx, db, sqlText, args, func(v T) bool {
+		out = append(out, v)
+		return true
+	})
+	return out, err
+}
+
+// QueryOne is like Query but returns only the first row, or
+// gorm.ErrRecordNotFound when the query yields none.
+func QueryOne[T any](ctx context.Context, db *gorm.DB, sqlText string, args ...interface{}) (T, error) {
+	var out T
+	found := false
+	err := queryRows[T](ctx, db, sqlText, args, func(v T) bool {
+		out, found = v, true
+		return false
+	})
+	if err == nil && !found {
+		err = gorm.ErrRecordNotFound
+	}
+	return out, err
+}
+
+func queryRows[T any](ctx context.Context, db *gorm.DB, sqlText string, args []interface{}, yield func(T) bool) error {
+	var opts []QueryOption
+	var vars []interface{}
+	for _, arg := range args {
+		if opt, ok := arg.(QueryOption); ok {
+			opts = append(opts, opt)
+			continue
+		}
+		vars = append(vars, arg)
+	}
+
+	rows, err := db.WithContext(ctx).Raw(sqlText, vars...).Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	var zero T
+	scanStruct := isStructDest(reflect.TypeOf(zero))
+	if err := checkColumns(db, rows, &zero, scanStruct, opts); err != nil {
+		return err
+	}
+
+	for rows.Next() {
+		var v T
+		if scanStruct {
+			err = db.ScanRows(rows, &v)
+		} else {
+			err = rows.Scan(&v)
+		}
+		if err != nil {
+			return err
+		}
+		if !yield(v) {
+			break
+		}
+	}
+	return rows.Err()
+}
+
+func isStructDest(t reflect.Type) bool {
+	if t == nil || t.Kind() != reflect.Struct {
+		return false
+	}
+	return t != reflect.TypeOf(time.Time{}) && !reflect.PointerTo(t).Implements(reflect.TypeOf((*sql.Scanner)(nil)).Elem())
+}
+
+func checkColumns(db *gorm.DB, rows *sql.Rows, dest interface{}, scanStruct bool, opts []QueryOption) error {
+	if len(opts) == 0 {
+		return nil
+	}
+	columns, err := rows.Columns()
+	if err != nil {
+		return err
+	}
+
+	if !scanStruct {
+		if len(columns) != 1 {
+			return fmt.Errorf("expected 1 column for %T, got %d: %s", dest, len(columns), strings.Join(columns, ", "))
+		}
+		return nil
+	}
+
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(dest); err != nil {
+		return err
+	}
+	for _, opt := range opts {
+		switch opt {
+		case StrictColumns:
+			for _, column := range columns {
+				if stmt.Schema.LookUpField(column) == nil {
+					return fmt.Errorf("column %q has no matching field in %s", column, stmt.Schema.Name)
+				}
+			}
+		case RequireAllFields:
+			for _, name := range stmt.Schema.DBNames {
+				if !slices.Contains(columns, name) {
+					return fmt.Errorf("field %s.%s is missing from the result", stmt.Schema.Name, name)
+				}
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package gormkit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+	"gorm.io/gorm"
+)
+
+type userReport struct {
+	Name  string
+	Total int
+}
+
+func TestQueryTyped(t *testing.T) {
+	manager, err := gormkit.New(&gormkit.Config{
+		Driver:   "test",
+		LogLevel: "silent",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	db := manager.DB()
+	db.AutoMigrate(&User{})
+	db.Create(&[]User{{Name: "A"}, {Name: "A"}, {Name: "B"}})
+	ctx := context.Background()
+
+	reports, err := gormkit.Query[userReport](ctx, db,
+		"SELECT name, COUNT(*) AS total FROM users GROUP BY name ORDER BY name")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 2 || reports[0].Name != "A" || reports[0].Total != 2 {
+		t.Errorf("Unexpected reports: %+v", reports)
+	}
+
+	names, err := gormkit.Query[string](ctx, db, "SELECT name FROM users WHERE name = ?", "A")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) != 2 {
+		t.Errorf("Expected 2 names, got %v", names)
+	}
+
+	count, err := gormkit.QueryOne[int64](ctx, db, "SELECT COUNT(*) FROM users")
+	if err != nil || count != 3 {
+		t.Errorf("Expected count 3, got %d (%v)", count, err)
+	}
+
+	_, err = gormkit.QueryOne[userReport](ctx, db, "SELECT name, 1 AS total FROM users WHERE name = ?", "missing")
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected ErrRecordNotFound, got %v", err)
+	}
+}
+
+func TestQueryStrictColumns(t *testing.T) {
+	manager, err := gormkit.New(&gormkit.Config{
+		Driver:   "test",
+		LogLevel: "silent",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	db := manager.DB()
+	db.AutoMigrate(&User{})
+	db.Create(&User{Name: "A"})
+	ctx := context.Background()
+
+	_, err = gormkit.Query[userReport](ctx, db, "SELECT name, 1 AS total, id FROM users", gormkit.StrictColumns)
+	if err == nil {
+		t.Error("Expected error for unmapped column with StrictColumns")
+	}
+
+	_, err = gormkit.Query[userReport](ctx, db, "SELECT name FROM users WHERE id = ?", gormkit.RequireAllFields, 1)
+	if err == nil {
+		t.Error("Expected error for missing field with RequireAllFields")
+	}
+
+	if _, err := gormkit.Query[userReport](ctx, db, "SELECT name, 1 AS total FROM users",
+		gormkit.StrictColumns, gormkit.RequireAllFields); err != nil {
+		t.Errorf("Exact match should pass: %v", err)
+	}
+}
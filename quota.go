@@ -0,0 +1,175 @@
+package gormkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"gorm.io/gorm"
+)
+
+// maxQuotaKeys bounds the buckets kept per quota; idle full ones, or else
+// the least recently used, are dropped beyond it.
+const maxQuotaKeys = 10000
+
+// Quota limits the statements per second of a table, or of each tenant,
+// with a token bucket, so one noisy feature or tenant can't saturate a
+// shared database.
+type Quota struct {
+	Table     string  // "" for every table
+	Operation string  // "read" (selects), "write" or "" for both
+	Rate      float64 // statements per second, must be positive
+	Burst     int     // statements allowed at once, default Rate rounded up
+	// Key gives each key its own bucket, e.g. the tenant of the context;
+	// statements with an empty key are not limited. Without Key all
+	// matching statements share one bucket.
+	Key func(ctx context.Context) string
+	// Mode is "delay" (default) to wait for a token, up to the context's
+	// deadline, or "reject" to fail with ErrQuotaExceeded right away.
+	Mode string
+}
+
+type quotaLimiter struct {
+	quota   Quota
+	mu      sync.Mutex
+	buckets map[string]*quotaBucket
+}
+
+type quotaBucket struct {
+	*rate.Limiter
+	used time.Time
+}
+
+func validateQuotas(quotas []Quota) error {
+	for i, q := range quotas {
+		if q.Rate <= 0 {
+			return fmt.Errorf("quota %d: rate must be positive, got %g", i, q.Rate)
+		}
+		switch q.Operation {
+		case "", "read", "write":
+		default:
+			return fmt.Errorf("quota %d: unknown operation %q", i, q.Operation)
+		}
+		switch q.Mode {
+		case "", "delay", "reject":
+		default:
+			return fmt.Errorf("quota %d: unknown mode %q", i, q.Mode)
+		}
+	}
+	return nil
+}
+
+func newQuotaLimiter(q Quota) *quotaLimiter {
+	if q.Burst <= 0 {
+		q.Burst = max(1, int(q.Rate+0.999))
+	}
+	return &quotaLimiter{quota: q, buckets: make(map[string]*quotaBucket)}
+}
+
+func (l *quotaLimiter) limiter(key string) *rate.Limiter {
+	now := time.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= maxQuotaKeys {
+			l.sweep()
+		}
+		b = &quotaBucket{Limiter: rate.NewLimiter(rate.Limit(l.quota.Rate), l.quota.Burst)}
+		l.buckets[key] = b
+	}
+	b.used = now
+	return b.Limiter
+}
+
+// sweep drops the buckets that have refilled, which are as good as new, or
+// the least recently used one when none has.
+func (l *quotaLimiter) sweep() {
+	var oldest string
+	for k, b := range l.buckets {
+		if b.Tokens() >= float64(l.quota.Burst) {
+			delete(l.buckets, k)
+		} else if oldest == "" || b.used.Before(l.buckets[oldest].used) {
+			oldest = k
+		}
+	}
+	if len(l.buckets) >= maxQuotaKeys {
+		delete(l.buckets, oldest)
+	}
+}
+
+// take waits for or claims a token of the statement's bucket.
+func (l *quotaLimiter) take(db *gorm.DB, operation string) {
+	q := l.quota
+	if (q.Table != "" && q.Table != db.Statement.Table) || (q.Operation != "" && q.Operation != operation) {
+		return
+	}
+	ctx := db.Statement.Context
+	var key string
+	if q.Key != nil {
+		if key = q.Key(ctx); key == "" {
+			return
+		}
+	}
+	b := l.limiter(key)
+	if q.Mode == "reject" {
+		if !b.Allow() {
+			db.AddError(fmt.Errorf("%w: %s", ErrQuotaExceeded, l.describe(key)))
+		}
+		return
+	}
+	if err := b.Wait(ctx); err != nil {
+		db.AddError(fmt.Errorf("%w: %s: %w", ErrQuotaExceeded, l.describe(key), err))
+	}
+}
+
+func (l *quotaLimiter) describe(key string) string {
+	s := fmt.Sprintf("%g statements/s", l.quota.Rate)
+	if l.quota.Table != "" {
+		s += " on " + l.quota.Table
+	}
+	if key != "" {
+		s += " for " + key
+	}
+	return s
+}
+
+// registerQuotas enforces Config.Quotas before every statement; reads are
+// selects, and raw SQL is classified by its leading keyword.
+func (m *Manager) registerQuotas() error {
+	limiters := make([]*quotaLimiter, len(m.config.Quotas))
+	for i, q := range m.config.Quotas {
+		limiters[i] = newQuotaLimiter(q)
+	}
+	enforce := func(operation string) func(*gorm.DB) {
+		return func(db *gorm.DB) {
+			if db.Error != nil {
+				return
+			}
+			op := operation
+			if op == "" {
+				op = "write"
+				if operationOf(db.Statement.SQL.String()) == "select" {
+					op = "read"
+				}
+			}
+			for _, l := range limiters {
+				if l.take(db, op); db.Error != nil {
+					return
+				}
+			}
+		}
+	}
+	cb := m.db.Callback()
+	return errors.Join(
+		cb.Create().Before("gorm:create").Register("gormkit:quota_before_create", enforce("write")),
+		cb.Query().Before("gorm:query").Register("gormkit:quota_before_query", enforce("read")),
+		cb.Update().Before("gorm:update").Register("gormkit:quota_before_update", enforce("write")),
+		cb.Delete().Before("gorm:delete").Register("gormkit:quota_before_delete", enforce("write")),
+		cb.Row().Before("gorm:row").Register("gormkit:quota_before_row", enforce("read")),
+		cb.Raw().Before("gorm:raw").Register("gormkit:quota_before_raw", enforce("")),
+	)
+}
@@ -0,0 +1,93 @@
+package gormkit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestQuotaReject(t *testing.T) {
+	tenant := func(ctx context.Context) string {
+		id, _ := gormkit.Fields(ctx)["tenant"].(string)
+		return id
+	}
+	manager, err := gormkit.New(&gormkit.Config{
+		Driver:   "test",
+		LogLevel: "silent",
+		Quotas:   []gormkit.Quota{{Table: "users", Operation: "read", Rate: 0.001, Burst: 2, Key: tenant, Mode: "reject"}},
+		SLOs:     []gormkit.SLO{{Name: "users", Table: "users", Target: 0.99}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+	db := manager.DB()
+	db.AutoMigrate(&User{})
+
+	a := db.WithContext(gormkit.WithFields(context.Background(), map[string]any{"tenant": "a"}))
+	b := db.WithContext(gormkit.WithFields(context.Background(), map[string]any{"tenant": "b"}))
+	for i := 0; i < 2; i++ {
+		if err := a.Find(&[]User{}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := a.Find(&[]User{}).Error; !errors.Is(err, gormkit.ErrQuotaExceeded) {
+		t.Errorf("Expected ErrQuotaExceeded for tenant a, got %v", err)
+	}
+	if err := a.Create(&User{Name: "alice"}).Error; err != nil {
+		t.Errorf("Expected writes not to be limited, got %v", err)
+	}
+	if err := b.Find(&[]User{}).Error; err != nil {
+		t.Errorf("Expected tenant b to have its own quota, got %v", err)
+	}
+	// Throttled statements never ran, so they don't spend the error budget.
+	if s := manager.SLOStatus()[0]; s.Good != s.Total {
+		t.Errorf("Expected rejections to be left out of SLOs, got %+v", s)
+	}
+}
+
+func TestQuotaDelay(t *testing.T) {
+	manager, err := gormkit.New(&gormkit.Config{
+		Driver:   "test",
+		LogLevel: "silent",
+		Quotas:   []gormkit.Quota{{Table: "users", Rate: 50, Burst: 1}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+	db := manager.DB()
+	db.AutoMigrate(&User{})
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := db.Find(&[]User{}).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("Expected statements over the quota to wait, took %v", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	db.Find(&[]User{})
+	if err := db.WithContext(ctx).Find(&[]User{}).Error; !errors.Is(err, gormkit.ErrQuotaExceeded) {
+		t.Errorf("Expected ErrQuotaExceeded past the deadline, got %v", err)
+	}
+}
+
+func TestQuotaValidation(t *testing.T) {
+	for _, q := range []gormkit.Quota{
+		{Rate: 0},
+		{Rate: 10, Operation: "select"},
+		{Rate: 10, Mode: "rejct"},
+	} {
+		if _, err := gormkit.New(&gormkit.Config{Driver: "test", LogLevel: "silent", Quotas: []gormkit.Quota{q}}); err == nil {
+			t.Errorf("Expected an error for quota %+v", q)
+		}
+	}
+}
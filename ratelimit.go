@@ -0,0 +1,106 @@
+package gormkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var errRateLimited = errors.New("rate limited")
+
+// RateLimit is a per-window hit counter kept by RateLimiter.
+type RateLimit struct {
+	Limiter string `gorm:"primaryKey;size:100"`
+	Key     string `gorm:"primaryKey;size:191;column:limit_key"`
+	Bucket  int64  `gorm:"primaryKey;autoIncrement:false"` // window number since the epoch
+	Hits    int
+}
+
+func (RateLimit) TableName() string {
+	return "gormkit_rate_limits"
+}
+
+// RateLimiter allows up to limit calls per key in any sliding window, using
+// the previous window's count weighted by its overlap. Counters live in the
+// database, so the limit holds across instances.
+type RateLimiter struct {
+	manager *Manager
+	name    string
+	limit   int
+	window  time.Duration
+	swept   atomic.Int64 // last bucket whose stale counters were deleted
+}
+
+// RateLimiter returns a limiter allowing limit calls per key per window,
+// both of which must be positive or Allow fails:
+//
+//	login := manager.RateLimiter("login", 5, time.Minute)
+//	if ok, err := login.Allow(ctx, clientIP); err == nil && !ok {
+//		http.Error(w, "too many attempts", http.StatusTooManyRequests)
+//	}
+func (m *Manager) RateLimiter(name string, limit int, window time.Duration) *RateLimiter {
+	return &RateLimiter{manager: m, name: name, limit: limit, window: window}
+}
+
+// Allow counts a call for key and reports whether it is within the limit.
+// Denied calls are not counted.
+func (l *RateLimiter) Allow(ctx context.Context, key string) (bool, error) {
+	if l.limit <= 0 || l.window <= 0 {
+		return false, fmt.Errorf("rate limiter %s needs a positive limit and window, got %d per %v", l.name, l.limit, l.window)
+	}
+	db := l.manager.db.WithContext(ctx)
+	if err := ensureTable(db, &RateLimit{}); err != nil {
+		return false, err
+	}
+
+	now := db.NowFunc()
+	bucket := now.UnixNano() / int64(l.window)
+	elapsed := float64(now.UnixNano()%int64(l.window)) / float64(l.window)
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		// The increment locks the counter row, serializing concurrent calls
+		// for the same key until this transaction ends.
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "limiter"}, {Name: "limit_key"}, {Name: "bucket"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{"hits": gorm.Expr("hits + 1")}),
+		}).Create(&RateLimit{Limiter: l.name, Key: key, Bucket: bucket, Hits: 1}).Error; err != nil {
+			return err
+		}
+
+		var counts []RateLimit
+		if err := tx.Where("limiter = ? AND limit_key = ? AND bucket IN ?", l.name, key, []int64{bucket - 1, bucket}).
+			Find(&counts).Error; err != nil {
+			return err
+		}
+		var current, previous int
+		for _, c := range counts {
+			if c.Bucket == bucket {
+				current = c.Hits
+			} else {
+				previous = c.Hits
+			}
+		}
+		if float64(previous)*(1-elapsed)+float64(current) > float64(l.limit) {
+			return errRateLimited
+		}
+		return nil
+	})
+	if errors.Is(err, errRateLimited) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if last := l.swept.Load(); last < bucket && l.swept.CompareAndSwap(last, bucket) {
+		if err := db.Where("limiter = ? AND bucket < ?", l.name, bucket-1).Delete(&RateLimit{}).Error; err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
@@ -0,0 +1,46 @@
+package gormkit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestRateLimiterAllow(t *testing.T) {
+	manager := gormkit.NewTestManager(t)
+	ctx := context.Background()
+
+	limiter := manager.RateLimiter("login", 3, time.Hour)
+	for i := 0; i < 5; i++ {
+		ok, err := limiter.Allow(ctx, "10.0.0.1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := i < 3; ok != want {
+			t.Errorf("Call %d: expected allowed=%v, got %v", i+1, want, ok)
+		}
+	}
+
+	// Keys and limiters are counted separately.
+	if ok, err := limiter.Allow(ctx, "10.0.0.2"); err != nil || !ok {
+		t.Errorf("Expected another key to be allowed, got %v, %v", ok, err)
+	}
+	if ok, err := manager.RateLimiter("signup", 1, time.Hour).Allow(ctx, "10.0.0.1"); err != nil || !ok {
+		t.Errorf("Expected another limiter to be allowed, got %v, %v", ok, err)
+	}
+	if ok, err := manager.RateLimiter("broken", 1, 0).Allow(ctx, "10.0.0.1"); err == nil || ok {
+		t.Errorf("Expected a zero window to fail, got %v, %v", ok, err)
+	}
+
+	var hits int
+	if err := manager.DB().Model(&gormkit.RateLimit{}).
+		Where("limiter = ? AND limit_key = ?", "login", "10.0.0.1").
+		Select("SUM(hits)").Scan(&hits).Error; err != nil {
+		t.Fatal(err)
+	}
+	if hits != 3 {
+		t.Errorf("Expected denied calls not to be counted, got %d hits", hits)
+	}
+}
@@ -0,0 +1,91 @@
+package gormkit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	readyInitialBackoff = 100 * time.Millisecond
+	readyMaxBackoff     = 5 * time.Second
+)
+
+// WaitReady connects with cfg, retrying with backoff until the database
+// accepts connections and the tables for models exist, or ctx is done.
+func WaitReady(ctx context.Context, cfg *Config, models ...interface{}) (*Manager, error) {
+	var lastErr error
+	for attempt := 0; ; attempt++ {
+		m, err := newManager(ctx, cfg)
+		if err == nil {
+			if err := m.WaitReady(ctx, models...); err != nil {
+				m.Close()
+				return nil, err
+			}
+			return m, nil
+		}
+		lastErr = err
+
+		if err := sleepContext(ctx, backoff(attempt)); err != nil {
+			return nil, fmt.Errorf("database not ready: %w (last error: %v)", err, lastErr)
+		}
+	}
+}
+
+// WaitReady blocks until the database answers pings and the tables for models
+// have been migrated, polling with backoff until ctx is done.
+func (m *Manager) WaitReady(ctx context.Context, models ...interface{}) error {
+	var lastErr error
+	for attempt := 0; ; attempt++ {
+		lastErr = m.checkReady(ctx, models...)
+		if lastErr == nil {
+			return nil
+		}
+
+		if err := sleepContext(ctx, backoff(attempt)); err != nil {
+			return fmt.Errorf("database not ready: %w (last error: %v)", err, lastErr)
+		}
+	}
+}
+
+func (m *Manager) checkReady(ctx context.Context, models ...interface{}) error {
+	if err := m.Ping(ctx); err != nil {
+		return err
+	}
+	return checkTables(m.WithContext(ctx), models...)
+}
+
+func checkTables(db *gorm.DB, models ...interface{}) error {
+	migrator := db.Migrator()
+	for _, model := range models {
+		if !migrator.HasTable(model) {
+			stmt := &gorm.Statement{DB: db}
+			if err := stmt.Parse(model); err != nil {
+				return err
+			}
+			return fmt.Errorf("table %s is not migrated", stmt.Table)
+		}
+	}
+	return nil
+}
+
+func backoff(attempt int) time.Duration {
+	d := readyInitialBackoff << attempt
+	if d <= 0 || d > readyMaxBackoff {
+		return readyMaxBackoff
+	}
+	return d
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,46 @@
+package gormkit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestWaitReady(t *testing.T) {
+	manager, err := gormkit.WaitReady(context.Background(), &gormkit.Config{
+		Driver:   "test",
+		LogLevel: "silent",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+}
+
+func TestWaitReadyWaitsForMigrations(t *testing.T) {
+	manager, err := gormkit.New(&gormkit.Config{
+		Driver:   "test",
+		LogLevel: "silent",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer cancel()
+
+	err = manager.WaitReady(ctx, &User{})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected deadline error before migration, got %v", err)
+	}
+
+	manager.DB().AutoMigrate(&User{})
+
+	if err := manager.WaitReady(context.Background(), &User{}); err != nil {
+		t.Errorf("WaitReady after migration failed: %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+package gormkit
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const watchDebounce = 100 * time.Millisecond
+
+// Reload applies the runtime-tunable settings of cfg to a live Manager: pool
+// sizes and lifetimes, log level and slow query threshold. Settings left
+// unset in cfg keep their current values. Connection settings (driver,
+// host, credentials) require a new Manager.
+func (m *Manager) Reload(cfg *Config) error {
+	if cfg == nil {
+		return fmt.Errorf("config is required")
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	next := *cfg
+	setDefault(&next.MaxOpenConns, m.config.MaxOpenConns)
+	setDefault(&next.MaxIdleConns, m.config.MaxIdleConns)
+	setDefault(&next.ConnMaxLifetime, m.config.ConnMaxLifetime)
+	setDefault(&next.ConnMaxIdleTime, m.config.ConnMaxIdleTime)
+	setDefault(&next.LogLevel, m.config.LogLevel)
+	setDefault(&next.SlowThreshold, m.config.SlowThreshold)
+	setDefault(&next.LogSampleRate, m.config.LogSampleRate)
+	applyDefaults(&next)
+
+	m.sqlDB.SetMaxOpenConns(next.MaxOpenConns)
+	m.sqlDB.SetMaxIdleConns(next.MaxIdleConns)
+	m.sqlDB.SetConnMaxLifetime(next.ConnMaxLifetime)
+	m.sqlDB.SetConnMaxIdleTime(next.ConnMaxIdleTime)
+	m.config.MaxOpenConns = next.MaxOpenConns
+	m.config.MaxIdleConns = next.MaxIdleConns
+	m.config.ConnMaxLifetime = next.ConnMaxLifetime
+	m.config.ConnMaxIdleTime = next.ConnMaxIdleTime
+
+	m.logger.set(parseLogLevel(next.LogLevel), next.SlowThreshold)
+	m.logger.sampler.setRate(next.LogSampleRate)
+	m.config.LogLevel = next.LogLevel
+	m.config.SlowThreshold = next.SlowThreshold
+	m.config.LogSampleRate = next.LogSampleRate
+	return nil
+}
+
+// LoadConfig reads a JSON config file. Keys match Config field names
+// case-insensitively, with or without underscores ("max_open_conns"), and
+// durations are strings such as "5m".
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parseConfig(data)
+}
+
+func parseConfig(data []byte) (*Config, error) {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	cfg := &Config{}
+	v := reflect.ValueOf(cfg).Elem()
+	durationType := reflect.TypeOf(time.Duration(0))
+
+	for key, value := range raw {
+		field := v.FieldByNameFunc(func(name string) bool {
+			return strings.EqualFold(name, strings.ReplaceAll(key, "_", ""))
+		})
+		if !field.IsValid() || field.Kind() == reflect.Func {
+			return nil, fmt.Errorf("invalid config: unknown key %q", key)
+		}
+
+		if field.Type() == durationType {
+			var s string
+			if err := json.Unmarshal(value, &s); err == nil {
+				d, err := time.ParseDuration(s)
+				if err != nil {
+					return nil, fmt.Errorf("invalid config: %s: %w", key, err)
+				}
+				field.SetInt(int64(d))
+				continue
+			}
+		}
+		if err := json.Unmarshal(value, field.Addr().Interface()); err != nil {
+			return nil, fmt.Errorf("invalid config: %s: %w", key, err)
+		}
+	}
+	return cfg, nil
+}
+
+type ConfigWatcher struct {
+	watcher *fsnotify.Watcher
+	done    chan struct{}
+	wg      sync.WaitGroup
+}
+
+// Watch calls onChange with the newly loaded config whenever the file at
+// path changes. The parent directory is watched, so atomic replacements such
+// as Kubernetes ConfigMap updates are picked up. Files that fail to load are
+// logged and skipped.
+//
+//	gormkit.Watch("/etc/app/db.json", func(cfg *gormkit.Config) { manager.Reload(cfg) })
+func Watch(path string, onChange func(*Config)) (*ConfigWatcher, error) {
+	path = filepath.Clean(path)
+	last, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	if err := watcher.Add(filepath.Dir(path)); err != nil {
+		watcher.Close()
+		return nil, err
+	}
+
+	w := &ConfigWatcher{watcher: watcher, done: make(chan struct{})}
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+
+		timer := time.NewTimer(0)
+		<-timer.C
+		for {
+			select {
+			case <-w.done:
+				timer.Stop()
+				return
+			case _, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				timer.Reset(watchDebounce)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("gormkit: config watch error: %v", err)
+			case <-timer.C:
+				data, err := os.ReadFile(path)
+				if err != nil || bytes.Equal(data, last) {
+					continue
+				}
+				cfg, err := parseConfig(data)
+				if err != nil {
+					log.Printf("gormkit: ignoring config change in %s: %v", path, err)
+					continue
+				}
+				last = data
+				onChange(cfg)
+			}
+		}
+	}()
+	return w, nil
+}
+
+func (w *ConfigWatcher) Close() error {
+	close(w.done)
+	err := w.watcher.Close()
+	w.wg.Wait()
+	return err
+}
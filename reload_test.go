@@ -0,0 +1,86 @@
+package gormkit_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestLoadConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.json")
+	os.WriteFile(path, []byte(`{
+		"driver": "test",
+		"max_open_conns": 40,
+		"ConnMaxLifetime": "10m",
+		"slow_threshold": 500000000,
+		"log_level": "silent"
+	}`), 0o644)
+
+	cfg, err := gormkit.LoadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Driver != "test" || cfg.MaxOpenConns != 40 || cfg.LogLevel != "silent" {
+		t.Errorf("Unexpected config: %+v", cfg)
+	}
+	if cfg.ConnMaxLifetime != 10*time.Minute || cfg.SlowThreshold != 500*time.Millisecond {
+		t.Errorf("Unexpected durations: %v, %v", cfg.ConnMaxLifetime, cfg.SlowThreshold)
+	}
+
+	os.WriteFile(path, []byte(`{"max_open": 1}`), 0o644)
+	if _, err := gormkit.LoadConfig(path); err == nil {
+		t.Error("Expected error for unknown key")
+	}
+}
+
+func TestReload(t *testing.T) {
+	manager, err := gormkit.New(&gormkit.Config{
+		Driver:   "test",
+		LogLevel: "silent",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	if err := manager.Reload(&gormkit.Config{MaxOpenConns: 7, LogLevel: "silent"}); err != nil {
+		t.Fatal(err)
+	}
+	if stats := manager.Stats(); stats.MaxOpenConnections != 7 {
+		t.Errorf("Expected MaxOpenConns=7 after reload, got %d", stats.MaxOpenConnections)
+	}
+
+	// Settings left out keep their reloaded values.
+	if err := manager.Reload(&gormkit.Config{SlowThreshold: time.Second}); err != nil {
+		t.Fatal(err)
+	}
+	if stats := manager.Stats(); stats.MaxOpenConnections != 7 {
+		t.Errorf("Expected MaxOpenConns to stay 7, got %d", stats.MaxOpenConnections)
+	}
+}
+
+func TestWatch(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.json")
+	os.WriteFile(path, []byte(`{"max_open_conns": 10}`), 0o644)
+
+	changes := make(chan *gormkit.Config, 1)
+	watcher, err := gormkit.Watch(path, func(cfg *gormkit.Config) { changes <- cfg })
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer watcher.Close()
+
+	os.WriteFile(path, []byte(`{"max_open_conns": 20}`), 0o644)
+
+	select {
+	case cfg := <-changes:
+		if cfg.MaxOpenConns != 20 {
+			t.Errorf("Expected MaxOpenConns=20, got %d", cfg.MaxOpenConns)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("No change detected")
+	}
+}
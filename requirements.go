@@ -0,0 +1,109 @@
+package gormkit
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// Requirements are properties of the database the application relies on,
+// checked at connect so a misprovisioned database fails fast with a clear
+// error instead of in confusing ways much later.
+type Requirements struct {
+	// Extensions that must be installed (Postgres), e.g. "uuid-ossp" or
+	// "pg_trgm".
+	Extensions []string
+	// Encoding of the database: "UTF8" on Postgres, "utf8mb4" on MySQL,
+	// "UTF-8" on SQLite.
+	Encoding string
+	// Collation of the database, e.g. "en_US.UTF-8" on Postgres or
+	// "utf8mb4_0900_ai_ci" on MySQL. SQLite has none.
+	Collation string
+	// MinVersion is the oldest server version supported, e.g. "14" or
+	// "8.0.32".
+	MinVersion string
+}
+
+// CheckRequirements checks the database against r, returning an error
+// wrapping ErrRequirementsNotMet that lists every unmet requirement.
+func (m *Manager) CheckRequirements(ctx context.Context, r Requirements) error {
+	db := m.db.WithContext(withoutDeadlineAudit(ctx))
+	flavor := flavorOf(db)
+	var problems []string
+
+	if r.MinVersion != "" {
+		min := parseServerInfo(m.info.Flavor, r.MinVersion)
+		switch {
+		case m.info.Version == "":
+			problems = append(problems, "server version is unknown")
+		case !versionAtLeast(m.info, min):
+			problems = append(problems, fmt.Sprintf("server version %s is older than %s", m.info.Version, r.MinVersion))
+		}
+	}
+
+	if len(r.Extensions) > 0 {
+		if flavor != "postgres" {
+			problems = append(problems, "extensions are only supported on postgres")
+		} else {
+			var installed []string
+			if err := db.Raw("SELECT extname FROM pg_extension").Scan(&installed).Error; err != nil {
+				return fmt.Errorf("failed to list extensions: %w", err)
+			}
+			for _, ext := range r.Extensions {
+				if !containsFold(installed, ext) {
+					problems = append(problems, fmt.Sprintf("extension %s is not installed (see EnsureExtensions)", ext))
+				}
+			}
+		}
+	}
+
+	if r.Encoding != "" || r.Collation != "" {
+		var encoding, collation string
+		var err error
+		switch flavor {
+		case "postgres":
+			err = db.Raw("SELECT pg_encoding_to_char(encoding), datcollate FROM pg_database WHERE datname = current_database()").
+				Row().Scan(&encoding, &collation)
+		case "mysql":
+			err = db.Raw("SELECT @@character_set_database, @@collation_database").Row().Scan(&encoding, &collation)
+		case "sqlite":
+			err = db.Raw("PRAGMA encoding").Row().Scan(&encoding)
+			if r.Collation != "" {
+				problems = append(problems, "collation can't be checked on sqlite")
+			}
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read encoding: %w", err)
+		}
+		if r.Encoding != "" && !strings.EqualFold(encoding, r.Encoding) {
+			problems = append(problems, fmt.Sprintf("encoding is %s, not %s", encoding, r.Encoding))
+		}
+		if r.Collation != "" && flavor != "sqlite" && !strings.EqualFold(collation, r.Collation) {
+			problems = append(problems, fmt.Sprintf("collation is %s, not %s", collation, r.Collation))
+		}
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: %s", ErrRequirementsNotMet, strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+func versionAtLeast(info, min ServerInfo) bool {
+	if info.Major != min.Major {
+		return info.Major > min.Major
+	}
+	if info.Minor != min.Minor {
+		return info.Minor > min.Minor
+	}
+	return info.Patch >= min.Patch
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,39 @@
+package gormkit_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestRequirements(t *testing.T) {
+	manager, err := gormkit.New(&gormkit.Config{Driver: "test", LogLevel: "silent",
+		Requirements: &gormkit.Requirements{Encoding: "utf-8", MinVersion: "3.8"}})
+	if err != nil {
+		t.Fatalf("Expected the requirements to be met, got %v", err)
+	}
+	defer manager.Close()
+
+	err = manager.CheckRequirements(context.Background(), gormkit.Requirements{
+		Extensions: []string{"pg_trgm"},
+		Encoding:   "UTF-16le",
+		MinVersion: "99.1",
+	})
+	if !errors.Is(err, gormkit.ErrRequirementsNotMet) {
+		t.Fatalf("Expected ErrRequirementsNotMet, got %v", err)
+	}
+	for _, want := range []string{"extensions", "encoding is UTF-8", "older than 99.1"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected %q in %q", want, err)
+		}
+	}
+
+	_, err = gormkit.New(&gormkit.Config{Driver: "test", LogLevel: "silent",
+		Requirements: &gormkit.Requirements{MinVersion: "99"}})
+	if !errors.Is(err, gormkit.ErrRequirementsNotMet) {
+		t.Errorf("Expected the connect to fail, got %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package gormkit
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateReturning inserts value and returns it with every column as stored,
+// including server-generated ones (defaults, triggers, generated columns).
+// It uses RETURNING where supported and a follow-up select by primary key
+// elsewhere (MySQL).
+func CreateReturning[T any](ctx context.Context, db *gorm.DB, value T) (T, error) {
+	db = db.WithContext(ctx)
+	if Supports(db, FeatureReturning) {
+		err := db.Clauses(clause.Returning{}).Create(&value).Error
+		return value, err
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&value).Error; err != nil {
+			return err
+		}
+		return tx.First(&value).Error
+	})
+	return value, err
+}
+
+// UpdateReturning applies updates to the T rows matching query and args and
+// returns them as stored after the update. Without RETURNING support
+// (MySQL, and MariaDB for UPDATE) the rows are locked, updated and re-read
+// by primary key in one transaction.
+func UpdateReturning[T any](ctx context.Context, db *gorm.DB, updates interface{}, query interface{}, args ...interface{}) ([]T, error) {
+	db = db.WithContext(ctx)
+	var out []T
+	if info := serverInfoOf(db); info.Flavor != "mariadb" && info.Supports(FeatureReturning) {
+		err := db.Model(&out).Clauses(clause.Returning{}).Where(query, args...).Updates(updates).Error
+		return out, err
+	}
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		stmt := &gorm.Statement{DB: tx}
+		if err := stmt.Parse(new(T)); err != nil {
+			return err
+		}
+		pk := stmt.Schema.PrioritizedPrimaryField
+		if pk == nil {
+			return gorm.ErrMissingWhereClause
+		}
+
+		var ids []interface{}
+		if err := tx.Model(new(T)).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where(query, args...).Pluck(pk.DBName, &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		if err := tx.Model(new(T)).Where(clause.IN{Column: clause.Column{Name: pk.DBName}, Values: ids}).
+			Updates(updates).Error; err != nil {
+			return err
+		}
+		return tx.Where(clause.IN{Column: clause.Column{Name: pk.DBName}, Values: ids}).Find(&out).Error
+	})
+	return out, err
+}
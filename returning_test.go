@@ -0,0 +1,41 @@
+package gormkit_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+type Ticket struct {
+	ID     uint `gorm:"primarykey"`
+	Title  string
+	Status string `gorm:"->;default:'open'"`
+}
+
+func TestCreateReturning(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &Ticket{})
+
+	ticket, err := gormkit.CreateReturning(context.Background(), manager.DB(), Ticket{Title: "Broken"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ticket.ID == 0 || ticket.Status != "open" {
+		t.Errorf("Expected server-generated columns, got %+v", ticket)
+	}
+}
+
+func TestUpdateReturning(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &Ticket{})
+	db := manager.DB()
+	db.Create(&[]Ticket{{Title: "a"}, {Title: "a"}, {Title: "b"}})
+
+	tickets, err := gormkit.UpdateReturning[Ticket](context.Background(), db,
+		map[string]interface{}{"title": "z"}, "title = ?", "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tickets) != 2 || tickets[0].Title != "z" || tickets[0].Status != "open" {
+		t.Errorf("Expected the 2 updated rows as stored, got %+v", tickets)
+	}
+}
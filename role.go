@@ -0,0 +1,53 @@
+package gormkit
+
+import (
+	"context"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+type sessionSetting struct {
+	name  string
+	value string
+}
+
+// AsRole returns a context whose transactions run as the given Postgres role
+// (SET LOCAL ROLE), so row-level-security policies apply to that role.
+func AsRole(ctx context.Context, role string) context.Context {
+	return WithSetting(ctx, "role", role)
+}
+
+// WithSetting returns a context whose transactions set a transaction-local
+// configuration parameter, e.g. WithSetting(ctx, "app.current_user_id", "42").
+func WithSetting(ctx context.Context, name, value string) context.Context {
+	prev := sessionSettingsFrom(ctx)
+	settings := make([]sessionSetting, 0, len(prev)+1)
+	for _, s := range prev {
+		if s.name != name {
+			settings = append(settings, s)
+		}
+	}
+	settings = append(settings, sessionSetting{name: name, value: value})
+	return context.WithValue(ctx, sessionSettingsKey, settings)
+}
+
+func sessionSettingsFrom(ctx context.Context) []sessionSetting {
+	settings, _ := ctx.Value(sessionSettingsKey).([]sessionSetting)
+	return settings
+}
+
+// applySessionSettings issues set_config(..., true) for every setting carried
+// by ctx. The values are local to tx and are discarded on commit or rollback.
+// Other drivers have no row-level security, so the settings are ignored there.
+func (m *Manager) applySessionSettings(ctx context.Context, tx *gorm.DB) error {
+	if m.config.Driver != "postgres" {
+		return nil
+	}
+	for _, s := range sessionSettingsFrom(ctx) {
+		if err := tx.Exec("SELECT set_config(?, ?, true)", s.name, s.value).Error; err != nil {
+			return fmt.Errorf("failed to set %s: %w", s.name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package gormkit_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+	"gorm.io/gorm"
+)
+
+func TestAsRoleIgnoredOnSQLite(t *testing.T) {
+	manager, err := gormkit.New(&gormkit.Config{
+		Driver:   "test",
+		LogLevel: "silent",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	manager.DB().AutoMigrate(&User{})
+
+	ctx := gormkit.AsRole(context.Background(), "app_user")
+	ctx = gormkit.WithSetting(ctx, "app.current_user_id", "42")
+
+	err = manager.Transaction(ctx, func(tx *gorm.DB) error {
+		return tx.Create(&User{Name: "RLS"}).Error
+	})
+	if err != nil {
+		t.Errorf("Transaction failed: %v", err)
+	}
+}
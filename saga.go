@@ -0,0 +1,243 @@
+package gormkit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	SagaRunning      = "running"
+	SagaCompensating = "compensating"
+	SagaCompleted    = "completed"
+	SagaCompensated  = "compensated"
+)
+
+// SagaStep is one step of a Saga. Action and Compensate may be retried after
+// a crash, so both must be idempotent. Compensate is optional.
+type SagaStep[T any] struct {
+	Name       string
+	Action     func(ctx context.Context, data *T) error
+	Compensate func(ctx context.Context, data *T) error
+}
+
+// Saga runs its steps in order, persisting progress and data in the
+// gormkit_sagas table after every step. When a step fails, the compensations
+// of the completed steps run in reverse order. A saga interrupted by a crash
+// continues where it stopped with Resume.
+type Saga[T any] struct {
+	Name  string
+	Steps []SagaStep[T]
+}
+
+// SagaState is the persisted state of one saga run.
+type SagaState struct {
+	ID        string `gorm:"primaryKey;size:191"`
+	Name      string `gorm:"size:191;index"`
+	Status    string `gorm:"size:32;index"`
+	Step      int    // steps completed
+	Data      string // JSON of the saga's data
+	Error     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (SagaState) TableName() string {
+	return "gormkit_sagas"
+}
+
+// Run starts the saga with the given id and data and runs it to the end.
+// Running an id that already exists claims and resumes it instead, so
+// callers can safely retry Run with the same id; an instance still running
+// it stops at its next step. The returned error is the failure of the step
+// that triggered compensation, or of a compensation itself.
+func (s *Saga[T]) Run(ctx context.Context, db *gorm.DB, id string, data T) error {
+	db = db.WithContext(ctx)
+	if err := ensureTable(db, &SagaState{}); err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("saga %s: %w", s.Name, err)
+	}
+	now := sagaNow(db)
+	state := SagaState{ID: id, Name: s.Name, Status: SagaRunning, Data: string(payload), CreatedAt: now, UpdatedAt: now}
+	if err := db.Create(&state).Error; err != nil {
+		if err := db.First(&state, "id = ?", id).Error; err != nil {
+			return err
+		}
+		if state.Name != s.Name {
+			return fmt.Errorf("saga %s: id %s belongs to saga %s", s.Name, id, state.Name)
+		}
+		claimed, err := claimSaga(db, &state)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			return fmt.Errorf("saga %s: %s was claimed by another instance", s.Name, id)
+		}
+	}
+	return s.execute(ctx, db, &state)
+}
+
+// Resume continues every saga of this name left running or compensating
+// and not updated for at least staleAfter, i.e. abandoned by a crashed
+// process. Each saga is claimed before it runs, so several instances may
+// call Resume concurrently.
+func (s *Saga[T]) Resume(ctx context.Context, db *gorm.DB, staleAfter time.Duration) error {
+	db = db.WithContext(ctx)
+	if err := ensureTable(db, &SagaState{}); err != nil {
+		return err
+	}
+
+	var states []SagaState
+	if err := db.Where("name = ? AND status IN ? AND updated_at <= ?",
+		s.Name, []string{SagaRunning, SagaCompensating}, db.NowFunc().Add(-staleAfter)).
+		Find(&states).Error; err != nil {
+		return err
+	}
+
+	var errs []error
+	for i := range states {
+		state := &states[i]
+		claimed, err := claimSaga(db, state)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if !claimed {
+			continue // claimed by another instance
+		}
+		if err := db.First(state, "id = ?", state.ID).Error; err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := s.execute(ctx, db, state); err != nil {
+			errs = append(errs, fmt.Errorf("saga %s %s: %w", s.Name, state.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Saga[T]) execute(ctx context.Context, db *gorm.DB, state *SagaState) error {
+	var data T
+	if err := json.Unmarshal([]byte(state.Data), &data); err != nil {
+		return fmt.Errorf("saga %s: %w", s.Name, err)
+	}
+	// Each save is conditional on the claim still being ours, so an
+	// instance whose saga was claimed away stops instead of running the
+	// remaining steps a second time.
+	save := func() error {
+		payload, err := json.Marshal(data)
+		if err != nil {
+			return err
+		}
+		state.Data = string(payload)
+		now := sagaNow(db)
+		res := db.Model(&SagaState{}).Where("id = ? AND updated_at = ?", state.ID, state.UpdatedAt).
+			Updates(map[string]interface{}{
+				"status": state.Status, "step": state.Step, "data": state.Data, "error": state.Error, "updated_at": now,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return fmt.Errorf("saga %s: %s was claimed by another instance", s.Name, state.ID)
+		}
+		state.UpdatedAt = now
+		return nil
+	}
+
+	var failure error
+	for state.Status == SagaRunning && state.Step < len(s.Steps) {
+		step := s.Steps[state.Step]
+		if err := step.Action(ctx, &data); err != nil {
+			failure = fmt.Errorf("saga %s: step %s: %w", s.Name, step.Name, err)
+			state.Status = SagaCompensating
+			state.Error = fmt.Sprintf("step %s: %v", step.Name, err)
+			if err := save(); err != nil {
+				return err
+			}
+			break
+		}
+		state.Step++
+		if err := save(); err != nil {
+			return err
+		}
+	}
+
+	switch state.Status {
+	case SagaRunning:
+		state.Status = SagaCompleted
+		return save()
+	case SagaCompleted:
+		return nil
+	}
+
+	for state.Status == SagaCompensating && state.Step > 0 {
+		step := s.Steps[state.Step-1]
+		if step.Compensate != nil {
+			if err := step.Compensate(ctx, &data); err != nil {
+				return fmt.Errorf("saga %s: compensating step %s: %w", s.Name, step.Name, err)
+			}
+		}
+		state.Step--
+		if err := save(); err != nil {
+			return err
+		}
+	}
+	if state.Status == SagaCompensating {
+		state.Status = SagaCompensated
+		if err := save(); err != nil {
+			return err
+		}
+	}
+	if failure != nil {
+		return failure
+	}
+	return fmt.Errorf("saga %s: %s", s.Name, state.Error)
+}
+
+// claimSaga takes state over for this instance by moving its updated_at,
+// provided nobody has since it was read.
+func claimSaga(db *gorm.DB, state *SagaState) (bool, error) {
+	now := sagaNow(db)
+	res := db.Model(&SagaState{}).Where("id = ? AND updated_at = ?", state.ID, state.UpdatedAt).
+		Update("updated_at", now)
+	if res.Error != nil || res.RowsAffected == 0 {
+		return false, res.Error
+	}
+	state.UpdatedAt = now
+	return true, nil
+}
+
+// sagaNow is the time claims are compared by, truncated to what every
+// driver stores exactly (MySQL keeps milliseconds).
+func sagaNow(db *gorm.DB) time.Time {
+	return db.NowFunc().Truncate(time.Millisecond)
+}
+
+var ensuredTables sync.Map
+
+// ensureTable creates the table of a kit-owned model once per connection
+// pool, regardless of Config.AutoMigrate.
+func ensureTable(db *gorm.DB, model interface{}) error {
+	key := struct {
+		pool  *gorm.Config
+		model string
+	}{db.Config, fmt.Sprintf("%T", model)}
+	if _, ok := ensuredTables.Load(key); ok {
+		return nil
+	}
+	if err := db.AutoMigrate(model); err != nil {
+		return err
+	}
+	ensuredTables.Store(key, true)
+	return nil
+}
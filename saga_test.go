@@ -0,0 +1,161 @@
+package gormkit_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+type order struct {
+	ID      int
+	Charged bool
+}
+
+func newOrderSaga(log *[]string, failAt string) *gormkit.Saga[order] {
+	step := func(name string) gormkit.SagaStep[order] {
+		return gormkit.SagaStep[order]{
+			Name: name,
+			Action: func(ctx context.Context, o *order) error {
+				if name == failAt {
+					return errors.New("declined")
+				}
+				*log = append(*log, name)
+				if name == "charge" {
+					o.Charged = true
+				}
+				return nil
+			},
+			Compensate: func(ctx context.Context, o *order) error {
+				*log = append(*log, "undo "+name)
+				return nil
+			},
+		}
+	}
+	return &gormkit.Saga[order]{
+		Name:  "checkout",
+		Steps: []gormkit.SagaStep[order]{step("reserve"), step("charge"), step("ship")},
+	}
+}
+
+func TestSagaCompletes(t *testing.T) {
+	manager := gormkit.NewTestManager(t)
+	ctx := context.Background()
+
+	var log []string
+	saga := newOrderSaga(&log, "")
+	if err := saga.Run(ctx, manager.DB(), "order-1", order{ID: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"reserve", "charge", "ship"}; !reflect.DeepEqual(log, want) {
+		t.Errorf("Expected %v, got %v", want, log)
+	}
+
+	var state gormkit.SagaState
+	if err := manager.DB().First(&state, "id = ?", "order-1").Error; err != nil {
+		t.Fatal(err)
+	}
+	if state.Status != gormkit.SagaCompleted || state.Data != `{"ID":1,"Charged":true}` {
+		t.Errorf("Unexpected state: %+v", state)
+	}
+
+	// Running the same id again does not repeat the steps.
+	if err := saga.Run(ctx, manager.DB(), "order-1", order{ID: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if len(log) != 3 {
+		t.Errorf("Expected no further steps, got %v", log)
+	}
+}
+
+func TestSagaCompensates(t *testing.T) {
+	manager := gormkit.NewTestManager(t)
+
+	var log []string
+	err := newOrderSaga(&log, "ship").Run(context.Background(), manager.DB(), "order-2", order{ID: 2})
+	if err == nil || err.Error() != "saga checkout: step ship: declined" {
+		t.Fatalf("Expected ship failure, got %v", err)
+	}
+	if want := []string{"reserve", "charge", "undo charge", "undo reserve"}; !reflect.DeepEqual(log, want) {
+		t.Errorf("Expected %v, got %v", want, log)
+	}
+
+	var state gormkit.SagaState
+	if err := manager.DB().First(&state, "id = ?", "order-2").Error; err != nil {
+		t.Fatal(err)
+	}
+	if state.Status != gormkit.SagaCompensated || state.Step != 0 {
+		t.Errorf("Unexpected state: %+v", state)
+	}
+}
+
+func TestSagaResume(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &gormkit.SagaState{})
+	db := manager.DB()
+
+	// A process crashed after reserving.
+	crashed := gormkit.SagaState{ID: "order-3", Name: "checkout", Status: gormkit.SagaRunning, Step: 1, Data: `{"ID":3}`}
+	if err := db.Create(&crashed).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Model(&crashed).UpdateColumn("updated_at", db.NowFunc().Add(-time.Hour)).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	var log []string
+	saga := newOrderSaga(&log, "")
+	if err := saga.Resume(context.Background(), db, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"charge", "ship"}; !reflect.DeepEqual(log, want) {
+		t.Errorf("Expected %v, got %v", want, log)
+	}
+
+	var state gormkit.SagaState
+	if err := db.First(&state, "id = ?", "order-3").Error; err != nil {
+		t.Fatal(err)
+	}
+	if state.Status != gormkit.SagaCompleted || state.Data != `{"ID":3,"Charged":true}` {
+		t.Errorf("Unexpected state: %+v", state)
+	}
+}
+
+func TestSagaRunClaims(t *testing.T) {
+	manager := gormkit.NewTestManager(t)
+	ctx := context.Background()
+
+	var reserved, shipped atomic.Int32
+	entered, release := make(chan struct{}), make(chan struct{})
+	saga := &gormkit.Saga[order]{Name: "checkout", Steps: []gormkit.SagaStep[order]{
+		{Name: "reserve", Action: func(ctx context.Context, o *order) error {
+			if reserved.Add(1) == 1 { // only the first run blocks
+				close(entered)
+				<-release
+			}
+			return nil
+		}},
+		{Name: "ship", Action: func(ctx context.Context, o *order) error {
+			shipped.Add(1)
+			return nil
+		}},
+	}}
+
+	first := make(chan error, 1)
+	go func() { first <- saga.Run(ctx, manager.DB(), "order-1", order{ID: 1}) }()
+	<-entered
+	// A retry takes the saga over while the first run is stuck in a step.
+	if err := saga.Run(ctx, manager.DB(), "order-1", order{ID: 1}); err != nil {
+		t.Fatal(err)
+	}
+	close(release)
+	if err := <-first; err == nil {
+		t.Error("Expected the first run to stop after losing its claim")
+	}
+	if n := shipped.Load(); n != 1 {
+		t.Errorf("Expected the saga to ship once, got %d", n)
+	}
+}
@@ -0,0 +1,50 @@
+package gormkit
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Sample picks n random rows, ordering by the dialect's random function.
+// The database still reads every matching row, so on large tables prefer
+// SamplePercent.
+//
+//	var users []User
+//	err := db.Scopes(gormkit.Sample(100)).Find(&users).Error
+func Sample(n int) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Clauses(clause.OrderBy{Expression: SQL.RandomFunc()}).Limit(n)
+	}
+}
+
+// SamplePercent picks about percent (0-100) of the rows at random. On
+// Postgres it uses TABLESAMPLE BERNOULLI, which samples before other
+// conditions apply; elsewhere each row is kept with that probability.
+func SamplePercent(percent float64) func(db *gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		percent = min(max(percent, 0), 100)
+		switch flavorOf(db) {
+		case "postgres":
+			table := db.Statement.Table
+			if table == "" {
+				model := db.Statement.Model
+				if model == nil {
+					model = db.Statement.Dest
+				}
+				if err := db.Statement.Parse(model); err != nil {
+					db.AddError(err)
+					return db
+				}
+				table = db.Statement.Table
+			}
+			return db.Table(fmt.Sprintf("%s TABLESAMPLE BERNOULLI (%g)", db.Statement.Quote(table), percent))
+		case "mysql":
+			return db.Where("RAND() < ?", percent/100)
+		default:
+			// RANDOM() is uniform over int64; map it to [0, 1).
+			return db.Where("RANDOM() / 18446744073709551616.0 + 0.5 < ?", percent/100)
+		}
+	}
+}
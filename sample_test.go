@@ -0,0 +1,56 @@
+package gormkit_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestSample(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &User{})
+	db := manager.DB()
+	var users []User
+	for i := 0; i < 200; i++ {
+		users = append(users, User{Name: fmt.Sprintf("user-%d", i)})
+	}
+	db.Create(&users)
+
+	var sample []User
+	if err := db.Scopes(gormkit.Sample(10)).Find(&sample).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(sample) != 10 {
+		t.Errorf("Expected 10 users, got %d", len(sample))
+	}
+
+	var none, all []User
+	db.Scopes(gormkit.SamplePercent(0)).Find(&none)
+	db.Scopes(gormkit.SamplePercent(100)).Find(&all)
+	if len(none) != 0 || len(all) != 200 {
+		t.Errorf("Expected 0 and 200 users, got %d and %d", len(none), len(all))
+	}
+	var half []User
+	db.Scopes(gormkit.SamplePercent(50)).Find(&half)
+	if len(half) < 50 || len(half) > 150 {
+		t.Errorf("Expected about 100 users, got %d", len(half))
+	}
+}
+
+func TestSamplePercentPostgres(t *testing.T) {
+	db, err := gorm.Open(postgres.New(postgres.Config{DSN: "host=localhost"}), &gorm.Config{
+		DryRun:               true,
+		DisableAutomaticPing: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sql := db.ToSQL(func(tx *gorm.DB) *gorm.DB {
+		return tx.Scopes(gormkit.SamplePercent(5)).Where("name <> ?", "").Find(&[]User{})
+	})
+	if want := `SELECT * FROM "users" TABLESAMPLE BERNOULLI (5) WHERE name <> ''`; sql != want {
+		t.Errorf("Expected %q, got %q", want, sql)
+	}
+}
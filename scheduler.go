@@ -0,0 +1,212 @@
+package gormkit
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+)
+
+// JobRun records one execution of a scheduled job.
+type JobRun struct {
+	ID          uint      `gorm:"primaryKey"`
+	Job         string    `gorm:"size:191;uniqueIndex:idx_gormkit_job_runs_tick"`
+	ScheduledAt time.Time `gorm:"uniqueIndex:idx_gormkit_job_runs_tick"`
+	Holder      string    `gorm:"size:191"`
+	Status      string    `gorm:"size:32"`
+	Error       string
+	StartedAt   time.Time
+	FinishedAt  *time.Time
+}
+
+func (JobRun) TableName() string {
+	return "gormkit_job_runs"
+}
+
+type scheduledJob struct {
+	name     string
+	schedule cron.Schedule
+	fn       func(ctx context.Context) error
+	next     time.Time
+	running  bool
+}
+
+// Scheduler runs jobs on cron schedules, once per tick across all instances:
+// only the holder of the "gormkit-scheduler" lease fires jobs, and each tick
+// is recorded in gormkit_job_runs under a unique key before it runs.
+type Scheduler struct {
+	manager  *Manager
+	election *LeaderElection
+
+	mu   sync.Mutex
+	jobs map[string]*scheduledJob
+	wake chan struct{}
+}
+
+// Scheduler returns the Manager's job scheduler. Jobs run once Run is called.
+func (m *Manager) Scheduler() *Scheduler {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.scheduler == nil {
+		m.scheduler = &Scheduler{
+			manager:  m,
+			election: m.LeaderElection("gormkit-scheduler"),
+			jobs:     make(map[string]*scheduledJob),
+			wake:     make(chan struct{}, 1),
+		}
+	}
+	return m.scheduler
+}
+
+// Register adds a job running fn on spec, a standard five-field cron
+// expression ("*/5 * * * *") or a descriptor such as "@hourly", evaluated in
+// Config.Timezone. Runs of the same job never overlap; a tick reached while
+// the previous run is still going is skipped.
+func (s *Scheduler) Register(name, spec string, fn func(ctx context.Context) error) error {
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return fmt.Errorf("invalid schedule for job %s: %w", name, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.jobs[name]; ok {
+		return fmt.Errorf("job %s is already registered", name)
+	}
+	s.jobs[name] = &scheduledJob{name: name, schedule: schedule, fn: fn}
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// IsLeader reports whether this instance is the one firing jobs.
+func (s *Scheduler) IsLeader() bool {
+	return s.election.IsLeader()
+}
+
+// Runs returns the latest runs of job, newest first.
+func (s *Scheduler) Runs(ctx context.Context, job string, limit int) ([]JobRun, error) {
+	db := s.manager.db.WithContext(ctx)
+	if err := ensureTable(db, &JobRun{}); err != nil {
+		return nil, err
+	}
+	var runs []JobRun
+	err := db.Where("job = ?", job).Order("scheduled_at DESC").Limit(limit).Find(&runs).Error
+	return runs, err
+}
+
+// Run campaigns for the scheduler lease and fires due jobs while holding it,
+// until ctx is done or the Manager is closed.
+func (s *Scheduler) Run(ctx context.Context) error {
+	if err := ensureTable(s.manager.db.WithContext(ctx), &JobRun{}); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	s.manager.onClose(func() {
+		cancel()
+		<-done
+	})
+	defer close(done)
+	defer cancel()
+	return s.election.Run(ctx, s.loop)
+}
+
+func (s *Scheduler) loop(ctx context.Context) {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	s.mu.Lock()
+	now := s.manager.db.NowFunc()
+	for _, job := range s.jobs {
+		job.next = job.schedule.Next(now)
+	}
+	s.mu.Unlock()
+
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		s.mu.Lock()
+		now := s.manager.db.NowFunc()
+		earliest := now.Add(time.Minute)
+		for _, job := range s.jobs {
+			if job.next.IsZero() {
+				job.next = job.schedule.Next(now)
+			}
+			if !job.next.After(now) {
+				tick := job.next
+				job.next = job.schedule.Next(now)
+				if job.running {
+					log.Printf("gormkit: job %s still running, skipping tick %s", job.name, tick.Format(time.RFC3339))
+				} else {
+					job.running = true
+					wg.Add(1)
+					go func(job *scheduledJob) {
+						defer wg.Done()
+						s.execute(ctx, job, tick)
+						s.mu.Lock()
+						job.running = false
+						s.mu.Unlock()
+					}(job)
+				}
+			}
+			if job.next.Before(earliest) {
+				earliest = job.next
+			}
+		}
+		s.mu.Unlock()
+
+		timer.Reset(earliest.Sub(now))
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+			timer.Stop()
+		case <-timer.C:
+		}
+	}
+}
+
+func (s *Scheduler) execute(ctx context.Context, job *scheduledJob, tick time.Time) {
+	db := s.manager.db.WithContext(withoutDeadlineAudit(context.WithoutCancel(ctx)))
+	run := JobRun{
+		Job:         job.name,
+		ScheduledAt: tick,
+		Holder:      s.election.Identity,
+		Status:      JobRunning,
+		StartedAt:   db.NowFunc(),
+	}
+	claim := db.Clauses(clause.OnConflict{DoNothing: true}).Create(&run)
+	if claim.Error != nil {
+		log.Printf("gormkit: job %s: %v", job.name, claim.Error)
+		return
+	}
+	if claim.RowsAffected == 0 {
+		return // already run by the previous leader
+	}
+
+	err := job.fn(ctx)
+	finished := db.NowFunc()
+	run.FinishedAt = &finished
+	run.Status = JobSucceeded
+	if err != nil {
+		run.Status = JobFailed
+		run.Error = err.Error()
+		log.Printf("gormkit: job %s failed: %v", job.name, err)
+	}
+	if err := db.Save(&run).Error; err != nil {
+		log.Printf("gormkit: job %s: %v", job.name, err)
+	}
+}
@@ -0,0 +1,65 @@
+package gormkit_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestSchedulerRunsJobs(t *testing.T) {
+	manager := gormkit.NewTestManager(t)
+	sched := manager.Scheduler()
+
+	ran := make(chan struct{}, 10)
+	if err := sched.Register("refresh", "@every 1s", func(ctx context.Context) error {
+		ran <- struct{}{}
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sched.Register("broken", "@every 1s", func(ctx context.Context) error {
+		return errors.New("boom")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := sched.Register("refresh", "@hourly", nil); err == nil {
+		t.Error("Expected duplicate job to be rejected")
+	}
+	if err := sched.Register("bad", "every minute", nil); err == nil {
+		t.Error("Expected invalid schedule to be rejected")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- sched.Run(ctx) }()
+
+	select {
+	case <-ran:
+	case <-time.After(3 * time.Second):
+		t.Fatal("job did not run")
+	}
+	if !sched.IsLeader() {
+		t.Error("Expected the only instance to lead")
+	}
+	cancel()
+	<-done
+
+	runs, err := sched.Runs(context.Background(), "refresh", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) == 0 || runs[0].Status != gormkit.JobSucceeded || runs[0].FinishedAt == nil {
+		t.Errorf("Unexpected refresh runs: %+v", runs)
+	}
+
+	runs, err = sched.Runs(context.Background(), "broken", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) == 0 || runs[0].Status != gormkit.JobFailed || runs[0].Error != "boom" {
+		t.Errorf("Unexpected broken runs: %+v", runs)
+	}
+}
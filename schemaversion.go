@@ -0,0 +1,104 @@
+package gormkit
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"reflect"
+
+	"gorm.io/gorm"
+)
+
+// SchemaVersionRange bounds the schema versions a build works with. The
+// schema version is the ID of the newest applied Migration, so versions
+// compare in the order migrations apply. Either bound may be empty.
+type SchemaVersionRange struct {
+	// Min is the oldest schema the build can run against: the newest
+	// migration its code relies on.
+	Min string
+	// Max is the newest schema the build can run against, e.g. the last
+	// migration before one dropping columns it still reads.
+	Max string
+}
+
+// SchemaVersioned is implemented by models that need a newer schema than
+// SchemaVersions.Min, e.g. because a recent migration creates their table.
+// Their statements fail fast with ErrSchemaVersion until that migration is
+// applied.
+//
+//	func (Refund) SchemaVersion() string { return "20240601_refunds" }
+type SchemaVersioned interface {
+	SchemaVersion() string
+}
+
+// SchemaVersion returns the live schema version as last read: at connect,
+// after ApplyMigrations and by CheckSchemaVersion. It is empty before the
+// first migration, or when SchemaVersions isn't set.
+func (m *Manager) SchemaVersion() string {
+	if v := m.schemaVersion.Load(); v != nil {
+		return *v
+	}
+	return ""
+}
+
+// CheckSchemaVersion reads the live schema version and checks it against
+// SchemaVersions, e.g. to let a degraded instance pick up a migration
+// applied by a newer one during a rolling deploy.
+func (m *Manager) CheckSchemaVersion(ctx context.Context) error {
+	db := m.db.WithContext(withoutDeadlineAudit(ctx))
+	var version string
+	if db.Migrator().HasTable(&SchemaMigration{}) {
+		var latest sql.NullString
+		if err := db.Model(&SchemaMigration{}).Select("MAX(id)").Row().Scan(&latest); err != nil {
+			return fmt.Errorf("failed to read schema version: %w", err)
+		}
+		version = latest.String
+	}
+	m.schemaVersion.Store(&version)
+
+	r := m.config.SchemaVersions
+	if r.Min != "" && version < r.Min {
+		return fmt.Errorf("%w: schema %q is older than %q", ErrSchemaVersion, version, r.Min)
+	}
+	if r.Max != "" && version > r.Max {
+		return fmt.Errorf("%w: schema %q is newer than %q", ErrSchemaVersion, version, r.Max)
+	}
+	return nil
+}
+
+// SupportsModel reports whether the live schema is new enough for model, so
+// features built on models that need a newer schema can be switched off.
+func (m *Manager) SupportsModel(model interface{}) bool {
+	versioned, ok := model.(SchemaVersioned)
+	if !ok {
+		return true
+	}
+	v := m.schemaVersion.Load()
+	return v == nil || *v >= versioned.SchemaVersion()
+}
+
+// checkSchemaVersion runs CheckSchemaVersion at connect, failing the
+// connect unless SchemaVersionMode is "degrade".
+func (m *Manager) checkSchemaVersion(ctx context.Context) error {
+	err := m.CheckSchemaVersion(ctx)
+	if err != nil && m.config.SchemaVersionMode == "degrade" {
+		log.Printf("gormkit: %v; running degraded", err)
+		return nil
+	}
+	return err
+}
+
+// beforeSchemaVersion fails statements on models the live schema is too
+// old for.
+func (m *Manager) beforeSchemaVersion(db *gorm.DB) {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return
+	}
+	model, ok := reflect.New(db.Statement.Schema.ModelType).Interface().(SchemaVersioned)
+	if !ok || m.SupportsModel(model) {
+		return
+	}
+	db.AddError(fmt.Errorf("%w: %s needs schema %q, live schema is %q",
+		ErrSchemaVersion, db.Statement.Schema.Name, model.SchemaVersion(), m.SchemaVersion()))
+}
@@ -0,0 +1,74 @@
+package gormkit_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+type refund struct {
+	ID     uint
+	Amount float64
+}
+
+func (refund) SchemaVersion() string { return "002_refunds" }
+
+func TestSchemaVersions(t *testing.T) {
+	ctx := context.Background()
+	database := filepath.Join(t.TempDir(), "app.db")
+	open := func(r gormkit.SchemaVersionRange, mode string) (*gormkit.Manager, error) {
+		return gormkit.New(&gormkit.Config{Driver: "test", Database: database, LogLevel: "silent",
+			SchemaVersions: r, SchemaVersionMode: mode})
+	}
+	migrations := []gormkit.Migration{
+		{ID: "001_init", Up: func(s *gormkit.Schema) { s.Exec("CREATE TABLE notes (id INTEGER)") }},
+		{ID: "002_refunds", Up: func(s *gormkit.Schema) { s.Exec("CREATE TABLE refunds (id INTEGER, amount REAL)") }},
+	}
+
+	old, err := open(gormkit.SchemaVersionRange{}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := old.ApplyMigrations(ctx, migrations[0]); err != nil {
+		t.Fatal(err)
+	}
+	old.Close()
+
+	if _, err := open(gormkit.SchemaVersionRange{Min: "002_refunds"}, ""); !errors.Is(err, gormkit.ErrSchemaVersion) {
+		t.Fatalf("Expected a too old schema to fail the connect, got %v", err)
+	}
+	if _, err := open(gormkit.SchemaVersionRange{Max: "000_none"}, ""); !errors.Is(err, gormkit.ErrSchemaVersion) {
+		t.Fatalf("Expected a too new schema to fail the connect, got %v", err)
+	}
+
+	manager, err := open(gormkit.SchemaVersionRange{Min: "002_refunds"}, "degrade")
+	if err != nil {
+		t.Fatalf("Expected a degraded start, got %v", err)
+	}
+	defer manager.Close()
+	if v := manager.SchemaVersion(); v != "001_init" {
+		t.Errorf("Expected schema version 001_init, got %q", v)
+	}
+	if manager.SupportsModel(refund{}) {
+		t.Error("Expected refunds to be unsupported before their migration")
+	}
+	if err := manager.DB().Find(&[]refund{}).Error; !errors.Is(err, gormkit.ErrSchemaVersion) {
+		t.Errorf("Expected refund queries to fail fast, got %v", err)
+	}
+
+	if err := manager.ApplyMigrations(ctx, migrations...); err != nil {
+		t.Fatal(err)
+	}
+	if !manager.SupportsModel(refund{}) {
+		t.Error("Expected refunds to be supported after their migration")
+	}
+	if err := manager.DB().Find(&[]refund{}).Error; err != nil {
+		t.Errorf("Expected refund queries to work, got %v", err)
+	}
+	if err := manager.CheckSchemaVersion(ctx); err != nil {
+		t.Errorf("Expected the schema to be in range, got %v", err)
+	}
+}
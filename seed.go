@@ -0,0 +1,381 @@
+package gormkit
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"unicode/utf8"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RootSpec selects the rows of one table a seed or subset starts from.
+type RootSpec struct {
+	Table   string
+	Where   string // optional condition, e.g. "created_at > ?"
+	Args    []interface{}
+	Limit   int     // random sample of this many rows
+	Percent float64 // or a random sample of about this share of rows
+}
+
+// SeedOptions configures ExportSeed.
+type SeedOptions struct {
+	Roots []RootSpec
+	// Anonymize replaces column values on export, keyed by "table.column".
+	Anonymize map[string]Anonymizer
+	// MaxRows caps the exported rows, referenced ones included; default
+	// 100000.
+	MaxRows int
+}
+
+// seedBinaryKey tags binary values in a seed file as {"$base64": "..."},
+// which would otherwise load back as base64 text.
+const seedBinaryKey = "$base64"
+
+// seedLine is one row of a seed file, which holds one JSON object per line.
+type seedLine struct {
+	Table string                 `json:"table"`
+	Row   map[string]interface{} `json:"row"`
+}
+
+// ExportSeed writes a subset of the database to w for loading into preview
+// or staging databases with LoadSeed: a random sample of each root, plus
+// every row those reference through foreign keys, recursively, so the seed
+// loads without dangling references. Rows are written parents first and
+// anonymized on the way out.
+//
+//	err := manager.ExportSeed(ctx, file, gormkit.SeedOptions{
+//		Roots:     []gormkit.RootSpec{{Table: "orders", Limit: 500}},
+//		Anonymize: map[string]gormkit.Anonymizer{"customers.email": gormkit.AnonymizeEmail(salt)},
+//	})
+func (m *Manager) ExportSeed(ctx context.Context, w io.Writer, opts SeedOptions) error {
+	if opts.MaxRows <= 0 {
+		opts.MaxRows = 100000
+	}
+	fks, err := m.ForeignKeys(ctx)
+	if err != nil {
+		return err
+	}
+	set, err := m.collectRows(ctx, fks, opts.Roots, opts.MaxRows)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	for _, table := range set.order(fks) {
+		for _, row := range set.rows[table] {
+			for column, value := range row {
+				if anon := opts.Anonymize[table+"."+column]; anon != nil {
+					row[column] = anon(value)
+				} else if b, ok := value.([]byte); ok && utf8.Valid(b) {
+					row[column] = string(b)
+				} else if ok {
+					row[column] = map[string]string{seedBinaryKey: base64.StdEncoding.EncodeToString(b)}
+				}
+			}
+			if err := enc.Encode(seedLine{Table: table, Row: row}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// rowSet holds the rows collected for a seed or subset, deduplicated.
+type rowSet struct {
+	rows    map[string][]map[string]interface{}
+	seen    map[string]bool // table + canonical row JSON
+	fetched map[string]bool // table.column = value already loaded
+	count   int
+}
+
+func (s *rowSet) add(table string, row map[string]interface{}) bool {
+	key, _ := json.Marshal(row)
+	if s.seen[table+"\x00"+string(key)] {
+		return false
+	}
+	s.seen[table+"\x00"+string(key)] = true
+	s.rows[table] = append(s.rows[table], row)
+	s.count++
+	return true
+}
+
+// collectRows loads the root rows and then, level by level, the rows they
+// reference.
+func (m *Manager) collectRows(ctx context.Context, fks []ForeignKeyRef, roots []RootSpec, maxRows int) (*rowSet, error) {
+	db := m.db.WithContext(withoutDeadlineAudit(ctx))
+	set := &rowSet{
+		rows:    make(map[string][]map[string]interface{}),
+		seen:    make(map[string]bool),
+		fetched: make(map[string]bool),
+	}
+	pending := make(map[string][]map[string]interface{})
+	for _, root := range roots {
+		q := db.Table(root.Table)
+		if root.Where != "" {
+			q = q.Where(root.Where, root.Args...)
+		}
+		switch {
+		case root.Limit > 0:
+			q = q.Scopes(Sample(root.Limit))
+		case root.Percent > 0:
+			q = q.Scopes(SamplePercent(root.Percent))
+		}
+		var rows []map[string]interface{}
+		if err := q.Find(&rows).Error; err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", root.Table, err)
+		}
+		for _, row := range rows {
+			if set.add(root.Table, row) {
+				pending[root.Table] = append(pending[root.Table], row)
+			}
+		}
+	}
+
+	outgoing := make(map[string][]ForeignKeyRef)
+	for _, fk := range fks {
+		outgoing[fk.Table] = append(outgoing[fk.Table], fk)
+	}
+	for len(pending) > 0 {
+		if set.count > maxRows {
+			return nil, fmt.Errorf("more than %d rows referenced; narrow the roots or raise MaxRows", maxRows)
+		}
+		next := make(map[string][]map[string]interface{})
+		for table, rows := range pending {
+			for _, fk := range outgoing[table] {
+				var values []interface{}
+				for _, row := range rows {
+					value := row[fk.Column]
+					if value == nil {
+						continue
+					}
+					key := fmt.Sprintf("%s.%s=%v", fk.RefTable, fk.RefColumn, idKey(value))
+					if !set.fetched[key] {
+						set.fetched[key] = true
+						values = append(values, value)
+					}
+				}
+				for start := 0; start < len(values); start += 500 {
+					var parents []map[string]interface{}
+					err := db.Table(fk.RefTable).
+						Where(clause.IN{Column: clause.Column{Name: fk.RefColumn}, Values: values[start:min(start+500, len(values))]}).
+						Find(&parents).Error
+					if err != nil {
+						return nil, fmt.Errorf("failed to read %s: %w", fk.RefTable, err)
+					}
+					for _, row := range parents {
+						if set.add(fk.RefTable, row) {
+							next[fk.RefTable] = append(next[fk.RefTable], row)
+						}
+					}
+				}
+			}
+		}
+		pending = next
+	}
+	return set, nil
+}
+
+// order returns the tables of s with referenced tables first, and sorts
+// the rows of self-referencing tables parents first.
+func (s *rowSet) order(fks []ForeignKeyRef) []string {
+	parents := make(map[string]map[string]bool)
+	for _, fk := range fks {
+		if fk.Table == fk.RefTable {
+			s.rows[fk.Table] = parentsFirst(s.rows[fk.Table], fk)
+			continue
+		}
+		if parents[fk.Table] == nil {
+			parents[fk.Table] = make(map[string]bool)
+		}
+		parents[fk.Table][fk.RefTable] = true
+	}
+
+	var tables []string
+	for table := range s.rows {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+
+	var ordered []string
+	done := make(map[string]bool)
+	for len(ordered) < len(tables) {
+		progress := false
+		for _, table := range tables {
+			if done[table] {
+				continue
+			}
+			ready := true
+			for parent := range parents[table] {
+				if _, ok := s.rows[parent]; ok && !done[parent] {
+					ready = false
+				}
+			}
+			if ready {
+				ordered = append(ordered, table)
+				done[table] = true
+				progress = true
+			}
+		}
+		if !progress {
+			// A reference cycle between tables: emit the rest as is.
+			for _, table := range tables {
+				if !done[table] {
+					ordered = append(ordered, table)
+					done[table] = true
+				}
+			}
+		}
+	}
+	return ordered
+}
+
+// parentsFirst orders rows so every row comes after the row it references
+// through the self-referencing fk.
+func parentsFirst(rows []map[string]interface{}, fk ForeignKeyRef) []map[string]interface{} {
+	present := make(map[string]bool, len(rows))
+	for _, row := range rows {
+		present[idKey(row[fk.RefColumn])] = true
+	}
+	emitted := make(map[string]bool, len(rows))
+	ordered := make([]map[string]interface{}, 0, len(rows))
+	for len(ordered) < len(rows) {
+		progress := false
+		for _, row := range rows {
+			self := idKey(row[fk.RefColumn])
+			if emitted[self] {
+				continue
+			}
+			parent := row[fk.Column]
+			if parent == nil || !present[idKey(parent)] || emitted[idKey(parent)] || idKey(parent) == self {
+				ordered = append(ordered, row)
+				emitted[self] = true
+				progress = true
+			}
+		}
+		if !progress {
+			for _, row := range rows {
+				if !emitted[idKey(row[fk.RefColumn])] {
+					ordered = append(ordered, row)
+					emitted[idKey(row[fk.RefColumn])] = true
+				}
+			}
+		}
+	}
+	return ordered
+}
+
+// LoadSeed inserts the rows of a seed written by ExportSeed, in one
+// transaction. On Postgres, serial id sequences are moved past the loaded
+// ids so later inserts don't collide.
+func (m *Manager) LoadSeed(ctx context.Context, r io.Reader) error {
+	db := m.db.WithContext(withoutDeadlineAudit(ctx))
+	dec := json.NewDecoder(bufio.NewReader(r))
+	dec.UseNumber()
+
+	var lines []seedLine
+	for {
+		var line seedLine
+		if err := dec.Decode(&line); err == io.EOF {
+			break
+		} else if err != nil {
+			return fmt.Errorf("invalid seed: %w", err)
+		}
+		for column, value := range line.Row {
+			switch v := value.(type) {
+			case json.Number:
+				if i, err := v.Int64(); err == nil {
+					line.Row[column] = i
+				} else {
+					line.Row[column], _ = v.Float64()
+				}
+			case map[string]interface{}:
+				encoded, ok := v[seedBinaryKey].(string)
+				if !ok || len(v) != 1 {
+					return fmt.Errorf("invalid seed: %s.%s: unexpected object", line.Table, column)
+				}
+				b, err := base64.StdEncoding.DecodeString(encoded)
+				if err != nil {
+					return fmt.Errorf("invalid seed: %s.%s: %w", line.Table, column, err)
+				}
+				line.Row[column] = b
+			}
+		}
+		lines = append(lines, line)
+	}
+	return m.insertRows(db, lines, false)
+}
+
+// insertRows inserts lines in order, batching consecutive rows of a table.
+// With skipExisting, rows conflicting with existing ones are left out.
+func (m *Manager) insertRows(db *gorm.DB, lines []seedLine, skipExisting bool) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		for start := 0; start < len(lines); {
+			end := start + 1
+			for end < len(lines) && end-start < 500 && lines[end].Table == lines[start].Table {
+				end++
+			}
+			batch := make([]map[string]interface{}, 0, end-start)
+			for _, line := range lines[start:end] {
+				batch = append(batch, line.Row)
+			}
+			q := tx.Table(lines[start].Table)
+			if skipExisting {
+				q = q.Clauses(clause.OnConflict{DoNothing: true})
+			}
+			if err := q.Create(&batch).Error; err != nil {
+				return fmt.Errorf("failed to insert into %s: %w", lines[start].Table, err)
+			}
+			start = end
+		}
+		if flavorOf(tx) != "postgres" {
+			return nil
+		}
+		// Inside the transaction, so a failed reset doesn't leave the rows
+		// loaded behind a stale sequence.
+		tables := make(map[string]bool)
+		for _, line := range lines {
+			if _, ok := line.Row["id"]; ok {
+				tables[line.Table] = true
+			}
+		}
+		for table := range tables {
+			if err := resetSequence(tx, table, "id"); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Subset copies the rows selected by roots from src to dst, plus every row
+// they reference through foreign keys, recursively, e.g. to reproduce a
+// customer's issue against a local database. Tables must exist in dst;
+// rows already there are kept.
+//
+//	err := gormkit.Subset(ctx, prod, local, []gormkit.RootSpec{
+//		{Table: "accounts", Where: "id = ?", Args: []interface{}{accountID}},
+//		{Table: "orders", Where: "account_id = ?", Args: []interface{}{accountID}},
+//	})
+func Subset(ctx context.Context, src, dst *Manager, roots []RootSpec) error {
+	fks, err := src.ForeignKeys(ctx)
+	if err != nil {
+		return err
+	}
+	set, err := src.collectRows(ctx, fks, roots, 100000)
+	if err != nil {
+		return err
+	}
+	var lines []seedLine
+	for _, table := range set.order(fks) {
+		for _, row := range set.rows[table] {
+			lines = append(lines, seedLine{Table: table, Row: row})
+		}
+	}
+	return dst.insertRows(dst.db.WithContext(withoutDeadlineAudit(ctx)), lines, true)
+}
@@ -0,0 +1,150 @@
+package gormkit_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+type Customer struct {
+	ID    uint
+	Email string
+}
+
+type Invoice struct {
+	ID         uint
+	CustomerID uint
+	Customer   Customer
+	Total      float64
+}
+
+type Employee struct {
+	ID        uint
+	Name      string
+	ManagerID *uint
+	Manager   *Employee
+}
+
+func TestExportAndLoadSeed(t *testing.T) {
+	src := gormkit.NewTestManager(t, &Customer{}, &Invoice{}, &Employee{})
+	db := src.DB()
+	ctx := context.Background()
+
+	alice := Customer{Email: "alice@corp.com"}
+	bob := Customer{Email: "bob@corp.com"}
+	db.Create(&alice)
+	db.Create(&bob)
+	db.Create(&Invoice{CustomerID: alice.ID, Total: 12.5})
+	ceo := Employee{Name: "ceo"}
+	db.Create(&ceo)
+	cto := Employee{Name: "cto", ManagerID: &ceo.ID}
+	db.Create(&cto)
+	db.Create(&Employee{Name: "dev", ManagerID: &cto.ID})
+
+	var seed bytes.Buffer
+	err := src.ExportSeed(ctx, &seed, gormkit.SeedOptions{
+		Roots: []gormkit.RootSpec{
+			{Table: "invoices", Limit: 10},
+			{Table: "employees", Where: "name = ?", Args: []interface{}{"dev"}},
+		},
+		Anonymize: map[string]gormkit.Anonymizer{"customers.email": gormkit.AnonymizeEmail("salt")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(seed.String(), "corp.com") || strings.Contains(seed.String(), `"bob`) {
+		t.Errorf("Expected only alice, anonymized, got %s", seed.String())
+	}
+
+	dst := gormkit.NewTestManager(t, &Customer{}, &Invoice{}, &Employee{})
+	dst.DB().Exec("PRAGMA foreign_keys = ON")
+	if err := dst.LoadSeed(ctx, &seed); err != nil {
+		t.Fatal(err)
+	}
+
+	var invoice Invoice
+	if err := dst.DB().Preload("Customer").First(&invoice).Error; err != nil {
+		t.Fatal(err)
+	}
+	if invoice.Total != 12.5 || !strings.HasSuffix(invoice.Customer.Email, "@example.com") {
+		t.Errorf("Unexpected invoice: %+v", invoice)
+	}
+	var employees int64
+	dst.DB().Model(&Employee{}).Count(&employees)
+	if employees != 3 {
+		t.Errorf("Expected the dev's management chain, got %d employees", employees)
+	}
+}
+
+func TestForeignKeys(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &Customer{}, &Invoice{})
+	fks, err := manager.ForeignKeys(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := gormkit.ForeignKeyRef{Table: "invoices", Column: "customer_id", RefTable: "customers", RefColumn: "id",
+		OnDelete: "NO ACTION", OnUpdate: "NO ACTION"}
+	if len(fks) != 1 || fks[0] != want {
+		t.Errorf("Unexpected foreign keys: %+v", fks)
+	}
+}
+
+func TestSubset(t *testing.T) {
+	src := gormkit.NewTestManager(t, &Customer{}, &Invoice{})
+	ctx := context.Background()
+	alice := Customer{Email: "alice@corp.com"}
+	bob := Customer{Email: "bob@corp.com"}
+	src.DB().Create(&alice)
+	src.DB().Create(&bob)
+	src.DB().Create(&[]Invoice{{CustomerID: alice.ID, Total: 1}, {CustomerID: bob.ID, Total: 2}})
+
+	dst := gormkit.NewTestManager(t, &Customer{}, &Invoice{})
+	dst.DB().Create(&Customer{ID: alice.ID, Email: "alice@local"})
+
+	err := gormkit.Subset(ctx, src, dst, []gormkit.RootSpec{
+		{Table: "invoices", Where: "customer_id = ?", Args: []interface{}{bob.ID}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var customers []Customer
+	dst.DB().Order("id").Find(&customers)
+	if len(customers) != 2 || customers[0].Email != "alice@local" || customers[1].Email != "bob@corp.com" {
+		t.Errorf("Unexpected customers: %+v", customers)
+	}
+	var invoices []Invoice
+	dst.DB().Find(&invoices)
+	if len(invoices) != 1 || invoices[0].CustomerID != bob.ID {
+		t.Errorf("Unexpected invoices: %+v", invoices)
+	}
+}
+
+type Attachment struct {
+	ID   uint
+	Data []byte
+}
+
+func TestSeedBinary(t *testing.T) {
+	src := gormkit.NewTestManager(t, &Attachment{})
+	ctx := context.Background()
+	data := []byte{0xff, 0x00, 0xfe, 'a'}
+	src.DB().Create(&Attachment{Data: data})
+
+	var seed bytes.Buffer
+	if err := src.ExportSeed(ctx, &seed, gormkit.SeedOptions{Roots: []gormkit.RootSpec{{Table: "attachments"}}}); err != nil {
+		t.Fatal(err)
+	}
+	dst := gormkit.NewTestManager(t, &Attachment{})
+	if err := dst.LoadSeed(ctx, &seed); err != nil {
+		t.Fatal(err)
+	}
+	var got Attachment
+	dst.DB().First(&got)
+	if !bytes.Equal(got.Data, data) {
+		t.Errorf("Expected %x, got %x", data, got.Data)
+	}
+}
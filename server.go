@@ -0,0 +1,161 @@
+package gormkit
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type ServerInfo struct {
+	Flavor  string // postgres, cockroachdb, mysql, mariadb or sqlite
+	Version string
+	Major   int
+	Minor   int
+	Patch   int
+}
+
+type Feature int
+
+const (
+	FeatureSkipLocked Feature = iota
+	FeatureUpsert
+	FeatureGeneratedColumns
+	FeatureReturning
+	FeatureRecursiveCTE
+	FeatureFollowerReads
+	FeatureNoWait
+)
+
+var versionPattern = regexp.MustCompile(`(\d+)(?:\.(\d+))?(?:\.(\d+))?`)
+
+// ServerInfo returns the database flavor and version detected at connect.
+func (m *Manager) ServerInfo() ServerInfo {
+	return m.info
+}
+
+// Supports reports whether the server behind db supports f.
+func Supports(db *gorm.DB, f Feature) bool {
+	return serverInfoOf(db).Supports(f)
+}
+
+// AtLeast reports whether the server version is at least major.minor.
+func (i ServerInfo) AtLeast(major, minor int) bool {
+	if i.Major != major {
+		return i.Major > major
+	}
+	return i.Minor >= minor
+}
+
+// Supports reports whether the server can run SQL that needs f. An unknown
+// version is treated as unsupported.
+func (i ServerInfo) Supports(f Feature) bool {
+	switch f {
+	case FeatureSkipLocked:
+		switch i.Flavor {
+		case "postgres":
+			return i.AtLeast(9, 5)
+		case "cockroachdb":
+			return i.AtLeast(23, 1)
+		case "mysql":
+			return i.AtLeast(8, 0)
+		case "mariadb":
+			return i.AtLeast(10, 6)
+		}
+	case FeatureUpsert:
+		switch i.Flavor {
+		case "postgres":
+			return i.AtLeast(9, 5)
+		case "cockroachdb", "mysql", "mariadb":
+			return true
+		case "sqlite":
+			return i.AtLeast(3, 24)
+		}
+	case FeatureGeneratedColumns:
+		switch i.Flavor {
+		case "postgres":
+			return i.AtLeast(12, 0)
+		case "cockroachdb":
+			return true
+		case "mysql":
+			return i.AtLeast(5, 7)
+		case "mariadb":
+			return i.AtLeast(10, 2)
+		case "sqlite":
+			return i.AtLeast(3, 31)
+		}
+	case FeatureRecursiveCTE:
+		switch i.Flavor {
+		case "postgres":
+			return true
+		case "cockroachdb":
+			return i.AtLeast(20, 1)
+		case "mysql":
+			return i.AtLeast(8, 0)
+		case "mariadb":
+			return i.AtLeast(10, 2)
+		case "sqlite":
+			return i.AtLeast(3, 8)
+		}
+	case FeatureReturning:
+		switch i.Flavor {
+		case "postgres", "cockroachdb":
+			return true
+		case "mariadb":
+			return i.AtLeast(10, 5)
+		case "sqlite":
+			return i.AtLeast(3, 35)
+		}
+	case FeatureFollowerReads:
+		return i.Flavor == "cockroachdb"
+	case FeatureNoWait:
+		switch i.Flavor {
+		case "postgres", "cockroachdb":
+			return true
+		case "mysql":
+			return i.AtLeast(8, 0)
+		case "mariadb":
+			return i.AtLeast(10, 3)
+		}
+	}
+	return false
+}
+
+func (m *Manager) detectServerInfo(ctx context.Context) (ServerInfo, error) {
+	version, err := m.serverVersion(ctx)
+	if err != nil {
+		return ServerInfo{Flavor: flavorOf(m.db)}, err
+	}
+	return parseServerInfo(flavorOf(m.db), version), nil
+}
+
+func parseServerInfo(flavor, version string) ServerInfo {
+	info := ServerInfo{Flavor: flavor, Version: version}
+	if flavor == "mysql" && strings.Contains(strings.ToLower(version), "mariadb") {
+		info.Flavor = "mariadb"
+	}
+	if flavor == "postgres" && strings.Contains(version, "CockroachDB") {
+		info.Flavor = "cockroachdb"
+	}
+	if match := versionPattern.FindStringSubmatch(version); match != nil {
+		info.Major, _ = strconv.Atoi(match[1])
+		info.Minor, _ = strconv.Atoi(match[2])
+		info.Patch, _ = strconv.Atoi(match[3])
+	}
+	return info
+}
+
+func flavorOf(db *gorm.DB) string {
+	return db.Dialector.Name()
+}
+
+// serverInfoOf returns the ServerInfo of the Manager that opened db, or just
+// the flavor when db was opened elsewhere.
+func serverInfoOf(db *gorm.DB) ServerInfo {
+	if m := managerOf(db); m != nil {
+		return m.info
+	}
+	return ServerInfo{Flavor: flavorOf(db)}
+}
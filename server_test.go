@@ -0,0 +1,59 @@
+package gormkit_test
+
+import (
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestServerInfoSQLite(t *testing.T) {
+	manager, err := gormkit.New(&gormkit.Config{
+		Driver:   "test",
+		LogLevel: "silent",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	info := manager.ServerInfo()
+	if info.Flavor != "sqlite" || info.Major != 3 {
+		t.Errorf("Unexpected server info: %+v", info)
+	}
+	if !gormkit.Supports(manager.DB(), gormkit.FeatureUpsert) {
+		t.Error("SQLite 3.24+ should support upserts")
+	}
+	if gormkit.Supports(manager.DB(), gormkit.FeatureSkipLocked) {
+		t.Error("SQLite should not support SKIP LOCKED")
+	}
+}
+
+func TestServerInfoSupports(t *testing.T) {
+	tests := []struct {
+		info    gormkit.ServerInfo
+		feature gormkit.Feature
+		want    bool
+	}{
+		{gormkit.ServerInfo{Flavor: "postgres", Major: 9, Minor: 4}, gormkit.FeatureSkipLocked, false},
+		{gormkit.ServerInfo{Flavor: "postgres", Major: 9, Minor: 6}, gormkit.FeatureSkipLocked, true},
+		{gormkit.ServerInfo{Flavor: "postgres", Major: 11}, gormkit.FeatureGeneratedColumns, false},
+		{gormkit.ServerInfo{Flavor: "postgres", Major: 16}, gormkit.FeatureGeneratedColumns, true},
+		{gormkit.ServerInfo{Flavor: "mysql", Major: 5, Minor: 7}, gormkit.FeatureSkipLocked, false},
+		{gormkit.ServerInfo{Flavor: "mysql", Major: 8}, gormkit.FeatureReturning, false},
+		{gormkit.ServerInfo{Flavor: "mariadb", Major: 10, Minor: 5}, gormkit.FeatureReturning, true},
+		{gormkit.ServerInfo{Flavor: "sqlite", Major: 3, Minor: 34}, gormkit.FeatureReturning, false},
+		{gormkit.ServerInfo{Flavor: "mysql", Major: 5, Minor: 7}, gormkit.FeatureRecursiveCTE, false},
+		{gormkit.ServerInfo{Flavor: "mariadb", Major: 10, Minor: 2}, gormkit.FeatureRecursiveCTE, true},
+		{gormkit.ServerInfo{Flavor: "cockroachdb", Major: 23, Minor: 1}, gormkit.FeatureReturning, true},
+		{gormkit.ServerInfo{Flavor: "cockroachdb", Major: 23, Minor: 1}, gormkit.FeatureFollowerReads, true},
+		{gormkit.ServerInfo{Flavor: "postgres", Major: 16}, gormkit.FeatureFollowerReads, false},
+		{gormkit.ServerInfo{Flavor: "mariadb", Major: 10, Minor: 3}, gormkit.FeatureNoWait, true},
+		{gormkit.ServerInfo{Flavor: "mysql", Major: 5, Minor: 7}, gormkit.FeatureNoWait, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.info.Supports(tt.feature); got != tt.want {
+			t.Errorf("%+v Supports(%d) = %v, want %v", tt.info, tt.feature, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package gormkit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Setting is a stored JSON setting. Version increases on every write.
+type Setting struct {
+	Key       string `gorm:"primaryKey;size:191;column:setting_key"`
+	Value     string
+	Version   int
+	UpdatedAt time.Time
+}
+
+func (Setting) TableName() string {
+	return "gormkit_settings"
+}
+
+type cachedSetting struct {
+	setting Setting
+	loaded  time.Time
+}
+
+// Settings is a key-value store of JSON settings in the gormkit_settings
+// table. Reads are cached for TTL; writes use optimistic concurrency on the
+// setting's version.
+type Settings struct {
+	// TTL bounds how stale a cached setting may be, default 30s.
+	TTL time.Duration
+
+	manager *Manager
+	mu      sync.Mutex
+	cache   map[string]cachedSetting
+}
+
+// Settings returns the Manager's settings store.
+func (m *Manager) Settings() *Settings {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.settings == nil {
+		m.settings = &Settings{TTL: 30 * time.Second, manager: m, cache: make(map[string]cachedSetting)}
+	}
+	return m.settings
+}
+
+// Get decodes the setting key into dest and returns its version, or
+// gorm.ErrRecordNotFound when it is not set.
+func (s *Settings) Get(ctx context.Context, key string, dest interface{}) (int, error) {
+	setting, err := s.load(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	if err := json.Unmarshal([]byte(setting.Value), dest); err != nil {
+		return 0, fmt.Errorf("setting %s: %w", key, err)
+	}
+	return setting.Version, nil
+}
+
+// Set stores value under key if the stored version still equals version
+// (0 for a new setting) and returns the new version. It fails with
+// ErrVersionConflict when the setting was changed or created concurrently.
+func (s *Settings) Set(ctx context.Context, key string, value interface{}, version int) (int, error) {
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return 0, fmt.Errorf("setting %s: %w", key, err)
+	}
+	db := s.manager.db.WithContext(ctx)
+	if err := ensureTable(db, &Setting{}); err != nil {
+		return 0, err
+	}
+	s.invalidate(key)
+
+	setting := Setting{Key: key, Value: string(payload), Version: version + 1}
+	var result *gorm.DB
+	if version == 0 {
+		result = db.Clauses(clause.OnConflict{DoNothing: true}).Create(&setting)
+	} else {
+		result = db.Model(&Setting{}).
+			Where("setting_key = ? AND version = ?", key, version).
+			Updates(map[string]interface{}{"value": setting.Value, "version": setting.Version, "updated_at": db.NowFunc()})
+	}
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return 0, fmt.Errorf("%w: setting %s", ErrVersionConflict, key)
+	}
+	return setting.Version, nil
+}
+
+// Delete removes the setting key.
+func (s *Settings) Delete(ctx context.Context, key string) error {
+	db := s.manager.db.WithContext(ctx)
+	if err := ensureTable(db, &Setting{}); err != nil {
+		return err
+	}
+	s.invalidate(key)
+	return db.Delete(&Setting{Key: key}).Error
+}
+
+// GetSetting returns the setting key decoded as a T.
+func GetSetting[T any](ctx context.Context, s *Settings, key string) (T, error) {
+	var value T
+	_, err := s.Get(ctx, key, &value)
+	return value, err
+}
+
+// UpdateSetting applies fn to the current value of key (the zero T when it
+// is not set) and stores the result, retrying when another writer changed
+// the setting in between.
+func UpdateSetting[T any](ctx context.Context, s *Settings, key string, fn func(T) T) (T, error) {
+	for {
+		var current T
+		version, err := s.Get(ctx, key, &current)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return current, err
+		}
+		next := fn(current)
+		if _, err = s.Set(ctx, key, next, version); err == nil {
+			return next, nil
+		}
+		if !errors.Is(err, ErrVersionConflict) {
+			return current, err
+		}
+		if err := ctx.Err(); err != nil {
+			return current, err
+		}
+	}
+}
+
+func (s *Settings) invalidate(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.cache, key)
+}
+
+func (s *Settings) load(ctx context.Context, key string) (Setting, error) {
+	s.mu.Lock()
+	cached, ok := s.cache[key]
+	s.mu.Unlock()
+	if ok && time.Since(cached.loaded) < s.TTL {
+		return cached.setting, nil
+	}
+
+	db := s.manager.db.WithContext(ctx)
+	if err := ensureTable(db, &Setting{}); err != nil {
+		return Setting{}, err
+	}
+	var setting Setting
+	if err := db.Where("setting_key = ?", key).First(&setting).Error; err != nil {
+		return Setting{}, err
+	}
+	s.mu.Lock()
+	s.cache[key] = cachedSetting{setting: setting, loaded: time.Now()}
+	s.mu.Unlock()
+	return setting, nil
+}
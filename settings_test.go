@@ -0,0 +1,79 @@
+package gormkit_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+	"gorm.io/gorm"
+)
+
+type smtpSettings struct {
+	Host string
+	Port int
+}
+
+func TestSettingsGetSet(t *testing.T) {
+	manager := gormkit.NewTestManager(t)
+	ctx := context.Background()
+	settings := manager.Settings()
+
+	if _, err := gormkit.GetSetting[smtpSettings](ctx, settings, "smtp"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Fatalf("Expected ErrRecordNotFound, got %v", err)
+	}
+
+	version, err := settings.Set(ctx, "smtp", smtpSettings{Host: "mail", Port: 25}, 0)
+	if err != nil || version != 1 {
+		t.Fatalf("Expected version 1, got %d, %v", version, err)
+	}
+	if _, err := settings.Set(ctx, "smtp", smtpSettings{}, 0); !errors.Is(err, gormkit.ErrVersionConflict) {
+		t.Errorf("Expected ErrVersionConflict creating twice, got %v", err)
+	}
+
+	var got smtpSettings
+	version, err = settings.Get(ctx, "smtp", &got)
+	if err != nil || version != 1 || got != (smtpSettings{Host: "mail", Port: 25}) {
+		t.Fatalf("Unexpected setting %+v version %d, %v", got, version, err)
+	}
+
+	if _, err := settings.Set(ctx, "smtp", smtpSettings{Host: "mail", Port: 587}, version); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := settings.Set(ctx, "smtp", smtpSettings{Host: "stale"}, version); !errors.Is(err, gormkit.ErrVersionConflict) {
+		t.Errorf("Expected ErrVersionConflict on a stale version, got %v", err)
+	}
+	if got, _ := gormkit.GetSetting[smtpSettings](ctx, settings, "smtp"); got.Port != 587 {
+		t.Errorf("Expected port 587, got %+v", got)
+	}
+
+	if err := settings.Delete(ctx, "smtp"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := gormkit.GetSetting[smtpSettings](ctx, settings, "smtp"); !errors.Is(err, gorm.ErrRecordNotFound) {
+		t.Errorf("Expected ErrRecordNotFound after delete, got %v", err)
+	}
+}
+
+func TestUpdateSettingConcurrent(t *testing.T) {
+	manager := gormkit.NewTestManager(t)
+	ctx := context.Background()
+	settings := manager.Settings()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := gormkit.UpdateSetting(ctx, settings, "counter", func(n int) int { return n + 1 }); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n, err := gormkit.GetSetting[int](ctx, settings, "counter"); err != nil || n != 10 {
+		t.Errorf("Expected 10, got %d, %v", n, err)
+	}
+}
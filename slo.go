@@ -0,0 +1,181 @@
+package gormkit
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// sloBuckets is the number of buckets an SLO's window is counted in; the
+// short window of burn alerts is the last 1/12 of them.
+const (
+	sloBuckets       = 60
+	sloShortBuckets  = sloBuckets / 12
+	sloMinShortTotal = 10 // statements in the short window before alerting
+)
+
+// SLO is a latency and error objective for a class of statements, e.g. 99%
+// of selects on orders succeed within 50ms. Statements slower than Latency
+// or failing (other than record-not-found) are bad.
+type SLO struct {
+	Name      string
+	Table     string        // "" for every table
+	Operation string        // select, insert, update, delete, other, read or write; "" for all
+	Latency   time.Duration // 0 to count errors only
+	Target    float64       // share of good statements, e.g. 0.99; between 0 and 1
+	// Window is the rolling window compliance is computed over, at least
+	// 1s; default 1h.
+	Window time.Duration
+	// BurnRate is how many times faster than sustainable the error budget
+	// must burn, over both Window and its last twelfth, for OnBurn to fire;
+	// default 14.4, which spends a 30-day budget's 2% in an hour.
+	BurnRate float64
+	// OnBurn is called, in its own goroutine, when the burn rate goes over
+	// BurnRate, and again only after it has recovered.
+	OnBurn func(SLOStatus)
+}
+
+// SLOStatus is the rolling compliance of an SLO.
+type SLOStatus struct {
+	Name          string
+	Target        float64
+	Total, Good   int64   // statements in the window
+	Compliance    float64 // Good / Total, 1 without statements
+	BurnRate      float64 // error rate over Window relative to 1 - Target
+	ShortBurnRate float64 // the same over the last twelfth of Window
+	Burning       bool
+}
+
+type sloBucket struct {
+	slot        int64
+	total, good int64
+}
+
+// sloTracker counts an SLO's statements in buckets covering its window.
+type sloTracker struct {
+	slo     SLO
+	width   time.Duration
+	mu      sync.Mutex
+	buckets [sloBuckets]sloBucket
+	burning bool
+}
+
+// validateSLOs rejects SLOs that would never match or can't be counted.
+func validateSLOs(slos []SLO) error {
+	for _, slo := range slos {
+		switch slo.Operation {
+		case "", "select", "insert", "update", "delete", "other", "read", "write":
+		default:
+			return fmt.Errorf("slo %s: unknown operation %q", slo.Name, slo.Operation)
+		}
+		if slo.Target <= 0 || slo.Target >= 1 {
+			return fmt.Errorf("slo %s: target %g is not between 0 and 1", slo.Name, slo.Target)
+		}
+		if slo.Window != 0 && slo.Window < time.Second {
+			return fmt.Errorf("slo %s: window %v is shorter than 1s", slo.Name, slo.Window)
+		}
+	}
+	return nil
+}
+
+func newSLOTracker(slo SLO) *sloTracker {
+	if slo.Window <= 0 {
+		slo.Window = time.Hour
+	}
+	if slo.BurnRate <= 0 {
+		slo.BurnRate = 14.4
+	}
+	return &sloTracker{slo: slo, width: slo.Window / sloBuckets}
+}
+
+// matches reports whether the SLO covers a statement; reads are selects and
+// writes everything else, as for Quota.
+func (t *sloTracker) matches(table, operation string) bool {
+	if t.slo.Table != "" && t.slo.Table != table {
+		return false
+	}
+	switch t.slo.Operation {
+	case "":
+		return true
+	case "read":
+		return operation == "select"
+	case "write":
+		return operation != "select"
+	}
+	return t.slo.Operation == operation
+}
+
+func (t *sloTracker) observe(now time.Time, d time.Duration, failed bool) {
+	good := !failed && (t.slo.Latency == 0 || d <= t.slo.Latency)
+	slot := now.UnixNano() / int64(t.width)
+
+	t.mu.Lock()
+	b := &t.buckets[slot%sloBuckets]
+	if b.slot != slot {
+		*b = sloBucket{slot: slot}
+	}
+	b.total++
+	if good {
+		b.good++
+	}
+	status := t.statusLocked(slot)
+	fire := status.Burning && !t.burning
+	t.burning = status.Burning
+	t.mu.Unlock()
+
+	if fire && t.slo.OnBurn != nil {
+		go t.slo.OnBurn(status)
+	}
+}
+
+func (t *sloTracker) status(now time.Time) SLOStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.statusLocked(now.UnixNano() / int64(t.width))
+}
+
+func (t *sloTracker) statusLocked(slot int64) SLOStatus {
+	var total, good, shortTotal, shortGood int64
+	for _, b := range t.buckets {
+		if age := slot - b.slot; age >= 0 && age < sloBuckets {
+			total += b.total
+			good += b.good
+			if age < sloShortBuckets {
+				shortTotal += b.total
+				shortGood += b.good
+			}
+		}
+	}
+	s := SLOStatus{
+		Name:          t.slo.Name,
+		Target:        t.slo.Target,
+		Total:         total,
+		Good:          good,
+		Compliance:    1,
+		BurnRate:      burnRate(total, good, t.slo.Target),
+		ShortBurnRate: burnRate(shortTotal, shortGood, t.slo.Target),
+	}
+	if total > 0 {
+		s.Compliance = float64(good) / float64(total)
+	}
+	s.Burning = shortTotal >= sloMinShortTotal && s.BurnRate >= t.slo.BurnRate && s.ShortBurnRate >= t.slo.BurnRate
+	return s
+}
+
+// burnRate is the error rate relative to the error budget, 1 - target.
+func burnRate(total, good int64, target float64) float64 {
+	if total == 0 || target >= 1 {
+		return 0
+	}
+	return float64(total-good) / float64(total) / (1 - target)
+}
+
+// SLOStatus returns the rolling compliance and burn rates of Config.SLOs.
+func (m *Manager) SLOStatus() []SLOStatus {
+	now := time.Now()
+	statuses := make([]SLOStatus, len(m.slos))
+	for i, t := range m.slos {
+		statuses[i] = t.status(now)
+	}
+	return statuses
+}
@@ -0,0 +1,77 @@
+package gormkit_test
+
+import (
+	"io"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestSLOBurnRate(t *testing.T) {
+	burns := make(chan gormkit.SLOStatus, 10)
+	manager, err := gormkit.New(&gormkit.Config{
+		Driver:   "test",
+		LogLevel: "silent",
+		SLOs: []gormkit.SLO{
+			{Name: "reads", Operation: "select", Latency: time.Minute, Target: 0.99,
+				OnBurn: func(s gormkit.SLOStatus) { burns <- s }},
+			{Name: "writes", Operation: "write", Target: 0.999},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+	db := manager.DB()
+	db.AutoMigrate(&User{})
+
+	for i := 0; i < 20; i++ {
+		db.Find(&[]User{})
+	}
+	if s := manager.SLOStatus()[0]; s.Total < 20 || s.Compliance != 1 || s.Burning {
+		t.Errorf("Expected a compliant SLO, got %+v", s)
+	}
+	for i := 0; i < 20; i++ {
+		db.Raw("SELECT * FROM missing_table").Scan(&[]User{})
+	}
+
+	select {
+	case s := <-burns:
+		if s.Name != "reads" || !s.Burning || s.BurnRate < 14.4 || s.Compliance > 0.9 {
+			t.Errorf("Unexpected burn: %+v", s)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected OnBurn to fire")
+	}
+	select {
+	case s := <-burns:
+		t.Errorf("Expected one alert while burning, got another: %+v", s)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if s := manager.SLOStatus()[1]; s.Name != "writes" || s.Good != s.Total || s.Compliance != 1 {
+		t.Errorf("Unexpected writes SLO: %+v", s)
+	}
+
+	rec := httptest.NewRecorder()
+	manager.MetricsHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+	if !strings.Contains(string(body), `gormkit_slo_compliance{slo="reads"} 0.5`) {
+		t.Errorf("Expected SLO compliance in metrics, got %s", body)
+	}
+}
+
+func TestSLOValidation(t *testing.T) {
+	for _, slo := range []gormkit.SLO{
+		{Name: "typo", Operation: "reads", Target: 0.99},
+		{Name: "tiny", Window: 10 * time.Nanosecond, Target: 0.99},
+		{Name: "untargeted"},
+		{Name: "perfect", Target: 1},
+	} {
+		if _, err := gormkit.New(&gormkit.Config{Driver: "test", LogLevel: "silent", SLOs: []gormkit.SLO{slo}}); err == nil {
+			t.Errorf("Expected an error for SLO %s", slo.Name)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package gormkit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gorm.io/gorm"
+)
+
+const snapshotSchema = "gormkit_snapshot"
+
+// Snapshot serializes a sqlite database, e.g. after expensive fixture setup,
+// so RestoreSnapshot can bring it back instantly. In-memory databases must be
+// shared by the pool (see NewTestManager) for the snapshot to see all data.
+func (m *Manager) Snapshot() ([]byte, error) {
+	if err := m.requireSQLite(); err != nil {
+		return nil, err
+	}
+
+	dir, err := os.MkdirTemp("", "gormkit-snapshot")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "snapshot.db")
+	if err := m.internalDB().Exec("VACUUM INTO ?", path).Error; err != nil {
+		return nil, fmt.Errorf("failed to snapshot: %w", err)
+	}
+	return os.ReadFile(path)
+}
+
+// RestoreSnapshot replaces every table, index, view and trigger of a sqlite
+// database with the contents of a Snapshot.
+func (m *Manager) RestoreSnapshot(snapshot []byte) error {
+	if err := m.requireSQLite(); err != nil {
+		return err
+	}
+
+	dir, err := os.MkdirTemp("", "gormkit-snapshot")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "snapshot.db")
+	if err := os.WriteFile(path, snapshot, 0o600); err != nil {
+		return err
+	}
+
+	// ATTACH and PRAGMA are per connection, so pin one for the whole restore.
+	err = m.internalDB().Connection(func(conn *gorm.DB) error {
+		var foreignKeys int
+		if err := conn.Raw("PRAGMA foreign_keys").Scan(&foreignKeys).Error; err != nil {
+			return err
+		}
+		if err := conn.Exec("PRAGMA foreign_keys = OFF").Error; err != nil {
+			return err
+		}
+		defer conn.Exec(fmt.Sprintf("PRAGMA foreign_keys = %d", foreignKeys))
+
+		if err := conn.Exec("ATTACH DATABASE ? AS "+snapshotSchema, path).Error; err != nil {
+			return err
+		}
+		defer conn.Exec("DETACH DATABASE " + snapshotSchema)
+
+		return conn.Transaction(func(tx *gorm.DB) error {
+			return restoreSchema(tx)
+		})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to restore snapshot: %w", err)
+	}
+	m.ClearStmtCache()
+	return nil
+}
+
+type sqliteObject struct {
+	Type string
+	Name string
+	SQL  string
+}
+
+func restoreSchema(tx *gorm.DB) error {
+	var current []sqliteObject
+	if err := tx.Raw("SELECT type, name FROM main.sqlite_master WHERE type IN ('table', 'view') AND name NOT LIKE 'sqlite_%'").
+		Scan(&current).Error; err != nil {
+		return err
+	}
+	for _, obj := range current {
+		// Dropping a table drops its indexes and triggers with it.
+		if err := tx.Exec(fmt.Sprintf("DROP %s main.%s", obj.Type, quoteIdent(obj.Name))).Error; err != nil {
+			return err
+		}
+	}
+
+	var objects []sqliteObject
+	if err := tx.Raw(`SELECT type, name, sql FROM ` + snapshotSchema + `.sqlite_master
+		WHERE sql IS NOT NULL AND name NOT LIKE 'sqlite_%'
+		ORDER BY CASE type WHEN 'table' THEN 0 WHEN 'index' THEN 1 ELSE 2 END`).
+		Scan(&objects).Error; err != nil {
+		return err
+	}
+
+	for _, obj := range objects {
+		if err := tx.Exec(obj.SQL).Error; err != nil {
+			return err
+		}
+		if obj.Type == "table" {
+			name := quoteIdent(obj.Name)
+			if err := tx.Exec(fmt.Sprintf("INSERT INTO main.%s SELECT * FROM %s.%s", name, snapshotSchema, name)).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	var hasSequence int64
+	tx.Raw("SELECT count(*) FROM " + snapshotSchema + ".sqlite_master WHERE name = 'sqlite_sequence'").Scan(&hasSequence)
+	if hasSequence > 0 {
+		if err := tx.Exec("DELETE FROM main.sqlite_sequence").Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("INSERT INTO main.sqlite_sequence SELECT * FROM " + snapshotSchema + ".sqlite_sequence").Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Manager) requireSQLite() error {
+	if m.config.Driver != "sqlite" && m.config.Driver != "test" {
+		return fmt.Errorf("%w: %s", ErrUnsupportedDriver, m.config.Driver)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package gormkit_test
+
+import (
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestSnapshotRestore(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &User{})
+	db := manager.DB()
+	db.Create(&User{Name: "Fixture"})
+
+	snapshot, err := manager.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db.Create(&User{Name: "Scratch"})
+	db.Exec("CREATE TABLE scratch (id INTEGER)")
+
+	if err := manager.RestoreSnapshot(snapshot); err != nil {
+		t.Fatal(err)
+	}
+
+	var users []User
+	db.Order("id").Find(&users)
+	if len(users) != 1 || users[0].Name != "Fixture" {
+		t.Errorf("Expected only the fixture user, got %+v", users)
+	}
+	if db.Migrator().HasTable("scratch") {
+		t.Error("Expected tables created after the snapshot to be dropped")
+	}
+	if err := db.Create(&User{Name: "Next"}).Error; err != nil {
+		t.Errorf("Create after restore failed: %v", err)
+	}
+}
@@ -0,0 +1,43 @@
+package gormkit
+
+import (
+	"context"
+	"database/sql"
+
+	"gorm.io/gorm"
+)
+
+// SnapshotRead runs fn in a read-only transaction whose queries all see
+// the database as of one point in time, without blocking writers, e.g. for
+// an export or report made of several queries. It uses REPEATABLE READ,
+// which is snapshot isolation on Postgres and MySQL; on MySQL the snapshot
+// is taken at the first read. On SQLite the transaction's reads see one
+// snapshot in WAL mode and block writers otherwise. Writes in fn fail.
+//
+//	err := manager.SnapshotRead(ctx, func(tx *gorm.DB) error {
+//		if err := tx.Find(&orders).Error; err != nil {
+//			return err
+//		}
+//		return tx.Find(&lineItems).Error // consistent with orders
+//	})
+func (m *Manager) SnapshotRead(ctx context.Context, fn func(*gorm.DB) error) error {
+	db := m.db.WithContext(ctx)
+	if flavorOf(db) == "sqlite" {
+		// The driver ignores transaction options; query_only is per
+		// connection, so it's turned off again before the connection
+		// returns to the pool.
+		return db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec("PRAGMA query_only = ON").Error; err != nil {
+				return err
+			}
+			defer tx.Exec("PRAGMA query_only = OFF")
+			return fn(tx)
+		})
+	}
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := m.applySessionSettings(ctx, tx); err != nil {
+			return err
+		}
+		return fn(tx)
+	}, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+}
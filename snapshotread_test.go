@@ -0,0 +1,34 @@
+package gormkit_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+	"gorm.io/gorm"
+)
+
+func TestSnapshotRead(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &User{})
+	db := manager.DB()
+	db.Create(&User{Name: "alice"})
+
+	var count int64
+	var writeErr error
+	err := manager.SnapshotRead(context.Background(), func(tx *gorm.DB) error {
+		writeErr = tx.Create(&User{Name: "bob"}).Error
+		return tx.Model(&User{}).Count(&count).Error
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if writeErr == nil || count != 1 {
+		t.Errorf("Expected the write to fail and 1 user, got %v and %d", writeErr, count)
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := db.Create(&User{Name: "carol"}).Error; err != nil {
+			t.Fatalf("Expected writes after the snapshot to work, got %v", err)
+		}
+	}
+}
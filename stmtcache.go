@@ -0,0 +1,78 @@
+package gormkit
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"gorm.io/gorm"
+)
+
+type StmtCacheStats struct {
+	Enabled bool
+	Size    int
+	Hits    uint64
+	Misses  uint64
+	HitRate float64
+}
+
+// stmtCache tracks which SQL strings were already prepared, since gorm's
+// statement store does not count hits itself. Statements evicted by size or
+// TTL are pruned on the next StmtCacheStats call, so counts are approximate.
+type stmtCache struct {
+	seen   sync.Map
+	hits   atomic.Uint64
+	misses atomic.Uint64
+}
+
+// StmtCacheStats reports the prepared statement cache size and hit rate.
+// Enabled is false unless Config.PrepareStmt is set.
+func (m *Manager) StmtCacheStats() StmtCacheStats {
+	pdb, ok := m.db.ConnPool.(*gorm.PreparedStmtDB)
+	if !ok {
+		return StmtCacheStats{}
+	}
+
+	keys := pdb.Stmts.Keys()
+	cached := make(map[string]struct{}, len(keys))
+	for _, key := range keys {
+		cached[key] = struct{}{}
+	}
+	m.stmts.seen.Range(func(key, _ interface{}) bool {
+		if _, ok := cached[key.(string)]; !ok {
+			m.stmts.seen.Delete(key)
+		}
+		return true
+	})
+
+	stats := StmtCacheStats{
+		Enabled: true,
+		Size:    len(keys),
+		Hits:    m.stmts.hits.Load(),
+		Misses:  m.stmts.misses.Load(),
+	}
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRate = float64(stats.Hits) / float64(total)
+	}
+	return stats
+}
+
+// ClearStmtCache closes and forgets every cached prepared statement, e.g.
+// after a schema change made them stale.
+func (m *Manager) ClearStmtCache() {
+	if pdb, ok := m.db.ConnPool.(*gorm.PreparedStmtDB); ok {
+		pdb.Close()
+	}
+	m.stmts.seen.Clear()
+}
+
+func (m *Manager) afterStmtCache(db *gorm.DB) {
+	key := db.Statement.SQL.String()
+	if key == "" || db.DryRun {
+		return
+	}
+	if _, loaded := m.stmts.seen.LoadOrStore(key, struct{}{}); loaded {
+		m.stmts.hits.Add(1)
+	} else {
+		m.stmts.misses.Add(1)
+	}
+}
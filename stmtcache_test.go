@@ -0,0 +1,56 @@
+package gormkit_test
+
+import (
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestStmtCacheStats(t *testing.T) {
+	manager, err := gormkit.New(&gormkit.Config{
+		Driver:      "test",
+		LogLevel:    "silent",
+		PrepareStmt: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	db := manager.DB()
+	db.AutoMigrate(&User{})
+
+	for i := 0; i < 3; i++ {
+		var users []User
+		db.Where("name = ?", "x").Find(&users)
+	}
+
+	stats := manager.StmtCacheStats()
+	if !stats.Enabled || stats.Size == 0 {
+		t.Fatalf("Expected populated cache, got %+v", stats)
+	}
+	if stats.Hits < 2 {
+		t.Errorf("Expected at least 2 hits, got %+v", stats)
+	}
+
+	manager.ClearStmtCache()
+
+	if stats := manager.StmtCacheStats(); stats.Size != 0 {
+		t.Errorf("Expected empty cache after clear, got %d", stats.Size)
+	}
+}
+
+func TestStmtCacheDisabled(t *testing.T) {
+	manager, err := gormkit.New(&gormkit.Config{
+		Driver:   "test",
+		LogLevel: "silent",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	if stats := manager.StmtCacheStats(); stats.Enabled {
+		t.Errorf("Cache should be disabled without PrepareStmt: %+v", stats)
+	}
+}
@@ -0,0 +1,132 @@
+package gormkit
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"gorm.io/gorm"
+)
+
+// Template is a text/template for dynamic SQL in which every value printed by
+// an action becomes a bound parameter, so data can never be spliced into the
+// query text. Use {{in .IDs}} to expand a slice into an IN list:
+//
+//	SELECT * FROM orders WHERE 1=1
+//	{{if .Status}} AND status = {{.Status}}{{end}}
+//	{{if .IDs}} AND id IN {{in .IDs}}{{end}}
+type Template struct {
+	tmpl *template.Template
+}
+
+type sqlFragment string
+
+var templateFuncs = template.FuncMap{
+	"bind": func(interface{}) string { return "" },
+	"in":   func(interface{}) sqlFragment { return "" },
+}
+
+func NewTemplate(name, text string) (*Template, error) {
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tmpl.Templates() {
+		if t.Tree != nil {
+			bindActions(t.Tree, t.Tree.Root)
+		}
+	}
+	return &Template{tmpl: tmpl}, nil
+}
+
+func MustTemplate(name, text string) *Template {
+	t, err := NewTemplate(name, text)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// Build renders the template with data and returns the SQL with ?
+// placeholders and the values bound to them.
+func (t *Template) Build(data interface{}) (string, []interface{}, error) {
+	var args []interface{}
+	tmpl, err := t.tmpl.Clone()
+	if err != nil {
+		return "", nil, err
+	}
+	tmpl.Funcs(template.FuncMap{
+		"bind": func(v interface{}) string {
+			if f, ok := v.(sqlFragment); ok {
+				return string(f)
+			}
+			args = append(args, v)
+			return "?"
+		},
+		"in": func(v interface{}) (sqlFragment, error) {
+			rv := reflect.ValueOf(v)
+			if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+				return "", fmt.Errorf("in: expected a slice, got %T", v)
+			}
+			if rv.Len() == 0 {
+				return "(NULL)", nil
+			}
+			placeholders := make([]string, rv.Len())
+			for i := range placeholders {
+				placeholders[i] = "?"
+				args = append(args, rv.Index(i).Interface())
+			}
+			return sqlFragment("(" + strings.Join(placeholders, ", ") + ")"), nil
+		},
+	})
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", nil, err
+	}
+	return strings.TrimSpace(sb.String()), args, nil
+}
+
+// Raw renders the template and returns db.Raw for the result.
+func (t *Template) Raw(db *gorm.DB, data interface{}) *gorm.DB {
+	sqlText, args, err := t.Build(data)
+	if err != nil {
+		db = db.Session(&gorm.Session{})
+		db.AddError(err)
+		return db
+	}
+	return db.Raw(sqlText, args...)
+}
+
+// bindActions pipes the output of every printing action through bind.
+func bindActions(tree *parse.Tree, node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			bindActions(tree, child)
+		}
+	case *parse.ActionNode:
+		if len(n.Pipe.Decl) == 0 {
+			bind := parse.NewIdentifier("bind").SetTree(tree).SetPos(n.Pos)
+			n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{
+				NodeType: parse.NodeCommand,
+				Pos:      n.Pos,
+				Args:     []parse.Node{bind},
+			})
+		}
+	case *parse.IfNode:
+		bindActions(tree, n.List)
+		bindActions(tree, n.ElseList)
+	case *parse.RangeNode:
+		bindActions(tree, n.List)
+		bindActions(tree, n.ElseList)
+	case *parse.WithNode:
+		bindActions(tree, n.List)
+		bindActions(tree, n.ElseList)
+	}
+}
@@ -0,0 +1,67 @@
+package gormkit_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+var usersReport = gormkit.MustTemplate("users", `
+	SELECT * FROM users WHERE 1=1
+	{{if .Name}} AND name = {{.Name}}{{end}}
+	{{if .IDs}} AND id IN {{in .IDs}}{{end}}
+	ORDER BY id
+`)
+
+func TestTemplateBuild(t *testing.T) {
+	sqlText, args, err := usersReport.Build(map[string]interface{}{
+		"Name": "x' OR '1'='1",
+		"IDs":  []int{1, 2, 3},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(sqlText, "OR '1'='1") {
+		t.Errorf("Value was interpolated into SQL: %s", sqlText)
+	}
+	if !strings.Contains(sqlText, "name = ?") || !strings.Contains(sqlText, "id IN (?, ?, ?)") {
+		t.Errorf("Unexpected SQL: %s", sqlText)
+	}
+	if len(args) != 4 {
+		t.Errorf("Expected 4 args, got %v", args)
+	}
+}
+
+func TestTemplateRaw(t *testing.T) {
+	manager, err := gormkit.New(&gormkit.Config{
+		Driver:   "test",
+		LogLevel: "silent",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	db := manager.WithContext(context.Background())
+	db.AutoMigrate(&User{})
+	db.Create(&[]User{{Name: "A"}, {Name: "B"}, {Name: "C"}})
+
+	var users []User
+	if err := usersReport.Raw(db, map[string]interface{}{"IDs": []uint{1, 3}}).Scan(&users).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 2 || users[1].Name != "C" {
+		t.Errorf("Unexpected users: %+v", users)
+	}
+
+	users = nil
+	if err := usersReport.Raw(db, map[string]interface{}{"IDs": []uint{}}).Scan(&users).Error; err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 3 {
+		t.Errorf("Empty IN list should skip the filter, got %d users", len(users))
+	}
+}
@@ -0,0 +1,269 @@
+package gormkit
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+const (
+	historySuffix   = "_history"
+	temporalIDsKey  = "gormkit:temporal_ids"
+	validFromColumn = "valid_from"
+	validToColumn   = "valid_to"
+)
+
+// Temporal, embedded in a model, keeps every version of its rows in a
+// <table>_history table with valid_from/valid_to columns, so past states can
+// be read with AsOf. The model needs a single-column primary key, and writes
+// must go through gorm (raw SQL bypasses the history).
+type Temporal struct{}
+
+func (Temporal) temporal() {}
+
+type temporalModel interface {
+	temporal()
+}
+
+var temporalType = reflect.TypeFor[temporalModel]()
+
+func isTemporal(s *schema.Schema) bool {
+	return s != nil && reflect.PointerTo(s.ModelType).Implements(temporalType)
+}
+
+// AsOf is a scope that reads a Temporal model as it was at t:
+//
+//	db.Scopes(gormkit.AsOf(invoiceDate)).Where("customer_id = ?", id).Find(&plans)
+func AsOf(t time.Time) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		model := db.Statement.Model
+		if model == nil {
+			model = db.Statement.Dest
+		}
+		if err := db.Statement.Parse(model); err != nil {
+			db.AddError(err)
+			return db
+		}
+		if !isTemporal(db.Statement.Schema) {
+			db.AddError(fmt.Errorf("%s does not embed gormkit.Temporal", db.Statement.Schema.Name))
+			return db
+		}
+		// Versions are stamped with NowFunc; compare in its zone, since sqlite
+		// compares timestamps as text.
+		t := t.In(db.NowFunc().Location())
+		table := db.Statement.Schema.Table + historySuffix
+		return db.Table(table).Where(
+			clause.Lte{Column: clause.Column{Table: table, Name: validFromColumn}, Value: t}).Where(
+			clause.Or(
+				clause.Eq{Column: clause.Column{Table: table, Name: validToColumn}, Value: nil},
+				clause.Gt{Column: clause.Column{Table: table, Name: validToColumn}, Value: t},
+			))
+	}
+}
+
+// migrateHistory creates or extends the history table of every Temporal
+// model with the columns of its main table.
+func (m *Manager) migrateHistory(models ...interface{}) error {
+	db := m.internalDB()
+	for _, model := range models {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return err
+		}
+		if !isTemporal(stmt.Schema) {
+			continue
+		}
+		if len(stmt.Schema.PrimaryFields) != 1 {
+			return fmt.Errorf("temporal model %s needs a single-column primary key", stmt.Schema.Name)
+		}
+
+		table := stmt.Schema.Table
+		history := table + historySuffix
+		migrator := db.Migrator()
+		if !migrator.HasTable(history) {
+			err := db.Exec(fmt.Sprintf("CREATE TABLE %s AS SELECT * FROM %s WHERE 1 = 0",
+				quoteIdentFor(db, history), quoteIdentFor(db, table))).Error
+			if err != nil {
+				return fmt.Errorf("failed to create %s: %w", history, err)
+			}
+		}
+
+		columns, err := migrator.ColumnTypes(table)
+		if err != nil {
+			return err
+		}
+		existing, err := migrator.ColumnTypes(history)
+		if err != nil {
+			return err
+		}
+		have := make(map[string]bool, len(existing))
+		for _, c := range existing {
+			have[c.Name()] = true
+		}
+
+		timestamp := "timestamp"
+		switch flavorOf(db) {
+		case "postgres":
+			timestamp = "timestamptz"
+		case "mysql":
+			timestamp = "datetime(6)"
+		case "sqlite":
+			timestamp = "datetime"
+		}
+		add := func(name, typ string) error {
+			if have[name] {
+				return nil
+			}
+			return db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s",
+				quoteIdentFor(db, history), quoteIdentFor(db, name), typ)).Error
+		}
+		for _, c := range columns {
+			if err := add(c.Name(), c.DatabaseTypeName()); err != nil {
+				return fmt.Errorf("failed to add %s to %s: %w", c.Name(), history, err)
+			}
+		}
+		if err := add(validFromColumn, timestamp); err != nil {
+			return err
+		}
+		if err := add(validToColumn, timestamp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *Manager) registerTemporal() error {
+	cb := m.db.Callback()
+	return errors.Join(
+		cb.Create().After("gorm:create").Register("gormkit:temporal_create", m.afterTemporalCreate),
+		cb.Update().Before("gorm:update").Register("gormkit:temporal_before_update", m.beforeTemporalChange),
+		cb.Update().After("gorm:update").Register("gormkit:temporal_update", m.afterTemporalUpdate),
+		cb.Delete().Before("gorm:delete").Register("gormkit:temporal_before_delete", m.beforeTemporalChange),
+		cb.Delete().After("gorm:delete").Register("gormkit:temporal_delete", m.afterTemporalDelete),
+	)
+}
+
+func (m *Manager) afterTemporalCreate(db *gorm.DB) {
+	if db.Error != nil || !isTemporal(db.Statement.Schema) || db.RowsAffected == 0 {
+		return
+	}
+	ids := modelIDs(db)
+	if len(ids) > 0 {
+		db.AddError(openVersions(db, ids))
+	}
+}
+
+// beforeTemporalChange records which rows an update or delete is about to
+// touch, since the WHERE clause may no longer match them afterwards.
+func (m *Manager) beforeTemporalChange(db *gorm.DB) {
+	if db.Error != nil || !isTemporal(db.Statement.Schema) {
+		return
+	}
+	pk := db.Statement.Schema.PrioritizedPrimaryField
+	ids := modelIDs(db)
+
+	where, hasWhere := db.Statement.Clauses["WHERE"]
+	if hasWhere || (len(ids) == 0 && db.AllowGlobalUpdate) {
+		var matched []interface{}
+		tx := db.Session(&gorm.Session{NewDB: true}).Table(db.Statement.Table)
+		if hasWhere {
+			tx.Statement.Clauses["WHERE"] = where
+		}
+		if len(ids) > 0 {
+			tx = tx.Where(clause.IN{Column: clause.Column{Name: pk.DBName}, Values: ids})
+		}
+		if err := tx.Pluck(pk.DBName, &matched).Error; err != nil {
+			db.AddError(err)
+			return
+		}
+		ids = matched
+	}
+	db.InstanceSet(temporalIDsKey, ids)
+}
+
+func (m *Manager) afterTemporalUpdate(db *gorm.DB) {
+	ids, ok := temporalIDs(db)
+	if !ok {
+		return
+	}
+	if err := closeVersions(db, ids); err != nil {
+		db.AddError(err)
+		return
+	}
+	db.AddError(openVersions(db, ids))
+}
+
+func (m *Manager) afterTemporalDelete(db *gorm.DB) {
+	if ids, ok := temporalIDs(db); ok {
+		db.AddError(closeVersions(db, ids))
+	}
+}
+
+func temporalIDs(db *gorm.DB) ([]interface{}, bool) {
+	if db.Error != nil || db.RowsAffected == 0 {
+		return nil, false
+	}
+	v, ok := db.InstanceGet(temporalIDsKey)
+	if !ok {
+		return nil, false
+	}
+	ids := v.([]interface{})
+	return ids, len(ids) > 0
+}
+
+// modelIDs returns the non-zero primary keys of the statement's model value.
+func modelIDs(db *gorm.DB) []interface{} {
+	pk := db.Statement.Schema.PrioritizedPrimaryField
+	if pk == nil {
+		return nil
+	}
+	ctx := db.Statement.Context
+	var ids []interface{}
+	rv := db.Statement.ReflectValue
+	switch rv.Kind() {
+	case reflect.Struct:
+		if id, zero := pk.ValueOf(ctx, rv); !zero {
+			ids = append(ids, id)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < rv.Len(); i++ {
+			if id, zero := pk.ValueOf(ctx, reflect.Indirect(rv.Index(i))); !zero {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+func openVersions(db *gorm.DB, ids []interface{}) error {
+	s := db.Statement.Schema
+	columns := make([]string, len(s.DBNames))
+	for i, name := range s.DBNames {
+		columns[i] = quoteIdentFor(db, name)
+	}
+	list := strings.Join(columns, ", ")
+	sql := fmt.Sprintf("INSERT INTO %s (%s, %s) SELECT %s, ? FROM %s WHERE %s IN ?",
+		quoteIdentFor(db, s.Table+historySuffix), list, quoteIdentFor(db, validFromColumn),
+		list, quoteIdentFor(db, s.Table), quoteIdentFor(db, s.PrioritizedPrimaryField.DBName))
+	return db.Session(&gorm.Session{NewDB: true}).Exec(sql, db.NowFunc(), ids).Error
+}
+
+func closeVersions(db *gorm.DB, ids []interface{}) error {
+	s := db.Statement.Schema
+	sql := fmt.Sprintf("UPDATE %s SET %s = ? WHERE %s IN ? AND %s IS NULL",
+		quoteIdentFor(db, s.Table+historySuffix), quoteIdentFor(db, validToColumn),
+		quoteIdentFor(db, s.PrioritizedPrimaryField.DBName), quoteIdentFor(db, validToColumn))
+	return db.Session(&gorm.Session{NewDB: true}).Exec(sql, db.NowFunc(), ids).Error
+}
+
+func quoteIdentFor(db *gorm.DB, name string) string {
+	var b strings.Builder
+	db.Dialector.QuoteTo(&b, name)
+	return b.String()
+}
@@ -0,0 +1,81 @@
+package gormkit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+type Plan struct {
+	gormkit.Temporal
+	ID    uint `gorm:"primarykey"`
+	Name  string
+	Price int
+}
+
+func TestTemporalAsOf(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &Plan{})
+	db := manager.DB()
+
+	if !db.Migrator().HasTable("plans_history") {
+		t.Fatal("Expected history table")
+	}
+
+	plan := Plan{Name: "basic", Price: 10}
+	db.Create(&plan)
+	time.Sleep(5 * time.Millisecond)
+	afterCreate := time.Now()
+	time.Sleep(5 * time.Millisecond)
+
+	db.Model(&plan).Update("price", 20)
+	time.Sleep(5 * time.Millisecond)
+	afterUpdate := time.Now()
+	time.Sleep(5 * time.Millisecond)
+
+	db.Where("name = ?", "basic").Updates(&Plan{Price: 30})
+	time.Sleep(5 * time.Millisecond)
+	afterMassUpdate := time.Now()
+	time.Sleep(5 * time.Millisecond)
+
+	db.Delete(&plan)
+
+	for _, tc := range []struct {
+		at    time.Time
+		price int
+		found bool
+	}{
+		{afterCreate, 10, true},
+		{afterUpdate, 20, true},
+		{afterMassUpdate, 30, true},
+		{time.Now(), 0, false},
+	} {
+		var plans []Plan
+		if err := db.Scopes(gormkit.AsOf(tc.at)).Find(&plans).Error; err != nil {
+			t.Fatal(err)
+		}
+		if !tc.found {
+			if len(plans) != 0 {
+				t.Errorf("Expected no plan after delete, got %+v", plans)
+			}
+			continue
+		}
+		if len(plans) != 1 || plans[0].Price != tc.price {
+			t.Errorf("Expected price %d as of %v, got %+v", tc.price, tc.at, plans)
+		}
+	}
+
+	var versions int64
+	db.Table("plans_history").Count(&versions)
+	if versions != 3 {
+		t.Errorf("Expected 3 versions, got %d", versions)
+	}
+}
+
+func TestAsOfRequiresTemporal(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &User{})
+	var users []User
+	if err := manager.DB().Scopes(gormkit.AsOf(time.Now())).Find(&users).Error; err == nil {
+		t.Error("Expected error for non-temporal model")
+	}
+}
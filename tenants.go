@@ -0,0 +1,180 @@
+package gormkit
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+)
+
+// TenantPools opens one Manager per tenant database on demand and keeps at
+// most a fixed number open, closing the least recently used beyond that.
+type TenantPools struct {
+	config func(ctx context.Context, tenant string) (*Config, error)
+	max    int
+
+	mu        sync.Mutex
+	pools     map[string]*list.Element // of *tenantPool
+	lru       *list.List               // most recently used first
+	opening   map[string]*tenantOpen
+	evictions uint64
+	closed    bool
+}
+
+type tenantPool struct {
+	tenant  string
+	manager *Manager
+}
+
+type tenantOpen struct {
+	done    chan struct{}
+	manager *Manager
+	err     error
+}
+
+// TenantPoolStats aggregates the pools of a TenantPools.
+type TenantPoolStats struct {
+	Pools           int
+	Evictions       uint64
+	OpenConnections int
+	InUse           int
+	Idle            int
+	WaitCount       int64
+}
+
+// NewTenantPools returns pools keeping at most max tenants open. config
+// returns the configuration of a tenant's database, e.g. from a tenant
+// registry; use SealedDSN for per-tenant connection strings.
+//
+//	pools := gormkit.NewTenantPools(50, func(ctx context.Context, tenant string) (*gormkit.Config, error) {
+//		return registry.DatabaseConfig(ctx, tenant)
+//	})
+//	m, err := pools.For(ctx, tenantID)
+func NewTenantPools(max int, config func(ctx context.Context, tenant string) (*Config, error)) *TenantPools {
+	return &TenantPools{
+		config:  config,
+		max:     max,
+		pools:   make(map[string]*list.Element),
+		lru:     list.New(),
+		opening: make(map[string]*tenantOpen),
+	}
+}
+
+// For returns the Manager of tenant, opening it on first use. An evicted
+// Manager is closed once its running queries finish, so don't keep the
+// result beyond a request; keep max above the number of tenants served
+// concurrently.
+func (p *TenantPools) For(ctx context.Context, tenant string) (*Manager, error) {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return nil, errors.New("tenant pools are closed")
+	}
+	if el, ok := p.pools[tenant]; ok {
+		p.lru.MoveToFront(el)
+		p.mu.Unlock()
+		return el.Value.(*tenantPool).manager, nil
+	}
+	if op, ok := p.opening[tenant]; ok {
+		p.mu.Unlock()
+		select {
+		case <-op.done:
+			return op.manager, op.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	op := &tenantOpen{done: make(chan struct{})}
+	p.opening[tenant] = op
+	p.mu.Unlock()
+
+	op.manager, op.err = p.open(ctx, tenant)
+
+	p.mu.Lock()
+	delete(p.opening, tenant)
+	var evicted []*tenantPool
+	if op.err == nil {
+		if p.closed {
+			op.manager.Close()
+			op.manager, op.err = nil, errors.New("tenant pools are closed")
+		} else {
+			p.pools[tenant] = p.lru.PushFront(&tenantPool{tenant: tenant, manager: op.manager})
+			for p.max > 0 && p.lru.Len() > p.max {
+				oldest := p.lru.Remove(p.lru.Back()).(*tenantPool)
+				delete(p.pools, oldest.tenant)
+				p.evictions++
+				evicted = append(evicted, oldest)
+			}
+		}
+	}
+	p.mu.Unlock()
+	close(op.done)
+
+	for _, pool := range evicted {
+		go func(pool *tenantPool) {
+			if err := pool.manager.Close(); err != nil {
+				log.Printf("gormkit: closing pool of tenant %s: %v", pool.tenant, err)
+			}
+		}(pool)
+	}
+	return op.manager, op.err
+}
+
+func (p *TenantPools) open(ctx context.Context, tenant string) (*Manager, error) {
+	cfg, err := p.config(ctx, tenant)
+	if err != nil {
+		return nil, fmt.Errorf("tenant %s: %w", tenant, err)
+	}
+	m, err := New(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("tenant %s: %w", tenant, err)
+	}
+	return m, nil
+}
+
+// Tenants lists the tenants with an open pool, most recently used first.
+func (p *TenantPools) Tenants() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	tenants := make([]string, 0, p.lru.Len())
+	for el := p.lru.Front(); el != nil; el = el.Next() {
+		tenants = append(tenants, el.Value.(*tenantPool).tenant)
+	}
+	return tenants
+}
+
+// Stats sums the connection pool stats of all open tenants.
+func (p *TenantPools) Stats() TenantPoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	stats := TenantPoolStats{Pools: p.lru.Len(), Evictions: p.evictions}
+	for el := p.lru.Front(); el != nil; el = el.Next() {
+		s := el.Value.(*tenantPool).manager.Stats()
+		stats.OpenConnections += s.OpenConnections
+		stats.InUse += s.InUse
+		stats.Idle += s.Idle
+		stats.WaitCount += s.WaitCount
+	}
+	return stats
+}
+
+// Close closes every tenant's Manager.
+func (p *TenantPools) Close() error {
+	p.mu.Lock()
+	p.closed = true
+	var managers []*Manager
+	for el := p.lru.Front(); el != nil; el = el.Next() {
+		managers = append(managers, el.Value.(*tenantPool).manager)
+	}
+	p.pools = make(map[string]*list.Element)
+	p.lru.Init()
+	p.mu.Unlock()
+
+	var errs []error
+	for _, m := range managers {
+		errs = append(errs, m.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,79 @@
+package gormkit_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestTenantPools(t *testing.T) {
+	var opened atomic.Int32
+	pools := gormkit.NewTenantPools(2, func(ctx context.Context, tenant string) (*gormkit.Config, error) {
+		opened.Add(1)
+		return &gormkit.Config{
+			Driver:   "test",
+			LogLevel: "silent",
+			Database: fmt.Sprintf("file:tenant_%s?mode=memory&cache=shared", tenant),
+		}, nil
+	})
+	defer pools.Close()
+	ctx := context.Background()
+
+	// Concurrent first use opens the tenant once.
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := pools.For(ctx, "acme"); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if opened.Load() != 1 {
+		t.Errorf("Expected 1 open, got %d", opened.Load())
+	}
+
+	acme, _ := pools.For(ctx, "acme")
+	if err := acme.DB().Exec("CREATE TABLE notes (body TEXT)").Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := pools.For(ctx, "globex"); err != nil {
+		t.Fatal(err)
+	}
+	pools.For(ctx, "acme") // acme is now the most recently used
+	if _, err := pools.For(ctx, "initech"); err != nil {
+		t.Fatal(err)
+	}
+
+	if tenants := pools.Tenants(); !reflect.DeepEqual(tenants, []string{"initech", "acme"}) {
+		t.Errorf("Expected globex to be evicted, got %v", tenants)
+	}
+	stats := pools.Stats()
+	if stats.Pools != 2 || stats.Evictions != 1 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+
+	// Tenants have separate databases.
+	initech, _ := pools.For(ctx, "initech")
+	if initech.DB().Migrator().HasTable("notes") {
+		t.Error("Expected initech not to see acme's table")
+	}
+}
+
+func TestTenantPoolsConfigError(t *testing.T) {
+	pools := gormkit.NewTenantPools(2, func(ctx context.Context, tenant string) (*gormkit.Config, error) {
+		return nil, fmt.Errorf("unknown tenant")
+	})
+	defer pools.Close()
+
+	if _, err := pools.For(context.Background(), "nobody"); err == nil || err.Error() != "tenant nobody: unknown tenant" {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
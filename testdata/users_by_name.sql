@@ -0,0 +1,2 @@
+SELECT * FROM `users` WHERE name = ? ORDER BY id LIMIT 10;
+UPDATE `users` SET `name`=? WHERE id = ?;
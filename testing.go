@@ -0,0 +1,108 @@
+package gormkit
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+var (
+	testDBSeq atomic.Int64
+
+	// createDBMu serializes CREATE DATABASE ... TEMPLATE, which fails when
+	// another copy of the same template is in progress.
+	createDBMu sync.Mutex
+)
+
+// NewTestManager returns a Manager on a private in-memory sqlite database,
+// migrated with models and closed when the test ends. Every call gets its own
+// database, so tests (including parallel ones) never see each other's rows.
+func NewTestManager(t testing.TB, models ...interface{}) *Manager {
+	t.Helper()
+
+	name := strings.NewReplacer("/", "_", " ", "_", "?", "_", "&", "_", "#", "_").Replace(t.Name())
+	m, err := New(&Config{
+		Driver:      "test",
+		Database:    fmt.Sprintf("file:%s_%d?mode=memory&cache=shared", name, testDBSeq.Add(1)),
+		LogLevel:    "silent",
+		AutoMigrate: true,
+	})
+	if err != nil {
+		t.Fatalf("gormkit: %v", err)
+	}
+
+	// A shared in-memory database lives only while a connection is open, so
+	// pin one for the whole test; pool recycling would otherwise wipe it.
+	conn, err := m.sqlDB.Conn(context.Background())
+	if err != nil {
+		m.Close()
+		t.Fatalf("gormkit: %v", err)
+	}
+	t.Cleanup(func() {
+		conn.Close()
+		m.Close()
+	})
+
+	if len(models) > 0 {
+		if err := m.Migrate(models...); err != nil {
+			t.Fatalf("gormkit: %v", err)
+		}
+	}
+	return m
+}
+
+// NewTestDatabase creates a throwaway Postgres database copied from template
+// (CREATE DATABASE ... TEMPLATE) and returns a Manager bound to it; the
+// database is dropped when the test ends. m is used only to issue the
+// CREATE and DROP and must not be connected to template, which in turn must
+// have no open connections - migrate it once in TestMain, then close it.
+func NewTestDatabase(t testing.TB, m *Manager, template string) *Manager {
+	t.Helper()
+
+	if m.config.Driver != "postgres" {
+		t.Fatalf("gormkit: %v: template databases need postgres", ErrUnsupportedDriver)
+		return nil
+	}
+
+	name := fmt.Sprintf("gormkit_test_%d_%d", os.Getpid(), testDBSeq.Add(1))
+	createDBMu.Lock()
+	err := m.internalDB().Exec(fmt.Sprintf("CREATE DATABASE %s TEMPLATE %s", quoteIdent(name), quoteIdent(template))).Error
+	createDBMu.Unlock()
+	if err != nil {
+		t.Fatalf("gormkit: failed to create test database: %v", err)
+		return nil
+	}
+
+	drop := func() {
+		sql := "DROP DATABASE IF EXISTS " + quoteIdent(name)
+		if m.info.AtLeast(13, 0) {
+			sql += " WITH (FORCE)"
+		}
+		if err := m.internalDB().Exec(sql).Error; err != nil {
+			t.Errorf("gormkit: failed to drop test database %s: %v", name, err)
+		}
+	}
+
+	cfg := *m.config
+	cfg.Database = name
+	cfg.Profile = ""
+	db, err := New(&cfg)
+	if err != nil {
+		drop()
+		t.Fatalf("gormkit: %v", err)
+		return nil
+	}
+	t.Cleanup(func() {
+		db.Close()
+		drop()
+	})
+	return db
+}
+
+func quoteIdent(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
@@ -0,0 +1,50 @@
+package gormkit_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestNewTestManager(t *testing.T) {
+	for _, name := range []string{"first", "second"} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			manager := gormkit.NewTestManager(t, &User{})
+			db := manager.DB()
+
+			if err := db.Create(&User{Name: name}).Error; err != nil {
+				t.Fatal(err)
+			}
+
+			var count int64
+			db.Model(&User{}).Count(&count)
+			if count != 1 {
+				t.Errorf("Expected an isolated database with 1 user, got %d", count)
+			}
+		})
+	}
+}
+
+type fatalRecorder struct {
+	testing.TB
+	fatal string
+}
+
+func (r *fatalRecorder) Fatalf(format string, args ...interface{}) {
+	r.fatal = fmt.Sprintf(format, args...)
+}
+
+func TestNewTestDatabaseRequiresPostgres(t *testing.T) {
+	manager := gormkit.NewTestManager(t)
+	rec := &fatalRecorder{TB: t}
+
+	if db := gormkit.NewTestDatabase(rec, manager, "app_template"); db != nil {
+		t.Error("Expected no manager for sqlite")
+	}
+	if !strings.Contains(rec.fatal, "not supported") {
+		t.Errorf("Expected unsupported driver failure, got %q", rec.fatal)
+	}
+}
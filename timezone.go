@@ -0,0 +1,46 @@
+package gormkit
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// mysqlTimeZone returns the session time_zone matching loc. Zones without
+// daylight saving time get their fixed offset, which works even when the
+// server's time zone tables are not loaded; others need the tables
+// (mysql_tzinfo_to_sql) to be set by name.
+func mysqlTimeZone(loc *time.Location) string {
+	year := time.Now().Year()
+	jan := time.Date(year, time.January, 1, 0, 0, 0, 0, loc)
+	jul := time.Date(year, time.July, 1, 0, 0, 0, 0, loc)
+	_, janOffset := jan.Zone()
+	_, julOffset := jul.Zone()
+	if janOffset != julOffset {
+		return loc.String()
+	}
+	sign := '+'
+	if janOffset < 0 {
+		sign, janOffset = '-', -janOffset
+	}
+	return fmt.Sprintf("%c%02d:%02d", sign, janOffset/3600, janOffset%3600/60)
+}
+
+// dsnTimezone returns the zone a driver DSN makes the connection use: the
+// TimeZone parameter for Postgres, loc for MySQL (UTC when unset). It
+// returns "" when the DSN does not say or cannot be parsed.
+func dsnTimezone(driver, dsn string) string {
+	switch driver {
+	case "postgres":
+		if pc, err := pgconn.ParseConfig(dsn); err == nil {
+			return pc.RuntimeParams["timezone"]
+		}
+	case "mysql":
+		if mc, err := mysql.ParseDSN(dsn); err == nil && mc.Loc != nil {
+			return mc.Loc.String()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,353 @@
+package gormkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/schema"
+)
+
+// Tree configures a model stored as an adjacency list, each row pointing to
+// its parent.
+type Tree struct {
+	ParentColumn string // default "parent_id"; NULL or zero for roots
+	MaxDepth     int    // levels followed, guarding against cycles; default 100
+
+	// ClosureTable, when set, names a table holding every ancestor and
+	// descendant pair, kept up to date as rows are created and deleted and
+	// used by Descendants and Ancestors instead of recursive queries.
+	// Create and fill it with RebuildClosure.
+	ClosureTable string
+}
+
+// TreeNode is implemented by hierarchical models, e.g. categories or org
+// charts, that need more than the Tree defaults.
+//
+//	func (Category) Tree() gormkit.Tree {
+//		return gormkit.Tree{ClosureTable: "category_paths"}
+//	}
+type TreeNode interface {
+	Tree() Tree
+}
+
+// TreePath is a row of a closure table.
+type TreePath struct {
+	AncestorID   uint64 `gorm:"primaryKey;autoIncrement:false"`
+	DescendantID uint64 `gorm:"primaryKey;autoIncrement:false;index"`
+	Depth        int
+}
+
+// Descendants returns all descendants of the T row with primary key id,
+// level by level. It uses the closure table if configured, a recursive CTE
+// where the server supports it, and one query per level otherwise.
+//
+//	children, err := gormkit.Descendants[Category](ctx, db, root.ID)
+func Descendants[T any](ctx context.Context, db *gorm.DB, id interface{}) ([]T, error) {
+	return walkTree[T](ctx, db, id, true)
+}
+
+// Ancestors returns the ancestors of the T row with primary key id, from its
+// parent up to the root.
+func Ancestors[T any](ctx context.Context, db *gorm.DB, id interface{}) ([]T, error) {
+	return walkTree[T](ctx, db, id, false)
+}
+
+type treeSchema struct {
+	tree   Tree
+	table  string
+	pk     *schema.Field
+	parent *schema.Field
+}
+
+func parseTree(db *gorm.DB, model interface{}) (*treeSchema, error) {
+	stmt := &gorm.Statement{DB: db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, err
+	}
+	ts := &treeSchema{table: stmt.Schema.Table, pk: stmt.Schema.PrioritizedPrimaryField}
+	if node, ok := model.(TreeNode); ok {
+		ts.tree = node.Tree()
+	}
+	if ts.tree.ParentColumn == "" {
+		ts.tree.ParentColumn = "parent_id"
+	}
+	if ts.tree.MaxDepth <= 0 {
+		ts.tree.MaxDepth = 100
+	}
+	if ts.pk == nil {
+		return nil, fmt.Errorf("%s needs a single primary key", ts.table)
+	}
+	if ts.parent = stmt.Schema.LookUpField(ts.tree.ParentColumn); ts.parent == nil {
+		return nil, fmt.Errorf("%s has no column %s", ts.table, ts.tree.ParentColumn)
+	}
+	return ts, nil
+}
+
+func walkTree[T any](ctx context.Context, db *gorm.DB, id interface{}, down bool) ([]T, error) {
+	db = db.WithContext(ctx)
+	ts, err := parseTree(db, new(T))
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []interface{}
+	switch {
+	case ts.tree.ClosureTable != "":
+		ids, err = ts.closureIDs(db, id, down)
+	case Supports(db, FeatureRecursiveCTE):
+		ids, err = ts.cteIDs(db, id, down)
+	default:
+		ids, err = ts.levelIDs(ctx, db, id, down)
+	}
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	// Load the rows through gorm, so scopes such as soft deletes apply, and
+	// put them back in tree order.
+	rows, err := FindIn[T](ctx, db, ts.pk.DBName, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]T, len(rows))
+	for _, row := range rows {
+		v, _ := ts.pk.ValueOf(ctx, reflect.ValueOf(row))
+		byID[idKey(v)] = row
+	}
+	ordered := make([]T, 0, len(rows))
+	for _, id := range ids {
+		if row, ok := byID[idKey(id)]; ok {
+			ordered = append(ordered, row)
+		}
+	}
+	return ordered, nil
+}
+
+func (ts *treeSchema) closureIDs(db *gorm.DB, id interface{}, down bool) ([]interface{}, error) {
+	from, to := "ancestor_id", "descendant_id"
+	if !down {
+		from, to = to, from
+	}
+	var ids []interface{}
+	err := db.Table(ts.tree.ClosureTable).
+		Where(clause.Eq{Column: clause.Column{Name: from}, Value: id}).Where("depth > 0").
+		Order("depth").Order(to).Pluck(to, &ids).Error
+	return ids, err
+}
+
+func (ts *treeSchema) cteIDs(db *gorm.DB, id interface{}, down bool) ([]interface{}, error) {
+	q := db.Statement.Quote
+	table, pk, parent := q(ts.table), q(ts.pk.DBName), q(ts.parent.DBName)
+	var sql string
+	if down {
+		sql = fmt.Sprintf(`WITH RECURSIVE tree (node, depth) AS (
+	SELECT %[2]s, 1 FROM %[1]s WHERE %[3]s = ?
+	UNION ALL
+	SELECT t.%[2]s, tree.depth + 1 FROM %[1]s t JOIN tree ON t.%[3]s = tree.node WHERE tree.depth < ?
+)
+SELECT node FROM tree ORDER BY depth, node`, table, pk, parent)
+	} else {
+		sql = fmt.Sprintf(`WITH RECURSIVE tree (node, parent, depth) AS (
+	SELECT %[2]s, %[3]s, 0 FROM %[1]s WHERE %[2]s = ?
+	UNION ALL
+	SELECT t.%[2]s, t.%[3]s, tree.depth + 1 FROM %[1]s t JOIN tree ON t.%[2]s = tree.parent WHERE tree.depth < ?
+)
+SELECT node FROM tree WHERE depth > 0 ORDER BY depth`, table, pk, parent)
+	}
+	var ids []interface{}
+	rows, err := db.Raw(sql, id, ts.tree.MaxDepth).Rows()
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id interface{}
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// levelIDs walks the tree one query per level, for servers without
+// recursive CTEs such as MySQL 5.7.
+func (ts *treeSchema) levelIDs(ctx context.Context, db *gorm.DB, id interface{}, down bool) ([]interface{}, error) {
+	var ids []interface{}
+	frontier := []interface{}{id}
+	for depth := 0; depth < ts.tree.MaxDepth && len(frontier) > 0; depth++ {
+		var next []interface{}
+		var err error
+		if down {
+			err = InChunks(db, frontier, func(chunk []interface{}) error {
+				var ids []interface{}
+				err := db.Table(ts.table).Where(clause.IN{Column: clause.Column{Name: ts.parent.DBName}, Values: chunk}).
+					Order(ts.pk.DBName).Pluck(ts.pk.DBName, &ids).Error
+				next = append(next, ids...)
+				return err
+			})
+		} else {
+			err = db.Table(ts.table).Where(clause.Eq{Column: clause.Column{Name: ts.pk.DBName}, Value: frontier[0]}).
+				Where(clause.Neq{Column: clause.Column{Name: ts.parent.DBName}, Value: nil}).
+				Pluck(ts.parent.DBName, &next).Error
+		}
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, next...)
+		frontier = next
+	}
+	return ids, nil
+}
+
+func idKey(id interface{}) string {
+	if b, ok := id.([]byte); ok {
+		return string(b)
+	}
+	return fmt.Sprint(id)
+}
+
+// RebuildClosure creates the closure table of T if needed and refills it
+// from the parent links, e.g. initially or after moving subtrees, which the
+// create and delete hooks don't track.
+func RebuildClosure[T any](ctx context.Context, db *gorm.DB) error {
+	db = db.WithContext(ctx)
+	ts, err := parseTree(db, new(T))
+	if err != nil {
+		return err
+	}
+	if ts.tree.ClosureTable == "" {
+		return fmt.Errorf("%s has no closure table", ts.table)
+	}
+	if err := db.Table(ts.tree.ClosureTable).AutoMigrate(&TreePath{}); err != nil {
+		return err
+	}
+
+	var links []struct{ ID, ParentID *uint64 }
+	if err := db.Table(ts.table).Select(fmt.Sprintf("%s AS id, %s AS parent_id",
+		db.Statement.Quote(ts.pk.DBName), db.Statement.Quote(ts.parent.DBName))).Scan(&links).Error; err != nil {
+		return err
+	}
+	parents := make(map[uint64]uint64, len(links))
+	for _, l := range links {
+		if l.ParentID != nil && *l.ParentID != 0 {
+			parents[*l.ID] = *l.ParentID
+		}
+	}
+	var paths []TreePath
+	for _, l := range links {
+		paths = append(paths, TreePath{AncestorID: *l.ID, DescendantID: *l.ID})
+		node := *l.ID
+		for depth := 1; depth <= ts.tree.MaxDepth; depth++ {
+			parent, ok := parents[node]
+			if !ok {
+				break
+			}
+			paths = append(paths, TreePath{AncestorID: parent, DescendantID: *l.ID, Depth: depth})
+			node = parent
+		}
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Table(ts.tree.ClosureTable).Where("1 = 1").Delete(&TreePath{}).Error; err != nil {
+			return err
+		}
+		if len(paths) == 0 {
+			return nil
+		}
+		return tx.Table(ts.tree.ClosureTable).CreateInBatches(paths, 500).Error
+	})
+}
+
+// closureTrees caches the treeSchema of models with a closure table, or nil.
+var closureTrees sync.Map // reflect.Type -> *treeSchema
+
+func closureTree(db *gorm.DB) *treeSchema {
+	if db.Error != nil || db.Statement.Schema == nil {
+		return nil
+	}
+	typ := db.Statement.Schema.ModelType
+	if ts, ok := closureTrees.Load(typ); ok {
+		return ts.(*treeSchema)
+	}
+	var ts *treeSchema
+	if node, ok := reflect.New(typ).Interface().(TreeNode); ok && node.Tree().ClosureTable != "" {
+		ts, _ = parseTree(db, node)
+	}
+	closureTrees.Store(typ, ts)
+	return ts
+}
+
+// afterCreateClosure adds the paths of created rows to their closure table.
+func afterCreateClosure(db *gorm.DB) {
+	ts := closureTree(db)
+	if ts == nil {
+		return
+	}
+	tx := db.Session(&gorm.Session{NewDB: true, SkipHooks: true})
+	q := db.Statement.Quote
+	closure := q(ts.tree.ClosureTable)
+	eachRow(db.Statement.ReflectValue, func(row reflect.Value) {
+		if db.Error != nil {
+			return
+		}
+		id, _ := ts.pk.ValueOf(db.Statement.Context, row)
+		parent, isRoot := ts.parent.ValueOf(db.Statement.Context, row)
+		if rv := reflect.ValueOf(parent); rv.Kind() == reflect.Pointer && !rv.IsNil() {
+			parent = rv.Elem().Interface()
+		}
+		err := tx.Exec(fmt.Sprintf("INSERT INTO %s (ancestor_id, descendant_id, depth) VALUES (?, ?, 0)", closure), id, id).Error
+		if err == nil && !isRoot {
+			err = tx.Exec(fmt.Sprintf(`INSERT INTO %[1]s (ancestor_id, descendant_id, depth)
+SELECT ancestor_id, ?, depth + 1 FROM %[1]s WHERE descendant_id = ?`, closure), id, parent).Error
+		}
+		if err != nil {
+			db.AddError(fmt.Errorf("failed to update closure table %s: %w", ts.tree.ClosureTable, err))
+		}
+	})
+}
+
+// afterDeleteClosure removes the paths of deleted rows, when deleted by
+// primary key.
+func afterDeleteClosure(db *gorm.DB) {
+	ts := closureTree(db)
+	if ts == nil {
+		return
+	}
+	tx := db.Session(&gorm.Session{NewDB: true, SkipHooks: true})
+	eachRow(db.Statement.ReflectValue, func(row reflect.Value) {
+		id, zero := ts.pk.ValueOf(db.Statement.Context, row)
+		if zero || db.Error != nil {
+			return
+		}
+		if err := tx.Table(ts.tree.ClosureTable).Where("descendant_id = ? OR ancestor_id = ?", id, id).
+			Delete(&TreePath{}).Error; err != nil {
+			db.AddError(fmt.Errorf("failed to update closure table %s: %w", ts.tree.ClosureTable, err))
+		}
+	})
+}
+
+func eachRow(v reflect.Value, fn func(reflect.Value)) {
+	v = reflect.Indirect(v)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			fn(reflect.Indirect(v.Index(i)))
+		}
+	case reflect.Struct:
+		fn(v)
+	}
+}
+
+func registerClosureHooks(db *gorm.DB) error {
+	cb := db.Callback()
+	return errors.Join(
+		cb.Create().After("gorm:create").Register("gormkit:closure_after_create", afterCreateClosure),
+		cb.Delete().After("gorm:delete").Register("gormkit:closure_after_delete", afterDeleteClosure),
+	)
+}
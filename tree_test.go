@@ -0,0 +1,143 @@
+package gormkit_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+type Category struct {
+	ID       uint
+	ParentID *uint
+	Name     string
+}
+
+type Department struct {
+	ID       uint
+	ParentID *uint
+	Name     string
+}
+
+func (Department) Tree() gormkit.Tree {
+	return gormkit.Tree{ClosureTable: "department_paths"}
+}
+
+func names[T any](rows []T, name func(T) string) []string {
+	var out []string
+	for _, row := range rows {
+		out = append(out, name(row))
+	}
+	return out
+}
+
+func TestDescendantsAndAncestors(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &Category{})
+	db := manager.DB()
+	ctx := context.Background()
+
+	root := Category{Name: "root"}
+	db.Create(&root)
+	books := Category{Name: "books", ParentID: &root.ID}
+	music := Category{Name: "music", ParentID: &root.ID}
+	db.Create(&books)
+	db.Create(&music)
+	novels := Category{Name: "novels", ParentID: &books.ID}
+	db.Create(&novels)
+
+	categoryName := func(c Category) string { return c.Name }
+	descendants, err := gormkit.Descendants[Category](ctx, db, root.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(descendants, categoryName); len(got) != 3 || got[0] != "books" || got[1] != "music" || got[2] != "novels" {
+		t.Errorf("Unexpected descendants: %v", got)
+	}
+
+	ancestors, err := gormkit.Ancestors[Category](ctx, db, novels.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(ancestors, categoryName); len(got) != 2 || got[0] != "books" || got[1] != "root" {
+		t.Errorf("Unexpected ancestors: %v", got)
+	}
+}
+
+func TestClosureTable(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &Department{})
+	db := manager.DB()
+	ctx := context.Background()
+
+	root := Department{Name: "company"}
+	db.Create(&root)
+	if err := gormkit.RebuildClosure[Department](ctx, db); err != nil {
+		t.Fatal(err)
+	}
+
+	eng := Department{Name: "engineering", ParentID: &root.ID}
+	db.Create(&eng)
+	platform := Department{Name: "platform", ParentID: &eng.ID}
+	db.Create(&platform)
+
+	departmentName := func(d Department) string { return d.Name }
+	descendants, err := gormkit.Descendants[Department](ctx, db, root.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(descendants, departmentName); len(got) != 2 || got[0] != "engineering" || got[1] != "platform" {
+		t.Errorf("Unexpected descendants: %v", got)
+	}
+
+	db.Delete(&platform)
+	ancestors, err := gormkit.Ancestors[Department](ctx, db, eng.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(ancestors, departmentName); len(got) != 1 || got[0] != "company" {
+		t.Errorf("Unexpected ancestors: %v", got)
+	}
+	var paths int64
+	db.Table("department_paths").Count(&paths)
+	if paths != 3 {
+		t.Errorf("Expected 3 paths after the delete, got %d", paths)
+	}
+}
+
+func TestDescendantsWithoutRecursiveCTE(t *testing.T) {
+	// A db not opened by a Manager has no known server version, so the tree
+	// is walked one level at a time.
+	db, err := gorm.Open(sqlite.Open("file:tree_levels?mode=memory&cache=shared"), &gorm.Config{
+		Logger: logger.Discard,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.AutoMigrate(&Category{})
+	ctx := context.Background()
+
+	root := Category{Name: "root"}
+	db.Create(&root)
+	child := Category{Name: "child", ParentID: &root.ID}
+	db.Create(&child)
+	grandchild := Category{Name: "grandchild", ParentID: &child.ID}
+	db.Create(&grandchild)
+
+	categoryName := func(c Category) string { return c.Name }
+	descendants, err := gormkit.Descendants[Category](ctx, db, root.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(descendants, categoryName); len(got) != 2 || got[0] != "child" || got[1] != "grandchild" {
+		t.Errorf("Unexpected descendants: %v", got)
+	}
+	ancestors, err := gormkit.Ancestors[Category](ctx, db, grandchild.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(ancestors, categoryName); len(got) != 2 || got[0] != "child" || got[1] != "root" {
+		t.Errorf("Unexpected ancestors: %v", got)
+	}
+}
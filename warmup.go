@@ -0,0 +1,63 @@
+package gormkit
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+)
+
+// warmup opens Config.WarmupConnections pooled connections at once and runs
+// Config.WarmupQueries on as many connections concurrently, so that with
+// PrepareStmt each connection has them prepared before traffic arrives.
+func (m *Manager) warmup(ctx context.Context) error {
+	n := min(m.config.WarmupConnections, m.config.MaxOpenConns)
+	if n <= 0 {
+		return nil
+	}
+	if n > m.config.MaxIdleConns {
+		log.Printf("gormkit: only %d of %d warm-up connections stay open, raise MaxIdleConns", m.config.MaxIdleConns, n)
+	}
+
+	conns := make([]*sql.Conn, 0, n)
+	defer func() {
+		for _, conn := range conns {
+			conn.Close()
+		}
+	}()
+	for i := 0; i < n; i++ {
+		conn, err := m.sqlDB.Conn(ctx)
+		if err != nil {
+			return fmt.Errorf("warm-up failed: %w", err)
+		}
+		conns = append(conns, conn)
+		if err := conn.PingContext(ctx); err != nil {
+			return fmt.Errorf("warm-up failed: %w", err)
+		}
+	}
+	for _, conn := range conns {
+		conn.Close()
+	}
+	conns = nil
+
+	db := m.db.WithContext(ctx)
+	for _, query := range m.config.WarmupQueries {
+		var wg sync.WaitGroup
+		errs := make([]error, n)
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				var discard []map[string]interface{}
+				errs[i] = db.Raw(query).Scan(&discard).Error
+			}(i)
+		}
+		wg.Wait()
+		if err := errors.Join(errs...); err != nil {
+			return fmt.Errorf("warm-up query %q failed: %w", query, err)
+		}
+	}
+	return nil
+}
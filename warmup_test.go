@@ -0,0 +1,42 @@
+package gormkit_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+func TestWarmupConnections(t *testing.T) {
+	manager, err := gormkit.New(&gormkit.Config{
+		Driver:            "test",
+		LogLevel:          "silent",
+		MaxIdleConns:      3,
+		WarmupConnections: 3,
+		WarmupQueries:     []string{"SELECT 1"},
+		PrepareStmt:       true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer manager.Close()
+
+	if stats := manager.Stats(); stats.OpenConnections != 3 || stats.Idle != 3 {
+		t.Errorf("Expected 3 idle connections, got %+v", stats)
+	}
+	if stats := manager.StmtCacheStats(); stats.Size != 1 {
+		t.Errorf("Expected the warm-up query to be prepared, got %+v", stats)
+	}
+}
+
+func TestWarmupQueryError(t *testing.T) {
+	_, err := gormkit.New(&gormkit.Config{
+		Driver:            "test",
+		LogLevel:          "silent",
+		WarmupConnections: 2,
+		WarmupQueries:     []string{"SELECT * FROM missing_table"},
+	})
+	if err == nil || !strings.Contains(err.Error(), "warm-up query") {
+		t.Errorf("Expected a warm-up error, got %v", err)
+	}
+}
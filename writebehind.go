@@ -0,0 +1,251 @@
+package gormkit
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WriteBehindOptions configures a WriteBehind.
+type WriteBehindOptions struct {
+	FlushInterval time.Duration // default 5s
+	MaxPending    int           // rows held in memory, default 10000; updates beyond are dropped
+	MaxAttempts   int           // flushes a row's updates are tried in before they are dropped, default 3
+
+	// OnError receives errors of background flushes; they are logged
+	// otherwise.
+	OnError func(err error)
+}
+
+// WriteBehindStats counts the rows handled by a WriteBehind.
+type WriteBehindStats struct {
+	Pending int   // rows waiting for the next flush
+	Flushed int64 // rows written
+	Failed  int64 // flushes that failed and were retried
+	Lost    int64 // row updates dropped, over MaxPending, failing MaxAttempts times or at close
+}
+
+// WriteBehind coalesces updates to one table in memory and writes them
+// periodically in a single transaction, for low-criticality columns such as
+// view counters or last_seen_at where one write per event is too costly.
+// Pending updates are flushed on Close and Manager close; updates still
+// pending when the process dies are lost, so use it only where that is
+// acceptable.
+type WriteBehind struct {
+	manager *Manager
+	table   string
+	pk      string
+	opts    WriteBehindOptions
+
+	flushMu sync.Mutex // serializes flushes, so a row's updates land in order
+	mu      sync.Mutex
+	pending map[interface{}]*pendingRow
+	stats   WriteBehindStats
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+type pendingRow struct {
+	set      map[string]interface{}
+	inc      map[string]int64
+	attempts int // failed flushes so far
+}
+
+// WriteBehind returns a write-behind buffer for the table of model, keyed
+// by its primary key.
+//
+//	seen, err := manager.WriteBehind(&User{}, gormkit.WriteBehindOptions{})
+//	seen.Set(user.ID, "last_seen_at", time.Now())
+//	seen.Increment(user.ID, "logins", 1)
+func (m *Manager) WriteBehind(model interface{}, opts WriteBehindOptions) (*WriteBehind, error) {
+	stmt := &gorm.Statement{DB: m.db}
+	if err := stmt.Parse(model); err != nil {
+		return nil, err
+	}
+	pk, err := primaryKey(m.db, model)
+	if err != nil {
+		return nil, err
+	}
+	if opts.FlushInterval <= 0 {
+		opts.FlushInterval = 5 * time.Second
+	}
+	if opts.MaxPending <= 0 {
+		opts.MaxPending = 10000
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 3
+	}
+	w := &WriteBehind{
+		manager: m,
+		table:   stmt.Schema.Table,
+		pk:      pk,
+		opts:    opts,
+		pending: make(map[interface{}]*pendingRow),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go w.run()
+	m.onClose(func() { w.Close() })
+	return w, nil
+}
+
+// Set sets column of the row with primary key id; the last value set before
+// a flush wins.
+func (w *WriteBehind) Set(id interface{}, column string, value interface{}) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if row := w.row(id); row != nil {
+		row.set[column] = value
+	}
+}
+
+// Increment adds n to column of the row with primary key id; increments are
+// summed until the next flush.
+func (w *WriteBehind) Increment(id interface{}, column string, n int64) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if row := w.row(id); row != nil {
+		row.inc[column] += n
+	}
+}
+
+// row returns the pending row of id, or nil when MaxPending is reached.
+func (w *WriteBehind) row(id interface{}) *pendingRow {
+	if row, ok := w.pending[id]; ok {
+		return row
+	}
+	if len(w.pending) >= w.opts.MaxPending {
+		w.lose(1)
+		return nil
+	}
+	row := &pendingRow{set: make(map[string]interface{}), inc: make(map[string]int64)}
+	w.pending[id] = row
+	return row
+}
+
+func (w *WriteBehind) lose(n int) {
+	w.stats.Lost += int64(n)
+	for i := 0; i < n; i++ {
+		w.manager.metrics.inc("gormkit_write_behind_lost_total", "Write-behind row updates dropped before reaching the database.",
+			"table", w.table)
+	}
+}
+
+// Flush writes the pending updates in one transaction. On failure they are
+// kept, merged with newer updates, for the next flush, except those that
+// have now failed MaxAttempts times, which are dropped and counted as lost.
+func (w *WriteBehind) Flush(ctx context.Context) error {
+	w.flushMu.Lock()
+	defer w.flushMu.Unlock()
+	w.mu.Lock()
+	rows := w.pending
+	w.pending = make(map[interface{}]*pendingRow)
+	w.mu.Unlock()
+	if len(rows) == 0 {
+		return nil
+	}
+
+	err := w.manager.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for id, row := range rows {
+			values := make(map[string]interface{}, len(row.set)+len(row.inc))
+			for column, value := range row.set {
+				values[column] = value
+			}
+			for column, n := range row.inc {
+				values[column] = incrementExpr(column, n)
+			}
+			if err := tx.Table(w.table).Where(clause.Eq{Column: clause.Column{Name: w.pk}, Value: id}).
+				Updates(values).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err == nil {
+		w.stats.Flushed += int64(len(rows))
+		return nil
+	}
+	w.stats.Failed++
+	dropped := 0
+	for id, old := range rows {
+		if old.attempts+1 >= w.opts.MaxAttempts {
+			dropped++
+			continue
+		}
+		row := w.row(id)
+		if row == nil {
+			continue
+		}
+		row.attempts = max(row.attempts, old.attempts+1)
+		for column, value := range old.set {
+			if _, newer := row.set[column]; !newer {
+				row.set[column] = value
+			}
+		}
+		for column, n := range old.inc {
+			row.inc[column] += n
+		}
+	}
+	if dropped > 0 {
+		w.lose(dropped)
+		return fmt.Errorf("write-behind flush of %s failed %d times, dropped %d rows: %w", w.table, w.opts.MaxAttempts, dropped, err)
+	}
+	return fmt.Errorf("write-behind flush of %s failed: %w", w.table, err)
+}
+
+// Stats returns the counts so far.
+func (w *WriteBehind) Stats() WriteBehindStats {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	stats := w.stats
+	stats.Pending = len(w.pending)
+	return stats
+}
+
+// Close stops the periodic flushes and flushes the pending updates; those
+// failing to flush are counted as lost.
+func (w *WriteBehind) Close() error {
+	var err error
+	w.closeOnce.Do(func() {
+		close(w.stop)
+		<-w.done
+		if err = w.Flush(withoutDeadlineAudit(context.Background())); err != nil {
+			w.mu.Lock()
+			w.lose(len(w.pending))
+			w.pending = make(map[interface{}]*pendingRow)
+			w.mu.Unlock()
+			log.Printf("gormkit: %v", err)
+		}
+	})
+	return err
+}
+
+func (w *WriteBehind) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.opts.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.stop:
+			return
+		case <-ticker.C:
+			if err := w.Flush(withoutDeadlineAudit(context.Background())); err != nil {
+				if w.opts.OnError != nil {
+					w.opts.OnError(err)
+				} else {
+					log.Printf("gormkit: %v", err)
+				}
+			}
+		}
+	}
+}
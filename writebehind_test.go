@@ -0,0 +1,104 @@
+package gormkit_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alinemone/gorm-kit"
+)
+
+type Article struct {
+	ID         uint
+	Title      string
+	Views      int64
+	LastSeenAt time.Time
+}
+
+func TestWriteBehind(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &Article{})
+	article := Article{Title: "hello"}
+	manager.DB().Create(&article)
+
+	wb, err := manager.WriteBehind(&Article{}, gormkit.WriteBehindOptions{FlushInterval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	seen := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 10; i++ {
+		wb.Increment(article.ID, "views", 2)
+	}
+	wb.Set(article.ID, "last_seen_at", seen.Add(-time.Hour))
+	wb.Set(article.ID, "last_seen_at", seen)
+
+	if stats := wb.Stats(); stats.Pending != 1 {
+		t.Errorf("Expected 1 pending row, got %+v", stats)
+	}
+	if err := wb.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	var got Article
+	manager.DB().First(&got, article.ID)
+	if got.Views != 20 || !got.LastSeenAt.Equal(seen) {
+		t.Errorf("Unexpected row: %+v", got)
+	}
+	if stats := wb.Stats(); stats.Pending != 0 || stats.Flushed != 1 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+func TestWriteBehindFailure(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &Article{})
+	article := Article{Title: "hello"}
+	manager.DB().Create(&article)
+
+	wb, err := manager.WriteBehind(&Article{}, gormkit.WriteBehindOptions{FlushInterval: time.Hour, MaxPending: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wb.Increment(article.ID, "missing", 1)
+	wb.Increment(article.ID+1, "views", 1) // over MaxPending
+
+	if err := wb.Flush(context.Background()); err == nil {
+		t.Fatal("Expected the flush to fail")
+	}
+	if stats := wb.Stats(); stats.Pending != 1 || stats.Failed != 1 || stats.Lost != 1 {
+		t.Errorf("Expected the failed row to be kept, got %+v", stats)
+	}
+
+	if err := wb.Close(); err == nil {
+		t.Fatal("Expected the final flush to fail")
+	}
+	if stats := wb.Stats(); stats.Pending != 0 || stats.Lost != 2 {
+		t.Errorf("Expected the row to be lost at close, got %+v", stats)
+	}
+}
+
+func TestWriteBehindMaxAttempts(t *testing.T) {
+	manager := gormkit.NewTestManager(t, &Article{})
+	article := Article{Title: "hello"}
+	manager.DB().Create(&article)
+
+	wb, err := manager.WriteBehind(&Article{}, gormkit.WriteBehindOptions{FlushInterval: time.Hour, MaxPending: 1, MaxAttempts: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	wb.Increment(article.ID, "missing", 1)
+	wb.Flush(context.Background())
+	if err := wb.Flush(context.Background()); err == nil {
+		t.Fatal("Expected the second flush to fail")
+	}
+	if stats := wb.Stats(); stats.Pending != 0 || stats.Failed != 2 || stats.Lost != 1 {
+		t.Errorf("Expected the row to be dropped, got %+v", stats)
+	}
+
+	// The slot is free again.
+	wb.Increment(article.ID, "views", 1)
+	if err := wb.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if stats := wb.Stats(); stats.Flushed != 1 || stats.Lost != 1 {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}